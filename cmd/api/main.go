@@ -7,6 +7,7 @@ import (
 	"libmngmt/internal/config"
 	"libmngmt/internal/database"
 	"libmngmt/internal/handlers"
+	"libmngmt/internal/metadata"
 	"libmngmt/internal/middleware"
 	"libmngmt/internal/repository"
 	"libmngmt/internal/service"
@@ -43,6 +44,13 @@ func main() {
 
 	// Initialize repositories
 	bookRepo := repository.NewBookRepository(db)
+	memberRepo := repository.NewMemberRepository(db)
+	loanRepo := repository.NewLoanRepository(db)
+	holdRepo := repository.NewHoldRepository(db)
+	copyRepo := repository.NewCopyRepository(db)
+	fineRepo := repository.NewFineRepository(db)
+	savedSearchRepo := repository.NewSavedSearchRepository(db)
+	idempotencyRepo := repository.NewIdempotencyRepository(db)
 
 	// Initialize Redis cache
 	var bookCache *cache.BookCache
@@ -67,22 +75,67 @@ func main() {
 
 	// Create enhanced components
 	workerPool := workers.NewBookProcessor(10, 100)
+	workerPool.Start()
+
+	metadataProvider, err := newMetadataProvider(cfg.Metadata)
+	if err != nil {
+		log.Fatalf("Failed to initialize metadata provider: %v", err)
+	}
 
 	// Initialize enhanced services
-	bookService := service.NewBookService(bookRepo, bookCache, workerPool)
+	webhookClient := service.NewWebhookClient(time.Duration(cfg.Alerts.WebhookTimeoutSeconds) * time.Second)
+	savedSearchService := service.NewSavedSearchService(savedSearchRepo, webhookClient)
+	bookService := service.NewBookService(bookRepo, bookCache, workerPool, metadataProvider, savedSearchService)
+	memberService := service.NewMemberService(memberRepo)
+	circulationPolicy := service.CirculationPolicy{
+		LoanPeriod:       time.Duration(cfg.Library.LoanPeriodDays) * 24 * time.Hour,
+		HoldPickupWindow: time.Duration(cfg.Library.HoldPickupDays) * 24 * time.Hour,
+	}
+	copyService := service.NewCopyService(copyRepo, bookRepo, holdRepo, bookCache, workerPool, circulationPolicy)
+	loanService := service.NewLoanService(loanRepo, memberRepo, holdRepo, bookCache, workerPool, circulationPolicy)
+	holdService := service.NewHoldService(holdRepo, memberRepo, bookCache, workerPool, circulationPolicy)
+	fineService := service.NewFineService(fineRepo, memberRepo, finePolicy(cfg.Fines))
+
+	// Schedule background sweeps
+	holdSweepInterval := time.Duration(cfg.Library.HoldSweepIntervalMinutes) * time.Minute
+	workerPool.Schedule(holdSweepInterval, func() workers.BookJob {
+		return service.HoldExpiryJob(holdService)
+	})
+	fineSweepInterval := time.Duration(cfg.Fines.SweepIntervalMinutes) * time.Minute
+	workerPool.Schedule(fineSweepInterval, func() workers.BookJob {
+		return service.FineAccrualJob(fineService)
+	})
+	trashRetention := time.Duration(cfg.Trash.RetentionDays) * 24 * time.Hour
+	trashPurgeInterval := time.Duration(cfg.Trash.PurgeIntervalMinutes) * time.Minute
+	workerPool.Schedule(trashPurgeInterval, func() workers.BookJob {
+		return service.TrashPurgeJob(bookService, trashRetention)
+	})
+	idempotencyPurgeInterval := time.Duration(cfg.Idempotency.PurgeIntervalMinutes) * time.Minute
+	workerPool.Schedule(idempotencyPurgeInterval, func() workers.BookJob {
+		return service.IdempotencyKeyPurgeJob(idempotencyRepo)
+	})
 
 	// Initialize enhanced handlers
 	bookHandler := handlers.NewBookHandler(bookService)
+	memberHandler := handlers.NewMemberHandler(memberService)
+	loanHandler := handlers.NewLoanHandler(loanService)
+	holdHandler := handlers.NewHoldHandler(holdService)
+	copyHandler := handlers.NewCopyHandler(copyService)
+	fineHandler := handlers.NewFineHandler(fineService)
+	isbnHandler := handlers.NewISBNHandler()
+	savedSearchHandler := handlers.NewSavedSearchHandler(savedSearchService)
 
 	// Setup routes
-	router := setupRoutes(bookHandler)
+	router := setupRoutes(bookHandler, memberHandler, loanHandler, holdHandler, copyHandler, fineHandler, isbnHandler, savedSearchHandler)
 
 	// Setup middleware
 	router.Use(middleware.RecoveryMiddleware)
 	router.Use(middleware.RequestIDMiddleware)
+	router.Use(middleware.ActorMiddleware)
 	router.Use(middleware.LoggingMiddleware)
 	router.Use(middleware.CORSMiddleware)
 	router.Use(middleware.JSONMiddleware)
+	router.Use(middleware.IdempotencyMiddleware(idempotencyRepo, time.Duration(cfg.Idempotency.TTLHours)*time.Hour))
 
 	// Setup HTTP server with graceful shutdown
 	addr := fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.Port)
@@ -138,7 +191,7 @@ func main() {
 	log.Println("Server stopped")
 }
 
-func setupRoutes(bookHandler *handlers.BookHandler) *mux.Router {
+func setupRoutes(bookHandler *handlers.BookHandler, memberHandler *handlers.MemberHandler, loanHandler *handlers.LoanHandler, holdHandler *handlers.HoldHandler, copyHandler *handlers.CopyHandler, fineHandler *handlers.FineHandler, isbnHandler *handlers.ISBNHandler, savedSearchHandler *handlers.SavedSearchHandler) *mux.Router {
 	router := mux.NewRouter()
 
 	// API routes
@@ -147,12 +200,60 @@ func setupRoutes(bookHandler *handlers.BookHandler) *mux.Router {
 	// Book routes
 	api.HandleFunc("/books", bookHandler.GetBooks).Methods("GET")
 	api.HandleFunc("/books", bookHandler.CreateBook).Methods("POST")
+	api.HandleFunc("/books/search", bookHandler.SearchBooks).Methods("GET")
+	api.HandleFunc("/books/autocomplete", bookHandler.AutocompleteBooks).Methods("GET")
+	api.HandleFunc("/books/trash", bookHandler.GetTrash).Methods("GET")
 	api.HandleFunc("/books/{id}", bookHandler.GetBook).Methods("GET")
 	api.HandleFunc("/books/{id}", bookHandler.UpdateBook).Methods("PUT")
+	api.HandleFunc("/books/{id}", bookHandler.PatchBook).Methods("PATCH")
 	api.HandleFunc("/books/{id}", bookHandler.DeleteBook).Methods("DELETE")
+	api.HandleFunc("/books/{id}/restore", bookHandler.RestoreBook).Methods("POST")
+	api.HandleFunc("/books/{id}/history", bookHandler.GetBookHistory).Methods("GET")
+	api.HandleFunc("/books/{id}/as-of", bookHandler.GetBookAsOf).Methods("GET")
+	api.HandleFunc("/books/{id}/revisions/{rev}/revert", bookHandler.RevertBook).Methods("POST")
 	api.HandleFunc("/books/bulk", bookHandler.BulkCreateBooks).Methods("POST")
+	api.HandleFunc("/books/enrich", bookHandler.EnrichBook).Methods("POST")
 	api.HandleFunc("/books/metrics", bookHandler.GetMetrics).Methods("GET")
 
+	// Copy (item) routes
+	api.HandleFunc("/books/{id}/copies", copyHandler.GetBookCopies).Methods("GET")
+	api.HandleFunc("/books/{id}/copies", copyHandler.AddCopy).Methods("POST")
+	api.HandleFunc("/copies/{id}", copyHandler.GetCopy).Methods("GET")
+	api.HandleFunc("/copies/{id}", copyHandler.UpdateCopy).Methods("PUT")
+	api.HandleFunc("/copies/{id}", copyHandler.DeleteCopy).Methods("DELETE")
+
+	// Circulation routes
+	api.HandleFunc("/books/{id}/checkout", loanHandler.CheckoutBook).Methods("POST")
+	api.HandleFunc("/books/{id}/return", loanHandler.ReturnBook).Methods("POST")
+	api.HandleFunc("/books/{id}/holds", holdHandler.GetBookHolds).Methods("GET")
+	api.HandleFunc("/books/{id}/holds", holdHandler.PlaceHold).Methods("POST")
+	api.HandleFunc("/holds/{id}", holdHandler.GetHold).Methods("GET")
+	api.HandleFunc("/holds/{id}", holdHandler.CancelHold).Methods("DELETE")
+
+	// Member routes
+	api.HandleFunc("/members", memberHandler.GetMembers).Methods("GET")
+	api.HandleFunc("/members", memberHandler.CreateMember).Methods("POST")
+	api.HandleFunc("/members/{id}", memberHandler.GetMember).Methods("GET")
+	api.HandleFunc("/members/{id}", memberHandler.UpdateMember).Methods("PUT")
+	api.HandleFunc("/members/{id}", memberHandler.DeleteMember).Methods("DELETE")
+	api.HandleFunc("/members/{id}/loans", loanHandler.GetMemberLoans).Methods("GET")
+
+	// ISBN helper routes
+	api.HandleFunc("/isbn/{isbn}/validate", isbnHandler.ValidateISBN).Methods("GET")
+
+	// Fine ledger routes; a borrower is identified by member ID
+	api.HandleFunc("/borrowers/{id}/fines", fineHandler.GetFines).Methods("GET")
+	api.HandleFunc("/borrowers/{id}/fines/payments", fineHandler.RecordPayment).Methods("POST")
+	api.HandleFunc("/borrowers/{id}/fines/waivers", fineHandler.WaiveFine).Methods("POST")
+
+	// Saved search and new-arrival alert routes
+	api.HandleFunc("/saved-searches", savedSearchHandler.GetSavedSearches).Methods("GET")
+	api.HandleFunc("/saved-searches", savedSearchHandler.CreateSavedSearch).Methods("POST")
+	api.HandleFunc("/saved-searches/{id}", savedSearchHandler.GetSavedSearch).Methods("GET")
+	api.HandleFunc("/saved-searches/{id}", savedSearchHandler.DeleteSavedSearch).Methods("DELETE")
+	api.HandleFunc("/alerts", savedSearchHandler.GetAlerts).Methods("GET")
+	api.HandleFunc("/alerts/{id}/read", savedSearchHandler.MarkAlertRead).Methods("POST")
+
 	// Health check
 	router.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
@@ -169,15 +270,61 @@ func setupRoutes(bookHandler *handlers.BookHandler) *mux.Router {
 			"version": "2.0.0",
 			"endpoints": {
 				"books": {
-					"GET /api/books": "Get all books with filtering and caching",
-					"POST /api/books": "Create a book with concurrent validation",
-					"GET /api/books/{id}": "Get a book by ID with caching",
-					"PUT /api/books/{id}": "Update a book with validation",
-					"DELETE /api/books/{id}": "Delete a book",
-					"POST /api/books/bulk": "Bulk create books with worker pool",
-					"GET /api/books/metrics": "Get performance metrics"
+					"GET /api/books": "Get all books with filtering, caching and copy counts (?q=author:kernighan pages>300 -available for the catalog query language, ?genre=a,b and ?author=!x for sets and negation, ?publisher=, ?isbn=, ?published_from=&published_to=, ?min_pages=&max_pages=, ?created_since=/?updated_since= ranges, ?fuzzy=true&similarity= for typo-tolerant matching, ?facets=true for facet counts, ?sort=title,-published_at for sorting, ?cursor= for keyset pagination, ?fields=id,title,author for sparse fieldsets, ?include=copies to embed copies)",
+					"POST /api/books": "Create a book, filling missing fields from ISBN metadata (send Idempotency-Key to make retries safe)",
+					"GET /api/books/search?q=": "Ranked full-text search over title, author, publisher and genre with highlighted snippets",
+					"GET /api/books/autocomplete?prefix=&field=": "Search-as-you-type suggestions for title, author or publisher, most common first",
+					"GET /api/books/{id}": "Get a book by ID with caching (?fields= and ?include=copies shape the response; ETag/Last-Modified with If-None-Match/If-Modified-Since for 304s)",
+					"PUT /api/books/{id}": "Replace a book's writable fields; omitted optional fields are cleared (availability follows loans; requires If-Match; stale versions get 409)",
+					"PATCH /api/books/{id}": "Partially update a book with application/merge-patch+json or application/json-patch+json (test/replace/remove; requires If-Match)",
+					"DELETE /api/books/{id}": "Move a book to the trash (requires If-Match)",
+					"GET /api/books/trash": "List trashed books, most recently deleted first (purged after TRASH_RETENTION_DAYS)",
+					"POST /api/books/{id}/restore": "Restore a book from the trash",
+					"GET /api/books/{id}/history": "Audit trail of every write to a book: action, actor (X-Actor header), request ID and field changes",
+					"GET /api/books/{id}/as-of?at=": "A book's catalog record as it stood at a point in time",
+					"POST /api/books/{id}/revisions/{rev}/revert": "Revert a book to an earlier revision from its history, recorded as a new revision (requires If-Match)",
+					"POST /api/books/bulk": "Bulk create books with worker pool (?atomic=true validates the whole batch first and creates all or nothing in one transaction; send Idempotency-Key to make retries safe)",
+					"POST /api/books/enrich": "Preview a book with missing fields filled from ISBN metadata",
+					"GET /api/books/metrics": "Get performance metrics",
+					"POST /api/books/{id}/checkout": "Check a book out to a member",
+					"POST /api/books/{id}/return": "Return a checked out copy, named by copy_id when several are out",
+					"GET /api/books/{id}/holds": "List the hold queue for a book",
+					"POST /api/books/{id}/holds": "Place a hold on a checked out book",
+					"GET /api/books/{id}/copies": "List the physical copies of a book",
+					"POST /api/books/{id}/copies": "Add a physical copy identified by barcode"
+				},
+				"copies": {
+					"GET /api/copies/{id}": "Get a copy by ID",
+					"PUT /api/copies/{id}": "Update a copy's status, location or condition",
+					"DELETE /api/copies/{id}": "Remove a copy"
+				},
+				"holds": {
+					"GET /api/holds/{id}": "Get a hold with its queue position",
+					"DELETE /api/holds/{id}": "Cancel a hold"
+				},
+				"members": {
+					"GET /api/members": "List members with name, email and status filters",
+					"POST /api/members": "Register a new member",
+					"GET /api/members/{id}": "Get a member by ID",
+					"PUT /api/members/{id}": "Update a member",
+					"DELETE /api/members/{id}": "Delete a member",
+					"GET /api/members/{id}/loans": "List a member's loans (?open=true for current loans)"
+				},
+				"fines": {
+					"GET /api/borrowers/{id}/fines": "Get a borrower's fine ledger and balance",
+					"POST /api/borrowers/{id}/fines/payments": "Record a fine payment",
+					"POST /api/borrowers/{id}/fines/waivers": "Waive part of a borrower's balance"
+				},
+				"saved_searches": {
+					"GET /api/saved-searches?owner=": "List an owner's saved searches",
+					"POST /api/saved-searches": "Save a named filter or query; new matching books raise alerts (optionally POSTed to webhook_url)",
+					"GET /api/saved-searches/{id}": "Get a saved search",
+					"DELETE /api/saved-searches/{id}": "Delete a saved search and its alerts",
+					"GET /api/alerts?owner=": "List an owner's new-arrival alerts (?unread=true for unread only)",
+					"POST /api/alerts/{id}/read": "Mark an alert as read"
 				},
 				"utility": {
+					"GET /api/isbn/{isbn}/validate": "Validate an ISBN-10 or ISBN-13 and return both forms",
 					"GET /health": "Health check with goroutine count"
 				}
 			},
@@ -197,3 +344,42 @@ func setupRoutes(bookHandler *handlers.BookHandler) *mux.Router {
 
 	return router
 }
+
+// finePolicy converts the configured fine schedule into the service policy
+func finePolicy(cfg config.FineConfig) service.FinePolicy {
+	toRule := func(r config.FineRule) service.FineRule {
+		return service.FineRule{
+			DailyRateCents: int64(r.DailyRateCents),
+			GraceDays:      r.GraceDays,
+			MaxCents:       int64(r.MaxCents),
+		}
+	}
+
+	policy := service.FinePolicy{
+		Default: toRule(cfg.Default),
+		ByGenre: make(map[string]service.FineRule, len(cfg.Genres)),
+	}
+	for genre, rule := range cfg.Genres {
+		policy.ByGenre[genre] = toRule(rule)
+	}
+	return policy
+}
+
+// newMetadataProvider builds the configured ISBN metadata provider; it returns
+// nil when enrichment is disabled
+func newMetadataProvider(cfg config.MetadataConfig) (service.MetadataProvider, error) {
+	switch cfg.Provider {
+	case "catalog":
+		catalog, err := metadata.NewCatalogProvider(cfg.CatalogPath)
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("Loaded %d catalog metadata records from %s", catalog.Len(), cfg.CatalogPath)
+		return catalog, nil
+	case "http":
+		timeout := time.Duration(cfg.HTTPTimeoutSeconds) * time.Second
+		return metadata.NewHTTPProvider(cfg.HTTPURL, timeout), nil
+	default:
+		return nil, nil
+	}
+}
@@ -99,7 +99,7 @@ func TestErrorHandlingIntegration(t *testing.T) {
 		duplicateBook := map[string]interface{}{
 			"title":     "Duplicate Test",
 			"author":    "Test Author",
-			"isbn":      "9781234567890", // This ISBN should already exist from previous tests
+			"isbn":      "9781234567897", // This ISBN should already exist from previous tests
 			"publisher": "Test",
 			"genre":     "Test",
 			"pages":     100,
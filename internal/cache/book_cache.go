@@ -4,6 +4,7 @@ import (
 	"context"
 	"libmngmt/internal/models"
 	"log"
+	"strings"
 	"sync"
 	"time"
 
@@ -27,6 +28,8 @@ type BookCache struct {
 	cancel   context.CancelFunc
 	stats    CacheStats
 	useRedis bool
+	// suggestions holds one set per suggest field; nil until loaded
+	suggestions map[models.SuggestField]*suggestionSet
 }
 
 // CacheStats provides cache statistics
@@ -142,6 +145,46 @@ func (c *BookCache) SetBook(book *models.Book) {
 	c.mu.Unlock()
 }
 
+// GetProjectedBook retrieves a book in the shape of a projection from cache
+func (c *BookCache) GetProjectedBook(id uuid.UUID, projection models.BookProjection) (*models.Book, bool) {
+	key := GenerateProjectedBookKey(id, projection)
+
+	// Try Redis first if available
+	if c.useRedis {
+		if book, err := c.redis.GetProjectedBook(c.ctx, key); err == nil {
+			c.mu.Lock()
+			c.stats.Hits++
+			c.stats.RedisHits++
+			c.mu.Unlock()
+			return book, true
+		}
+	}
+
+	// Fallback to in-memory cache
+	data, found := c.Get(key)
+	if !found {
+		return nil, false
+	}
+
+	book, ok := data.(*models.Book)
+	return book, ok
+}
+
+// SetProjectedBook stores a book in the shape of a projection in cache
+func (c *BookCache) SetProjectedBook(book *models.Book, projection models.BookProjection) {
+	key := GenerateProjectedBookKey(book.ID, projection)
+
+	// Store in Redis if available
+	if c.useRedis {
+		if err := c.redis.SetProjectedBook(c.ctx, key, book, c.ttl); err != nil {
+			log.Printf("Failed to cache projected book in Redis: %v", err)
+		}
+	}
+
+	// Also store in in-memory cache as fallback
+	c.Set(key, book)
+}
+
 // GetBookList retrieves a book list from cache
 func (c *BookCache) GetBookList(filter models.BookFilter) (*models.BooksListResponse, bool) {
 	key := GenerateBookListKey(filter)
@@ -213,6 +256,46 @@ func (c *BookCache) SetBookList(filter models.BookFilter, response *models.Books
 	c.mu.Unlock()
 }
 
+// GetBookFacets retrieves facet counts from cache
+func (c *BookCache) GetBookFacets(filter models.BookFilter) (*models.BookFacets, bool) {
+	key := GenerateBookFacetsKey(filter)
+
+	// Try Redis first if available
+	if c.useRedis {
+		if facets, err := c.redis.GetBookFacets(c.ctx, key); err == nil {
+			c.mu.Lock()
+			c.stats.Hits++
+			c.stats.RedisHits++
+			c.mu.Unlock()
+			return facets, true
+		}
+	}
+
+	// Fallback to in-memory cache
+	data, found := c.Get(key)
+	if !found {
+		return nil, false
+	}
+
+	facets, ok := data.(*models.BookFacets)
+	return facets, ok
+}
+
+// SetBookFacets stores facet counts in cache
+func (c *BookCache) SetBookFacets(filter models.BookFilter, facets *models.BookFacets) {
+	key := GenerateBookFacetsKey(filter)
+
+	// Store in Redis if available
+	if c.useRedis {
+		if err := c.redis.SetBookFacets(c.ctx, key, facets, c.ttl); err != nil {
+			log.Printf("Failed to cache book facets in Redis: %v", err)
+		}
+	}
+
+	// Also store in in-memory cache as fallback
+	c.Set(key, facets)
+}
+
 // InvalidateBook removes a book from cache and related book lists
 func (c *BookCache) InvalidateBook(id uuid.UUID) {
 	key := id.String()
@@ -232,9 +315,9 @@ func (c *BookCache) InvalidateBook(id uuid.UUID) {
 	c.mu.Lock()
 	delete(c.inMemory, key)
 
-	// Remove all book list caches (simple approach for in-memory)
+	// Remove all book list and facet caches (simple approach for in-memory)
 	for k := range c.inMemory {
-		if len(k) == 32 && k != key { // Assuming book list keys are MD5 hashes (32 chars)
+		if strings.HasPrefix(k, BookListKeyPrefix) {
 			delete(c.inMemory, k)
 		}
 	}
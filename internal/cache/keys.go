@@ -4,37 +4,117 @@ import (
 	"crypto/md5"
 	"fmt"
 	"strconv"
+	"time"
 
 	"libmngmt/internal/models"
+
+	"github.com/google/uuid"
 )
 
 // GenerateBookListKey creates a cache key for book list queries
 func GenerateBookListKey(filter models.BookFilter) string {
+	// Create a string representation of the filter
+	filterStr := fmt.Sprintf("%s|sort:%s|limit:%d|offset:%d|cursor:%s|total:%t|%s",
+		filterKeyString(filter), filter.Sort, filter.Limit, filter.Offset, filter.Cursor, filter.CountsTotal(),
+		projectionKeyString(filter.BookProjection))
+
+	// Generate MD5 hash to create consistent, shorter keys
+	hash := md5.Sum([]byte(filterStr))
+	return fmt.Sprintf("books:%x", hash)
+}
+
+// GenerateProjectedBookKey creates a cache key for a book in the shape of a
+// projection. Projected books share the list prefix, so the writes that
+// invalidate lists drop them too.
+func GenerateProjectedBookKey(id uuid.UUID, projection models.BookProjection) string {
+	hash := md5.Sum([]byte(projectionKeyString(projection)))
+	return fmt.Sprintf("books:book:%s:%x", id, hash)
+}
+
+// GenerateBookFacetsKey creates a cache key for facet counts. Facets do not
+// depend on pagination, so every page of a listing shares one entry.
+func GenerateBookFacetsKey(filter models.BookFilter) string {
+	hash := md5.Sum([]byte(filterKeyString(filter)))
+	return fmt.Sprintf("books:facets:%x", hash)
+}
+
+// filterKeyString renders the filter fields that select which books match
+func filterKeyString(filter models.BookFilter) string {
 	var availableStr string
 	if filter.Available != nil {
 		availableStr = strconv.FormatBool(*filter.Available)
 	}
 
-	// Create a string representation of the filter
-	filterStr := fmt.Sprintf("author:%s|genre:%s|language:%s|available:%s|limit:%d|offset:%d",
+	return fmt.Sprintf("author:%s|genre:%s|language:%s|available:%s|fuzzy:%t|similarity:%g"+
+		"|publisher:%s|isbn:%s|genres:%s|languages:%s|not_authors:%s|not_publishers:%s|not_genres:%s|not_languages:%s"+
+		"|published:%s..%s|pages:%s..%s|created:%s..%s|updated:%s..%s|q:%q",
 		filter.Author,
 		filter.Genre,
 		filter.Language,
 		availableStr,
-		filter.Limit,
-		filter.Offset,
+		filter.Fuzzy,
+		filter.Similarity,
+		filter.Publisher,
+		filter.ISBN,
+		listKeyString(filter.Genres),
+		listKeyString(filter.Languages),
+		listKeyString(filter.ExcludeAuthors),
+		listKeyString(filter.ExcludePublishers),
+		listKeyString(filter.ExcludeGenres),
+		listKeyString(filter.ExcludeLanguages),
+		timeKeyString(filter.PublishedFrom), timeKeyString(filter.PublishedTo),
+		intKeyString(filter.MinPages), intKeyString(filter.MaxPages),
+		timeKeyString(filter.CreatedSince), timeKeyString(filter.CreatedUntil),
+		timeKeyString(filter.UpdatedSince), timeKeyString(filter.UpdatedUntil),
+		filter.Query,
 	)
+}
 
-	// Generate MD5 hash to create consistent, shorter keys
-	hash := md5.Sum([]byte(filterStr))
-	return fmt.Sprintf("books:%x", hash)
+// projectionKeyString renders the fields and included resources that shape
+// a response
+func projectionKeyString(projection models.BookProjection) string {
+	return fmt.Sprintf("fields:%s|include:%s", listKeyString(projection.Fields), listKeyString(projection.Include))
+}
+
+// listKeyString renders a value list; values are quoted so separators
+// inside them cannot collide with another filter
+func listKeyString(values []string) string {
+	return fmt.Sprintf("%q", values)
+}
+
+// timeKeyString renders an optional range bound
+func timeKeyString(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// intKeyString renders an optional range bound
+func intKeyString(n *int) string {
+	if n == nil {
+		return ""
+	}
+	return strconv.Itoa(*n)
+}
+
+// SuggestionSetKey names the sorted set holding a field's suggestions
+func SuggestionSetKey(field string) string {
+	return SuggestionKeyPrefix + field
+}
+
+// SuggestionCountsKey names the hash counting the books behind each suggestion
+func SuggestionCountsKey(field string) string {
+	return SuggestionKeyPrefix + field + ":counts"
 }
 
-// Cache key constants
+// Cache key constants. Suggestion sets live outside the book and book list
+// prefixes so list invalidation leaves them intact.
 const (
-	BookKeyPrefix     = "book:"
-	BookListKeyPrefix = "books:"
-	BookListPattern   = "books:*"
+	BookKeyPrefix       = "book:"
+	BookListKeyPrefix   = "books:"
+	BookListPattern     = "books:*"
+	SuggestionKeyPrefix = "suggest:"
 )
 
 // Cache TTL constants
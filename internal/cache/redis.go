@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"libmngmt/internal/models"
@@ -17,12 +18,24 @@ type Cache interface {
 	GetBook(ctx context.Context, id string) (*models.Book, error)
 	SetBook(ctx context.Context, book *models.Book, ttl time.Duration) error
 	DeleteBook(ctx context.Context, id string) error
+	GetProjectedBook(ctx context.Context, key string) (*models.Book, error)
+	SetProjectedBook(ctx context.Context, key string, book *models.Book, ttl time.Duration) error
 
 	// Book list operations
 	GetBookList(ctx context.Context, key string) (*models.BooksListResponse, error)
 	SetBookList(ctx context.Context, key string, response *models.BooksListResponse, ttl time.Duration) error
 	DeleteBookListCache(ctx context.Context, pattern string) error
 
+	// Facet count operations
+	GetBookFacets(ctx context.Context, key string) (*models.BookFacets, error)
+	SetBookFacets(ctx context.Context, key string, facets *models.BookFacets, ttl time.Duration) error
+
+	// Suggestion set operations
+	GetSuggestions(ctx context.Context, field, prefix string, limit int) ([]string, error)
+	ReplaceSuggestions(ctx context.Context, field string, counts map[string]int) error
+	AddSuggestion(ctx context.Context, field, value string) error
+	RemoveSuggestion(ctx context.Context, field, value string) error
+
 	// Health check
 	Ping(ctx context.Context) error
 	Close() error
@@ -80,6 +93,28 @@ func (r *RedisCache) DeleteBook(ctx context.Context, id string) error {
 	return r.client.Del(ctx, key).Err()
 }
 
+// GetProjectedBook retrieves a projected book from cache
+func (r *RedisCache) GetProjectedBook(ctx context.Context, key string) (*models.Book, error) {
+	data, err := r.client.Get(ctx, key).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	var book models.Book
+	err = json.Unmarshal([]byte(data), &book)
+	return &book, err
+}
+
+// SetProjectedBook stores a projected book in cache
+func (r *RedisCache) SetProjectedBook(ctx context.Context, key string, book *models.Book, ttl time.Duration) error {
+	data, err := json.Marshal(book)
+	if err != nil {
+		return err
+	}
+
+	return r.client.Set(ctx, key, data, ttl).Err()
+}
+
 // GetBookList retrieves a book list from cache
 func (r *RedisCache) GetBookList(ctx context.Context, key string) (*models.BooksListResponse, error) {
 	data, err := r.client.Get(ctx, key).Result()
@@ -102,6 +137,128 @@ func (r *RedisCache) SetBookList(ctx context.Context, key string, response *mode
 	return r.client.Set(ctx, key, data, ttl).Err()
 }
 
+// GetBookFacets retrieves facet counts from cache
+func (r *RedisCache) GetBookFacets(ctx context.Context, key string) (*models.BookFacets, error) {
+	data, err := r.client.Get(ctx, key).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	var facets models.BookFacets
+	err = json.Unmarshal([]byte(data), &facets)
+	return &facets, err
+}
+
+// SetBookFacets stores facet counts in cache
+func (r *RedisCache) SetBookFacets(ctx context.Context, key string, facets *models.BookFacets, ttl time.Duration) error {
+	data, err := json.Marshal(facets)
+	if err != nil {
+		return err
+	}
+
+	return r.client.Set(ctx, key, data, ttl).Err()
+}
+
+// Suggestions are kept in a sorted set whose members all score zero, so
+// ZRANGEBYLEX gives prefix lookups. Each member is the lower-cased value, a
+// NUL separator and the value itself; a companion hash holds reference counts.
+
+// suggestionMember encodes value as a suggestion sorted set member
+func suggestionMember(value string) string {
+	return strings.ToLower(value) + "\x00" + value
+}
+
+// topSuggestionsScript ranks the members between ARGV[1] and ARGV[2] by their
+// reference count, ties in member order, and returns the values of the first
+// ARGV[3]
+var topSuggestionsScript = redis.NewScript(`
+	local members = redis.call('ZRANGEBYLEX', KEYS[1], ARGV[1], ARGV[2])
+	local ranked = {}
+	for i, member in ipairs(members) do
+		local value = string.sub(member, string.find(member, '\0', 1, true) + 1)
+		ranked[i] = {member = member, value = value, count = tonumber(redis.call('HGET', KEYS[2], value)) or 0}
+	end
+	table.sort(ranked, function(a, b)
+		if a.count ~= b.count then
+			return a.count > b.count
+		end
+		return a.member < b.member
+	end)
+
+	local values = {}
+	for i = 1, math.min(#ranked, tonumber(ARGV[3])) do
+		values[i] = ranked[i].value
+	end
+	return values
+`)
+
+// GetSuggestions retrieves up to limit suggestions starting with prefix,
+// ignoring case, the ones carried by the most books first. A missing set
+// yields redis.Nil.
+func (r *RedisCache) GetSuggestions(ctx context.Context, field, prefix string, limit int) ([]string, error) {
+	key := SuggestionSetKey(field)
+	exists, err := r.client.Exists(ctx, key).Result()
+	if err != nil {
+		return nil, err
+	}
+	if exists == 0 {
+		return nil, redis.Nil
+	}
+
+	// 0xff never occurs in UTF-8, so it bounds every member sharing the prefix
+	prefix = strings.ToLower(prefix)
+	keys := []string{key, SuggestionCountsKey(field)}
+	return topSuggestionsScript.Run(ctx, r.client, keys, "["+prefix, "["+prefix+"\xff", limit).StringSlice()
+}
+
+// ReplaceSuggestions replaces the suggestion set for field with counts
+func (r *RedisCache) ReplaceSuggestions(ctx context.Context, field string, counts map[string]int) error {
+	setKey, countsKey := SuggestionSetKey(field), SuggestionCountsKey(field)
+
+	pipe := r.client.TxPipeline()
+	pipe.Del(ctx, setKey, countsKey)
+	if len(counts) > 0 {
+		members := make([]*redis.Z, 0, len(counts))
+		values := make(map[string]interface{}, len(counts))
+		for value, count := range counts {
+			members = append(members, &redis.Z{Member: suggestionMember(value)})
+			values[value] = count
+		}
+		pipe.ZAdd(ctx, setKey, members...)
+		pipe.HSet(ctx, countsKey, values)
+	}
+
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// AddSuggestion records one more book carrying value
+func (r *RedisCache) AddSuggestion(ctx context.Context, field, value string) error {
+	pipe := r.client.TxPipeline()
+	pipe.HIncrBy(ctx, SuggestionCountsKey(field), value, 1)
+	pipe.ZAdd(ctx, SuggestionSetKey(field), &redis.Z{Member: suggestionMember(value)})
+
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// removeSuggestionScript decrements a value's count and drops the value from
+// the sorted set once no book carries it
+var removeSuggestionScript = redis.NewScript(`
+	local count = redis.call('HINCRBY', KEYS[2], ARGV[1], -1)
+	if count <= 0 then
+		redis.call('HDEL', KEYS[2], ARGV[1])
+		redis.call('ZREM', KEYS[1], ARGV[2])
+	end
+	return count
+`)
+
+// RemoveSuggestion records one fewer book carrying value
+func (r *RedisCache) RemoveSuggestion(ctx context.Context, field, value string) error {
+	keys := []string{SuggestionSetKey(field), SuggestionCountsKey(field)}
+	return removeSuggestionScript.Run(ctx, r.client, keys, value, suggestionMember(value)).Err()
+}
+
 // DeleteBookListCache removes book list caches matching pattern
 func (r *RedisCache) DeleteBookListCache(ctx context.Context, pattern string) error {
 	iter := r.client.Scan(ctx, 0, pattern, 0).Iterator()
@@ -151,6 +308,14 @@ func (n *NoOpCache) DeleteBook(ctx context.Context, id string) error {
 	return nil
 }
 
+func (n *NoOpCache) GetProjectedBook(ctx context.Context, key string) (*models.Book, error) {
+	return nil, redis.Nil
+}
+
+func (n *NoOpCache) SetProjectedBook(ctx context.Context, key string, book *models.Book, ttl time.Duration) error {
+	return nil
+}
+
 func (n *NoOpCache) GetBookList(ctx context.Context, key string) (*models.BooksListResponse, error) {
 	return nil, redis.Nil
 }
@@ -159,6 +324,30 @@ func (n *NoOpCache) SetBookList(ctx context.Context, key string, response *model
 	return nil
 }
 
+func (n *NoOpCache) GetBookFacets(ctx context.Context, key string) (*models.BookFacets, error) {
+	return nil, redis.Nil
+}
+
+func (n *NoOpCache) SetBookFacets(ctx context.Context, key string, facets *models.BookFacets, ttl time.Duration) error {
+	return nil
+}
+
+func (n *NoOpCache) GetSuggestions(ctx context.Context, field, prefix string, limit int) ([]string, error) {
+	return nil, redis.Nil
+}
+
+func (n *NoOpCache) ReplaceSuggestions(ctx context.Context, field string, counts map[string]int) error {
+	return nil
+}
+
+func (n *NoOpCache) AddSuggestion(ctx context.Context, field, value string) error {
+	return nil
+}
+
+func (n *NoOpCache) RemoveSuggestion(ctx context.Context, field, value string) error {
+	return nil
+}
+
 func (n *NoOpCache) DeleteBookListCache(ctx context.Context, pattern string) error {
 	return nil
 }
@@ -0,0 +1,206 @@
+package cache
+
+import (
+	"libmngmt/internal/models"
+	"log"
+	"sort"
+	"strings"
+)
+
+// suggestionSet holds the distinct values of one book field ordered
+// case-insensitively, so a prefix lookup is a binary search followed by a
+// short scan. Each value is reference-counted by the books that carry it.
+type suggestionSet struct {
+	counts map[string]int
+	sorted []string
+}
+
+func newSuggestionSet() *suggestionSet {
+	return &suggestionSet{counts: make(map[string]int)}
+}
+
+// suggestionLess orders values by their lower-cased form, then exactly
+func suggestionLess(a, b string) bool {
+	la, lb := strings.ToLower(a), strings.ToLower(b)
+	if la != lb {
+		return la < lb
+	}
+	return a < b
+}
+
+// add records one more book carrying value and reports whether the value is
+// new to the set
+func (s *suggestionSet) add(value string) bool {
+	if value == "" {
+		return false
+	}
+
+	s.counts[value]++
+	if s.counts[value] > 1 {
+		return false
+	}
+
+	i := sort.Search(len(s.sorted), func(i int) bool { return !suggestionLess(s.sorted[i], value) })
+	s.sorted = append(s.sorted, "")
+	copy(s.sorted[i+1:], s.sorted[i:])
+	s.sorted[i] = value
+	return true
+}
+
+// remove records one fewer book carrying value and reports whether the value
+// has left the set
+func (s *suggestionSet) remove(value string) bool {
+	count, ok := s.counts[value]
+	if !ok {
+		return false
+	}
+	if count > 1 {
+		s.counts[value] = count - 1
+		return false
+	}
+
+	delete(s.counts, value)
+	i := sort.Search(len(s.sorted), func(i int) bool { return !suggestionLess(s.sorted[i], value) })
+	if i < len(s.sorted) && s.sorted[i] == value {
+		s.sorted = append(s.sorted[:i], s.sorted[i+1:]...)
+	}
+	return true
+}
+
+// match returns up to limit values starting with prefix, ignoring case, the
+// ones carried by the most books first
+func (s *suggestionSet) match(prefix string, limit int) []string {
+	prefix = strings.ToLower(prefix)
+	matches := make([]string, 0, limit)
+
+	i := sort.Search(len(s.sorted), func(i int) bool { return strings.ToLower(s.sorted[i]) >= prefix })
+	for ; i < len(s.sorted); i++ {
+		if !strings.HasPrefix(strings.ToLower(s.sorted[i]), prefix) {
+			break
+		}
+		matches = append(matches, s.sorted[i])
+	}
+
+	// Matches are in suggestionLess order, which a stable sort keeps for ties
+	sort.SliceStable(matches, func(a, b int) bool { return s.counts[matches[a]] > s.counts[matches[b]] })
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
+
+// SuggestionsLoaded reports whether the suggestion sets have been seeded
+func (c *BookCache) SuggestionsLoaded() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.suggestions != nil
+}
+
+// LoadSuggestions seeds the suggestion sets from the number of books carrying
+// each value of each field, replacing anything previously loaded
+func (c *BookCache) LoadSuggestions(counts map[models.SuggestField]map[string]int) {
+	// Store in Redis if available
+	if c.useRedis {
+		for field, values := range counts {
+			if err := c.redis.ReplaceSuggestions(c.ctx, string(field), values); err != nil {
+				log.Printf("Failed to load %s suggestions into Redis: %v", field, err)
+			}
+		}
+	}
+
+	// Also keep the sets in memory as fallback
+	suggestions := make(map[models.SuggestField]*suggestionSet, len(counts))
+	for field, values := range counts {
+		set := newSuggestionSet()
+		for value, count := range values {
+			if set.add(value) {
+				set.counts[value] = count
+			}
+		}
+		suggestions[field] = set
+	}
+
+	c.mu.Lock()
+	c.suggestions = suggestions
+	c.mu.Unlock()
+}
+
+// Suggest returns up to limit values of field starting with prefix, ignoring
+// case, the ones carried by the most books first. It reports false until the
+// suggestion sets have been loaded.
+func (c *BookCache) Suggest(field models.SuggestField, prefix string, limit int) ([]string, bool) {
+	// Try Redis first if available
+	if c.useRedis {
+		if suggestions, err := c.redis.GetSuggestions(c.ctx, string(field), prefix, limit); err == nil {
+			c.mu.Lock()
+			c.stats.Hits++
+			c.stats.RedisHits++
+			c.mu.Unlock()
+			return suggestions, true
+		}
+	}
+
+	// Fallback to in-memory sets
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	set, ok := c.suggestions[field]
+	if !ok {
+		c.stats.Misses++
+		return nil, false
+	}
+
+	c.stats.Hits++
+	c.stats.MemoryHits++
+	return set.match(prefix, limit), true
+}
+
+// AddBookSuggestions records the suggestable values of a created book
+func (c *BookCache) AddBookSuggestions(book *models.Book) {
+	c.updateBookSuggestions(book, true)
+}
+
+// RemoveBookSuggestions forgets the suggestable values of a deleted book
+func (c *BookCache) RemoveBookSuggestions(book *models.Book) {
+	c.updateBookSuggestions(book, false)
+}
+
+// ReplaceBookSuggestions swaps an updated book's old values for its new ones
+func (c *BookCache) ReplaceBookSuggestions(old, updated *models.Book) {
+	c.updateBookSuggestions(old, false)
+	c.updateBookSuggestions(updated, true)
+}
+
+// updateBookSuggestions adds or removes each of the book's suggestable values
+func (c *BookCache) updateBookSuggestions(book *models.Book, add bool) {
+	for _, field := range models.SuggestFields {
+		value := field.ValueOf(book)
+		if value == "" {
+			continue
+		}
+
+		// Update Redis if available
+		if c.useRedis {
+			var err error
+			if add {
+				err = c.redis.AddSuggestion(c.ctx, string(field), value)
+			} else {
+				err = c.redis.RemoveSuggestion(c.ctx, string(field), value)
+			}
+			if err != nil {
+				log.Printf("Failed to update %s suggestions in Redis: %v", field, err)
+			}
+		}
+
+		// Update in-memory sets once loaded
+		c.mu.Lock()
+		if set, ok := c.suggestions[field]; ok {
+			if add {
+				set.add(value)
+			} else {
+				set.remove(value)
+			}
+		}
+		c.mu.Unlock()
+	}
+}
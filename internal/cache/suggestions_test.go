@@ -0,0 +1,104 @@
+package cache
+
+import (
+	"libmngmt/internal/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestCache(counts map[string]int) *BookCache {
+	c := NewBookCache(time.Minute, time.Minute, nil)
+	c.LoadSuggestions(map[models.SuggestField]map[string]int{models.SuggestFieldAuthor: counts})
+	return c
+}
+
+func TestSuggestionSet_Match(t *testing.T) {
+	t.Run("most common values come first", func(t *testing.T) {
+		set := newSuggestionSet()
+		for value, count := range map[string]int{"Adams": 1, "Asimov": 5, "Atwood": 3, "Austen": 3, "Bradbury": 9} {
+			for i := 0; i < count; i++ {
+				set.add(value)
+			}
+		}
+
+		assert.Equal(t, []string{"Asimov", "Atwood", "Austen"}, set.match("a", 3))
+		assert.Equal(t, []string{"Asimov", "Atwood", "Austen", "Adams"}, set.match("A", 10))
+		assert.Empty(t, set.match("c", 3))
+	})
+
+	t.Run("ties keep case-insensitive order", func(t *testing.T) {
+		set := newSuggestionSet()
+		set.add("banks")
+		set.add("Baxter")
+		set.add("Banks")
+
+		assert.Equal(t, []string{"Banks", "banks", "Baxter"}, set.match("ba", 5))
+	})
+}
+
+func TestBookCache_Suggestions(t *testing.T) {
+	t.Run("suggest reports false until loaded", func(t *testing.T) {
+		c := NewBookCache(time.Minute, time.Minute, nil)
+		defer c.Shutdown()
+
+		_, ok := c.Suggest(models.SuggestFieldAuthor, "a", 5)
+		assert.False(t, ok)
+		assert.False(t, c.SuggestionsLoaded())
+	})
+
+	t.Run("added books raise a value's rank", func(t *testing.T) {
+		c := newTestCache(map[string]int{"Asimov": 2, "Atwood": 1})
+		defer c.Shutdown()
+
+		c.AddBookSuggestions(&models.Book{Author: "Atwood"})
+		c.AddBookSuggestions(&models.Book{Author: "Atwood"})
+
+		suggestions, ok := c.Suggest(models.SuggestFieldAuthor, "a", 5)
+		assert.True(t, ok)
+		assert.Equal(t, []string{"Atwood", "Asimov"}, suggestions)
+	})
+
+	t.Run("a value stays until its last book is removed", func(t *testing.T) {
+		c := newTestCache(map[string]int{"Asimov": 2})
+		defer c.Shutdown()
+
+		c.RemoveBookSuggestions(&models.Book{Author: "Asimov"})
+		suggestions, _ := c.Suggest(models.SuggestFieldAuthor, "as", 5)
+		assert.Equal(t, []string{"Asimov"}, suggestions)
+
+		c.RemoveBookSuggestions(&models.Book{Author: "Asimov"})
+		suggestions, _ = c.Suggest(models.SuggestFieldAuthor, "as", 5)
+		assert.Empty(t, suggestions)
+
+		// Removing a value no book carries is a no-op
+		c.RemoveBookSuggestions(&models.Book{Author: "Asimov"})
+		c.AddBookSuggestions(&models.Book{Author: "Asimov"})
+		suggestions, _ = c.Suggest(models.SuggestFieldAuthor, "as", 5)
+		assert.Equal(t, []string{"Asimov"}, suggestions)
+	})
+
+	t.Run("replace moves one reference to the new value", func(t *testing.T) {
+		c := newTestCache(map[string]int{"Asimov": 1, "Atwood": 2})
+		defer c.Shutdown()
+
+		c.ReplaceBookSuggestions(&models.Book{Author: "Atwood"}, &models.Book{Author: "Austen"})
+
+		suggestions, _ := c.Suggest(models.SuggestFieldAuthor, "a", 5)
+		assert.Equal(t, []string{"Asimov", "Atwood", "Austen"}, suggestions)
+
+		c.ReplaceBookSuggestions(&models.Book{Author: "Asimov"}, &models.Book{Author: "Austen"})
+
+		suggestions, _ = c.Suggest(models.SuggestFieldAuthor, "a", 5)
+		assert.Equal(t, []string{"Austen", "Atwood"}, suggestions)
+	})
+
+	t.Run("limit keeps the top values", func(t *testing.T) {
+		c := newTestCache(map[string]int{"Zed": 1, "Zelazny": 7, "Zola": 4})
+		defer c.Shutdown()
+
+		suggestions, _ := c.Suggest(models.SuggestFieldAuthor, "z", 2)
+		assert.Equal(t, []string{"Zelazny", "Zola"}, suggestions)
+	})
+}
@@ -5,15 +5,22 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	Database DatabaseConfig
-	Server   ServerConfig
-	Redis    RedisConfig
-	LogLevel string
+	Database    DatabaseConfig
+	Server      ServerConfig
+	Redis       RedisConfig
+	Library     LibraryConfig
+	Fines       FineConfig
+	Metadata    MetadataConfig
+	Alerts      AlertConfig
+	Trash       TrashConfig
+	Idempotency IdempotencyConfig
+	LogLevel    string
 }
 
 type DatabaseConfig struct {
@@ -39,6 +46,56 @@ type RedisConfig struct {
 	Enabled  bool
 }
 
+// LibraryConfig holds circulation policy settings
+type LibraryConfig struct {
+	LoanPeriodDays           int
+	HoldPickupDays           int
+	HoldSweepIntervalMinutes int
+}
+
+// FineRule describes how overdue fines accrue for a class of books
+type FineRule struct {
+	DailyRateCents int
+	GraceDays      int
+	MaxCents       int
+}
+
+// FineConfig holds the overdue fine schedule. Genres maps a lower-cased genre
+// to a rule overriding Default.
+type FineConfig struct {
+	Default              FineRule
+	Genres               map[string]FineRule
+	SweepIntervalMinutes int
+}
+
+// MetadataConfig selects the ISBN metadata provider used to enrich books.
+// Provider is one of "none", "catalog" (a local CSV or JSON file) or "http".
+type MetadataConfig struct {
+	Provider           string
+	CatalogPath        string
+	HTTPURL            string
+	HTTPTimeoutSeconds int
+}
+
+// AlertConfig holds settings for saved-search new-arrival alerts
+type AlertConfig struct {
+	WebhookTimeoutSeconds int
+}
+
+// TrashConfig holds how long deleted books stay restorable and how often the
+// trash is swept for books past that period
+type TrashConfig struct {
+	RetentionDays        int
+	PurgeIntervalMinutes int
+}
+
+// IdempotencyConfig holds how long responses stored under an Idempotency-Key
+// are replayed to retries and how often expired keys are swept
+type IdempotencyConfig struct {
+	TTLHours             int
+	PurgeIntervalMinutes int
+}
+
 // LoadWithValidation loads configuration with proper error handling
 func LoadWithValidation() (*Config, error) {
 	// Load .env file if it exists
@@ -76,6 +133,124 @@ func LoadWithValidation() (*Config, error) {
 		return nil, fmt.Errorf("invalid REDIS_ENABLED: %w", err)
 	}
 
+	// Parse default loan period with proper error handling
+	loanPeriodDays, err := parseIntWithDefault("LOAN_PERIOD_DAYS", "14")
+	if err != nil {
+		return nil, fmt.Errorf("invalid LOAN_PERIOD_DAYS: %w", err)
+	}
+	if loanPeriodDays <= 0 {
+		return nil, fmt.Errorf("invalid LOAN_PERIOD_DAYS: %d is not positive", loanPeriodDays)
+	}
+
+	// Parse hold pickup window with proper error handling
+	holdPickupDays, err := parseIntWithDefault("HOLD_PICKUP_DAYS", "3")
+	if err != nil {
+		return nil, fmt.Errorf("invalid HOLD_PICKUP_DAYS: %w", err)
+	}
+	if holdPickupDays <= 0 {
+		return nil, fmt.Errorf("invalid HOLD_PICKUP_DAYS: %d is not positive", holdPickupDays)
+	}
+
+	// Parse hold expiry sweep interval with proper error handling
+	holdSweepInterval, err := parseIntWithDefault("HOLD_SWEEP_INTERVAL_MINUTES", "60")
+	if err != nil {
+		return nil, fmt.Errorf("invalid HOLD_SWEEP_INTERVAL_MINUTES: %w", err)
+	}
+	if holdSweepInterval <= 0 {
+		return nil, fmt.Errorf("invalid HOLD_SWEEP_INTERVAL_MINUTES: %d is not positive", holdSweepInterval)
+	}
+
+	// Parse default fine rule with proper error handling
+	fineDailyRate, err := parseIntWithDefault("FINE_DAILY_RATE_CENTS", "25")
+	if err != nil {
+		return nil, fmt.Errorf("invalid FINE_DAILY_RATE_CENTS: %w", err)
+	}
+
+	fineGraceDays, err := parseIntWithDefault("FINE_GRACE_DAYS", "0")
+	if err != nil {
+		return nil, fmt.Errorf("invalid FINE_GRACE_DAYS: %w", err)
+	}
+
+	fineMax, err := parseIntWithDefault("FINE_MAX_CENTS", "1000")
+	if err != nil {
+		return nil, fmt.Errorf("invalid FINE_MAX_CENTS: %w", err)
+	}
+
+	// Parse per-genre fine overrides with proper error handling
+	fineGenres, err := parseFineRules(getEnv("FINE_GENRE_RULES", ""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid FINE_GENRE_RULES: %w", err)
+	}
+
+	// Parse fine accrual sweep interval with proper error handling
+	fineSweepInterval, err := parseIntWithDefault("FINE_SWEEP_INTERVAL_MINUTES", "60")
+	if err != nil {
+		return nil, fmt.Errorf("invalid FINE_SWEEP_INTERVAL_MINUTES: %w", err)
+	}
+	if fineSweepInterval <= 0 {
+		return nil, fmt.Errorf("invalid FINE_SWEEP_INTERVAL_MINUTES: %d is not positive", fineSweepInterval)
+	}
+
+	// Parse metadata provider settings with proper error handling
+	metadataProvider := strings.ToLower(getEnv("METADATA_PROVIDER", "none"))
+	switch metadataProvider {
+	case "none":
+	case "catalog":
+		if getEnv("METADATA_CATALOG_PATH", "") == "" {
+			return nil, fmt.Errorf("METADATA_CATALOG_PATH is required when METADATA_PROVIDER is catalog")
+		}
+	case "http":
+		if getEnv("METADATA_HTTP_URL", "") == "" {
+			return nil, fmt.Errorf("METADATA_HTTP_URL is required when METADATA_PROVIDER is http")
+		}
+	default:
+		return nil, fmt.Errorf("invalid METADATA_PROVIDER: %q is not one of none, catalog, http", metadataProvider)
+	}
+
+	metadataTimeout, err := parseIntWithDefault("METADATA_HTTP_TIMEOUT_SECONDS", "5")
+	if err != nil {
+		return nil, fmt.Errorf("invalid METADATA_HTTP_TIMEOUT_SECONDS: %w", err)
+	}
+
+	alertWebhookTimeout, err := parseIntWithDefault("ALERT_WEBHOOK_TIMEOUT_SECONDS", "10")
+	if err != nil {
+		return nil, fmt.Errorf("invalid ALERT_WEBHOOK_TIMEOUT_SECONDS: %w", err)
+	}
+
+	// Parse trash retention settings with proper error handling
+	trashRetentionDays, err := parseIntWithDefault("TRASH_RETENTION_DAYS", "30")
+	if err != nil {
+		return nil, fmt.Errorf("invalid TRASH_RETENTION_DAYS: %w", err)
+	}
+	if trashRetentionDays < 0 {
+		return nil, fmt.Errorf("invalid TRASH_RETENTION_DAYS: %d is negative", trashRetentionDays)
+	}
+
+	trashPurgeInterval, err := parseIntWithDefault("TRASH_PURGE_INTERVAL_MINUTES", "60")
+	if err != nil {
+		return nil, fmt.Errorf("invalid TRASH_PURGE_INTERVAL_MINUTES: %w", err)
+	}
+	if trashPurgeInterval <= 0 {
+		return nil, fmt.Errorf("invalid TRASH_PURGE_INTERVAL_MINUTES: %d is not positive", trashPurgeInterval)
+	}
+
+	// Parse idempotency key settings with proper error handling
+	idempotencyTTL, err := parseIntWithDefault("IDEMPOTENCY_KEY_TTL_HOURS", "24")
+	if err != nil {
+		return nil, fmt.Errorf("invalid IDEMPOTENCY_KEY_TTL_HOURS: %w", err)
+	}
+	if idempotencyTTL <= 0 {
+		return nil, fmt.Errorf("invalid IDEMPOTENCY_KEY_TTL_HOURS: %d is not positive", idempotencyTTL)
+	}
+
+	idempotencyPurgeInterval, err := parseIntWithDefault("IDEMPOTENCY_PURGE_INTERVAL_MINUTES", "60")
+	if err != nil {
+		return nil, fmt.Errorf("invalid IDEMPOTENCY_PURGE_INTERVAL_MINUTES: %w", err)
+	}
+	if idempotencyPurgeInterval <= 0 {
+		return nil, fmt.Errorf("invalid IDEMPOTENCY_PURGE_INTERVAL_MINUTES: %d is not positive", idempotencyPurgeInterval)
+	}
+
 	return &Config{
 		Database: DatabaseConfig{
 			Host:     getEnv("DB_HOST", "localhost"),
@@ -97,6 +272,37 @@ func LoadWithValidation() (*Config, error) {
 			DB:       redisDB,
 			Enabled:  redisEnabled,
 		},
+		Library: LibraryConfig{
+			LoanPeriodDays:           loanPeriodDays,
+			HoldPickupDays:           holdPickupDays,
+			HoldSweepIntervalMinutes: holdSweepInterval,
+		},
+		Fines: FineConfig{
+			Default: FineRule{
+				DailyRateCents: fineDailyRate,
+				GraceDays:      fineGraceDays,
+				MaxCents:       fineMax,
+			},
+			Genres:               fineGenres,
+			SweepIntervalMinutes: fineSweepInterval,
+		},
+		Metadata: MetadataConfig{
+			Provider:           metadataProvider,
+			CatalogPath:        getEnv("METADATA_CATALOG_PATH", ""),
+			HTTPURL:            getEnv("METADATA_HTTP_URL", ""),
+			HTTPTimeoutSeconds: metadataTimeout,
+		},
+		Alerts: AlertConfig{
+			WebhookTimeoutSeconds: alertWebhookTimeout,
+		},
+		Trash: TrashConfig{
+			RetentionDays:        trashRetentionDays,
+			PurgeIntervalMinutes: trashPurgeInterval,
+		},
+		Idempotency: IdempotencyConfig{
+			TTLHours:             idempotencyTTL,
+			PurgeIntervalMinutes: idempotencyPurgeInterval,
+		},
 		LogLevel: getEnv("LOG_LEVEL", "info"),
 	}, nil
 }
@@ -115,6 +321,45 @@ func parseIntWithDefault(key, defaultValue string) (int, error) {
 	return strconv.Atoi(valueStr)
 }
 
+// parseFineRules parses per-genre fine overrides written as
+// "genre:daily_rate_cents:grace_days:max_cents", separated by commas,
+// e.g. "children:10:3:500,reference:100:0:5000".
+func parseFineRules(value string) (map[string]FineRule, error) {
+	rules := make(map[string]FineRule)
+	if strings.TrimSpace(value) == "" {
+		return rules, nil
+	}
+
+	for _, entry := range strings.Split(value, ",") {
+		parts := strings.Split(strings.TrimSpace(entry), ":")
+		if len(parts) != 4 {
+			return nil, fmt.Errorf("rule %q must have the form genre:daily_rate_cents:grace_days:max_cents", entry)
+		}
+
+		genre := strings.ToLower(strings.TrimSpace(parts[0]))
+		if genre == "" {
+			return nil, fmt.Errorf("rule %q has an empty genre", entry)
+		}
+
+		var numbers [3]int
+		for i, part := range parts[1:] {
+			n, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("rule %q has an invalid amount %q", entry, part)
+			}
+			numbers[i] = n
+		}
+
+		rules[genre] = FineRule{
+			DailyRateCents: numbers[0],
+			GraceDays:      numbers[1],
+			MaxCents:       numbers[2],
+		}
+	}
+
+	return rules, nil
+}
+
 func parseBoolWithDefault(key, defaultValue string) (bool, error) {
 	valueStr := getEnv(key, defaultValue)
 	return strconv.ParseBool(valueStr)
@@ -22,6 +22,15 @@ func TestLoad(t *testing.T) {
 		assert.Equal(t, "disable", cfg.Database.SSLMode)
 		assert.Equal(t, "localhost", cfg.Server.Host)
 		assert.Equal(t, 8080, cfg.Server.Port)
+		assert.Equal(t, 14, cfg.Library.LoanPeriodDays)
+		assert.Equal(t, 3, cfg.Library.HoldPickupDays)
+		assert.Equal(t, 60, cfg.Library.HoldSweepIntervalMinutes)
+		assert.Equal(t, FineRule{DailyRateCents: 25, GraceDays: 0, MaxCents: 1000}, cfg.Fines.Default)
+		assert.Empty(t, cfg.Fines.Genres)
+		assert.Equal(t, 60, cfg.Fines.SweepIntervalMinutes)
+		assert.Equal(t, "none", cfg.Metadata.Provider)
+		assert.Equal(t, 5, cfg.Metadata.HTTPTimeoutSeconds)
+		assert.Equal(t, 10, cfg.Alerts.WebhookTimeoutSeconds)
 		assert.Equal(t, "info", cfg.LogLevel)
 	})
 
@@ -275,10 +284,176 @@ func TestEnvironmentLoading(t *testing.T) {
 func clearEnvVars() {
 	envVars := []string{
 		"DB_HOST", "DB_PORT", "DB_USER", "DB_PASSWORD", "DB_NAME", "DB_SSLMODE",
-		"SERVER_HOST", "SERVER_PORT", "LOG_LEVEL",
+		"SERVER_HOST", "SERVER_PORT", "LOG_LEVEL", "LOAN_PERIOD_DAYS",
+		"HOLD_PICKUP_DAYS", "HOLD_SWEEP_INTERVAL_MINUTES", "FINE_DAILY_RATE_CENTS",
+		"FINE_GRACE_DAYS", "FINE_MAX_CENTS", "FINE_GENRE_RULES", "FINE_SWEEP_INTERVAL_MINUTES",
+		"METADATA_PROVIDER", "METADATA_CATALOG_PATH", "METADATA_HTTP_URL", "METADATA_HTTP_TIMEOUT_SECONDS",
+		"ALERT_WEBHOOK_TIMEOUT_SECONDS", "TRASH_RETENTION_DAYS", "TRASH_PURGE_INTERVAL_MINUTES",
+		"IDEMPOTENCY_KEY_TTL_HOURS", "IDEMPOTENCY_PURGE_INTERVAL_MINUTES",
 	}
 
 	for _, envVar := range envVars {
 		os.Unsetenv(envVar)
 	}
 }
+
+func TestParseFineRules(t *testing.T) {
+	t.Run("parse genre overrides", func(t *testing.T) {
+		rules, err := parseFineRules("Children:10:3:500, reference:100:0:5000")
+
+		assert.NoError(t, err)
+		assert.Len(t, rules, 2)
+		assert.Equal(t, FineRule{DailyRateCents: 10, GraceDays: 3, MaxCents: 500}, rules["children"])
+		assert.Equal(t, FineRule{DailyRateCents: 100, GraceDays: 0, MaxCents: 5000}, rules["reference"])
+	})
+
+	t.Run("empty value yields no overrides", func(t *testing.T) {
+		rules, err := parseFineRules("")
+
+		assert.NoError(t, err)
+		assert.Empty(t, rules)
+	})
+
+	t.Run("malformed rules are rejected", func(t *testing.T) {
+		_, err := parseFineRules("children:10:3")
+		assert.Error(t, err)
+
+		_, err = parseFineRules("children:ten:3:500")
+		assert.Error(t, err)
+
+		_, err = parseFineRules(":10:3:500")
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid rules fail configuration loading", func(t *testing.T) {
+		clearEnvVars()
+		os.Setenv("FINE_GENRE_RULES", "children:-1:0:500")
+
+		_, err := LoadWithValidation()
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "FINE_GENRE_RULES")
+		clearEnvVars()
+	})
+}
+
+func TestMetadataConfig(t *testing.T) {
+	t.Run("catalog provider requires a path", func(t *testing.T) {
+		clearEnvVars()
+		os.Setenv("METADATA_PROVIDER", "catalog")
+
+		_, err := LoadWithValidation()
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "METADATA_CATALOG_PATH")
+		clearEnvVars()
+	})
+
+	t.Run("http provider settings are loaded", func(t *testing.T) {
+		clearEnvVars()
+		os.Setenv("METADATA_PROVIDER", "HTTP")
+		os.Setenv("METADATA_HTTP_URL", "https://metadata.example.com/isbn/{isbn}")
+		os.Setenv("METADATA_HTTP_TIMEOUT_SECONDS", "2")
+
+		cfg, err := LoadWithValidation()
+
+		assert.NoError(t, err)
+		assert.Equal(t, "http", cfg.Metadata.Provider)
+		assert.Equal(t, "https://metadata.example.com/isbn/{isbn}", cfg.Metadata.HTTPURL)
+		assert.Equal(t, 2, cfg.Metadata.HTTPTimeoutSeconds)
+		clearEnvVars()
+	})
+
+	t.Run("unknown provider is rejected", func(t *testing.T) {
+		clearEnvVars()
+		os.Setenv("METADATA_PROVIDER", "openlibrary")
+
+		_, err := LoadWithValidation()
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "METADATA_PROVIDER")
+		clearEnvVars()
+	})
+}
+
+func TestTrashConfig(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		clearEnvVars()
+
+		cfg, err := LoadWithValidation()
+
+		assert.NoError(t, err)
+		assert.Equal(t, 30, cfg.Trash.RetentionDays)
+		assert.Equal(t, 60, cfg.Trash.PurgeIntervalMinutes)
+	})
+
+	t.Run("negative retention is rejected", func(t *testing.T) {
+		clearEnvVars()
+		os.Setenv("TRASH_RETENTION_DAYS", "-1")
+
+		_, err := LoadWithValidation()
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "TRASH_RETENTION_DAYS")
+		clearEnvVars()
+	})
+}
+
+func TestIdempotencyConfig(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		clearEnvVars()
+
+		cfg, err := LoadWithValidation()
+
+		assert.NoError(t, err)
+		assert.Equal(t, 24, cfg.Idempotency.TTLHours)
+		assert.Equal(t, 60, cfg.Idempotency.PurgeIntervalMinutes)
+	})
+
+	t.Run("keys must be kept for some time", func(t *testing.T) {
+		clearEnvVars()
+		os.Setenv("IDEMPOTENCY_KEY_TTL_HOURS", "0")
+
+		_, err := LoadWithValidation()
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "IDEMPOTENCY_KEY_TTL_HOURS")
+		clearEnvVars()
+	})
+}
+
+func TestSchedulingConfig(t *testing.T) {
+	t.Run("periods and intervals must be positive", func(t *testing.T) {
+		names := []string{
+			"LOAN_PERIOD_DAYS", "HOLD_PICKUP_DAYS", "HOLD_SWEEP_INTERVAL_MINUTES",
+			"FINE_SWEEP_INTERVAL_MINUTES", "TRASH_PURGE_INTERVAL_MINUTES", "IDEMPOTENCY_PURGE_INTERVAL_MINUTES",
+		}
+		for _, name := range names {
+			for _, value := range []string{"0", "-5"} {
+				clearEnvVars()
+				os.Setenv(name, value)
+
+				_, err := LoadWithValidation()
+
+				assert.Error(t, err, name+"="+value)
+				if err != nil {
+					assert.Contains(t, err.Error(), "invalid "+name)
+				}
+			}
+		}
+		clearEnvVars()
+	})
+
+	t.Run("positive values are accepted", func(t *testing.T) {
+		clearEnvVars()
+		os.Setenv("LOAN_PERIOD_DAYS", "21")
+		os.Setenv("HOLD_SWEEP_INTERVAL_MINUTES", "1")
+
+		cfg, err := LoadWithValidation()
+
+		assert.NoError(t, err)
+		assert.Equal(t, 21, cfg.Library.LoanPeriodDays)
+		assert.Equal(t, 1, cfg.Library.HoldSweepIntervalMinutes)
+		clearEnvVars()
+	})
+}
@@ -53,7 +53,7 @@ func (db *DB) Migrate() error {
 		id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
 		title VARCHAR(255) NOT NULL,
 		author VARCHAR(255) NOT NULL,
-		isbn VARCHAR(17) UNIQUE NOT NULL,
+		isbn VARCHAR(17) NOT NULL,
 		publisher VARCHAR(255),
 		genre VARCHAR(100),
 		published_at TIMESTAMP,
@@ -68,6 +68,8 @@ func (db *DB) Migrate() error {
 	CREATE INDEX IF NOT EXISTS idx_books_genre ON books(genre);
 	CREATE INDEX IF NOT EXISTS idx_books_available ON books(available);
 	CREATE INDEX IF NOT EXISTS idx_books_isbn ON books(isbn);
+	-- Listing order; backs keyset pagination
+	CREATE INDEX IF NOT EXISTS idx_books_created_at_id ON books(created_at DESC, id DESC);
 
 	-- Trigger to update updated_at timestamp
 	CREATE OR REPLACE FUNCTION update_updated_at_column()
@@ -83,6 +85,359 @@ func (db *DB) Migrate() error {
 		BEFORE UPDATE ON books
 		FOR EACH ROW
 		EXECUTE FUNCTION update_updated_at_column();
+
+	-- Optimistic locking: every write to a book, whichever path makes it,
+	-- moves the book to its next version
+	ALTER TABLE books ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+
+	CREATE OR REPLACE FUNCTION increment_version_column()
+	RETURNS TRIGGER AS $$
+	BEGIN
+		NEW.version = OLD.version + 1;
+		RETURN NEW;
+	END;
+	$$ language 'plpgsql';
+
+	DROP TRIGGER IF EXISTS increment_books_version ON books;
+	CREATE TRIGGER increment_books_version
+		BEFORE UPDATE ON books
+		FOR EACH ROW
+		EXECUTE FUNCTION increment_version_column();
+
+	-- Soft delete: deleting a book moves it to the trash until it is restored
+	-- or purged, and only books outside the trash need distinct ISBNs
+	ALTER TABLE books ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+	ALTER TABLE books DROP CONSTRAINT IF EXISTS books_isbn_key;
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_books_isbn_live ON books(isbn) WHERE deleted_at IS NULL;
+	CREATE INDEX IF NOT EXISTS idx_books_deleted_at ON books(deleted_at) WHERE deleted_at IS NOT NULL;
+
+	-- Full-text search: each book is indexed with the text search configuration
+	-- matching its language, weighting title over author, publisher and genre.
+	-- The repository's searchConfigs lists the same configurations.
+	CREATE OR REPLACE FUNCTION book_search_config(lang TEXT)
+	RETURNS regconfig AS $$
+		SELECT CASE
+			WHEN lower(trim(coalesce(lang, ''))) IN (
+				'danish', 'dutch', 'english', 'finnish', 'french', 'german', 'hungarian', 'italian',
+				'norwegian', 'portuguese', 'romanian', 'russian', 'spanish', 'swedish', 'turkish'
+			) THEN lower(trim(lang))::regconfig
+			ELSE 'simple'::regconfig
+		END;
+	$$ LANGUAGE sql IMMUTABLE;
+
+	CREATE OR REPLACE FUNCTION book_search_vector(title TEXT, author TEXT, publisher TEXT, genre TEXT, lang TEXT)
+	RETURNS tsvector AS $$
+		SELECT setweight(to_tsvector(book_search_config(lang), coalesce(title, '')), 'A') ||
+			setweight(to_tsvector(book_search_config(lang), coalesce(author, '')), 'B') ||
+			setweight(to_tsvector(book_search_config(lang), coalesce(publisher, '')), 'C') ||
+			setweight(to_tsvector(book_search_config(lang), coalesce(genre, '')), 'D');
+	$$ LANGUAGE sql IMMUTABLE;
+
+	CREATE OR REPLACE FUNCTION update_books_search_vector()
+	RETURNS TRIGGER AS $$
+	BEGIN
+		NEW.search_vector = book_search_vector(NEW.title, NEW.author, NEW.publisher, NEW.genre, NEW.language);
+		RETURN NEW;
+	END;
+	$$ language 'plpgsql';
+
+	ALTER TABLE books ADD COLUMN IF NOT EXISTS search_vector tsvector;
+	UPDATE books SET search_vector = book_search_vector(title, author, publisher, genre, language)
+	WHERE search_vector IS NULL;
+	CREATE INDEX IF NOT EXISTS idx_books_search_vector ON books USING GIN(search_vector);
+
+	DROP TRIGGER IF EXISTS update_books_search_vector ON books;
+	CREATE TRIGGER update_books_search_vector
+		BEFORE INSERT OR UPDATE OF title, author, publisher, genre, language ON books
+		FOR EACH ROW
+		EXECUTE FUNCTION update_books_search_vector();
+
+	-- Title sort key: lower-cased with a leading article dropped, ordered in
+	-- the database collation
+	CREATE OR REPLACE FUNCTION book_sort_title(title TEXT)
+	RETURNS TEXT AS $$
+		SELECT regexp_replace(lower(coalesce(title, '')), '^(the|an|a)\s+', '');
+	$$ LANGUAGE sql IMMUTABLE;
+
+	CREATE INDEX IF NOT EXISTS idx_books_sort_title ON books (book_sort_title(title), id);
+
+	-- Trigram similarity backs typo-tolerant author and genre matching
+	CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+	-- Case-insensitive prefix indexes back search-as-you-type suggestions
+	CREATE INDEX IF NOT EXISTS idx_books_title_prefix ON books (LOWER(title) text_pattern_ops);
+	CREATE INDEX IF NOT EXISTS idx_books_author_prefix ON books (LOWER(author) text_pattern_ops);
+	CREATE INDEX IF NOT EXISTS idx_books_publisher_prefix ON books (LOWER(publisher) text_pattern_ops);
+
+	-- ISBNs are stored in canonical ISBN-13 form, without hyphens or spaces
+	CREATE OR REPLACE FUNCTION book_isbn_canonical(raw TEXT)
+	RETURNS TEXT AS $$
+		SELECT CASE
+			WHEN stripped ~ '^[0-9]{9}[0-9Xx]$' THEN '978' || substr(stripped, 1, 9) || ((10 - (
+				SELECT SUM(substr('978' || substr(stripped, 1, 9), i, 1)::int * CASE WHEN i % 2 = 1 THEN 1 ELSE 3 END)
+				FROM generate_series(1, 12) AS i
+			) % 10) % 10)::text
+			ELSE stripped
+		END
+		FROM (SELECT regexp_replace(trim(raw), '[- ]', '', 'g') AS stripped) AS s;
+	$$ LANGUAGE sql IMMUTABLE;
+
+	-- Convert legacy rows. A live book whose canonical ISBN another live book
+	-- already has (or would get) is left as it is, since converting it would
+	-- break idx_books_isbn_live; Migrate reports these for a librarian to resolve.
+	UPDATE books SET isbn = book_isbn_canonical(books.isbn)
+	WHERE isbn <> book_isbn_canonical(isbn)
+		AND (deleted_at IS NOT NULL OR NOT EXISTS (
+			SELECT 1 FROM books other
+			WHERE other.id <> books.id AND other.deleted_at IS NULL
+				AND book_isbn_canonical(other.isbn) = book_isbn_canonical(books.isbn)
+		));
+
+	CREATE TABLE IF NOT EXISTS members (
+		id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+		card_number VARCHAR(32) UNIQUE NOT NULL,
+		first_name VARCHAR(100) NOT NULL,
+		last_name VARCHAR(100) NOT NULL,
+		email VARCHAR(255) NOT NULL,
+		phone VARCHAR(32),
+		address VARCHAR(255),
+		status VARCHAR(20) NOT NULL DEFAULT 'active',
+		expires_at TIMESTAMP NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_members_email ON members(email);
+	CREATE INDEX IF NOT EXISTS idx_members_status ON members(status);
+
+	DROP TRIGGER IF EXISTS update_members_updated_at ON members;
+	CREATE TRIGGER update_members_updated_at
+		BEFORE UPDATE ON members
+		FOR EACH ROW
+		EXECUTE FUNCTION update_updated_at_column();
+
+	CREATE TABLE IF NOT EXISTS copies (
+		id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+		book_id UUID NOT NULL REFERENCES books(id) ON DELETE CASCADE,
+		barcode VARCHAR(32) UNIQUE NOT NULL,
+		status VARCHAR(20) NOT NULL DEFAULT 'available',
+		location VARCHAR(100) NOT NULL DEFAULT '',
+		condition VARCHAR(20) NOT NULL DEFAULT 'good',
+		notes TEXT NOT NULL DEFAULT '',
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_copies_book_status ON copies(book_id, status);
+
+	DROP TRIGGER IF EXISTS update_copies_updated_at ON copies;
+	CREATE TRIGGER update_copies_updated_at
+		BEFORE UPDATE ON copies
+		FOR EACH ROW
+		EXECUTE FUNCTION update_updated_at_column();
+
+	CREATE TABLE IF NOT EXISTS loans (
+		id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+		book_id UUID REFERENCES books(id) ON DELETE SET NULL,
+		member_id UUID NOT NULL REFERENCES members(id) ON DELETE RESTRICT,
+		checked_out_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		due_at TIMESTAMP NOT NULL,
+		returned_at TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_loans_member ON loans(member_id);
+	CREATE INDEX IF NOT EXISTS idx_loans_due_at ON loans(due_at) WHERE returned_at IS NULL;
+
+	-- Loans are for a particular copy; books without copies are lent as a single item
+	ALTER TABLE loans ADD COLUMN IF NOT EXISTS copy_id UUID REFERENCES copies(id) ON DELETE SET NULL;
+	-- At most one open loan per copy, and per book for books lent without a copy,
+	-- enforced by the database as a last line of defence
+	DROP INDEX IF EXISTS idx_loans_open_book;
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_loans_open_copy ON loans(copy_id) WHERE returned_at IS NULL AND copy_id IS NOT NULL;
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_loans_open_book_item ON loans(book_id) WHERE returned_at IS NULL AND copy_id IS NULL;
+
+	-- Loans are circulation history and must outlive a purged book: they keep
+	-- the title and ISBN lent, and lose only the link to the book. Databases
+	-- created when deleting a book cascaded to, or was blocked by, its loans
+	-- are switched over.
+	ALTER TABLE loans ADD COLUMN IF NOT EXISTS book_title VARCHAR(255) NOT NULL DEFAULT '';
+	ALTER TABLE loans ADD COLUMN IF NOT EXISTS book_isbn VARCHAR(17) NOT NULL DEFAULT '';
+	UPDATE loans SET book_title = books.title, book_isbn = books.isbn
+	FROM books
+	WHERE books.id = loans.book_id AND loans.book_title = '';
+	ALTER TABLE loans ALTER COLUMN book_id DROP NOT NULL;
+	DO $$
+	BEGIN
+		IF EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'loans_book_id_fkey' AND confdeltype <> 'n') THEN
+			ALTER TABLE loans DROP CONSTRAINT loans_book_id_fkey;
+			ALTER TABLE loans ADD CONSTRAINT loans_book_id_fkey
+				FOREIGN KEY (book_id) REFERENCES books(id) ON DELETE SET NULL;
+		END IF;
+	END $$;
+
+	-- Set once a returned loan's overdue fine has been fully charged
+	ALTER TABLE loans ADD COLUMN IF NOT EXISTS fines_closed BOOLEAN NOT NULL DEFAULT false;
+
+	CREATE TABLE IF NOT EXISTS fine_entries (
+		id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+		member_id UUID NOT NULL REFERENCES members(id) ON DELETE RESTRICT,
+		loan_id UUID REFERENCES loans(id) ON DELETE SET NULL,
+		entry_type VARCHAR(20) NOT NULL,
+		amount_cents BIGINT NOT NULL CHECK (amount_cents > 0),
+		note TEXT NOT NULL DEFAULT '',
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_fine_entries_member ON fine_entries(member_id, created_at);
+	CREATE INDEX IF NOT EXISTS idx_fine_entries_loan ON fine_entries(loan_id) WHERE entry_type = 'charge';
+
+	CREATE TABLE IF NOT EXISTS holds (
+		id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+		book_id UUID NOT NULL REFERENCES books(id) ON DELETE CASCADE,
+		member_id UUID NOT NULL REFERENCES members(id) ON DELETE CASCADE,
+		status VARCHAR(20) NOT NULL DEFAULT 'waiting',
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		ready_at TIMESTAMP,
+		expires_at TIMESTAMP,
+		notified_at TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_holds_queue ON holds(book_id, created_at) WHERE status IN ('waiting', 'ready');
+	CREATE INDEX IF NOT EXISTS idx_holds_expiry ON holds(expires_at) WHERE status = 'ready';
+	-- A member may only hold a place in a book's queue once
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_holds_active_member ON holds(book_id, member_id) WHERE status IN ('waiting', 'ready');
+
+	-- A ready hold sets aside one copy of the book for pickup
+	ALTER TABLE holds ADD COLUMN IF NOT EXISTS copy_id UUID REFERENCES copies(id) ON DELETE SET NULL;
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_holds_ready_copy ON holds(copy_id) WHERE status = 'ready' AND copy_id IS NOT NULL;
+
+	-- Saved searches alert their owner when a newly created book matches the
+	-- stored filter (a JSON-encoded BookFilter)
+	CREATE TABLE IF NOT EXISTS saved_searches (
+		id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+		owner VARCHAR(255) NOT NULL,
+		name VARCHAR(100) NOT NULL,
+		filter JSONB NOT NULL DEFAULT '{}',
+		webhook_url TEXT NOT NULL DEFAULT '',
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_saved_searches_owner ON saved_searches(owner, created_at);
+
+	CREATE TABLE IF NOT EXISTS search_alerts (
+		id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+		saved_search_id UUID NOT NULL REFERENCES saved_searches(id) ON DELETE CASCADE,
+		owner VARCHAR(255) NOT NULL,
+		book_id UUID NOT NULL REFERENCES books(id) ON DELETE CASCADE,
+		book_title VARCHAR(255) NOT NULL,
+		book_author VARCHAR(255) NOT NULL,
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		read_at TIMESTAMP,
+		delivered_at TIMESTAMP,
+		delivery_error TEXT NOT NULL DEFAULT ''
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_search_alerts_owner ON search_alerts(owner, created_at DESC);
+	-- A retried matching job must not alert twice for the same book
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_search_alerts_book ON search_alerts(saved_search_id, book_id);
+
+	-- Book audit trail: one row per catalog write, made in the write's own
+	-- transaction. There is no foreign key so the trail outlives a purge.
+	CREATE TABLE IF NOT EXISTS book_audit (
+		id BIGSERIAL PRIMARY KEY,
+		book_id UUID NOT NULL,
+		action VARCHAR(20) NOT NULL,
+		actor VARCHAR(255) NOT NULL,
+		request_id VARCHAR(255) NOT NULL DEFAULT '',
+		before JSONB,
+		after JSONB,
+		changes JSONB NOT NULL DEFAULT '{}',
+		changed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_book_audit_book ON book_audit(book_id, changed_at, id);
+
+	-- Writes that leave a record behind are numbered as the book's revisions
+	ALTER TABLE book_audit ADD COLUMN IF NOT EXISTS revision INTEGER;
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_book_audit_revision ON book_audit(book_id, revision) WHERE revision IS NOT NULL;
+
+	CREATE OR REPLACE FUNCTION reject_book_audit_change()
+	RETURNS TRIGGER AS $$
+	BEGIN
+		RAISE EXCEPTION 'book_audit is append-only';
+	END;
+	$$ language 'plpgsql';
+
+	DROP TRIGGER IF EXISTS book_audit_append_only ON book_audit;
+	CREATE TRIGGER book_audit_append_only
+		BEFORE UPDATE OR DELETE ON book_audit
+		FOR EACH ROW
+		EXECUTE FUNCTION reject_book_audit_change();
+
+	-- Idempotency keys: the first response to a POST carrying a key is replayed
+	-- to retries of the same request until the key expires. A key without a
+	-- status code is held by a request still in flight.
+	CREATE TABLE IF NOT EXISTS idempotency_keys (
+		key VARCHAR(255) PRIMARY KEY,
+		fingerprint CHAR(64) NOT NULL,
+		status_code INTEGER,
+		response_header JSONB,
+		response_body BYTEA,
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		expires_at TIMESTAMP NOT NULL
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
+
+	-- Open loans and ready holds from before copies circulated, or made while a
+	-- book had no copies, take a copy of their book that is not already lent
+	-- or set aside. Each book had at most one of each, so no copy is taken twice.
+	UPDATE loans SET copy_id = (
+		SELECT c.id FROM copies c
+		WHERE c.book_id = loans.book_id
+			AND c.status IN ('available', 'checked_out')
+			AND NOT EXISTS (SELECT 1 FROM loans l WHERE l.copy_id = c.id AND l.returned_at IS NULL)
+			AND NOT EXISTS (SELECT 1 FROM holds h WHERE h.copy_id = c.id AND h.status = 'ready')
+		ORDER BY c.status = 'checked_out' DESC, c.barcode
+		LIMIT 1
+	)
+	WHERE returned_at IS NULL AND copy_id IS NULL
+		AND EXISTS (SELECT 1 FROM copies c WHERE c.book_id = loans.book_id);
+
+	UPDATE holds SET copy_id = (
+		SELECT c.id FROM copies c
+		WHERE c.book_id = holds.book_id
+			AND c.status = 'available'
+			AND NOT EXISTS (SELECT 1 FROM loans l WHERE l.copy_id = c.id AND l.returned_at IS NULL)
+			AND NOT EXISTS (SELECT 1 FROM holds h WHERE h.copy_id = c.id AND h.status = 'ready')
+		ORDER BY c.barcode
+		LIMIT 1
+	)
+	WHERE status = 'ready' AND copy_id IS NULL
+		AND EXISTS (SELECT 1 FROM copies c WHERE c.book_id = holds.book_id);
+
+	-- Copy statuses follow circulation: lent copies are checked_out and no others
+	UPDATE copies SET status = 'checked_out'
+	WHERE status <> 'checked_out'
+		AND EXISTS (SELECT 1 FROM loans WHERE loans.copy_id = copies.id AND loans.returned_at IS NULL);
+	UPDATE copies SET status = 'available'
+	WHERE status = 'checked_out'
+		AND NOT EXISTS (SELECT 1 FROM loans WHERE loans.copy_id = copies.id AND loans.returned_at IS NULL);
+
+	-- Availability is derived from free copies, or for books without copies from
+	-- open loans and holds awaiting pickup; reconcile any rows that drifted
+	UPDATE books SET available = NOT available
+	WHERE available <> CASE
+		WHEN EXISTS (SELECT 1 FROM copies c WHERE c.book_id = books.id) THEN EXISTS (
+			SELECT 1 FROM copies c
+			WHERE c.book_id = books.id
+				AND c.status = 'available'
+				AND NOT EXISTS (SELECT 1 FROM loans l WHERE l.copy_id = c.id AND l.returned_at IS NULL)
+				AND NOT EXISTS (SELECT 1 FROM holds h WHERE h.copy_id = c.id AND h.status = 'ready')
+		)
+		ELSE NOT EXISTS (SELECT 1 FROM loans WHERE loans.book_id = books.id AND loans.returned_at IS NULL)
+			AND NOT EXISTS (SELECT 1 FROM holds WHERE holds.book_id = books.id AND holds.status = 'ready')
+	END;
 	`
 
 	_, err := db.Exec(query)
@@ -90,6 +445,35 @@ func (db *DB) Migrate() error {
 		return fmt.Errorf("failed to run migrations: %w", err)
 	}
 
+	if err := db.reportISBNCollisions(); err != nil {
+		return err
+	}
+
 	log.Println("Database migrations completed")
 	return nil
 }
+
+// reportISBNCollisions logs the live books whose ISBN the migration could not
+// convert to canonical form because another live book already holds it
+func (db *DB) reportISBNCollisions() error {
+	rows, err := db.Query(`
+		SELECT id, isbn, book_isbn_canonical(isbn)
+		FROM books
+		WHERE deleted_at IS NULL AND isbn <> book_isbn_canonical(isbn)
+		ORDER BY isbn
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to check ISBN collisions: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id, isbn, canonical string
+		if err := rows.Scan(&id, &isbn, &canonical); err != nil {
+			return fmt.Errorf("failed to scan ISBN collision: %w", err)
+		}
+		log.Printf("Book %s keeps ISBN %q: another book has or converts to %s; merge or delete the duplicate and restart to convert it",
+			id, isbn, canonical)
+	}
+	return rows.Err()
+}
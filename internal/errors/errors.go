@@ -2,6 +2,7 @@ package errors
 
 import (
 	"encoding/json"
+	stderrors "errors"
 	"fmt"
 	"net/http"
 )
@@ -10,22 +11,31 @@ import (
 type ErrorCode string
 
 const (
-	CodeValidation   ErrorCode = "VALIDATION_ERROR"
-	CodeNotFound     ErrorCode = "NOT_FOUND"
-	CodeConflict     ErrorCode = "CONFLICT"
-	CodeInternal     ErrorCode = "INTERNAL_ERROR"
-	CodeTimeout      ErrorCode = "TIMEOUT"
-	CodeUnauthorized ErrorCode = "UNAUTHORIZED"
-	CodeRateLimit    ErrorCode = "RATE_LIMIT"
+	CodeValidation ErrorCode = "VALIDATION_ERROR"
+	CodeNotFound   ErrorCode = "NOT_FOUND"
+	CodeConflict   ErrorCode = "CONFLICT"
+	// CodeVersionConflict marks a write based on an outdated version of a resource
+	CodeVersionConflict ErrorCode = "VERSION_CONFLICT"
+	// CodeIdempotencyKeyReused marks a request that reuses an idempotency key
+	// first sent with a different request
+	CodeIdempotencyKeyReused ErrorCode = "IDEMPOTENCY_KEY_REUSED"
+	CodeInternal             ErrorCode = "INTERNAL_ERROR"
+	CodeTimeout              ErrorCode = "TIMEOUT"
+	CodeUnauthorized         ErrorCode = "UNAUTHORIZED"
+	CodeRateLimit            ErrorCode = "RATE_LIMIT"
 )
 
 // AppError represents a structured application error
 type AppError struct {
-	Code       ErrorCode `json:"code"`
-	Message    string    `json:"message"`
-	Details    string    `json:"details,omitempty"`
-	RequestID  string    `json:"request_id,omitempty"`
-	StatusCode int       `json:"-"`
+	Code    ErrorCode `json:"code"`
+	Message string    `json:"message"`
+	Details string    `json:"details,omitempty"`
+	// Position is the 1-based character offset of a syntax error in its input
+	Position int `json:"position,omitempty"`
+	// Current is the server's copy of a resource a stale write conflicted with
+	Current    interface{} `json:"current,omitempty"`
+	RequestID  string      `json:"request_id,omitempty"`
+	StatusCode int         `json:"-"`
 }
 
 func (e *AppError) Error() string {
@@ -47,6 +57,23 @@ func Validation(message, details string) *AppError {
 	return New(CodeValidation, message, details)
 }
 
+// Syntax creates a validation error for malformed input, pointing at the
+// 1-based character position where parsing failed
+func Syntax(message, details string, position int) *AppError {
+	err := New(CodeValidation, message, details)
+	err.Position = position
+	return err
+}
+
+// As finds the first AppError in err's chain
+func As(err error) (*AppError, bool) {
+	var appErr *AppError
+	if stderrors.As(err, &appErr) {
+		return appErr, true
+	}
+	return nil, false
+}
+
 // NotFound creates a not found error
 func NotFound(resource string) *AppError {
 	return New(CodeNotFound, fmt.Sprintf("%s not found", resource), "")
@@ -57,6 +84,15 @@ func Conflict(message, details string) *AppError {
 	return New(CodeConflict, message, details)
 }
 
+// VersionConflict creates a conflict error for a write made against an
+// outdated version of a resource, carrying the current copy so the client
+// can merge and retry
+func VersionConflict(message, details string, current interface{}) *AppError {
+	err := New(CodeVersionConflict, message, details)
+	err.Current = current
+	return err
+}
+
 // Internal creates an internal server error
 func Internal(message, details string) *AppError {
 	return New(CodeInternal, message, details)
@@ -99,8 +135,10 @@ func getStatusCodeForErrorCode(code ErrorCode) int {
 		return http.StatusBadRequest
 	case CodeNotFound:
 		return http.StatusNotFound
-	case CodeConflict:
+	case CodeConflict, CodeVersionConflict:
 		return http.StatusConflict
+	case CodeIdempotencyKeyReused:
+		return http.StatusUnprocessableEntity
 	case CodeUnauthorized:
 		return http.StatusUnauthorized
 	case CodeRateLimit:
@@ -1,6 +1,7 @@
 package errors
 
 import (
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -68,6 +69,51 @@ func TestAppError(t *testing.T) {
 	})
 }
 
+func TestSyntax(t *testing.T) {
+	t.Run("creates positioned validation error", func(t *testing.T) {
+		err := Syntax("invalid query", "unexpected \")\" at position 7", 7)
+
+		if err.Code != CodeValidation || err.StatusCode != http.StatusBadRequest {
+			t.Errorf("Expected a validation error, got %s (%d)", err.Code, err.StatusCode)
+		}
+
+		if err.Position != 7 {
+			t.Errorf("Expected position 7, got %d", err.Position)
+		}
+	})
+
+	t.Run("finds wrapped app errors", func(t *testing.T) {
+		wrapped := fmt.Errorf("failed to get books: %w", Syntax("invalid query", "query is empty at position 1", 1))
+
+		appErr, ok := As(wrapped)
+		if !ok || appErr.Position != 1 {
+			t.Errorf("Expected to find the wrapped app error, got %v", appErr)
+		}
+
+		if _, ok := As(&TestError{message: "generic error"}); ok {
+			t.Error("Expected no app error in a generic error")
+		}
+	})
+}
+
+func TestVersionConflict(t *testing.T) {
+	t.Run("carries the current copy", func(t *testing.T) {
+		current := map[string]int{"version": 4}
+		err := VersionConflict("book has been modified", "expected version 3, current version is 4", current)
+
+		if err.Code != CodeVersionConflict || err.StatusCode != http.StatusConflict {
+			t.Errorf("Expected a version conflict, got %s (%d)", err.Code, err.StatusCode)
+		}
+
+		w := httptest.NewRecorder()
+		WriteErrorResponse(w, err, "test-request-789")
+
+		if !containsString(w.Body.String(), `"current":{"version":4}`) {
+			t.Errorf("Expected response to contain the current copy, got %s", w.Body.String())
+		}
+	})
+}
+
 func TestWriteErrorResponse(t *testing.T) {
 	t.Run("writes app error response", func(t *testing.T) {
 		w := httptest.NewRecorder()
@@ -117,6 +163,8 @@ func TestErrorCodes(t *testing.T) {
 		{CodeValidation, http.StatusBadRequest},
 		{CodeNotFound, http.StatusNotFound},
 		{CodeConflict, http.StatusConflict},
+		{CodeVersionConflict, http.StatusConflict},
+		{CodeIdempotencyKeyReused, http.StatusUnprocessableEntity},
 		{CodeUnauthorized, http.StatusUnauthorized},
 		{CodeRateLimit, http.StatusTooManyRequests},
 		{CodeTimeout, http.StatusRequestTimeout},
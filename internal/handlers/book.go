@@ -3,12 +3,15 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"io"
 	"libmngmt/internal/errors"
 	"libmngmt/internal/middleware"
 	"libmngmt/internal/models"
 	"libmngmt/internal/service"
+	"mime"
 	"net/http"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -93,7 +96,7 @@ func (h *BookHandler) CreateBook(w http.ResponseWriter, r *http.Request) {
 	errorChan := make(chan error, 1)
 
 	go func() {
-		book, err := h.bookService.CreateBook(req)
+		book, err := h.bookService.CreateBook(req, auditInfo(r))
 		if err != nil {
 			errorChan <- err
 			return
@@ -132,6 +135,8 @@ func (h *BookHandler) GetBook(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	projection := parseProjection(r)
+
 	// Context with timeout
 	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
 	defer cancel()
@@ -141,7 +146,7 @@ func (h *BookHandler) GetBook(w http.ResponseWriter, r *http.Request) {
 	errChan := make(chan error, 1)
 
 	go func() {
-		book, err := h.bookService.GetBookByID(id)
+		book, err := h.bookService.GetBookByID(id, projection)
 		if err != nil {
 			errChan <- err
 			return
@@ -151,10 +156,22 @@ func (h *BookHandler) GetBook(w http.ResponseWriter, r *http.Request) {
 
 	select {
 	case book := <-bookChan:
+		etag, err := contentETag(book)
+		if err != nil {
+			h.writeErrorResponse(w, http.StatusInternalServerError, "Internal server error", err.Error())
+			return
+		}
+		setValidators(w, etag, book.UpdatedAt)
+		if notModified(r, etag, book.UpdatedAt) {
+			writeNotModified(w)
+			return
+		}
 		h.writeSuccessResponse(w, http.StatusOK, "Book retrieved successfully", book)
 	case err := <-errChan:
 		if isNotFoundError(err) {
 			h.writeErrorResponse(w, http.StatusNotFound, "Book not found", err.Error())
+		} else if isValidationError(err) {
+			h.writeErrorResponse(w, http.StatusBadRequest, "Validation error", err.Error())
 		} else {
 			h.writeErrorResponse(w, http.StatusInternalServerError, "Internal server error", err.Error())
 		}
@@ -176,20 +193,83 @@ func (h *BookHandler) GetBooks(w http.ResponseWriter, r *http.Request) {
 	go func() {
 		filter := models.BookFilter{}
 
+		// Author and publisher match by substring; a leading '!' excludes instead
 		if author := r.URL.Query().Get("author"); author != "" {
-			filter.Author = author
+			if excluded, ok := strings.CutPrefix(author, "!"); ok {
+				if excluded != "" {
+					filter.ExcludeAuthors = []string{excluded}
+				}
+			} else {
+				filter.Author = author
+			}
 		}
+		if publisher := r.URL.Query().Get("publisher"); publisher != "" {
+			if excluded, ok := strings.CutPrefix(publisher, "!"); ok {
+				if excluded != "" {
+					filter.ExcludePublishers = []string{excluded}
+				}
+			} else {
+				filter.Publisher = publisher
+			}
+		}
+		if isbn := r.URL.Query().Get("isbn"); isbn != "" {
+			filter.ISBN = isbn
+		}
+		// A single genre or language keeps its usual matching; lists and
+		// '!'-negated values become exact sets
 		if genre := r.URL.Query().Get("genre"); genre != "" {
-			filter.Genre = genre
+			if isValueList(genre) {
+				filter.Genres, filter.ExcludeGenres = parseValueList(genre)
+			} else {
+				filter.Genre = genre
+			}
 		}
 		if language := r.URL.Query().Get("language"); language != "" {
-			filter.Language = language
+			if isValueList(language) {
+				filter.Languages, filter.ExcludeLanguages = parseValueList(language)
+			} else {
+				filter.Language = language
+			}
+		}
+		filter.PublishedFrom = parseDateParam(r.URL.Query().Get("published_from"), false)
+		filter.PublishedTo = parseDateParam(r.URL.Query().Get("published_to"), true)
+		filter.CreatedSince = parseDateParam(r.URL.Query().Get("created_since"), false)
+		filter.CreatedUntil = parseDateParam(r.URL.Query().Get("created_until"), true)
+		filter.UpdatedSince = parseDateParam(r.URL.Query().Get("updated_since"), false)
+		filter.UpdatedUntil = parseDateParam(r.URL.Query().Get("updated_until"), true)
+		if minPagesStr := r.URL.Query().Get("min_pages"); minPagesStr != "" {
+			if minPages, err := strconv.Atoi(minPagesStr); err == nil && minPages >= 0 {
+				filter.MinPages = &minPages
+			}
+		}
+		if maxPagesStr := r.URL.Query().Get("max_pages"); maxPagesStr != "" {
+			if maxPages, err := strconv.Atoi(maxPagesStr); err == nil && maxPages >= 0 {
+				filter.MaxPages = &maxPages
+			}
+		}
+		if q := r.URL.Query().Get("q"); q != "" {
+			filter.Query = q
 		}
 		if availableStr := r.URL.Query().Get("available"); availableStr != "" {
 			if available, err := strconv.ParseBool(availableStr); err == nil {
 				filter.Available = &available
 			}
 		}
+		if fuzzyStr := r.URL.Query().Get("fuzzy"); fuzzyStr != "" {
+			if fuzzy, err := strconv.ParseBool(fuzzyStr); err == nil {
+				filter.Fuzzy = fuzzy
+			}
+		}
+		if facetsStr := r.URL.Query().Get("facets"); facetsStr != "" {
+			if facets, err := strconv.ParseBool(facetsStr); err == nil {
+				filter.Facets = facets
+			}
+		}
+		if similarityStr := r.URL.Query().Get("similarity"); similarityStr != "" {
+			if similarity, err := strconv.ParseFloat(similarityStr, 64); err == nil && similarity > 0 && similarity <= 1 {
+				filter.Similarity = similarity
+			}
+		}
 		if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
 			if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 {
 				filter.Limit = limit
@@ -200,6 +280,18 @@ func (h *BookHandler) GetBooks(w http.ResponseWriter, r *http.Request) {
 				filter.Offset = offset
 			}
 		}
+		if sort := r.URL.Query().Get("sort"); sort != "" {
+			filter.Sort = sort
+		}
+		if cursor := r.URL.Query().Get("cursor"); cursor != "" {
+			filter.Cursor = cursor
+		}
+		if includeTotalStr := r.URL.Query().Get("include_total"); includeTotalStr != "" {
+			if includeTotal, err := strconv.ParseBool(includeTotalStr); err == nil {
+				filter.IncludeTotal = &includeTotal
+			}
+		}
+		filter.BookProjection = parseProjection(r)
 
 		filterChan <- filter
 	}()
@@ -225,15 +317,95 @@ func (h *BookHandler) GetBooks(w http.ResponseWriter, r *http.Request) {
 
 	select {
 	case response := <-responseChan:
+		// A listing has no Last-Modified: the newest updated_at on a page
+		// misses removals and changes to books that left it, so only the
+		// content ETag can tell whether the listing is unchanged
+		etag, err := contentETag(response)
+		if err != nil {
+			h.writeErrorResponse(w, http.StatusInternalServerError, "Internal server error", err.Error())
+			return
+		}
+		setValidators(w, etag, time.Time{})
+		if notModified(r, etag, time.Time{}) {
+			writeNotModified(w)
+			return
+		}
 		h.writeSuccessResponse(w, http.StatusOK, "Books retrieved successfully", response)
 	case err := <-errChan:
+		// Query syntax errors carry the position of the problem
+		if appErr, ok := errors.As(err); ok {
+			errors.WriteErrorResponse(w, appErr, middleware.GetRequestID(r.Context()))
+			return
+		}
+		if isValidationError(err) {
+			h.writeErrorResponse(w, http.StatusBadRequest, "Validation error", err.Error())
+			return
+		}
 		h.writeErrorResponse(w, http.StatusInternalServerError, "Internal server error", err.Error())
 	case <-ctx.Done():
 		h.writeErrorResponse(w, http.StatusRequestTimeout, "Request timeout", "Books retrieval timed out")
 	}
 }
 
-// UpdateBook handles PUT /api/books/{id}
+// SearchBooks handles GET /api/books/search?q=
+func (h *BookHandler) SearchBooks(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	defer h.recordMetrics("SearchBooks", start)
+
+	query := r.URL.Query()
+	filter := models.BookSearchFilter{
+		Query:    query.Get("q"),
+		Language: query.Get("language"),
+	}
+	if limit, err := strconv.Atoi(query.Get("limit")); err == nil && limit > 0 {
+		filter.Limit = limit
+	}
+	if offset, err := strconv.Atoi(query.Get("offset")); err == nil && offset >= 0 {
+		filter.Offset = offset
+	}
+
+	response, err := h.bookService.SearchBooks(filter)
+	if err != nil {
+		if isValidationError(err) {
+			h.writeErrorResponse(w, http.StatusBadRequest, "Validation error", err.Error())
+			return
+		}
+		h.writeErrorResponse(w, http.StatusInternalServerError, "Internal server error", err.Error())
+		return
+	}
+
+	h.writeSuccessResponse(w, http.StatusOK, "Search completed successfully", response)
+}
+
+// AutocompleteBooks handles GET /api/books/autocomplete?prefix=&field=
+func (h *BookHandler) AutocompleteBooks(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	defer h.recordMetrics("AutocompleteBooks", start)
+
+	query := r.URL.Query()
+	filter := models.AutocompleteFilter{
+		Field:  models.SuggestField(query.Get("field")),
+		Prefix: query.Get("prefix"),
+	}
+	if limit, err := strconv.Atoi(query.Get("limit")); err == nil && limit > 0 {
+		filter.Limit = limit
+	}
+
+	response, err := h.bookService.AutocompleteBooks(filter)
+	if err != nil {
+		if isValidationError(err) {
+			h.writeErrorResponse(w, http.StatusBadRequest, "Validation error", err.Error())
+			return
+		}
+		h.writeErrorResponse(w, http.StatusInternalServerError, "Internal server error", err.Error())
+		return
+	}
+
+	h.writeSuccessResponse(w, http.StatusOK, "Suggestions retrieved successfully", response)
+}
+
+// UpdateBook handles PUT /api/books/{id}, replacing every writable field of
+// the book with the request body
 func (h *BookHandler) UpdateBook(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id, err := uuid.Parse(vars["id"])
@@ -242,22 +414,202 @@ func (h *BookHandler) UpdateBook(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var req models.UpdateBookRequest
+	var req models.ReplaceBookRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON", err.Error())
 		return
 	}
 
-	book, err := h.bookService.UpdateBook(id, &req)
+	matched, ok := h.checkIfMatch(w, r, id)
+	if !ok {
+		return
+	}
+	// The update only applies to the version the If-Match tag was computed
+	// from, closing the window between the check and the write
+	pinned := req.Version == nil && matched != nil
+	if pinned {
+		req.Version = &matched.Version
+	}
+
+	book, err := h.bookService.ReplaceBook(id, &req, auditInfo(r))
+	if err != nil {
+		h.writeBookWriteError(w, r, err, pinned)
+		return
+	}
+
+	if etag, err := contentETag(book); err == nil {
+		setValidators(w, etag, book.UpdatedAt)
+	}
+	h.writeSuccessResponse(w, http.StatusOK, "Book updated successfully", book)
+}
+
+// PatchBook handles PATCH /api/books/{id} with a JSON Merge Patch or a JSON
+// Patch document, chosen by Content-Type
+func (h *BookHandler) PatchBook(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := uuid.Parse(vars["id"])
+	if err != nil {
+		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid book ID", "ID must be a valid UUID")
+		return
+	}
+
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	format := models.PatchFormat(mediaType)
+	if err != nil || (format != models.PatchMerge && format != models.PatchJSON) {
+		w.Header().Set("Accept-Patch", string(models.PatchMerge)+", "+string(models.PatchJSON))
+		h.writeErrorResponse(w, http.StatusUnsupportedMediaType, "Unsupported patch format",
+			"Content-Type must be "+string(models.PatchMerge)+" or "+string(models.PatchJSON))
+		return
+	}
+
+	document, err := io.ReadAll(r.Body)
 	if err != nil {
+		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	matched, ok := h.checkIfMatch(w, r, id)
+	if !ok {
+		return
+	}
+	bookPatch := &models.BookPatch{Format: format, Document: document}
+	if matched != nil {
+		bookPatch.Version = &matched.Version
+	}
+
+	book, err := h.bookService.PatchBook(id, bookPatch, auditInfo(r))
+	if err != nil {
+		h.writeBookWriteError(w, r, err, matched != nil)
+		return
+	}
+
+	if etag, err := contentETag(book); err == nil {
+		setValidators(w, etag, book.UpdatedAt)
+	}
+	h.writeSuccessResponse(w, http.StatusOK, "Book updated successfully", book)
+}
+
+// writeBookWriteError maps an error from replacing, patching or reverting a
+// book to its response. pinned reports whether the write was pinned to the
+// version an If-Match tag matched, in which case a version conflict means the
+// precondition failed rather than the body's version being stale.
+func (h *BookHandler) writeBookWriteError(w http.ResponseWriter, r *http.Request, err error, pinned bool) {
+	// Version conflicts carry the current server copy
+	if appErr, ok := errors.As(err); ok {
+		if pinned && appErr.Code == errors.CodeVersionConflict {
+			h.writeChangedPrecondition(w, appErr)
+			return
+		}
+		errors.WriteErrorResponse(w, appErr, middleware.GetRequestID(r.Context()))
+		return
+	}
+	if isNotFoundError(err) {
+		h.writeErrorResponse(w, http.StatusNotFound, "Book not found", err.Error())
+		return
+	}
+	if isValidationError(err) {
+		h.writeErrorResponse(w, http.StatusBadRequest, "Validation error", err.Error())
+		return
+	}
+	if isDuplicateError(err) {
+		h.writeErrorResponse(w, http.StatusConflict, "Duplicate resource", err.Error())
+		return
+	}
+	h.writeErrorResponse(w, http.StatusInternalServerError, "Internal server error", err.Error())
+}
+
+// DeleteBook handles DELETE /api/books/{id}
+func (h *BookHandler) DeleteBook(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := uuid.Parse(vars["id"])
+	if err != nil {
+		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid book ID", "ID must be a valid UUID")
+		return
+	}
+
+	matched, ok := h.checkIfMatch(w, r, id)
+	if !ok {
+		return
+	}
+	// The delete only goes ahead on the version the ETag was computed from
+	var version *int
+	if matched != nil {
+		version = &matched.Version
+	}
+
+	err = h.bookService.DeleteBook(id, version, auditInfo(r))
+	if err != nil {
+		if appErr, ok := errors.As(err); ok && appErr.Code == errors.CodeVersionConflict {
+			h.writeChangedPrecondition(w, appErr)
+			return
+		}
 		if isNotFoundError(err) {
 			h.writeErrorResponse(w, http.StatusNotFound, "Book not found", err.Error())
 			return
 		}
-		if isValidationError(err) {
-			h.writeErrorResponse(w, http.StatusBadRequest, "Validation error", err.Error())
+		if isCirculationConflict(err) {
+			h.writeErrorResponse(w, http.StatusConflict, "Circulation conflict", err.Error())
 			return
 		}
+		h.writeErrorResponse(w, http.StatusInternalServerError, "Internal server error", err.Error())
+		return
+	}
+
+	h.writeSuccessResponse(w, http.StatusOK, "Book deleted successfully", nil)
+}
+
+// writeChangedPrecondition answers a write whose If-Match tag matched but
+// which lost to a concurrent write before it landed, with the validators of
+// the current book
+func (h *BookHandler) writeChangedPrecondition(w http.ResponseWriter, appErr *errors.AppError) {
+	if current, ok := appErr.Current.(*models.Book); ok {
+		if etag, err := contentETag(current); err == nil {
+			setValidators(w, etag, current.UpdatedAt)
+		}
+	}
+	h.writeErrorResponse(w, http.StatusPreconditionFailed, "Precondition failed",
+		"book has changed since it was read; fetch it again and retry with its current ETag")
+}
+
+// GetTrash handles GET /api/books/trash
+func (h *BookHandler) GetTrash(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	defer h.recordMetrics("GetTrash", start)
+
+	query := r.URL.Query()
+	var filter models.TrashFilter
+	if limit, err := strconv.Atoi(query.Get("limit")); err == nil && limit > 0 {
+		filter.Limit = limit
+	}
+	if offset, err := strconv.Atoi(query.Get("offset")); err == nil && offset >= 0 {
+		filter.Offset = offset
+	}
+
+	response, err := h.bookService.GetTrash(filter)
+	if err != nil {
+		h.writeErrorResponse(w, http.StatusInternalServerError, "Internal server error", err.Error())
+		return
+	}
+
+	h.writeSuccessResponse(w, http.StatusOK, "Trash retrieved successfully", response)
+}
+
+// RestoreBook handles POST /api/books/{id}/restore
+func (h *BookHandler) RestoreBook(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := uuid.Parse(vars["id"])
+	if err != nil {
+		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid book ID", "ID must be a valid UUID")
+		return
+	}
+
+	book, err := h.bookService.RestoreBook(id, auditInfo(r))
+	if err != nil {
+		if isNotFoundError(err) {
+			h.writeErrorResponse(w, http.StatusNotFound, "Book not found", err.Error())
+			return
+		}
+		// Another book took the ISBN while this one was in the trash
 		if isDuplicateError(err) {
 			h.writeErrorResponse(w, http.StatusConflict, "Duplicate resource", err.Error())
 			return
@@ -266,11 +618,17 @@ func (h *BookHandler) UpdateBook(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	h.writeSuccessResponse(w, http.StatusOK, "Book updated successfully", book)
+	if etag, err := contentETag(book); err == nil {
+		setValidators(w, etag, book.UpdatedAt)
+	}
+	h.writeSuccessResponse(w, http.StatusOK, "Book restored successfully", book)
 }
 
-// DeleteBook handles DELETE /api/books/{id}
-func (h *BookHandler) DeleteBook(w http.ResponseWriter, r *http.Request) {
+// GetBookHistory handles GET /api/books/{id}/history
+func (h *BookHandler) GetBookHistory(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	defer h.recordMetrics("GetBookHistory", start)
+
 	vars := mux.Vars(r)
 	id, err := uuid.Parse(vars["id"])
 	if err != nil {
@@ -278,7 +636,7 @@ func (h *BookHandler) DeleteBook(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = h.bookService.DeleteBook(id)
+	history, err := h.bookService.GetBookHistory(id)
 	if err != nil {
 		if isNotFoundError(err) {
 			h.writeErrorResponse(w, http.StatusNotFound, "Book not found", err.Error())
@@ -288,7 +646,124 @@ func (h *BookHandler) DeleteBook(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	h.writeSuccessResponse(w, http.StatusOK, "Book deleted successfully", nil)
+	h.writeSuccessResponse(w, http.StatusOK, "Book history retrieved successfully", history)
+}
+
+// GetBookAsOf handles GET /api/books/{id}/as-of?at=, the book's catalog
+// record as it stood at a point in time
+func (h *BookHandler) GetBookAsOf(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	defer h.recordMetrics("GetBookAsOf", start)
+
+	vars := mux.Vars(r)
+	id, err := uuid.Parse(vars["id"])
+	if err != nil {
+		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid book ID", "ID must be a valid UUID")
+		return
+	}
+
+	// A bare date means the end of that day
+	at := parseDateParam(r.URL.Query().Get("at"), true)
+	if at == nil {
+		h.writeErrorResponse(w, http.StatusBadRequest, "Validation error", "at is required as an RFC 3339 timestamp or a 2006-01-02 date")
+		return
+	}
+
+	snapshot, err := h.bookService.GetBookAsOf(id, *at)
+	if err != nil {
+		if isNotFoundError(err) {
+			h.writeErrorResponse(w, http.StatusNotFound, "Book not found", err.Error())
+			return
+		}
+		h.writeErrorResponse(w, http.StatusInternalServerError, "Internal server error", err.Error())
+		return
+	}
+
+	h.writeSuccessResponse(w, http.StatusOK, "Book retrieved successfully", snapshot)
+}
+
+// RevertBook handles POST /api/books/{id}/revisions/{rev}/revert, writing an
+// earlier revision of the book back as a new revision
+func (h *BookHandler) RevertBook(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := uuid.Parse(vars["id"])
+	if err != nil {
+		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid book ID", "ID must be a valid UUID")
+		return
+	}
+
+	revision, err := strconv.Atoi(vars["rev"])
+	if err != nil || revision < 1 {
+		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid revision", "Revision must be a positive integer")
+		return
+	}
+
+	matched, ok := h.checkIfMatch(w, r, id)
+	if !ok {
+		return
+	}
+	revert := &models.BookRevert{Revision: revision}
+	if matched != nil {
+		revert.Version = &matched.Version
+	}
+
+	book, err := h.bookService.RevertBook(id, revert, auditInfo(r))
+	if err != nil {
+		h.writeBookWriteError(w, r, err, matched != nil)
+		return
+	}
+
+	if etag, err := contentETag(book); err == nil {
+		setValidators(w, etag, book.UpdatedAt)
+	}
+	h.writeSuccessResponse(w, http.StatusOK, "Book reverted successfully", book)
+}
+
+// auditInfo identifies the actor and request behind a write for the audit trail
+func auditInfo(r *http.Request) models.AuditInfo {
+	return models.AuditInfo{
+		Actor:     middleware.GetActor(r.Context()),
+		RequestID: middleware.GetRequestID(r.Context()),
+	}
+}
+
+// checkIfMatch requires a write to a book to carry an If-Match header naming
+// the book's current ETag, so clients cannot overwrite changes they have not
+// seen. It returns the book the header matched, or nil for "*", and writes
+// the error response and returns false when the write must not go ahead.
+func (h *BookHandler) checkIfMatch(w http.ResponseWriter, r *http.Request, id uuid.UUID) (*models.Book, bool) {
+	header := r.Header.Get("If-Match")
+	if header == "" {
+		h.writeErrorResponse(w, http.StatusPreconditionRequired, "Precondition required",
+			"If-Match header with the book's current ETag is required")
+		return nil, false
+	}
+
+	current, err := h.bookService.GetBookByID(id, models.BookProjection{})
+	if err != nil {
+		if isNotFoundError(err) {
+			h.writeErrorResponse(w, http.StatusNotFound, "Book not found", err.Error())
+		} else {
+			h.writeErrorResponse(w, http.StatusInternalServerError, "Internal server error", err.Error())
+		}
+		return nil, false
+	}
+
+	etag, err := contentETag(current)
+	if err != nil {
+		h.writeErrorResponse(w, http.StatusInternalServerError, "Internal server error", err.Error())
+		return nil, false
+	}
+	if !etagListMatches(header, etag, true) {
+		setValidators(w, etag, current.UpdatedAt)
+		h.writeErrorResponse(w, http.StatusPreconditionFailed, "Precondition failed",
+			"book has changed since it was read; fetch it again and retry with its current ETag")
+		return nil, false
+	}
+	if strings.TrimSpace(header) == "*" {
+		return nil, true
+	}
+	return current, true
 }
 
 // BulkCreateBooks handles bulk book creation
@@ -320,6 +795,20 @@ func (h *BookHandler) BulkCreateBooks(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	atomic := false
+	if value := r.URL.Query().Get("atomic"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			h.writeErrorResponse(w, http.StatusBadRequest, "Invalid parameter", "atomic must be true or false")
+			return
+		}
+		atomic = parsed
+	}
+	if atomic {
+		h.bulkCreateAtomic(ctx, w, r, requests)
+		return
+	}
+
 	// Process bulk creation
 	resultChan := make(chan struct {
 		books  []*models.Book
@@ -327,7 +816,7 @@ func (h *BookHandler) BulkCreateBooks(w http.ResponseWriter, r *http.Request) {
 	}, 1)
 
 	go func() {
-		books, errors := h.bookService.BulkCreateBooks(requests)
+		books, errors := h.bookService.BulkCreateBooks(requests, auditInfo(r))
 		resultChan <- struct {
 			books  []*models.Book
 			errors []error
@@ -376,6 +865,84 @@ func (h *BookHandler) BulkCreateBooks(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// bulkCreateAtomic creates a batch of books all together or not at all. A
+// batch with invalid books gets 400 listing each one's error by index, in the
+// same shape as a bulk create where every book failed. The batch is written in
+// a transaction bound to ctx and so is rolled back if the request times out.
+func (h *BookHandler) bulkCreateAtomic(ctx context.Context, w http.ResponseWriter, r *http.Request, requests []*models.CreateBookRequest) {
+	books, errs, err := h.bookService.BulkCreateBooksAtomic(ctx, requests, auditInfo(r))
+	if errs != nil {
+		var errorDetails []map[string]interface{}
+		for i, err := range errs {
+			if err != nil {
+				errorDetails = append(errorDetails, map[string]interface{}{
+					"index": i,
+					"error": err.Error(),
+					"book":  requests[i],
+				})
+			}
+		}
+
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"total_requested": len(requests),
+			"successful":      0,
+			"failed":          len(requests),
+			"books":           []*models.Book{},
+			"errors":          errorDetails,
+		})
+		return
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			h.writeErrorResponse(w, http.StatusRequestTimeout, "Request timeout", "Bulk creation timed out")
+			return
+		}
+		h.writeErrorResponse(w, http.StatusInternalServerError, "Internal server error", err.Error())
+		return
+	}
+
+	h.writeSuccessResponse(w, http.StatusCreated, "All books created successfully", map[string]interface{}{
+		"total_requested": len(requests),
+		"successful":      len(books),
+		"failed":          0,
+		"books":           books,
+		"errors":          nil,
+	})
+}
+
+// EnrichBook handles POST /api/books/enrich. It fills the missing fields of a
+// create request from the ISBN's metadata and returns it without creating a book.
+func (h *BookHandler) EnrichBook(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	defer h.recordMetrics("EnrichBook", start)
+
+	var req models.CreateBookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON", err.Error())
+		return
+	}
+
+	response, err := h.bookService.EnrichBook(&req)
+	if err != nil {
+		switch {
+		case contains(err.Error(), "not configured"):
+			h.writeErrorResponse(w, http.StatusServiceUnavailable, "Metadata unavailable", err.Error())
+		case contains(err.Error(), "lookup failed"):
+			h.writeErrorResponse(w, http.StatusBadGateway, "Metadata lookup failed", err.Error())
+		case isNotFoundError(err):
+			h.writeErrorResponse(w, http.StatusNotFound, "Metadata not found", err.Error())
+		case isValidationError(err):
+			h.writeErrorResponse(w, http.StatusBadRequest, "Validation error", err.Error())
+		default:
+			h.writeErrorResponse(w, http.StatusInternalServerError, "Internal server error", err.Error())
+		}
+		return
+	}
+
+	h.writeSuccessResponse(w, http.StatusOK, "Book metadata enriched", response)
+}
+
 // GetMetrics returns handler metrics
 func (h *BookHandler) GetMetrics(w http.ResponseWriter, r *http.Request) {
 	h.metrics.mu.RLock()
@@ -436,22 +1003,11 @@ func (h *BookHandler) recordMetrics(operation string, start time.Time) {
 }
 
 func (h *BookHandler) writeSuccessResponse(w http.ResponseWriter, statusCode int, message string, data interface{}) {
-	w.WriteHeader(statusCode)
-	response := models.SuccessResponse{
-		Message: message,
-		Data:    data,
-	}
-	json.NewEncoder(w).Encode(response)
+	writeSuccessResponse(w, statusCode, message, data)
 }
 
 func (h *BookHandler) writeErrorResponse(w http.ResponseWriter, statusCode int, error, message string) {
-	w.WriteHeader(statusCode)
-	response := models.ErrorResponse{
-		Error:   error,
-		Message: message,
-		Code:    statusCode,
-	}
-	json.NewEncoder(w).Encode(response)
+	writeErrorResponse(w, statusCode, error, message)
 }
 
 // writeStructuredErrorResponse uses the new error handling system
@@ -480,6 +1036,61 @@ func (h *BookHandler) writeStructuredErrorResponse(w http.ResponseWriter, r *htt
 	errors.WriteErrorResponse(w, appErr, requestID)
 }
 
+// isValueList reports whether a genre or language parameter lists several
+// values or negates one
+func isValueList(value string) bool {
+	return strings.Contains(value, ",") || strings.HasPrefix(value, "!")
+}
+
+// parseValueList splits a comma-separated parameter into the values to match
+// and, for items prefixed with '!', the values to exclude
+func parseValueList(value string) (include, exclude []string) {
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if excluded, ok := strings.CutPrefix(item, "!"); ok {
+			if excluded = strings.TrimSpace(excluded); excluded != "" {
+				exclude = append(exclude, excluded)
+			}
+		} else if item != "" {
+			include = append(include, item)
+		}
+	}
+	return include, exclude
+}
+
+// parseProjection reads the comma-separated fields and include parameters
+// that shape book responses. Names are validated by the service.
+func parseProjection(r *http.Request) models.BookProjection {
+	var projection models.BookProjection
+	if fields := r.URL.Query().Get("fields"); fields != "" {
+		projection.Fields = strings.Split(fields, ",")
+	}
+	if include := r.URL.Query().Get("include"); include != "" {
+		projection.Include = strings.Split(include, ",")
+	}
+	return projection
+}
+
+// parseDateParam parses an RFC 3339 timestamp or a 2006-01-02 date. A bare
+// date used as an upper bound covers the whole day. Unparsable values are
+// ignored like the other list parameters.
+func parseDateParam(value string, upper bool) *time.Time {
+	if value == "" {
+		return nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil
+	}
+	if upper {
+		t = t.Add(24*time.Hour - time.Microsecond)
+	}
+	return &t
+}
+
 // Error classification helpers
 func isValidationError(err error) bool {
 	errMsg := err.Error()
@@ -5,6 +5,8 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	apperrors "libmngmt/internal/errors"
+	"libmngmt/internal/middleware"
 	"libmngmt/internal/models"
 	"libmngmt/internal/service"
 	"net/http"
@@ -20,21 +22,24 @@ import (
 	"github.com/stretchr/testify/mock"
 )
 
+// anonymousAudit is what writes made without an X-Actor header are recorded as
+var anonymousAudit = models.AuditInfo{Actor: middleware.AnonymousActor}
+
 // MockBookService is a mock implementation of BookService for testing
 type MockBookService struct {
 	mock.Mock
 }
 
-func (m *MockBookService) CreateBook(req *models.CreateBookRequest) (*models.Book, error) {
-	args := m.Called(req)
+func (m *MockBookService) CreateBook(req *models.CreateBookRequest, audit models.AuditInfo) (*models.Book, error) {
+	args := m.Called(req, audit)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*models.Book), args.Error(1)
 }
 
-func (m *MockBookService) GetBookByID(id uuid.UUID) (*models.Book, error) {
-	args := m.Called(id)
+func (m *MockBookService) GetBookByID(id uuid.UUID, projection models.BookProjection) (*models.Book, error) {
+	args := m.Called(id, projection)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -49,27 +54,125 @@ func (m *MockBookService) GetAllBooks(filter models.BookFilter) (*models.BooksLi
 	return args.Get(0).(*models.BooksListResponse), args.Error(1)
 }
 
-func (m *MockBookService) UpdateBook(id uuid.UUID, req *models.UpdateBookRequest) (*models.Book, error) {
-	args := m.Called(id, req)
+func (m *MockBookService) UpdateBook(id uuid.UUID, req *models.UpdateBookRequest, audit models.AuditInfo) (*models.Book, error) {
+	args := m.Called(id, req, audit)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*models.Book), args.Error(1)
 }
 
-func (m *MockBookService) DeleteBook(id uuid.UUID) error {
-	args := m.Called(id)
+func (m *MockBookService) ReplaceBook(id uuid.UUID, req *models.ReplaceBookRequest, audit models.AuditInfo) (*models.Book, error) {
+	args := m.Called(id, req, audit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Book), args.Error(1)
+}
+
+func (m *MockBookService) PatchBook(id uuid.UUID, p *models.BookPatch, audit models.AuditInfo) (*models.Book, error) {
+	args := m.Called(id, p, audit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Book), args.Error(1)
+}
+
+func (m *MockBookService) DeleteBook(id uuid.UUID, version *int, audit models.AuditInfo) error {
+	args := m.Called(id, version, audit)
 	return args.Error(0)
 }
 
-func (m *MockBookService) BulkCreateBooks(reqs []*models.CreateBookRequest) ([]*models.Book, []error) {
-	args := m.Called(reqs)
+func (m *MockBookService) GetTrash(filter models.TrashFilter) (*models.BooksListResponse, error) {
+	args := m.Called(filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.BooksListResponse), args.Error(1)
+}
+
+func (m *MockBookService) RestoreBook(id uuid.UUID, audit models.AuditInfo) (*models.Book, error) {
+	args := m.Called(id, audit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Book), args.Error(1)
+}
+
+func (m *MockBookService) PurgeTrash(retention time.Duration) (int, error) {
+	args := m.Called(retention)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockBookService) GetBookHistory(id uuid.UUID) (*models.BookHistoryResponse, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.BookHistoryResponse), args.Error(1)
+}
+
+func (m *MockBookService) GetBookAsOf(id uuid.UUID, at time.Time) (*models.BookAsOfResponse, error) {
+	args := m.Called(id, at)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.BookAsOfResponse), args.Error(1)
+}
+
+func (m *MockBookService) RevertBook(id uuid.UUID, revert *models.BookRevert, audit models.AuditInfo) (*models.Book, error) {
+	args := m.Called(id, revert, audit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Book), args.Error(1)
+}
+
+func (m *MockBookService) BulkCreateBooks(reqs []*models.CreateBookRequest, audit models.AuditInfo) ([]*models.Book, []error) {
+	args := m.Called(reqs, audit)
 	if args.Get(0) == nil {
 		return nil, args.Get(1).([]error)
 	}
 	return args.Get(0).([]*models.Book), args.Get(1).([]error)
 }
 
+func (m *MockBookService) BulkCreateBooksAtomic(ctx context.Context, reqs []*models.CreateBookRequest, audit models.AuditInfo) ([]*models.Book, []error, error) {
+	args := m.Called(ctx, reqs, audit)
+	var books []*models.Book
+	if args.Get(0) != nil {
+		books = args.Get(0).([]*models.Book)
+	}
+	var errs []error
+	if args.Get(1) != nil {
+		errs = args.Get(1).([]error)
+	}
+	return books, errs, args.Error(2)
+}
+
+func (m *MockBookService) SearchBooks(filter models.BookSearchFilter) (*models.BookSearchResponse, error) {
+	args := m.Called(filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.BookSearchResponse), args.Error(1)
+}
+
+func (m *MockBookService) AutocompleteBooks(filter models.AutocompleteFilter) (*models.AutocompleteResponse, error) {
+	args := m.Called(filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.AutocompleteResponse), args.Error(1)
+}
+
+func (m *MockBookService) EnrichBook(req *models.CreateBookRequest) (*models.EnrichBookResponse, error) {
+	args := m.Called(req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.EnrichBookResponse), args.Error(1)
+}
+
 func (m *MockBookService) GetMetrics() service.ServiceMetrics {
 	args := m.Called()
 	return args.Get(0).(service.ServiceMetrics)
@@ -81,6 +184,10 @@ func (m *MockBookService) Shutdown(ctx context.Context) error {
 }
 
 // Test utilities
+func intPtr(value int) *int {
+	return &value
+}
+
 func createTestBook() *models.Book {
 	id := uuid.New()
 	now := time.Now()
@@ -141,7 +248,7 @@ func TestBookHandler_CreateBook(t *testing.T) {
 		req := createValidCreateRequest()
 		book := createTestBook()
 
-		mockService.On("CreateBook", req).Return(book, nil)
+		mockService.On("CreateBook", req, anonymousAudit).Return(book, nil)
 
 		reqBody, _ := json.Marshal(req)
 		httpReq := httptest.NewRequest("POST", "/api/books", bytes.NewBuffer(reqBody))
@@ -184,7 +291,7 @@ func TestBookHandler_CreateBook(t *testing.T) {
 
 		req := createValidCreateRequest()
 
-		mockService.On("CreateBook", req).Return(nil, errors.New("service error"))
+		mockService.On("CreateBook", req, anonymousAudit).Return(nil, errors.New("service error"))
 
 		reqBody, _ := json.Marshal(req)
 		httpReq := httptest.NewRequest("POST", "/api/books", bytes.NewBuffer(reqBody))
@@ -209,7 +316,7 @@ func TestBookHandler_CreateBook(t *testing.T) {
 
 		req := createValidCreateRequest()
 
-		mockService.On("CreateBook", req).Return(nil, errors.New("title is required"))
+		mockService.On("CreateBook", req, anonymousAudit).Return(nil, errors.New("title is required"))
 
 		reqBody, _ := json.Marshal(req)
 		httpReq := httptest.NewRequest("POST", "/api/books", bytes.NewBuffer(reqBody))
@@ -234,7 +341,7 @@ func TestBookHandler_CreateBook(t *testing.T) {
 
 		req := createValidCreateRequest()
 
-		mockService.On("CreateBook", req).Return(nil, errors.New("book with ISBN 9781234567890 already exists"))
+		mockService.On("CreateBook", req, anonymousAudit).Return(nil, errors.New("book with ISBN 9781234567890 already exists"))
 
 		reqBody, _ := json.Marshal(req)
 		httpReq := httptest.NewRequest("POST", "/api/books", bytes.NewBuffer(reqBody))
@@ -287,7 +394,7 @@ func TestBookHandler_GetBook(t *testing.T) {
 
 		book := createTestBook()
 
-		mockService.On("GetBookByID", book.ID).Return(book, nil)
+		mockService.On("GetBookByID", book.ID, models.BookProjection{}).Return(book, nil)
 
 		httpReq := httptest.NewRequest("GET", "/api/books/"+book.ID.String(), nil)
 		httpReq = mux.SetURLVars(httpReq, map[string]string{"id": book.ID.String()})
@@ -329,7 +436,7 @@ func TestBookHandler_GetBook(t *testing.T) {
 
 		id := uuid.New()
 
-		mockService.On("GetBookByID", id).Return(nil, errors.New("book not found"))
+		mockService.On("GetBookByID", id, models.BookProjection{}).Return(nil, errors.New("book not found"))
 
 		httpReq := httptest.NewRequest("GET", "/api/books/"+id.String(), nil)
 		httpReq = mux.SetURLVars(httpReq, map[string]string{"id": id.String()})
@@ -352,7 +459,7 @@ func TestBookHandler_GetBook(t *testing.T) {
 
 		id := uuid.New()
 
-		mockService.On("GetBookByID", id).Return(nil, errors.New("service error"))
+		mockService.On("GetBookByID", id, models.BookProjection{}).Return(nil, errors.New("service error"))
 
 		httpReq := httptest.NewRequest("GET", "/api/books/"+id.String(), nil)
 		httpReq = mux.SetURLVars(httpReq, map[string]string{"id": id.String()})
@@ -373,6 +480,81 @@ func TestBookHandler_GetBook(t *testing.T) {
 }
 
 // Test GetBooks handler
+func TestBookHandler_GetBookConditional(t *testing.T) {
+	t.Run("validators are sent and a matching ETag is not modified", func(t *testing.T) {
+		handler, mockService := setupHandlerTest()
+
+		book := createTestBook()
+		mockService.On("GetBookByID", book.ID, models.BookProjection{}).Return(book, nil)
+
+		get := func(header, value string) *httptest.ResponseRecorder {
+			httpReq := httptest.NewRequest("GET", "/api/books/"+book.ID.String(), nil)
+			httpReq = mux.SetURLVars(httpReq, map[string]string{"id": book.ID.String()})
+			if header != "" {
+				httpReq.Header.Set(header, value)
+			}
+			w := httptest.NewRecorder()
+			handler.GetBook(w, httpReq)
+			return w
+		}
+
+		first := get("", "")
+		assert.Equal(t, http.StatusOK, first.Code)
+		etag := first.Header().Get("ETag")
+		assert.Regexp(t, `^"[0-9a-f]{32}"$`, etag)
+		assert.Equal(t, book.UpdatedAt.UTC().Format(http.TimeFormat), first.Header().Get("Last-Modified"))
+
+		revalidated := get("If-None-Match", `"stale", `+etag)
+		assert.Equal(t, http.StatusNotModified, revalidated.Code)
+		assert.Empty(t, revalidated.Body.String())
+		assert.Equal(t, etag, revalidated.Header().Get("ETag"))
+
+		assert.Equal(t, http.StatusOK, get("If-None-Match", `"stale"`).Code)
+		assert.Equal(t, http.StatusNotModified, get("If-Modified-Since", book.UpdatedAt.Add(time.Second).UTC().Format(http.TimeFormat)).Code)
+		assert.Equal(t, http.StatusOK, get("If-Modified-Since", book.UpdatedAt.Add(-time.Hour).UTC().Format(http.TimeFormat)).Code)
+	})
+}
+
+func TestBookHandler_GetBooksConditional(t *testing.T) {
+	t.Run("listing is not modified while its content is unchanged", func(t *testing.T) {
+		handler, mockService := setupHandlerTest()
+
+		older, newer := createTestBook(), createTestBook()
+		older.UpdatedAt = newer.UpdatedAt.Add(-time.Hour)
+		response := &models.BooksListResponse{Books: []models.Book{*older, *newer}, Limit: 50}
+		mockService.On("GetAllBooks", models.BookFilter{}).Return(response, nil)
+
+		w := httptest.NewRecorder()
+		handler.GetBooks(w, httptest.NewRequest("GET", "/api/books", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("Last-Modified"))
+
+		httpReq := httptest.NewRequest("GET", "/api/books", nil)
+		httpReq.Header.Set("If-None-Match", w.Header().Get("ETag"))
+		w = httptest.NewRecorder()
+		handler.GetBooks(w, httpReq)
+
+		assert.Equal(t, http.StatusNotModified, w.Code)
+	})
+
+	t.Run("If-Modified-Since is ignored for listings", func(t *testing.T) {
+		handler, mockService := setupHandlerTest()
+
+		book := createTestBook()
+		response := &models.BooksListResponse{Books: []models.Book{*book}, Limit: 50}
+		mockService.On("GetAllBooks", models.BookFilter{}).Return(response, nil)
+
+		// A removal from the listing leaves every remaining updated_at as it was
+		httpReq := httptest.NewRequest("GET", "/api/books", nil)
+		httpReq.Header.Set("If-Modified-Since", book.UpdatedAt.Add(time.Hour).UTC().Format(http.TimeFormat))
+		w := httptest.NewRecorder()
+		handler.GetBooks(w, httpReq)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
+
 func TestBookHandler_GetBooks(t *testing.T) {
 	t.Run("get books successfully", func(t *testing.T) {
 		handler, mockService := setupHandlerTest()
@@ -382,7 +564,7 @@ func TestBookHandler_GetBooks(t *testing.T) {
 
 		mockService.On("GetAllBooks", filter).Return(&models.BooksListResponse{
 			Books:  books,
-			Total:  2,
+			Total:  intPtr(2),
 			Limit:  10,
 			Offset: 0,
 		}, nil)
@@ -419,7 +601,7 @@ func TestBookHandler_GetBooks(t *testing.T) {
 
 		mockService.On("GetAllBooks", filter).Return(&models.BooksListResponse{
 			Books:  books,
-			Total:  1,
+			Total:  intPtr(1),
 			Limit:  5,
 			Offset: 10,
 		}, nil)
@@ -465,3 +647,1014 @@ func TestBookHandler_GetBooks(t *testing.T) {
 		mockService.AssertExpectations(t)
 	})
 }
+
+func TestBookHandler_EnrichBook(t *testing.T) {
+	t.Run("enrich book successfully", func(t *testing.T) {
+		handler, mockService := setupHandlerTest()
+
+		req := &models.CreateBookRequest{ISBN: "9781234567897"}
+		resp := &models.EnrichBookResponse{
+			Book:   models.CreateBookRequest{ISBN: "9781234567897", Title: "Test Book"},
+			Filled: []string{"title"},
+		}
+
+		mockService.On("EnrichBook", req).Return(resp, nil)
+
+		reqBody, _ := json.Marshal(req)
+		httpReq := httptest.NewRequest("POST", "/api/books/enrich", bytes.NewBuffer(reqBody))
+		w := httptest.NewRecorder()
+
+		handler.EnrichBook(w, httpReq)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	errorCases := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{"provider not configured", errors.New("metadata provider is not configured"), http.StatusServiceUnavailable},
+		{"metadata not found", errors.New("metadata for ISBN 9781234567897 not found"), http.StatusNotFound},
+		{"invalid ISBN", errors.New("invalid ISBN format: bad check digit"), http.StatusBadRequest},
+		{"provider failure", errors.New("metadata lookup failed: connection refused"), http.StatusBadGateway},
+	}
+
+	for _, tc := range errorCases {
+		t.Run(tc.name, func(t *testing.T) {
+			handler, mockService := setupHandlerTest()
+
+			mockService.On("EnrichBook", mock.Anything).Return(nil, tc.err)
+
+			httpReq := httptest.NewRequest("POST", "/api/books/enrich", strings.NewReader(`{"isbn": "9781234567897"}`))
+			w := httptest.NewRecorder()
+
+			handler.EnrichBook(w, httpReq)
+
+			assert.Equal(t, tc.status, w.Code)
+		})
+	}
+}
+
+func TestBookHandler_GetBooksFuzzy(t *testing.T) {
+	t.Run("fuzzy parameters are parsed", func(t *testing.T) {
+		handler, mockService := setupHandlerTest()
+
+		filter := models.BookFilter{Author: "Kernigan", Fuzzy: true, Similarity: 0.45}
+		mockService.On("GetAllBooks", filter).Return(&models.BooksListResponse{Books: []models.Book{}}, nil)
+
+		httpReq := httptest.NewRequest("GET", "/api/books?author=Kernigan&fuzzy=true&similarity=0.45", nil)
+		w := httptest.NewRecorder()
+
+		handler.GetBooks(w, httpReq)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("sort and cursor parameters are parsed", func(t *testing.T) {
+		handler, mockService := setupHandlerTest()
+
+		includeTotal := true
+		mockService.On("GetAllBooks", models.BookFilter{Sort: "title,-pages", Limit: 10, Cursor: "abc", IncludeTotal: &includeTotal}).Return(&models.BooksListResponse{
+			Books:      []models.Book{},
+			NextCursor: "def",
+		}, nil)
+
+		httpReq := httptest.NewRequest("GET", "/api/books?sort=title,-pages&limit=10&cursor=abc&include_total=true", nil)
+		w := httptest.NewRecorder()
+
+		handler.GetBooks(w, httpReq)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"next_cursor":"def"`)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("invalid cursor is a bad request", func(t *testing.T) {
+		handler, mockService := setupHandlerTest()
+
+		mockService.On("GetAllBooks", mock.Anything).Return(nil, errors.New("invalid cursor: illegal base64 data"))
+
+		httpReq := httptest.NewRequest("GET", "/api/books?cursor=garbage", nil)
+		w := httptest.NewRecorder()
+
+		handler.GetBooks(w, httpReq)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("facets parameter is parsed", func(t *testing.T) {
+		handler, mockService := setupHandlerTest()
+
+		mockService.On("GetAllBooks", models.BookFilter{Genre: "fantasy", Facets: true}).Return(&models.BooksListResponse{
+			Books:  []models.Book{},
+			Facets: models.NewBookFacets(),
+		}, nil)
+
+		httpReq := httptest.NewRequest("GET", "/api/books?genre=fantasy&facets=true", nil)
+		w := httptest.NewRecorder()
+
+		handler.GetBooks(w, httpReq)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"facets"`)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("out of range similarity is ignored", func(t *testing.T) {
+		handler, mockService := setupHandlerTest()
+
+		filter := models.BookFilter{Author: "Kernigan", Fuzzy: true}
+		mockService.On("GetAllBooks", filter).Return(&models.BooksListResponse{Books: []models.Book{}}, nil)
+
+		httpReq := httptest.NewRequest("GET", "/api/books?author=Kernigan&fuzzy=1&similarity=7", nil)
+		w := httptest.NewRecorder()
+
+		handler.GetBooks(w, httpReq)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestBookHandler_GetBooksFiltered(t *testing.T) {
+	t.Run("sets, negations and ranges are parsed", func(t *testing.T) {
+		handler, mockService := setupHandlerTest()
+
+		from := time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)
+		to := time.Date(2020, 12, 31, 23, 59, 59, 999999000, time.UTC)
+		since := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+		minPages, maxPages := 300, 800
+		filter := models.BookFilter{
+			ExcludeAuthors:   []string{"smith"},
+			Publisher:        "reilly",
+			ISBN:             "978-1-234-56789-7",
+			Genres:           []string{"programming", "databases"},
+			ExcludeGenres:    []string{"fiction"},
+			ExcludeLanguages: []string{"fr"},
+			PublishedFrom:    &from,
+			PublishedTo:      &to,
+			CreatedSince:     &since,
+			MinPages:         &minPages,
+			MaxPages:         &maxPages,
+		}
+		mockService.On("GetAllBooks", filter).Return(&models.BooksListResponse{Books: []models.Book{}}, nil)
+
+		httpReq := httptest.NewRequest("GET", "/api/books?author=!smith&publisher=reilly&isbn=978-1-234-56789-7"+
+			"&genre=programming,databases,!fiction&language=!fr&published_from=2015-01-01&published_to=2020-12-31"+
+			"&created_since=2024-03-01T12:00:00Z&min_pages=300&max_pages=800", nil)
+		w := httptest.NewRecorder()
+
+		handler.GetBooks(w, httpReq)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("unparsable ranges are ignored", func(t *testing.T) {
+		handler, mockService := setupHandlerTest()
+
+		mockService.On("GetAllBooks", models.BookFilter{Genre: "fantasy"}).Return(&models.BooksListResponse{Books: []models.Book{}}, nil)
+
+		httpReq := httptest.NewRequest("GET", "/api/books?genre=fantasy&published_from=yesterday&min_pages=-3", nil)
+		w := httptest.NewRecorder()
+
+		handler.GetBooks(w, httpReq)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("inverted range is a bad request", func(t *testing.T) {
+		handler, mockService := setupHandlerTest()
+
+		mockService.On("GetAllBooks", mock.Anything).Return(nil, errors.New("invalid filter: min_pages is greater than max_pages"))
+
+		httpReq := httptest.NewRequest("GET", "/api/books?min_pages=500&max_pages=100", nil)
+		w := httptest.NewRecorder()
+
+		handler.GetBooks(w, httpReq)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestBookHandler_GetBooksProjected(t *testing.T) {
+	t.Run("only projected fields are written", func(t *testing.T) {
+		handler, mockService := setupHandlerTest()
+
+		filter := models.BookFilter{BookProjection: models.BookProjection{Fields: []string{"id", "title", "author"}}}
+		book := models.Book{ID: uuid.New(), Title: "The Hobbit", Author: "J.R.R. Tolkien", Fields: []string{"id", "title", "author"}}
+		mockService.On("GetAllBooks", filter).Return(&models.BooksListResponse{Books: []models.Book{book}}, nil)
+
+		httpReq := httptest.NewRequest("GET", "/api/books?fields=id,title,author", nil)
+		w := httptest.NewRecorder()
+
+		handler.GetBooks(w, httpReq)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response struct {
+			Data struct {
+				Books []map[string]interface{} `json:"books"`
+			} `json:"data"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Len(t, response.Data.Books[0], 3)
+		assert.Equal(t, "The Hobbit", response.Data.Books[0]["title"])
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("unknown field on a single book is a bad request", func(t *testing.T) {
+		handler, mockService := setupHandlerTest()
+
+		id := uuid.New()
+		projection := models.BookProjection{Fields: []string{"rating"}, Include: []string{"copies"}}
+		mockService.On("GetBookByID", id, projection).Return(nil, errors.New(`invalid fields: unknown field "rating"`))
+
+		httpReq := httptest.NewRequest("GET", "/api/books/"+id.String()+"?fields=rating&include=copies", nil)
+		httpReq = mux.SetURLVars(httpReq, map[string]string{"id": id.String()})
+		w := httptest.NewRecorder()
+
+		handler.GetBook(w, httpReq)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestBookHandler_GetBooksQuery(t *testing.T) {
+	t.Run("q parameter is parsed", func(t *testing.T) {
+		handler, mockService := setupHandlerTest()
+
+		mockService.On("GetAllBooks", models.BookFilter{Query: "author:kernighan pages>300"}).Return(&models.BooksListResponse{Books: []models.Book{}}, nil)
+
+		httpReq := httptest.NewRequest("GET", "/api/books?q="+url.QueryEscape("author:kernighan pages>300"), nil)
+		w := httptest.NewRecorder()
+
+		handler.GetBooks(w, httpReq)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("syntax error is a structured bad request with its position", func(t *testing.T) {
+		handler, mockService := setupHandlerTest()
+
+		mockService.On("GetAllBooks", mock.Anything).Return(nil, apperrors.Syntax("invalid query", `unknown field "rating" at position 18`, 18))
+
+		httpReq := httptest.NewRequest("GET", "/api/books?q="+url.QueryEscape("author:kernighan rating>4"), nil)
+		w := httptest.NewRecorder()
+
+		handler.GetBooks(w, httpReq)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		var response struct {
+			Error apperrors.AppError `json:"error"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, apperrors.CodeValidation, response.Error.Code)
+		assert.Equal(t, 18, response.Error.Position)
+	})
+}
+
+func TestBookHandler_SearchBooks(t *testing.T) {
+	t.Run("search books successfully", func(t *testing.T) {
+		handler, mockService := setupHandlerTest()
+
+		filter := models.BookSearchFilter{Query: "hobbit", Language: "english", Limit: 5, Offset: 10}
+		response := &models.BookSearchResponse{
+			Query:   "hobbit",
+			Results: []models.BookSearchResult{{Book: *createTestBook(), Rank: 0.5, Headline: "The <mark>Hobbit</mark>"}},
+			Total:   11,
+			Limit:   5,
+			Offset:  10,
+		}
+
+		mockService.On("SearchBooks", filter).Return(response, nil)
+
+		httpReq := httptest.NewRequest("GET", "/api/books/search?q=hobbit&language=english&limit=5&offset=10", nil)
+		w := httptest.NewRecorder()
+
+		handler.SearchBooks(w, httpReq)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var body map[string]interface{}
+		err := json.Unmarshal(w.Body.Bytes(), &body)
+		assert.NoError(t, err)
+		assert.Equal(t, "Search completed successfully", body["message"])
+
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("search without query", func(t *testing.T) {
+		handler, mockService := setupHandlerTest()
+
+		mockService.On("SearchBooks", models.BookSearchFilter{}).Return(nil, errors.New("search query is required"))
+
+		httpReq := httptest.NewRequest("GET", "/api/books/search", nil)
+		w := httptest.NewRecorder()
+
+		handler.SearchBooks(w, httpReq)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("search with service error", func(t *testing.T) {
+		handler, mockService := setupHandlerTest()
+
+		mockService.On("SearchBooks", mock.Anything).Return(nil, errors.New("failed to search books: connection lost"))
+
+		httpReq := httptest.NewRequest("GET", "/api/books/search?q=hobbit", nil)
+		w := httptest.NewRecorder()
+
+		handler.SearchBooks(w, httpReq)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+func TestBookHandler_Trash(t *testing.T) {
+	t.Run("trash is listed with paging", func(t *testing.T) {
+		handler, mockService := setupHandlerTest()
+
+		total := 1
+		response := &models.BooksListResponse{Books: []models.Book{*createTestBook()}, Total: &total, Limit: 5, Offset: 10}
+		mockService.On("GetTrash", models.TrashFilter{Limit: 5, Offset: 10}).Return(response, nil)
+
+		httpReq := httptest.NewRequest("GET", "/api/books/trash?limit=5&offset=10", nil)
+		w := httptest.NewRecorder()
+
+		handler.GetTrash(w, httpReq)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var body map[string]interface{}
+		err := json.Unmarshal(w.Body.Bytes(), &body)
+		assert.NoError(t, err)
+		assert.Equal(t, "Trash retrieved successfully", body["message"])
+		mockService.AssertExpectations(t)
+	})
+
+	newRestoreRequest := func(id string) *http.Request {
+		httpReq := httptest.NewRequest("POST", "/api/books/"+id+"/restore", nil)
+		return mux.SetURLVars(httpReq, map[string]string{"id": id})
+	}
+
+	t.Run("restored book is returned with its ETag", func(t *testing.T) {
+		handler, mockService := setupHandlerTest()
+		book := createTestBook()
+		mockService.On("RestoreBook", book.ID, anonymousAudit).Return(book, nil)
+		etag, _ := contentETag(book)
+
+		w := httptest.NewRecorder()
+		handler.RestoreBook(w, newRestoreRequest(book.ID.String()))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, etag, w.Header().Get("ETag"))
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("restore of a book not in the trash", func(t *testing.T) {
+		handler, mockService := setupHandlerTest()
+		id := uuid.New()
+		mockService.On("RestoreBook", id, anonymousAudit).Return(nil, errors.New("failed to restore book: book not found in trash"))
+
+		w := httptest.NewRecorder()
+		handler.RestoreBook(w, newRestoreRequest(id.String()))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("restore when the ISBN has been reused", func(t *testing.T) {
+		handler, mockService := setupHandlerTest()
+		id := uuid.New()
+		mockService.On("RestoreBook", id, anonymousAudit).Return(nil, errors.New("failed to restore book: book with ISBN 9780441172719 already exists"))
+
+		w := httptest.NewRecorder()
+		handler.RestoreBook(w, newRestoreRequest(id.String()))
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("restore with an invalid ID", func(t *testing.T) {
+		handler, mockService := setupHandlerTest()
+
+		w := httptest.NewRecorder()
+		handler.RestoreBook(w, newRestoreRequest("not-a-uuid"))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "RestoreBook", mock.Anything, mock.Anything)
+	})
+}
+
+func TestBookHandler_History(t *testing.T) {
+	newRequest := func(path, id string) *http.Request {
+		httpReq := httptest.NewRequest("GET", path, nil)
+		return mux.SetURLVars(httpReq, map[string]string{"id": id})
+	}
+
+	t.Run("history is returned", func(t *testing.T) {
+		handler, mockService := setupHandlerTest()
+		id := uuid.New()
+		history := &models.BookHistoryResponse{BookID: id, Entries: []models.BookAuditEntry{
+			{ID: 1, BookID: id, Action: models.AuditCreate, Actor: "librarian-7"},
+		}}
+		mockService.On("GetBookHistory", id).Return(history, nil)
+
+		w := httptest.NewRecorder()
+		handler.GetBookHistory(w, newRequest("/api/books/"+id.String()+"/history", id.String()))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var body map[string]interface{}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, "Book history retrieved successfully", body["message"])
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("history of an unknown book", func(t *testing.T) {
+		handler, mockService := setupHandlerTest()
+		id := uuid.New()
+		mockService.On("GetBookHistory", id).Return(nil, errors.New("book not found"))
+
+		w := httptest.NewRecorder()
+		handler.GetBookHistory(w, newRequest("/api/books/"+id.String()+"/history", id.String()))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("a bare as-of date means the end of the day", func(t *testing.T) {
+		handler, mockService := setupHandlerTest()
+		id := uuid.New()
+		at := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC).Add(24*time.Hour - time.Microsecond)
+		mockService.On("GetBookAsOf", id, at).Return(&models.BookAsOfResponse{
+			AsOf: at, Book: &models.BookSnapshot{ID: id, Title: "Dune"}, Action: models.AuditUpdate,
+		}, nil)
+
+		w := httptest.NewRecorder()
+		handler.GetBookAsOf(w, newRequest("/api/books/"+id.String()+"/as-of?at=2026-03-01", id.String()))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("as of requires a time", func(t *testing.T) {
+		handler, mockService := setupHandlerTest()
+		id := uuid.New()
+
+		w := httptest.NewRecorder()
+		handler.GetBookAsOf(w, newRequest("/api/books/"+id.String()+"/as-of?at=yesterday", id.String()))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "GetBookAsOf", mock.Anything, mock.Anything)
+	})
+
+	t.Run("as of a time before the book existed", func(t *testing.T) {
+		handler, mockService := setupHandlerTest()
+		id := uuid.New()
+		mockService.On("GetBookAsOf", id, mock.Anything).Return(nil, errors.New("book not found as of 2020-01-01T00:00:00Z"))
+
+		w := httptest.NewRecorder()
+		handler.GetBookAsOf(w, newRequest("/api/books/"+id.String()+"/as-of?at=2020-01-01T00:00:00Z", id.String()))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("writes are audited as the actor and request", func(t *testing.T) {
+		handler, mockService := setupHandlerTest()
+		book := createTestBook()
+		mockService.On("RestoreBook", book.ID, models.AuditInfo{Actor: "librarian-7", RequestID: "req-1"}).Return(book, nil)
+
+		httpReq := httptest.NewRequest("POST", "/api/books/"+book.ID.String()+"/restore", nil)
+		httpReq = mux.SetURLVars(httpReq, map[string]string{"id": book.ID.String()})
+		httpReq.Header.Set("X-Actor", "librarian-7")
+		httpReq.Header.Set("X-Request-ID", "req-1")
+		w := httptest.NewRecorder()
+
+		middleware.RequestIDMiddleware(middleware.ActorMiddleware(http.HandlerFunc(handler.RestoreBook))).ServeHTTP(w, httpReq)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestBookHandler_BulkCreateAtomic(t *testing.T) {
+	body := `[{"title": "Dune", "author": "Frank Herbert", "isbn": "9780441013593", "pages": 412},
+		{"title": "Dune", "author": "Frank Herbert", "isbn": "0441013597", "pages": 412}]`
+	newRequest := func(query string) *http.Request {
+		return httptest.NewRequest("POST", "/api/books/bulk"+query, strings.NewReader(body))
+	}
+
+	t.Run("a valid batch is created", func(t *testing.T) {
+		handler, mockService := setupHandlerTest()
+		books := []*models.Book{createTestBook(), createTestBook()}
+		mockService.On("BulkCreateBooksAtomic", mock.Anything, mock.Anything, anonymousAudit).Return(books, nil, nil)
+
+		w := httptest.NewRecorder()
+		handler.BulkCreateBooks(w, newRequest("?atomic=true"))
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Contains(t, w.Body.String(), `"successful":2`)
+		mockService.AssertNotCalled(t, "BulkCreateBooks", mock.Anything, mock.Anything)
+	})
+
+	t.Run("validation errors are reported by index", func(t *testing.T) {
+		handler, mockService := setupHandlerTest()
+		mockService.On("BulkCreateBooksAtomic", mock.Anything, mock.Anything, anonymousAudit).Return(nil,
+			[]error{nil, errors.New("book with ISBN 9780441013593 already exists in the batch at index 0")},
+			errors.New("invalid batch: 1 of 2 books failed validation"))
+
+		w := httptest.NewRecorder()
+		handler.BulkCreateBooks(w, newRequest("?atomic=true"))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		var response struct {
+			Successful int `json:"successful"`
+			Errors     []struct {
+				Index int    `json:"index"`
+				Error string `json:"error"`
+			} `json:"errors"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, 0, response.Successful)
+		assert.Len(t, response.Errors, 1)
+		assert.Equal(t, 1, response.Errors[0].Index)
+		assert.Contains(t, response.Errors[0].Error, "in the batch at index 0")
+	})
+
+	t.Run("a failed transaction creates nothing", func(t *testing.T) {
+		handler, mockService := setupHandlerTest()
+		mockService.On("BulkCreateBooksAtomic", mock.Anything, mock.Anything, anonymousAudit).Return(nil, nil,
+			errors.New("failed to create books: book at index 1: connection lost"))
+
+		w := httptest.NewRecorder()
+		handler.BulkCreateBooks(w, newRequest("?atomic=true"))
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+
+	t.Run("a timed out batch is rolled back", func(t *testing.T) {
+		handler, mockService := setupHandlerTest()
+		mockService.On("BulkCreateBooksAtomic", mock.Anything, mock.Anything, anonymousAudit).Return(nil, nil,
+			errors.New("failed to create books: context canceled"))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		w := httptest.NewRecorder()
+		handler.BulkCreateBooks(w, newRequest("?atomic=true").WithContext(ctx))
+
+		assert.Equal(t, http.StatusRequestTimeout, w.Code)
+	})
+
+	t.Run("invalid atomic flag", func(t *testing.T) {
+		handler, mockService := setupHandlerTest()
+
+		w := httptest.NewRecorder()
+		handler.BulkCreateBooks(w, newRequest("?atomic=sometimes"))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "BulkCreateBooksAtomic", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestBookHandler_RevertBook(t *testing.T) {
+	newRequest := func(book *models.Book, rev, ifMatch string) *http.Request {
+		httpReq := httptest.NewRequest("POST", "/api/books/"+book.ID.String()+"/revisions/"+rev+"/revert", nil)
+		httpReq = mux.SetURLVars(httpReq, map[string]string{"id": book.ID.String(), "rev": rev})
+		if ifMatch != "" {
+			httpReq.Header.Set("If-Match", ifMatch)
+		}
+		return httpReq
+	}
+
+	t.Run("revert pins the version the ETag was computed from", func(t *testing.T) {
+		handler, mockService := setupHandlerTest()
+		book := createTestBook()
+		book.Version = 4
+		reverted := *book
+		reverted.Title = "Original Title"
+		reverted.Version = 5
+		mockService.On("GetBookByID", book.ID, models.BookProjection{}).Return(book, nil)
+		mockService.On("RevertBook", book.ID, mock.MatchedBy(func(revert *models.BookRevert) bool {
+			return revert.Revision == 2 && revert.Version != nil && *revert.Version == 4
+		}), anonymousAudit).Return(&reverted, nil)
+		etag, _ := contentETag(book)
+		newETag, _ := contentETag(&reverted)
+
+		w := httptest.NewRecorder()
+		handler.RevertBook(w, newRequest(book, "2", etag))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, newETag, w.Header().Get("ETag"))
+		assert.Contains(t, w.Body.String(), "Book reverted successfully")
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("revert without If-Match is rejected", func(t *testing.T) {
+		handler, mockService := setupHandlerTest()
+		book := createTestBook()
+
+		w := httptest.NewRecorder()
+		handler.RevertBook(w, newRequest(book, "2", ""))
+
+		assert.Equal(t, http.StatusPreconditionRequired, w.Code)
+		mockService.AssertNotCalled(t, "RevertBook", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("invalid revision", func(t *testing.T) {
+		handler, mockService := setupHandlerTest()
+		book := createTestBook()
+
+		for _, rev := range []string{"0", "-1", "latest"} {
+			w := httptest.NewRecorder()
+			handler.RevertBook(w, newRequest(book, rev, "*"))
+
+			assert.Equal(t, http.StatusBadRequest, w.Code, rev)
+		}
+		mockService.AssertNotCalled(t, "RevertBook", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("unknown revision", func(t *testing.T) {
+		handler, mockService := setupHandlerTest()
+		book := createTestBook()
+		mockService.On("GetBookByID", book.ID, models.BookProjection{}).Return(book, nil)
+		mockService.On("RevertBook", book.ID, mock.Anything, anonymousAudit).Return(nil,
+			errors.New("failed to revert book: revision 9 of book not found"))
+
+		w := httptest.NewRecorder()
+		handler.RevertBook(w, newRequest(book, "9", "*"))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("revert to an ISBN another book has taken", func(t *testing.T) {
+		handler, mockService := setupHandlerTest()
+		book := createTestBook()
+		mockService.On("GetBookByID", book.ID, models.BookProjection{}).Return(book, nil)
+		mockService.On("RevertBook", book.ID, mock.Anything, anonymousAudit).Return(nil,
+			errors.New("book with ISBN 9780441013593 already exists"))
+
+		w := httptest.NewRecorder()
+		handler.RevertBook(w, newRequest(book, "1", "*"))
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("revert of a book changed after the If-Match check fails the precondition", func(t *testing.T) {
+		handler, mockService := setupHandlerTest()
+		book := createTestBook()
+		current := *book
+		current.Version = book.Version + 1
+		mockService.On("GetBookByID", book.ID, models.BookProjection{}).Return(book, nil)
+		mockService.On("RevertBook", book.ID, mock.Anything, anonymousAudit).Return(nil,
+			apperrors.VersionConflict("book has been modified", "expected version 1, current version is 2", &current))
+		etag, _ := contentETag(book)
+
+		w := httptest.NewRecorder()
+		handler.RevertBook(w, newRequest(book, "1", etag))
+
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	})
+}
+
+func TestBookHandler_AutocompleteBooks(t *testing.T) {
+	t.Run("suggestions are returned", func(t *testing.T) {
+		handler, mockService := setupHandlerTest()
+
+		filter := models.AutocompleteFilter{Field: models.SuggestFieldAuthor, Prefix: "tol", Limit: 5}
+		mockService.On("AutocompleteBooks", filter).Return(&models.AutocompleteResponse{
+			Field:       models.SuggestFieldAuthor,
+			Prefix:      "tol",
+			Suggestions: []string{"J.R.R. Tolkien", "Leo Tolstoy"},
+		}, nil)
+
+		httpReq := httptest.NewRequest("GET", "/api/books/autocomplete?prefix=tol&field=author&limit=5", nil)
+		w := httptest.NewRecorder()
+
+		handler.AutocompleteBooks(w, httpReq)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "Leo Tolstoy")
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("invalid field", func(t *testing.T) {
+		handler, mockService := setupHandlerTest()
+
+		mockService.On("AutocompleteBooks", mock.Anything).Return(nil, errors.New("invalid suggestion field: isbn"))
+
+		httpReq := httptest.NewRequest("GET", "/api/books/autocomplete?prefix=978&field=isbn", nil)
+		w := httptest.NewRecorder()
+
+		handler.AutocompleteBooks(w, httpReq)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("service error", func(t *testing.T) {
+		handler, mockService := setupHandlerTest()
+
+		mockService.On("AutocompleteBooks", mock.Anything).Return(nil, errors.New("failed to get suggestions: connection lost"))
+
+		httpReq := httptest.NewRequest("GET", "/api/books/autocomplete?prefix=the", nil)
+		w := httptest.NewRecorder()
+
+		handler.AutocompleteBooks(w, httpReq)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+func TestBookHandler_WritePreconditions(t *testing.T) {
+	newRequest := func(method string, book *models.Book, body, ifMatch string) *http.Request {
+		httpReq := httptest.NewRequest(method, "/api/books/"+book.ID.String(), strings.NewReader(body))
+		httpReq = mux.SetURLVars(httpReq, map[string]string{"id": book.ID.String()})
+		if ifMatch != "" {
+			httpReq.Header.Set("If-Match", ifMatch)
+		}
+		return httpReq
+	}
+
+	t.Run("update without If-Match is rejected", func(t *testing.T) {
+		handler, mockService := setupHandlerTest()
+		book := createTestBook()
+
+		w := httptest.NewRecorder()
+		handler.UpdateBook(w, newRequest("PUT", book, `{"pages": 320}`, ""))
+
+		assert.Equal(t, http.StatusPreconditionRequired, w.Code)
+		mockService.AssertNotCalled(t, "ReplaceBook", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("update with a stale ETag fails with the current one", func(t *testing.T) {
+		handler, mockService := setupHandlerTest()
+		book := createTestBook()
+		mockService.On("GetBookByID", book.ID, models.BookProjection{}).Return(book, nil)
+		etag, _ := contentETag(book)
+
+		w := httptest.NewRecorder()
+		handler.UpdateBook(w, newRequest("PUT", book, `{"pages": 320}`, `"stale"`))
+
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+		assert.Equal(t, etag, w.Header().Get("ETag"))
+		mockService.AssertNotCalled(t, "ReplaceBook", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("update with the current ETag returns the new one", func(t *testing.T) {
+		handler, mockService := setupHandlerTest()
+		book := createTestBook()
+		updated := *book
+		updated.Pages = 320
+		mockService.On("GetBookByID", book.ID, models.BookProjection{}).Return(book, nil)
+		mockService.On("ReplaceBook", book.ID, mock.Anything, anonymousAudit).Return(&updated, nil)
+		etag, _ := contentETag(book)
+		newETag, _ := contentETag(&updated)
+
+		w := httptest.NewRecorder()
+		handler.UpdateBook(w, newRequest("PUT", book, `{"pages": 320}`, etag))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, newETag, w.Header().Get("ETag"))
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("update pins the version the ETag was computed from", func(t *testing.T) {
+		handler, mockService := setupHandlerTest()
+		book := createTestBook()
+		book.Version = 5
+		mockService.On("GetBookByID", book.ID, models.BookProjection{}).Return(book, nil)
+		mockService.On("ReplaceBook", book.ID, mock.MatchedBy(func(req *models.ReplaceBookRequest) bool {
+			return req.Version != nil && *req.Version == 5
+		}), anonymousAudit).Return(book, nil)
+		etag, _ := contentETag(book)
+
+		w := httptest.NewRecorder()
+		handler.UpdateBook(w, newRequest("PUT", book, `{"pages": 320}`, etag))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("version conflict returns the current copy", func(t *testing.T) {
+		handler, mockService := setupHandlerTest()
+		book := createTestBook()
+		current := *book
+		current.Version = 7
+		mockService.On("GetBookByID", book.ID, models.BookProjection{}).Return(book, nil)
+		mockService.On("ReplaceBook", book.ID, mock.Anything, anonymousAudit).Return(nil,
+			apperrors.VersionConflict("book has been modified", "expected version 6, current version is 7", &current))
+
+		w := httptest.NewRecorder()
+		handler.UpdateBook(w, newRequest("PUT", book, `{"pages": 320, "version": 6}`, "*"))
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+
+		var response struct {
+			Error struct {
+				Code    apperrors.ErrorCode `json:"code"`
+				Current models.Book         `json:"current"`
+			} `json:"error"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, apperrors.CodeVersionConflict, response.Error.Code)
+		assert.Equal(t, 7, response.Error.Current.Version)
+	})
+
+	t.Run("update of a book changed after the If-Match check fails the precondition", func(t *testing.T) {
+		handler, mockService := setupHandlerTest()
+		book := createTestBook()
+		current := *book
+		current.Version = book.Version + 1
+		current.Pages = 500
+		mockService.On("GetBookByID", book.ID, models.BookProjection{}).Return(book, nil)
+		mockService.On("ReplaceBook", book.ID, mock.Anything, anonymousAudit).Return(nil,
+			apperrors.VersionConflict("book has been modified", "expected version 1, current version is 2", &current))
+		etag, _ := contentETag(book)
+		currentETag, _ := contentETag(&current)
+
+		w := httptest.NewRecorder()
+		handler.UpdateBook(w, newRequest("PUT", book, `{"pages": 320}`, etag))
+
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+		assert.Equal(t, currentETag, w.Header().Get("ETag"))
+	})
+
+	t.Run("weak ETags never match If-Match", func(t *testing.T) {
+		handler, mockService := setupHandlerTest()
+		book := createTestBook()
+		mockService.On("GetBookByID", book.ID, models.BookProjection{}).Return(book, nil)
+		etag, _ := contentETag(book)
+
+		w := httptest.NewRecorder()
+		handler.DeleteBook(w, newRequest("DELETE", book, "", "W/"+etag))
+
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+		mockService.AssertNotCalled(t, "DeleteBook", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("delete with a wildcard If-Match", func(t *testing.T) {
+		handler, mockService := setupHandlerTest()
+		book := createTestBook()
+		mockService.On("GetBookByID", book.ID, models.BookProjection{}).Return(book, nil)
+		mockService.On("DeleteBook", book.ID, (*int)(nil), anonymousAudit).Return(nil)
+
+		w := httptest.NewRecorder()
+		handler.DeleteBook(w, newRequest("DELETE", book, "", "*"))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("delete pins the version the ETag was computed from", func(t *testing.T) {
+		handler, mockService := setupHandlerTest()
+		book := createTestBook()
+		book.Version = 5
+		mockService.On("GetBookByID", book.ID, models.BookProjection{}).Return(book, nil)
+		mockService.On("DeleteBook", book.ID, mock.MatchedBy(func(version *int) bool {
+			return version != nil && *version == 5
+		}), anonymousAudit).Return(nil)
+		etag, _ := contentETag(book)
+
+		w := httptest.NewRecorder()
+		handler.DeleteBook(w, newRequest("DELETE", book, "", etag))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("delete of a book changed after the If-Match check fails the precondition", func(t *testing.T) {
+		handler, mockService := setupHandlerTest()
+		book := createTestBook()
+		current := *book
+		current.Version = book.Version + 1
+		current.Pages = 500
+		mockService.On("GetBookByID", book.ID, models.BookProjection{}).Return(book, nil)
+		mockService.On("DeleteBook", book.ID, mock.Anything, anonymousAudit).Return(
+			apperrors.VersionConflict("book has been modified", "expected version 1, current version is 2", &current))
+		etag, _ := contentETag(book)
+		currentETag, _ := contentETag(&current)
+
+		w := httptest.NewRecorder()
+		handler.DeleteBook(w, newRequest("DELETE", book, "", etag))
+
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+		assert.Equal(t, currentETag, w.Header().Get("ETag"))
+	})
+
+	t.Run("delete of a missing book is not found", func(t *testing.T) {
+		handler, mockService := setupHandlerTest()
+		book := createTestBook()
+		mockService.On("GetBookByID", book.ID, models.BookProjection{}).Return(nil, errors.New("failed to get book: book not found"))
+
+		w := httptest.NewRecorder()
+		handler.DeleteBook(w, newRequest("DELETE", book, "", "*"))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("a book out on loan cannot be deleted", func(t *testing.T) {
+		handler, mockService := setupHandlerTest()
+		book := createTestBook()
+		mockService.On("GetBookByID", book.ID, models.BookProjection{}).Return(book, nil)
+		mockService.On("DeleteBook", book.ID, (*int)(nil), anonymousAudit).
+			Return(errors.New("failed to delete book: book is still checked out and cannot be deleted"))
+
+		w := httptest.NewRecorder()
+		handler.DeleteBook(w, newRequest("DELETE", book, "", "*"))
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+}
+
+func TestBookHandler_PatchBook(t *testing.T) {
+	newRequest := func(book *models.Book, contentType, body string) *http.Request {
+		httpReq := httptest.NewRequest("PATCH", "/api/books/"+book.ID.String(), strings.NewReader(body))
+		httpReq = mux.SetURLVars(httpReq, map[string]string{"id": book.ID.String()})
+		httpReq.Header.Set("Content-Type", contentType)
+		httpReq.Header.Set("If-Match", "*")
+		return httpReq
+	}
+
+	t.Run("merge patch is passed through", func(t *testing.T) {
+		handler, mockService := setupHandlerTest()
+		book := createTestBook()
+		mockService.On("GetBookByID", book.ID, models.BookProjection{}).Return(book, nil)
+		mockService.On("PatchBook", book.ID, &models.BookPatch{
+			Format:   models.PatchMerge,
+			Document: []byte(`{"publisher": null}`),
+		}, anonymousAudit).Return(book, nil)
+
+		w := httptest.NewRecorder()
+		handler.PatchBook(w, newRequest(book, "application/merge-patch+json; charset=utf-8", `{"publisher": null}`))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotEmpty(t, w.Header().Get("ETag"))
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("json patch is pinned to the matched version", func(t *testing.T) {
+		handler, mockService := setupHandlerTest()
+		book := createTestBook()
+		book.Version = 3
+		mockService.On("GetBookByID", book.ID, models.BookProjection{}).Return(book, nil)
+		mockService.On("PatchBook", book.ID, mock.MatchedBy(func(p *models.BookPatch) bool {
+			return p.Format == models.PatchJSON && p.Version != nil && *p.Version == 3
+		}), anonymousAudit).Return(book, nil)
+		etag, _ := contentETag(book)
+
+		httpReq := newRequest(book, "application/json-patch+json", `[{"op": "replace", "path": "/pages", "value": 320}]`)
+		httpReq.Header.Set("If-Match", etag)
+		w := httptest.NewRecorder()
+		handler.PatchBook(w, httpReq)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("failed test is a conflict", func(t *testing.T) {
+		handler, mockService := setupHandlerTest()
+		book := createTestBook()
+		mockService.On("GetBookByID", book.ID, models.BookProjection{}).Return(book, nil)
+		mockService.On("PatchBook", book.ID, mock.Anything, anonymousAudit).Return(nil,
+			apperrors.Conflict("patch test failed", "patch test failed: /title is not \"Emma\""))
+
+		w := httptest.NewRecorder()
+		handler.PatchBook(w, newRequest(book, "application/json-patch+json", `[{"op": "test", "path": "/title", "value": "Emma"}]`))
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("patch of a book changed after the If-Match check fails the precondition", func(t *testing.T) {
+		handler, mockService := setupHandlerTest()
+		book := createTestBook()
+		current := *book
+		current.Version = book.Version + 1
+		mockService.On("GetBookByID", book.ID, models.BookProjection{}).Return(book, nil)
+		mockService.On("PatchBook", book.ID, mock.Anything, anonymousAudit).Return(nil,
+			apperrors.VersionConflict("book has been modified", "expected version 1, current version is 2", &current))
+		etag, _ := contentETag(book)
+
+		httpReq := newRequest(book, "application/merge-patch+json", `{"pages": 320}`)
+		httpReq.Header.Set("If-Match", etag)
+		w := httptest.NewRecorder()
+		handler.PatchBook(w, httpReq)
+
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	})
+
+	t.Run("other content types are unsupported", func(t *testing.T) {
+		handler, mockService := setupHandlerTest()
+		book := createTestBook()
+
+		w := httptest.NewRecorder()
+		handler.PatchBook(w, newRequest(book, "application/json", `{"pages": 320}`))
+
+		assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+		assert.Contains(t, w.Header().Get("Accept-Patch"), "application/merge-patch+json")
+		mockService.AssertNotCalled(t, "PatchBook", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// contentETag derives a strong entity tag from the JSON representation of v,
+// so every shape of a resource, projected or not, gets its own tag
+func contentETag(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`, nil
+}
+
+// setValidators sets the ETag and, when known, Last-Modified headers
+func setValidators(w http.ResponseWriter, etag string, lastModified time.Time) {
+	w.Header().Set("ETag", etag)
+	if !lastModified.IsZero() {
+		w.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+}
+
+// notModified reports whether a GET's validators show the client already has
+// the current representation. If-None-Match takes precedence over
+// If-Modified-Since, which HTTP dates only resolve to the second.
+func notModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if header := r.Header.Get("If-None-Match"); header != "" {
+		return etagListMatches(header, etag, false)
+	}
+	if header := r.Header.Get("If-Modified-Since"); header != "" && !lastModified.IsZero() {
+		since, err := http.ParseTime(header)
+		if err != nil {
+			return false
+		}
+		return !lastModified.Truncate(time.Second).After(since)
+	}
+	return false
+}
+
+// writeNotModified ends a conditional GET with 304 and no body
+func writeNotModified(w http.ResponseWriter) {
+	w.Header().Del("Content-Type")
+	w.WriteHeader(http.StatusNotModified)
+}
+
+// etagListMatches reports whether a comma-separated If-Match or
+// If-None-Match header lists etag or is "*". Strong comparison, required by
+// If-Match, never matches weak tags; weak comparison ignores the W/ prefix.
+func etagListMatches(header, etag string, strong bool) bool {
+	if strings.TrimSpace(header) == "*" {
+		return true
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if weak, ok := strings.CutPrefix(candidate, "W/"); ok {
+			if strong {
+				continue
+			}
+			candidate = weak
+		}
+		if candidate == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"encoding/json"
+	"libmngmt/internal/models"
+	"libmngmt/internal/service"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// CopyHandler handles HTTP requests for the physical copies of a book
+type CopyHandler struct {
+	copyService service.CopyService
+}
+
+// NewCopyHandler creates a new copy handler
+func NewCopyHandler(copyService service.CopyService) *CopyHandler {
+	return &CopyHandler{copyService: copyService}
+}
+
+// AddCopy handles POST /api/books/{id}/copies
+func (h *CopyHandler) AddCopy(w http.ResponseWriter, r *http.Request) {
+	bookID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid book ID", "ID must be a valid UUID")
+		return
+	}
+
+	var req models.CreateCopyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON", err.Error())
+		return
+	}
+
+	item, err := h.copyService.AddCopy(bookID, &req)
+	if err != nil {
+		writeCopyError(w, err)
+		return
+	}
+
+	writeSuccessResponse(w, http.StatusCreated, "Copy added successfully", item)
+}
+
+// GetBookCopies handles GET /api/books/{id}/copies
+func (h *CopyHandler) GetBookCopies(w http.ResponseWriter, r *http.Request) {
+	bookID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid book ID", "ID must be a valid UUID")
+		return
+	}
+
+	copies, err := h.copyService.GetBookCopies(bookID)
+	if err != nil {
+		writeCopyError(w, err)
+		return
+	}
+
+	writeSuccessResponse(w, http.StatusOK, "Copies retrieved successfully", copies)
+}
+
+// GetCopy handles GET /api/copies/{id}
+func (h *CopyHandler) GetCopy(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid copy ID", "ID must be a valid UUID")
+		return
+	}
+
+	item, err := h.copyService.GetCopy(id)
+	if err != nil {
+		writeCopyError(w, err)
+		return
+	}
+
+	writeSuccessResponse(w, http.StatusOK, "Copy retrieved successfully", item)
+}
+
+// UpdateCopy handles PUT /api/copies/{id}
+func (h *CopyHandler) UpdateCopy(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid copy ID", "ID must be a valid UUID")
+		return
+	}
+
+	var req models.UpdateCopyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON", err.Error())
+		return
+	}
+
+	item, err := h.copyService.UpdateCopy(id, &req)
+	if err != nil {
+		writeCopyError(w, err)
+		return
+	}
+
+	writeSuccessResponse(w, http.StatusOK, "Copy updated successfully", item)
+}
+
+// DeleteCopy handles DELETE /api/copies/{id}
+func (h *CopyHandler) DeleteCopy(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid copy ID", "ID must be a valid UUID")
+		return
+	}
+
+	if err := h.copyService.DeleteCopy(id); err != nil {
+		writeCopyError(w, err)
+		return
+	}
+
+	writeSuccessResponse(w, http.StatusOK, "Copy deleted successfully", nil)
+}
+
+// writeCopyError maps copy service errors onto HTTP responses
+func writeCopyError(w http.ResponseWriter, err error) {
+	switch {
+	case isNotFoundError(err):
+		writeErrorResponse(w, http.StatusNotFound, "Resource not found", err.Error())
+	case isDuplicateError(err):
+		writeErrorResponse(w, http.StatusConflict, "Duplicate resource", err.Error())
+	case isCirculationConflict(err):
+		writeErrorResponse(w, http.StatusConflict, "Circulation conflict", err.Error())
+	case isValidationError(err):
+		writeErrorResponse(w, http.StatusBadRequest, "Validation error", err.Error())
+	default:
+		writeErrorResponse(w, http.StatusInternalServerError, "Internal server error", err.Error())
+	}
+}
@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"libmngmt/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockCopyService is a mock implementation of CopyService for testing
+type MockCopyService struct {
+	mock.Mock
+}
+
+func (m *MockCopyService) AddCopy(bookID uuid.UUID, req *models.CreateCopyRequest) (*models.Copy, error) {
+	args := m.Called(bookID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Copy), args.Error(1)
+}
+
+func (m *MockCopyService) GetCopy(id uuid.UUID) (*models.Copy, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Copy), args.Error(1)
+}
+
+func (m *MockCopyService) GetBookCopies(bookID uuid.UUID) ([]models.Copy, error) {
+	args := m.Called(bookID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Copy), args.Error(1)
+}
+
+func (m *MockCopyService) UpdateCopy(id uuid.UUID, req *models.UpdateCopyRequest) (*models.Copy, error) {
+	args := m.Called(id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Copy), args.Error(1)
+}
+
+func (m *MockCopyService) DeleteCopy(id uuid.UUID) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func TestCopyHandler_AddCopy(t *testing.T) {
+	t.Run("add copy successfully", func(t *testing.T) {
+		mockService := &MockCopyService{}
+		handler := NewCopyHandler(mockService)
+
+		bookID := uuid.New()
+		req := &models.CreateCopyRequest{Barcode: "B0001"}
+		mockService.On("AddCopy", bookID, req).Return(&models.Copy{BookID: bookID, Barcode: "B0001"}, nil)
+
+		body, _ := json.Marshal(req)
+		httpReq := httptest.NewRequest("POST", "/api/books/"+bookID.String()+"/copies", bytes.NewBuffer(body))
+		httpReq = mux.SetURLVars(httpReq, map[string]string{"id": bookID.String()})
+		w := httptest.NewRecorder()
+
+		handler.AddCopy(w, httpReq)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("add copy with duplicate barcode", func(t *testing.T) {
+		mockService := &MockCopyService{}
+		handler := NewCopyHandler(mockService)
+
+		bookID := uuid.New()
+		req := &models.CreateCopyRequest{Barcode: "B0001"}
+		mockService.On("AddCopy", bookID, req).Return(nil, errors.New("copy with barcode B0001 already exists"))
+
+		body, _ := json.Marshal(req)
+		httpReq := httptest.NewRequest("POST", "/api/books/"+bookID.String()+"/copies", bytes.NewBuffer(body))
+		httpReq = mux.SetURLVars(httpReq, map[string]string{"id": bookID.String()})
+		w := httptest.NewRecorder()
+
+		handler.AddCopy(w, httpReq)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+}
+
+func TestCopyHandler_GetCopy(t *testing.T) {
+	t.Run("get unknown copy", func(t *testing.T) {
+		mockService := &MockCopyService{}
+		handler := NewCopyHandler(mockService)
+
+		id := uuid.New()
+		mockService.On("GetCopy", id).Return(nil, errors.New("failed to get copy: copy not found"))
+
+		httpReq := httptest.NewRequest("GET", "/api/copies/"+id.String(), nil)
+		httpReq = mux.SetURLVars(httpReq, map[string]string{"id": id.String()})
+		w := httptest.NewRecorder()
+
+		handler.GetCopy(w, httpReq)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("get copy with invalid ID", func(t *testing.T) {
+		handler := NewCopyHandler(&MockCopyService{})
+
+		httpReq := httptest.NewRequest("GET", "/api/copies/bad", nil)
+		httpReq = mux.SetURLVars(httpReq, map[string]string{"id": "bad"})
+		w := httptest.NewRecorder()
+
+		handler.GetCopy(w, httpReq)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"encoding/json"
+	"libmngmt/internal/models"
+	"libmngmt/internal/service"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// FineHandler handles HTTP requests for a borrower's fine ledger
+type FineHandler struct {
+	fineService service.FineService
+}
+
+// NewFineHandler creates a new fine handler
+func NewFineHandler(fineService service.FineService) *FineHandler {
+	return &FineHandler{fineService: fineService}
+}
+
+// GetFines handles GET /api/borrowers/{id}/fines
+func (h *FineHandler) GetFines(w http.ResponseWriter, r *http.Request) {
+	memberID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid borrower ID", "ID must be a valid UUID")
+		return
+	}
+
+	ledger, err := h.fineService.GetLedger(memberID)
+	if err != nil {
+		writeLoanError(w, err)
+		return
+	}
+
+	writeSuccessResponse(w, http.StatusOK, "Fine ledger retrieved successfully", ledger)
+}
+
+// RecordPayment handles POST /api/borrowers/{id}/fines/payments
+func (h *FineHandler) RecordPayment(w http.ResponseWriter, r *http.Request) {
+	h.recordCredit(w, r, h.fineService.RecordPayment, "Payment recorded successfully")
+}
+
+// WaiveFine handles POST /api/borrowers/{id}/fines/waivers
+func (h *FineHandler) WaiveFine(w http.ResponseWriter, r *http.Request) {
+	h.recordCredit(w, r, h.fineService.WaiveFine, "Waiver recorded successfully")
+}
+
+func (h *FineHandler) recordCredit(
+	w http.ResponseWriter,
+	r *http.Request,
+	record func(uuid.UUID, *models.FineCreditRequest) (*models.FineEntry, error),
+	message string,
+) {
+	memberID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid borrower ID", "ID must be a valid UUID")
+		return
+	}
+
+	var req models.FineCreditRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON", err.Error())
+		return
+	}
+
+	entry, err := record(memberID, &req)
+	if err != nil {
+		writeLoanError(w, err)
+		return
+	}
+
+	writeSuccessResponse(w, http.StatusCreated, message, entry)
+}
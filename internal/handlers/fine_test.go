@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"libmngmt/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockFineService is a mock implementation of FineService for testing
+type MockFineService struct {
+	mock.Mock
+}
+
+func (m *MockFineService) AccrueOverdueFines() (int, error) {
+	args := m.Called()
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockFineService) GetLedger(memberID uuid.UUID) (*models.FineLedger, error) {
+	args := m.Called(memberID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.FineLedger), args.Error(1)
+}
+
+func (m *MockFineService) RecordPayment(memberID uuid.UUID, req *models.FineCreditRequest) (*models.FineEntry, error) {
+	args := m.Called(memberID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.FineEntry), args.Error(1)
+}
+
+func (m *MockFineService) WaiveFine(memberID uuid.UUID, req *models.FineCreditRequest) (*models.FineEntry, error) {
+	args := m.Called(memberID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.FineEntry), args.Error(1)
+}
+
+func TestFineHandler_GetFines(t *testing.T) {
+	t.Run("get fine ledger", func(t *testing.T) {
+		mockService := &MockFineService{}
+		handler := NewFineHandler(mockService)
+
+		memberID := uuid.New()
+		mockService.On("GetLedger", memberID).Return(&models.FineLedger{MemberID: memberID, BalanceCents: 150}, nil)
+
+		httpReq := httptest.NewRequest("GET", "/api/borrowers/"+memberID.String()+"/fines", nil)
+		httpReq = mux.SetURLVars(httpReq, map[string]string{"id": memberID.String()})
+		w := httptest.NewRecorder()
+
+		handler.GetFines(w, httpReq)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("unknown borrower", func(t *testing.T) {
+		mockService := &MockFineService{}
+		handler := NewFineHandler(mockService)
+
+		memberID := uuid.New()
+		mockService.On("GetLedger", memberID).Return(nil, errors.New("failed to get member: member not found"))
+
+		httpReq := httptest.NewRequest("GET", "/api/borrowers/"+memberID.String()+"/fines", nil)
+		httpReq = mux.SetURLVars(httpReq, map[string]string{"id": memberID.String()})
+		w := httptest.NewRecorder()
+
+		handler.GetFines(w, httpReq)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestFineHandler_RecordPayment(t *testing.T) {
+	t.Run("record payment", func(t *testing.T) {
+		mockService := &MockFineService{}
+		handler := NewFineHandler(mockService)
+
+		memberID := uuid.New()
+		req := &models.FineCreditRequest{AmountCents: 100, Note: "cash"}
+		mockService.On("RecordPayment", memberID, req).
+			Return(&models.FineEntry{EntryType: models.FineEntryPayment, AmountCents: 100}, nil)
+
+		body, _ := json.Marshal(req)
+		httpReq := httptest.NewRequest("POST", "/api/borrowers/"+memberID.String()+"/fines/payments", bytes.NewBuffer(body))
+		httpReq = mux.SetURLVars(httpReq, map[string]string{"id": memberID.String()})
+		w := httptest.NewRecorder()
+
+		handler.RecordPayment(w, httpReq)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("overpayment is a validation error", func(t *testing.T) {
+		mockService := &MockFineService{}
+		handler := NewFineHandler(mockService)
+
+		memberID := uuid.New()
+		req := &models.FineCreditRequest{AmountCents: 900}
+		mockService.On("RecordPayment", memberID, req).
+			Return(nil, errors.New("failed to record payment: invalid amount_cents: exceeds outstanding balance of 150"))
+
+		body, _ := json.Marshal(req)
+		httpReq := httptest.NewRequest("POST", "/api/borrowers/"+memberID.String()+"/fines/payments", bytes.NewBuffer(body))
+		httpReq = mux.SetURLVars(httpReq, map[string]string{"id": memberID.String()})
+		w := httptest.NewRecorder()
+
+		handler.RecordPayment(w, httpReq)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
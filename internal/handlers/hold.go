@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"encoding/json"
+	"libmngmt/internal/models"
+	"libmngmt/internal/service"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// HoldHandler handles HTTP requests for book holds
+type HoldHandler struct {
+	holdService service.HoldService
+}
+
+// NewHoldHandler creates a new hold handler
+func NewHoldHandler(holdService service.HoldService) *HoldHandler {
+	return &HoldHandler{holdService: holdService}
+}
+
+// PlaceHold handles POST /api/books/{id}/holds
+func (h *HoldHandler) PlaceHold(w http.ResponseWriter, r *http.Request) {
+	bookID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid book ID", "ID must be a valid UUID")
+		return
+	}
+
+	var req models.PlaceHoldRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON", err.Error())
+		return
+	}
+
+	hold, err := h.holdService.PlaceHold(bookID, &req)
+	if err != nil {
+		writeLoanError(w, err)
+		return
+	}
+
+	writeSuccessResponse(w, http.StatusCreated, "Hold placed successfully", hold)
+}
+
+// GetBookHolds handles GET /api/books/{id}/holds
+func (h *HoldHandler) GetBookHolds(w http.ResponseWriter, r *http.Request) {
+	bookID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid book ID", "ID must be a valid UUID")
+		return
+	}
+
+	holds, err := h.holdService.GetBookHolds(bookID)
+	if err != nil {
+		writeLoanError(w, err)
+		return
+	}
+
+	writeSuccessResponse(w, http.StatusOK, "Holds retrieved successfully", holds)
+}
+
+// GetHold handles GET /api/holds/{id}
+func (h *HoldHandler) GetHold(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid hold ID", "ID must be a valid UUID")
+		return
+	}
+
+	hold, err := h.holdService.GetHold(id)
+	if err != nil {
+		writeLoanError(w, err)
+		return
+	}
+
+	writeSuccessResponse(w, http.StatusOK, "Hold retrieved successfully", hold)
+}
+
+// CancelHold handles DELETE /api/holds/{id}
+func (h *HoldHandler) CancelHold(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid hold ID", "ID must be a valid UUID")
+		return
+	}
+
+	hold, err := h.holdService.CancelHold(id)
+	if err != nil {
+		writeLoanError(w, err)
+		return
+	}
+
+	writeSuccessResponse(w, http.StatusOK, "Hold cancelled successfully", hold)
+}
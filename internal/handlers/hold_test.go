@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"libmngmt/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockHoldService is a mock implementation of HoldService for testing
+type MockHoldService struct {
+	mock.Mock
+}
+
+func (m *MockHoldService) PlaceHold(bookID uuid.UUID, req *models.PlaceHoldRequest) (*models.Hold, error) {
+	args := m.Called(bookID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Hold), args.Error(1)
+}
+
+func (m *MockHoldService) GetHold(id uuid.UUID) (*models.Hold, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Hold), args.Error(1)
+}
+
+func (m *MockHoldService) GetBookHolds(bookID uuid.UUID) ([]models.Hold, error) {
+	args := m.Called(bookID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Hold), args.Error(1)
+}
+
+func (m *MockHoldService) CancelHold(id uuid.UUID) (*models.Hold, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Hold), args.Error(1)
+}
+
+func (m *MockHoldService) ExpireReadyHolds() (int, error) {
+	args := m.Called()
+	return args.Int(0), args.Error(1)
+}
+
+func TestHoldHandler_PlaceHold(t *testing.T) {
+	t.Run("place hold successfully", func(t *testing.T) {
+		mockService := &MockHoldService{}
+		handler := NewHoldHandler(mockService)
+
+		bookID := uuid.New()
+		req := &models.PlaceHoldRequest{MemberID: uuid.New()}
+		mockService.On("PlaceHold", bookID, req).
+			Return(&models.Hold{BookID: bookID, MemberID: req.MemberID, Status: models.HoldStatusWaiting, Position: 1}, nil)
+
+		body, _ := json.Marshal(req)
+		httpReq := httptest.NewRequest("POST", "/api/books/"+bookID.String()+"/holds", bytes.NewBuffer(body))
+		httpReq = mux.SetURLVars(httpReq, map[string]string{"id": bookID.String()})
+		w := httptest.NewRecorder()
+
+		handler.PlaceHold(w, httpReq)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("place hold on available book", func(t *testing.T) {
+		mockService := &MockHoldService{}
+		handler := NewHoldHandler(mockService)
+
+		bookID := uuid.New()
+		req := &models.PlaceHoldRequest{MemberID: uuid.New()}
+		mockService.On("PlaceHold", bookID, req).
+			Return(nil, errors.New("failed to place hold: book is available for checkout, no hold needed"))
+
+		body, _ := json.Marshal(req)
+		httpReq := httptest.NewRequest("POST", "/api/books/"+bookID.String()+"/holds", bytes.NewBuffer(body))
+		httpReq = mux.SetURLVars(httpReq, map[string]string{"id": bookID.String()})
+		w := httptest.NewRecorder()
+
+		handler.PlaceHold(w, httpReq)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+}
+
+func TestHoldHandler_CancelHold(t *testing.T) {
+	t.Run("cancel unknown hold", func(t *testing.T) {
+		mockService := &MockHoldService{}
+		handler := NewHoldHandler(mockService)
+
+		id := uuid.New()
+		mockService.On("CancelHold", id).Return(nil, errors.New("failed to cancel hold: hold not found"))
+
+		httpReq := httptest.NewRequest("DELETE", "/api/holds/"+id.String(), nil)
+		httpReq = mux.SetURLVars(httpReq, map[string]string{"id": id.String()})
+		w := httptest.NewRecorder()
+
+		handler.CancelHold(w, httpReq)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("cancel with invalid ID", func(t *testing.T) {
+		handler := NewHoldHandler(&MockHoldService{})
+
+		httpReq := httptest.NewRequest("DELETE", "/api/holds/bad", nil)
+		httpReq = mux.SetURLVars(httpReq, map[string]string{"id": "bad"})
+		w := httptest.NewRecorder()
+
+		handler.CancelHold(w, httpReq)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"libmngmt/internal/isbn"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// ISBNHandler handles HTTP requests for ISBN validation
+type ISBNHandler struct{}
+
+// NewISBNHandler creates a new ISBN handler
+func NewISBNHandler() *ISBNHandler {
+	return &ISBNHandler{}
+}
+
+// ValidateISBN handles GET /api/isbn/{isbn}/validate. A valid ISBN-10 or
+// ISBN-13 is returned in both forms; otherwise the reason it was rejected.
+func (h *ISBNHandler) ValidateISBN(w http.ResponseWriter, r *http.Request) {
+	parsed, err := isbn.Parse(mux.Vars(r)["isbn"])
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid ISBN", err.Error())
+		return
+	}
+
+	writeSuccessResponse(w, http.StatusOK, "ISBN is valid", parsed)
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestISBNHandler_ValidateISBN(t *testing.T) {
+	handler := NewISBNHandler()
+
+	validate := func(value string) *httptest.ResponseRecorder {
+		httpReq := httptest.NewRequest("GET", "/api/isbn/"+value+"/validate", nil)
+		httpReq = mux.SetURLVars(httpReq, map[string]string{"isbn": value})
+		w := httptest.NewRecorder()
+		handler.ValidateISBN(w, httpReq)
+		return w
+	}
+
+	t.Run("valid ISBN-10 returns both forms", func(t *testing.T) {
+		w := validate("0-547-92822-X")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response struct {
+			Data map[string]string `json:"data"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "9780547928227", response.Data["isbn13"])
+		assert.Equal(t, "054792822X", response.Data["isbn10"])
+	})
+
+	t.Run("bad check digit explains the rejection", func(t *testing.T) {
+		w := validate("9780547928220")
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "check digit should be 7, got 0")
+	})
+}
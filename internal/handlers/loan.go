@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"libmngmt/internal/models"
+	"libmngmt/internal/service"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// LoanHandler handles HTTP requests for checkouts and returns
+type LoanHandler struct {
+	loanService service.LoanService
+}
+
+// NewLoanHandler creates a new loan handler
+func NewLoanHandler(loanService service.LoanService) *LoanHandler {
+	return &LoanHandler{loanService: loanService}
+}
+
+// CheckoutBook handles POST /api/books/{id}/checkout
+func (h *LoanHandler) CheckoutBook(w http.ResponseWriter, r *http.Request) {
+	bookID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid book ID", "ID must be a valid UUID")
+		return
+	}
+
+	var req models.CheckoutRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON", err.Error())
+		return
+	}
+
+	loan, err := h.loanService.CheckoutBook(bookID, &req)
+	if err != nil {
+		writeLoanError(w, err)
+		return
+	}
+
+	writeSuccessResponse(w, http.StatusCreated, "Book checked out successfully", loan)
+}
+
+// ReturnBook handles POST /api/books/{id}/return
+func (h *LoanHandler) ReturnBook(w http.ResponseWriter, r *http.Request) {
+	bookID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid book ID", "ID must be a valid UUID")
+		return
+	}
+
+	// The body is optional: it names the copy when several are on loan
+	var req models.ReturnRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON", err.Error())
+		return
+	}
+
+	loan, err := h.loanService.ReturnBook(bookID, &req)
+	if err != nil {
+		writeLoanError(w, err)
+		return
+	}
+
+	writeSuccessResponse(w, http.StatusOK, "Book returned successfully", loan)
+}
+
+// GetMemberLoans handles GET /api/members/{id}/loans
+func (h *LoanHandler) GetMemberLoans(w http.ResponseWriter, r *http.Request) {
+	memberID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid member ID", "ID must be a valid UUID")
+		return
+	}
+
+	openOnly, _ := strconv.ParseBool(r.URL.Query().Get("open"))
+
+	loans, err := h.loanService.GetMemberLoans(memberID, openOnly)
+	if err != nil {
+		writeLoanError(w, err)
+		return
+	}
+
+	writeSuccessResponse(w, http.StatusOK, "Loans retrieved successfully", loans)
+}
+
+// writeLoanError maps circulation errors onto HTTP responses
+func writeLoanError(w http.ResponseWriter, err error) {
+	switch {
+	case isNotFoundError(err):
+		writeErrorResponse(w, http.StatusNotFound, "Resource not found", err.Error())
+	case isCirculationConflict(err):
+		writeErrorResponse(w, http.StatusConflict, "Circulation conflict", err.Error())
+	case isValidationError(err):
+		writeErrorResponse(w, http.StatusBadRequest, "Validation error", err.Error())
+	default:
+		writeErrorResponse(w, http.StatusInternalServerError, "Internal server error", err.Error())
+	}
+}
+
+func isCirculationConflict(err error) bool {
+	circulationKeywords := []string{
+		"already checked out",
+		"still checked out",
+		"not checked out",
+		"cannot borrow",
+		"on hold for another member",
+		"no hold needed",
+		"already has",
+		"hold is already",
+		"no copy available",
+		"set aside for a hold",
+	}
+	for _, keyword := range circulationKeywords {
+		if contains(err.Error(), keyword) {
+			return true
+		}
+	}
+	return false
+}
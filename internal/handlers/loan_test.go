@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"libmngmt/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockLoanService is a mock implementation of LoanService for testing
+type MockLoanService struct {
+	mock.Mock
+}
+
+func (m *MockLoanService) CheckoutBook(bookID uuid.UUID, req *models.CheckoutRequest) (*models.Loan, error) {
+	args := m.Called(bookID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Loan), args.Error(1)
+}
+
+func (m *MockLoanService) ReturnBook(bookID uuid.UUID, req *models.ReturnRequest) (*models.Loan, error) {
+	args := m.Called(bookID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Loan), args.Error(1)
+}
+
+func (m *MockLoanService) GetMemberLoans(memberID uuid.UUID, openOnly bool) ([]models.Loan, error) {
+	args := m.Called(memberID, openOnly)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Loan), args.Error(1)
+}
+
+func TestLoanHandler_CheckoutBook(t *testing.T) {
+	t.Run("checkout book successfully", func(t *testing.T) {
+		mockService := &MockLoanService{}
+		handler := NewLoanHandler(mockService)
+
+		bookID := uuid.New()
+		req := &models.CheckoutRequest{MemberID: uuid.New()}
+		mockService.On("CheckoutBook", bookID, req).Return(&models.Loan{BookID: &bookID, MemberID: req.MemberID}, nil)
+
+		body, _ := json.Marshal(req)
+		httpReq := httptest.NewRequest("POST", "/api/books/"+bookID.String()+"/checkout", bytes.NewBuffer(body))
+		httpReq = mux.SetURLVars(httpReq, map[string]string{"id": bookID.String()})
+		w := httptest.NewRecorder()
+
+		handler.CheckoutBook(w, httpReq)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("checkout book already on loan", func(t *testing.T) {
+		mockService := &MockLoanService{}
+		handler := NewLoanHandler(mockService)
+
+		bookID := uuid.New()
+		req := &models.CheckoutRequest{MemberID: uuid.New()}
+		mockService.On("CheckoutBook", bookID, req).
+			Return(nil, errors.New("failed to checkout book: book is already checked out"))
+
+		body, _ := json.Marshal(req)
+		httpReq := httptest.NewRequest("POST", "/api/books/"+bookID.String()+"/checkout", bytes.NewBuffer(body))
+		httpReq = mux.SetURLVars(httpReq, map[string]string{"id": bookID.String()})
+		w := httptest.NewRecorder()
+
+		handler.CheckoutBook(w, httpReq)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestLoanHandler_ReturnBook(t *testing.T) {
+	t.Run("return book that is not on loan", func(t *testing.T) {
+		mockService := &MockLoanService{}
+		handler := NewLoanHandler(mockService)
+
+		bookID := uuid.New()
+		mockService.On("ReturnBook", bookID, &models.ReturnRequest{}).
+			Return(nil, errors.New("failed to return book: book is not checked out"))
+
+		httpReq := httptest.NewRequest("POST", "/api/books/"+bookID.String()+"/return", nil)
+		httpReq = mux.SetURLVars(httpReq, map[string]string{"id": bookID.String()})
+		w := httptest.NewRecorder()
+
+		handler.ReturnBook(w, httpReq)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("return names the copy", func(t *testing.T) {
+		mockService := &MockLoanService{}
+		handler := NewLoanHandler(mockService)
+
+		bookID := uuid.New()
+		copyID := uuid.New()
+		req := &models.ReturnRequest{CopyID: &copyID}
+		mockService.On("ReturnBook", bookID, req).Return(&models.Loan{BookID: &bookID, CopyID: &copyID}, nil)
+
+		body, _ := json.Marshal(req)
+		httpReq := httptest.NewRequest("POST", "/api/books/"+bookID.String()+"/return", bytes.NewBuffer(body))
+		httpReq = mux.SetURLVars(httpReq, map[string]string{"id": bookID.String()})
+		w := httptest.NewRecorder()
+
+		handler.ReturnBook(w, httpReq)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("return without naming one of several copies on loan", func(t *testing.T) {
+		mockService := &MockLoanService{}
+		handler := NewLoanHandler(mockService)
+
+		bookID := uuid.New()
+		mockService.On("ReturnBook", bookID, &models.ReturnRequest{}).
+			Return(nil, errors.New("failed to return book: copy_id is required when several copies of the book are on loan"))
+
+		httpReq := httptest.NewRequest("POST", "/api/books/"+bookID.String()+"/return", nil)
+		httpReq = mux.SetURLVars(httpReq, map[string]string{"id": bookID.String()})
+		w := httptest.NewRecorder()
+
+		handler.ReturnBook(w, httpReq)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"encoding/json"
+	"libmngmt/internal/models"
+	"libmngmt/internal/service"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// MemberHandler handles HTTP requests for library members
+type MemberHandler struct {
+	memberService service.MemberService
+}
+
+// NewMemberHandler creates a new member handler
+func NewMemberHandler(memberService service.MemberService) *MemberHandler {
+	return &MemberHandler{memberService: memberService}
+}
+
+// CreateMember handles POST /api/members
+func (h *MemberHandler) CreateMember(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON", err.Error())
+		return
+	}
+
+	member, err := h.memberService.CreateMember(&req)
+	if err != nil {
+		writeMemberError(w, err)
+		return
+	}
+
+	writeSuccessResponse(w, http.StatusCreated, "Member created successfully", member)
+}
+
+// GetMember handles GET /api/members/{id}
+func (h *MemberHandler) GetMember(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid member ID", "ID must be a valid UUID")
+		return
+	}
+
+	member, err := h.memberService.GetMemberByID(id)
+	if err != nil {
+		writeMemberError(w, err)
+		return
+	}
+
+	writeSuccessResponse(w, http.StatusOK, "Member retrieved successfully", member)
+}
+
+// GetMembers handles GET /api/members
+func (h *MemberHandler) GetMembers(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := models.MemberFilter{
+		Name:   query.Get("name"),
+		Email:  query.Get("email"),
+		Status: models.MemberStatus(query.Get("status")),
+	}
+	if limit, err := strconv.Atoi(query.Get("limit")); err == nil && limit > 0 {
+		filter.Limit = limit
+	}
+	if offset, err := strconv.Atoi(query.Get("offset")); err == nil && offset >= 0 {
+		filter.Offset = offset
+	}
+
+	response, err := h.memberService.GetAllMembers(filter)
+	if err != nil {
+		writeMemberError(w, err)
+		return
+	}
+
+	writeSuccessResponse(w, http.StatusOK, "Members retrieved successfully", response)
+}
+
+// UpdateMember handles PUT /api/members/{id}
+func (h *MemberHandler) UpdateMember(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid member ID", "ID must be a valid UUID")
+		return
+	}
+
+	var req models.UpdateMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON", err.Error())
+		return
+	}
+
+	member, err := h.memberService.UpdateMember(id, &req)
+	if err != nil {
+		writeMemberError(w, err)
+		return
+	}
+
+	writeSuccessResponse(w, http.StatusOK, "Member updated successfully", member)
+}
+
+// DeleteMember handles DELETE /api/members/{id}
+func (h *MemberHandler) DeleteMember(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid member ID", "ID must be a valid UUID")
+		return
+	}
+
+	if err := h.memberService.DeleteMember(id); err != nil {
+		writeMemberError(w, err)
+		return
+	}
+
+	writeSuccessResponse(w, http.StatusOK, "Member deleted successfully", nil)
+}
+
+// writeMemberError maps member service errors onto HTTP responses
+func writeMemberError(w http.ResponseWriter, err error) {
+	switch {
+	case isNotFoundError(err):
+		writeErrorResponse(w, http.StatusNotFound, "Member not found", err.Error())
+	case isDuplicateError(err):
+		writeErrorResponse(w, http.StatusConflict, "Duplicate resource", err.Error())
+	case isValidationError(err):
+		writeErrorResponse(w, http.StatusBadRequest, "Validation error", err.Error())
+	default:
+		writeErrorResponse(w, http.StatusInternalServerError, "Internal server error", err.Error())
+	}
+}
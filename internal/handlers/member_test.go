@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"libmngmt/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockMemberService is a mock implementation of MemberService for testing
+type MockMemberService struct {
+	mock.Mock
+}
+
+func (m *MockMemberService) CreateMember(req *models.CreateMemberRequest) (*models.Member, error) {
+	args := m.Called(req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Member), args.Error(1)
+}
+
+func (m *MockMemberService) GetMemberByID(id uuid.UUID) (*models.Member, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Member), args.Error(1)
+}
+
+func (m *MockMemberService) GetAllMembers(filter models.MemberFilter) (*models.MembersListResponse, error) {
+	args := m.Called(filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.MembersListResponse), args.Error(1)
+}
+
+func (m *MockMemberService) UpdateMember(id uuid.UUID, req *models.UpdateMemberRequest) (*models.Member, error) {
+	args := m.Called(id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Member), args.Error(1)
+}
+
+func (m *MockMemberService) DeleteMember(id uuid.UUID) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func TestMemberHandler_CreateMember(t *testing.T) {
+	t.Run("create member successfully", func(t *testing.T) {
+		mockService := &MockMemberService{}
+		handler := NewMemberHandler(mockService)
+
+		req := &models.CreateMemberRequest{CardNumber: "LIB-0001", FirstName: "Ada", LastName: "Lovelace", Email: "ada@example.com"}
+		mockService.On("CreateMember", req).Return(&models.Member{ID: uuid.New(), CardNumber: "LIB-0001"}, nil)
+
+		body, _ := json.Marshal(req)
+		w := httptest.NewRecorder()
+		handler.CreateMember(w, httptest.NewRequest("POST", "/api/members", bytes.NewBuffer(body)))
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("create member with duplicate card number", func(t *testing.T) {
+		mockService := &MockMemberService{}
+		handler := NewMemberHandler(mockService)
+
+		req := &models.CreateMemberRequest{CardNumber: "LIB-0001", FirstName: "Ada", LastName: "Lovelace", Email: "ada@example.com"}
+		mockService.On("CreateMember", req).Return(nil, errors.New("member with card number LIB-0001 already exists"))
+
+		body, _ := json.Marshal(req)
+		w := httptest.NewRecorder()
+		handler.CreateMember(w, httptest.NewRequest("POST", "/api/members", bytes.NewBuffer(body)))
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestMemberHandler_GetMember(t *testing.T) {
+	t.Run("get member with invalid UUID", func(t *testing.T) {
+		handler := NewMemberHandler(&MockMemberService{})
+
+		httpReq := mux.SetURLVars(httptest.NewRequest("GET", "/api/members/bad", nil), map[string]string{"id": "bad"})
+		w := httptest.NewRecorder()
+		handler.GetMember(w, httpReq)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("get member not found", func(t *testing.T) {
+		mockService := &MockMemberService{}
+		handler := NewMemberHandler(mockService)
+
+		id := uuid.New()
+		mockService.On("GetMemberByID", id).Return(nil, errors.New("failed to get member: member not found"))
+
+		httpReq := mux.SetURLVars(httptest.NewRequest("GET", "/api/members/"+id.String(), nil), map[string]string{"id": id.String()})
+		w := httptest.NewRecorder()
+		handler.GetMember(w, httpReq)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		var response map[string]interface{}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "Member not found", response["error"])
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestMemberHandler_GetMembers(t *testing.T) {
+	t.Run("get members with status filter", func(t *testing.T) {
+		mockService := &MockMemberService{}
+		handler := NewMemberHandler(mockService)
+
+		filter := models.MemberFilter{Status: models.MemberStatusActive, Limit: 10}
+		mockService.On("GetAllMembers", filter).Return(&models.MembersListResponse{Members: []models.Member{}, Limit: 10}, nil)
+
+		w := httptest.NewRecorder()
+		handler.GetMembers(w, httptest.NewRequest("GET", "/api/members?status=active&limit=10", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"encoding/json"
+	"libmngmt/internal/models"
+	"net/http"
+)
+
+// writeSuccessResponse writes a SuccessResponse envelope shared by all handlers
+func writeSuccessResponse(w http.ResponseWriter, statusCode int, message string, data interface{}) {
+	w.WriteHeader(statusCode)
+	response := models.SuccessResponse{
+		Message: message,
+		Data:    data,
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// writeErrorResponse writes an ErrorResponse envelope shared by all handlers
+func writeErrorResponse(w http.ResponseWriter, statusCode int, error, message string) {
+	w.WriteHeader(statusCode)
+	response := models.ErrorResponse{
+		Error:   error,
+		Message: message,
+		Code:    statusCode,
+	}
+	json.NewEncoder(w).Encode(response)
+}
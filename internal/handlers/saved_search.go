@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"encoding/json"
+	"libmngmt/internal/errors"
+	"libmngmt/internal/middleware"
+	"libmngmt/internal/models"
+	"libmngmt/internal/service"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// SavedSearchHandler handles HTTP requests for saved searches and their alerts
+type SavedSearchHandler struct {
+	searchService service.SavedSearchService
+}
+
+// NewSavedSearchHandler creates a new saved search handler
+func NewSavedSearchHandler(searchService service.SavedSearchService) *SavedSearchHandler {
+	return &SavedSearchHandler{searchService: searchService}
+}
+
+// CreateSavedSearch handles POST /api/saved-searches
+func (h *SavedSearchHandler) CreateSavedSearch(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateSavedSearchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON", err.Error())
+		return
+	}
+
+	search, err := h.searchService.CreateSavedSearch(&req)
+	if err != nil {
+		writeSavedSearchError(w, r, err)
+		return
+	}
+
+	writeSuccessResponse(w, http.StatusCreated, "Saved search created successfully", search)
+}
+
+// GetSavedSearches handles GET /api/saved-searches?owner=
+func (h *SavedSearchHandler) GetSavedSearches(w http.ResponseWriter, r *http.Request) {
+	searches, err := h.searchService.GetSavedSearches(r.URL.Query().Get("owner"))
+	if err != nil {
+		writeSavedSearchError(w, r, err)
+		return
+	}
+
+	writeSuccessResponse(w, http.StatusOK, "Saved searches retrieved successfully", searches)
+}
+
+// GetSavedSearch handles GET /api/saved-searches/{id}
+func (h *SavedSearchHandler) GetSavedSearch(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid saved search ID", "ID must be a valid UUID")
+		return
+	}
+
+	search, err := h.searchService.GetSavedSearch(id)
+	if err != nil {
+		writeSavedSearchError(w, r, err)
+		return
+	}
+
+	writeSuccessResponse(w, http.StatusOK, "Saved search retrieved successfully", search)
+}
+
+// DeleteSavedSearch handles DELETE /api/saved-searches/{id}
+func (h *SavedSearchHandler) DeleteSavedSearch(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid saved search ID", "ID must be a valid UUID")
+		return
+	}
+
+	if err := h.searchService.DeleteSavedSearch(id); err != nil {
+		writeSavedSearchError(w, r, err)
+		return
+	}
+
+	writeSuccessResponse(w, http.StatusOK, "Saved search deleted successfully", nil)
+}
+
+// GetAlerts handles GET /api/alerts?owner=&unread=true
+func (h *SavedSearchHandler) GetAlerts(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	unreadOnly, _ := strconv.ParseBool(query.Get("unread"))
+
+	alerts, err := h.searchService.GetAlerts(query.Get("owner"), unreadOnly)
+	if err != nil {
+		writeSavedSearchError(w, r, err)
+		return
+	}
+
+	writeSuccessResponse(w, http.StatusOK, "Alerts retrieved successfully", alerts)
+}
+
+// MarkAlertRead handles POST /api/alerts/{id}/read
+func (h *SavedSearchHandler) MarkAlertRead(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid alert ID", "ID must be a valid UUID")
+		return
+	}
+
+	alert, err := h.searchService.MarkAlertRead(id)
+	if err != nil {
+		writeSavedSearchError(w, r, err)
+		return
+	}
+
+	writeSuccessResponse(w, http.StatusOK, "Alert marked as read", alert)
+}
+
+// writeSavedSearchError maps saved search service errors onto HTTP responses.
+// Catalog query syntax errors carry the position of the problem.
+func writeSavedSearchError(w http.ResponseWriter, r *http.Request, err error) {
+	if appErr, ok := errors.As(err); ok {
+		errors.WriteErrorResponse(w, appErr, middleware.GetRequestID(r.Context()))
+		return
+	}
+
+	switch {
+	case isNotFoundError(err):
+		writeErrorResponse(w, http.StatusNotFound, "Resource not found", err.Error())
+	case isValidationError(err):
+		writeErrorResponse(w, http.StatusBadRequest, "Validation error", err.Error())
+	default:
+		writeErrorResponse(w, http.StatusInternalServerError, "Internal server error", err.Error())
+	}
+}
@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	apperrors "libmngmt/internal/errors"
+	"libmngmt/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockSavedSearchService is a mock implementation of SavedSearchService for testing
+type MockSavedSearchService struct {
+	mock.Mock
+}
+
+func (m *MockSavedSearchService) MatchNewArrival(ctx context.Context, book *models.Book) (int, error) {
+	args := m.Called(ctx, book)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockSavedSearchService) CreateSavedSearch(req *models.CreateSavedSearchRequest) (*models.SavedSearch, error) {
+	args := m.Called(req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SavedSearch), args.Error(1)
+}
+
+func (m *MockSavedSearchService) GetSavedSearch(id uuid.UUID) (*models.SavedSearch, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SavedSearch), args.Error(1)
+}
+
+func (m *MockSavedSearchService) GetSavedSearches(owner string) ([]models.SavedSearch, error) {
+	args := m.Called(owner)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.SavedSearch), args.Error(1)
+}
+
+func (m *MockSavedSearchService) DeleteSavedSearch(id uuid.UUID) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockSavedSearchService) GetAlerts(owner string, unreadOnly bool) ([]models.SearchAlert, error) {
+	args := m.Called(owner, unreadOnly)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.SearchAlert), args.Error(1)
+}
+
+func (m *MockSavedSearchService) MarkAlertRead(id uuid.UUID) (*models.SearchAlert, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SearchAlert), args.Error(1)
+}
+
+func TestSavedSearchHandler_CreateSavedSearch(t *testing.T) {
+	t.Run("create saved search successfully", func(t *testing.T) {
+		mockService := &MockSavedSearchService{}
+		handler := NewSavedSearchHandler(mockService)
+
+		req := &models.CreateSavedSearchRequest{
+			Owner:      "member-42",
+			Name:       "Go books",
+			Filter:     models.BookFilter{Query: "title:go"},
+			WebhookURL: "https://example.com/hook",
+		}
+		mockService.On("CreateSavedSearch", req).Return(&models.SavedSearch{ID: uuid.New(), Owner: "member-42"}, nil)
+
+		body, _ := json.Marshal(req)
+		w := httptest.NewRecorder()
+		handler.CreateSavedSearch(w, httptest.NewRequest("POST", "/api/saved-searches", bytes.NewBuffer(body)))
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("missing name is a bad request", func(t *testing.T) {
+		mockService := &MockSavedSearchService{}
+		handler := NewSavedSearchHandler(mockService)
+
+		mockService.On("CreateSavedSearch", mock.Anything).Return(nil, errors.New("name is required"))
+
+		w := httptest.NewRecorder()
+		handler.CreateSavedSearch(w, httptest.NewRequest("POST", "/api/saved-searches", bytes.NewBufferString(`{"owner":"member-42"}`)))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("query syntax error carries its position", func(t *testing.T) {
+		mockService := &MockSavedSearchService{}
+		handler := NewSavedSearchHandler(mockService)
+
+		mockService.On("CreateSavedSearch", mock.Anything).Return(nil, apperrors.Syntax("invalid query", `unknown field "rating" at position 1`, 1))
+
+		body := `{"owner":"member-42","name":"Top rated","filter":{"q":"rating>4"}}`
+		w := httptest.NewRecorder()
+		handler.CreateSavedSearch(w, httptest.NewRequest("POST", "/api/saved-searches", bytes.NewBufferString(body)))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		var response struct {
+			Error apperrors.AppError `json:"error"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, 1, response.Error.Position)
+	})
+}
+
+func TestSavedSearchHandler_GetSavedSearch(t *testing.T) {
+	t.Run("missing saved search is not found", func(t *testing.T) {
+		mockService := &MockSavedSearchService{}
+		handler := NewSavedSearchHandler(mockService)
+		id := uuid.New()
+
+		mockService.On("GetSavedSearch", id).Return(nil, errors.New("failed to get saved search: saved search not found"))
+
+		httpReq := httptest.NewRequest("GET", "/api/saved-searches/"+id.String(), nil)
+		httpReq = mux.SetURLVars(httpReq, map[string]string{"id": id.String()})
+		w := httptest.NewRecorder()
+		handler.GetSavedSearch(w, httpReq)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestSavedSearchHandler_GetAlerts(t *testing.T) {
+	t.Run("unread alerts for an owner", func(t *testing.T) {
+		mockService := &MockSavedSearchService{}
+		handler := NewSavedSearchHandler(mockService)
+
+		alerts := []models.SearchAlert{{ID: uuid.New(), Owner: "member-42", BookTitle: "Dune", CreatedAt: time.Now()}}
+		mockService.On("GetAlerts", "member-42", true).Return(alerts, nil)
+
+		w := httptest.NewRecorder()
+		handler.GetAlerts(w, httptest.NewRequest("GET", "/api/alerts?owner=member-42&unread=true", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestSavedSearchHandler_MarkAlertRead(t *testing.T) {
+	t.Run("invalid alert ID", func(t *testing.T) {
+		mockService := &MockSavedSearchService{}
+		handler := NewSavedSearchHandler(mockService)
+
+		httpReq := httptest.NewRequest("POST", "/api/alerts/abc/read", nil)
+		httpReq = mux.SetURLVars(httpReq, map[string]string{"id": "abc"})
+		w := httptest.NewRecorder()
+		handler.MarkAlertRead(w, httpReq)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "MarkAlertRead", mock.Anything)
+	})
+}
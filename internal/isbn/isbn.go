@@ -0,0 +1,142 @@
+// Package isbn validates and converts International Standard Book Numbers.
+//
+// Both ISBN-10 and ISBN-13 are accepted on input, with or without hyphens and
+// spaces. Books are stored under their canonical ISBN-13; the ISBN-10 form is
+// derived for display when the number has one (only 978-prefixed ISBNs do).
+package isbn
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ISBN holds both forms of a validated ISBN
+type ISBN struct {
+	ISBN13 string `json:"isbn13"`
+	// ISBN10 is empty for 979-prefixed numbers, which have no ISBN-10 form
+	ISBN10 string `json:"isbn10,omitempty"`
+}
+
+// Strip removes the hyphens and spaces commonly used to group ISBN digits
+func Strip(s string) string {
+	return strings.NewReplacer("-", "", " ", "").Replace(strings.TrimSpace(s))
+}
+
+// Parse validates an ISBN-10 or ISBN-13 and returns both forms. The error
+// names the precise reason a number was rejected.
+func Parse(s string) (ISBN, error) {
+	digits := strings.ToUpper(Strip(s))
+
+	switch len(digits) {
+	case 10:
+		if err := checkISBN10(digits); err != nil {
+			return ISBN{}, err
+		}
+		isbn13 := "978" + digits[:9]
+		isbn13 += string(checkDigit13(isbn13))
+		return ISBN{ISBN13: isbn13, ISBN10: digits}, nil
+	case 13:
+		if err := checkISBN13(digits); err != nil {
+			return ISBN{}, err
+		}
+		result := ISBN{ISBN13: digits}
+		if strings.HasPrefix(digits, "978") {
+			result.ISBN10 = digits[3:12] + string(checkDigit10(digits[3:12]))
+		}
+		return result, nil
+	default:
+		return ISBN{}, fmt.Errorf("must have 10 or 13 digits, got %d characters", len(digits))
+	}
+}
+
+// Canonical returns the ISBN-13 form of a valid ISBN-10 or ISBN-13
+func Canonical(s string) (string, error) {
+	parsed, err := Parse(s)
+	if err != nil {
+		return "", err
+	}
+	return parsed.ISBN13, nil
+}
+
+// Valid reports whether s is a valid ISBN-10 or ISBN-13
+func Valid(s string) bool {
+	_, err := Parse(s)
+	return err == nil
+}
+
+// To10 returns the ISBN-10 form of a valid ISBN, or "" when it has none
+func To10(s string) string {
+	parsed, err := Parse(s)
+	if err != nil {
+		return ""
+	}
+	return parsed.ISBN10
+}
+
+func checkISBN10(digits string) error {
+	for i := 0; i < 9; i++ {
+		if !isDigit(digits[i]) {
+			return fmt.Errorf("ISBN-10 has unexpected character %q at position %d", digits[i], i+1)
+		}
+	}
+	last := digits[9]
+	if !isDigit(last) && last != 'X' {
+		return fmt.Errorf("ISBN-10 check digit must be 0-9 or X, got %q", last)
+	}
+
+	if want := checkDigit10(digits[:9]); last != want {
+		return fmt.Errorf("ISBN-10 check digit should be %c, got %c", want, last)
+	}
+	return nil
+}
+
+func checkISBN13(digits string) error {
+	for i := 0; i < 13; i++ {
+		if !isDigit(digits[i]) {
+			if digits[i] == 'X' && i == 12 {
+				return fmt.Errorf("X is only allowed as an ISBN-10 check digit")
+			}
+			return fmt.Errorf("ISBN-13 has unexpected character %q at position %d", digits[i], i+1)
+		}
+	}
+	if prefix := digits[:3]; prefix != "978" && prefix != "979" {
+		return fmt.Errorf("ISBN-13 prefix must be 978 or 979, got %s", prefix)
+	}
+
+	if want, last := checkDigit13(digits[:12]), digits[12]; last != want {
+		return fmt.Errorf("ISBN-13 check digit should be %c, got %c", want, last)
+	}
+	return nil
+}
+
+// checkDigit10 computes the ISBN-10 check digit for nine digits: the weighted
+// sum with weights 10..2 plus the check digit must be divisible by 11.
+func checkDigit10(nine string) byte {
+	sum := 0
+	for i := 0; i < 9; i++ {
+		sum += int(nine[i]-'0') * (10 - i)
+	}
+	check := (11 - sum%11) % 11
+	if check == 10 {
+		return 'X'
+	}
+	return byte('0' + check)
+}
+
+// checkDigit13 computes the ISBN-13 check digit for twelve digits using
+// alternating weights of 1 and 3.
+func checkDigit13(twelve string) byte {
+	sum := 0
+	for i := 0; i < 12; i++ {
+		weight := 1
+		if i%2 == 1 {
+			weight = 3
+		}
+		sum += int(twelve[i]-'0') * weight
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
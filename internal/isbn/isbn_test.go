@@ -0,0 +1,78 @@
+package isbn
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	t.Run("ISBN-10 converts to ISBN-13", func(t *testing.T) {
+		parsed, err := Parse("0-13-235088-2")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "9780132350884", parsed.ISBN13)
+		assert.Equal(t, "0132350882", parsed.ISBN10)
+	})
+
+	t.Run("ISBN-10 with X check digit", func(t *testing.T) {
+		parsed, err := Parse("054792822x")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "9780547928227", parsed.ISBN13)
+		assert.Equal(t, "054792822X", parsed.ISBN10)
+	})
+
+	t.Run("978 ISBN-13 derives ISBN-10", func(t *testing.T) {
+		parsed, err := Parse("978 0 451 52493 5")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "9780451524935", parsed.ISBN13)
+		assert.Equal(t, "0451524934", parsed.ISBN10)
+	})
+
+	t.Run("979 ISBN-13 has no ISBN-10", func(t *testing.T) {
+		parsed, err := Parse("9791234567896")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "", parsed.ISBN10)
+	})
+
+	t.Run("rejections name the reason", func(t *testing.T) {
+		cases := map[string]string{
+			"123456":        "must have 10 or 13 digits",
+			"9781234567890": "ISBN-13 check digit should be 7, got 0",
+			"1234567890":    "ISBN-10 check digit should be X, got 0",
+			"12345A7890":    "unexpected character 'A' at position 6",
+			"978123456789X": "X is only allowed as an ISBN-10 check digit",
+			"9771234567898": "prefix must be 978 or 979",
+			"123456789Z":    "check digit must be 0-9 or X",
+		}
+		for input, reason := range cases {
+			_, err := Parse(input)
+			if assert.Error(t, err, input) {
+				assert.Contains(t, err.Error(), reason, input)
+			}
+		}
+	})
+}
+
+func TestCanonical(t *testing.T) {
+	t.Run("canonical form is ISBN-13", func(t *testing.T) {
+		canonical, err := Canonical("0123456789")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "9780123456786", canonical)
+	})
+
+	t.Run("invalid input", func(t *testing.T) {
+		_, err := Canonical("")
+		assert.Error(t, err)
+	})
+}
+
+func TestTo10(t *testing.T) {
+	assert.Equal(t, "0123456789", To10("9780123456786"))
+	assert.Equal(t, "", To10("9791234567896"))
+	assert.Equal(t, "", To10("not an isbn"))
+}
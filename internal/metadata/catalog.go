@@ -0,0 +1,130 @@
+package metadata
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"libmngmt/internal/isbn"
+	"libmngmt/internal/models"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// CatalogProvider serves metadata from a catalog dump loaded into memory
+type CatalogProvider struct {
+	records map[string]models.BookMetadata
+}
+
+// NewCatalogProvider loads a catalog dump. Files ending in .csv must have a
+// header row naming the columns; anything else is read as a JSON array.
+func NewCatalogProvider(path string) (*CatalogProvider, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open catalog: %w", err)
+	}
+	defer file.Close()
+
+	var records []record
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		records, err = readCSV(file)
+	} else {
+		err = json.NewDecoder(file).Decode(&records)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read catalog %s: %w", path, err)
+	}
+
+	provider := &CatalogProvider{records: make(map[string]models.BookMetadata, len(records))}
+	for i, rec := range records {
+		canonical, err := isbn.Canonical(rec.ISBN)
+		if err != nil {
+			return nil, fmt.Errorf("catalog entry %d: invalid ISBN %q: %w", i+1, rec.ISBN, err)
+		}
+
+		meta, err := rec.toMetadata()
+		if err != nil {
+			return nil, fmt.Errorf("catalog entry %d: %w", i+1, err)
+		}
+		meta.ISBN = canonical
+		provider.records[canonical] = *meta
+	}
+
+	return provider, nil
+}
+
+// Lookup returns the catalog entry for an ISBN in either form
+func (p *CatalogProvider) Lookup(ctx context.Context, value string) (*models.BookMetadata, error) {
+	canonical, err := isbn.Canonical(value)
+	if err != nil {
+		return nil, ErrNotFound
+	}
+
+	meta, ok := p.records[canonical]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &meta, nil
+}
+
+// Len returns the number of entries in the catalog
+func (p *CatalogProvider) Len() int {
+	return len(p.records)
+}
+
+func readCSV(r io.Reader) ([]record, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["isbn"]; !ok {
+		return nil, fmt.Errorf("header has no isbn column")
+	}
+
+	field := func(row []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(row) {
+			return row[i]
+		}
+		return ""
+	}
+
+	var records []record
+	for line := 2; ; line++ {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		rec := record{
+			ISBN:        field(row, "isbn"),
+			Title:       field(row, "title"),
+			Author:      field(row, "author"),
+			Publisher:   field(row, "publisher"),
+			Genre:       field(row, "genre"),
+			PublishedAt: field(row, "published_at"),
+			Language:    field(row, "language"),
+		}
+		if pages := strings.TrimSpace(field(row, "pages")); pages != "" {
+			if rec.Pages, err = strconv.Atoi(pages); err != nil {
+				return nil, fmt.Errorf("line %d: invalid pages %q", line, pages)
+			}
+		}
+		records = append(records, rec)
+	}
+
+	return records, nil
+}
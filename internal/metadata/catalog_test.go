@@ -0,0 +1,82 @@
+package metadata
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeCatalog(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write catalog: %v", err)
+	}
+	return path
+}
+
+func TestNewCatalogProvider(t *testing.T) {
+	t.Run("load JSON catalog", func(t *testing.T) {
+		path := writeCatalog(t, "catalog.json", `[
+			{"isbn": "0-261-10235-4", "title": "The Fellowship of the Ring", "author": "J.R.R. Tolkien", "published_at": "1954-07-29", "pages": 423}
+		]`)
+
+		provider, err := NewCatalogProvider(path)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, provider.Len())
+
+		meta, err := provider.Lookup(context.Background(), "9780261102354")
+		assert.NoError(t, err)
+		assert.Equal(t, "9780261102354", meta.ISBN)
+		assert.Equal(t, "The Fellowship of the Ring", meta.Title)
+		assert.Equal(t, 1954, meta.PublishedAt.Year())
+		assert.Equal(t, 423, meta.Pages)
+	})
+
+	t.Run("load CSV catalog", func(t *testing.T) {
+		path := writeCatalog(t, "catalog.csv", "ISBN,Title,Author,Published_At,Pages\n"+
+			"9780261102354,The Fellowship of the Ring,J.R.R. Tolkien,1954,423\n")
+
+		provider, err := NewCatalogProvider(path)
+
+		assert.NoError(t, err)
+
+		meta, err := provider.Lookup(context.Background(), "0261102354")
+		assert.NoError(t, err)
+		assert.Equal(t, "J.R.R. Tolkien", meta.Author)
+		assert.Equal(t, 423, meta.Pages)
+	})
+
+	t.Run("unknown ISBN", func(t *testing.T) {
+		path := writeCatalog(t, "catalog.json", `[]`)
+		provider, err := NewCatalogProvider(path)
+		assert.NoError(t, err)
+
+		_, err = provider.Lookup(context.Background(), "9780261102354")
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("invalid entries are rejected", func(t *testing.T) {
+		path := writeCatalog(t, "catalog.json", `[{"isbn": "9780261102355", "title": "Bad"}]`)
+		_, err := NewCatalogProvider(path)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "catalog entry 1")
+
+		path = writeCatalog(t, "catalog.csv", "isbn,pages\n9780261102354,many\n")
+		_, err = NewCatalogProvider(path)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid pages")
+
+		path = writeCatalog(t, "catalog.csv", "title,author\nA,B\n")
+		_, err = NewCatalogProvider(path)
+		assert.Error(t, err)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := NewCatalogProvider(filepath.Join(t.TempDir(), "missing.json"))
+		assert.Error(t, err)
+	})
+}
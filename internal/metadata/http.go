@@ -0,0 +1,77 @@
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"libmngmt/internal/isbn"
+	"libmngmt/internal/models"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// HTTPProvider looks metadata up from a remote service. The service must
+// answer GET requests with a JSON record, or 404 when the ISBN is unknown.
+type HTTPProvider struct {
+	urlTemplate string
+	client      *http.Client
+}
+
+// NewHTTPProvider creates a provider for the given endpoint. An "{isbn}"
+// placeholder in urlTemplate is replaced with the ISBN-13; without one the
+// ISBN is appended as the final path segment.
+func NewHTTPProvider(urlTemplate string, timeout time.Duration) *HTTPProvider {
+	return &HTTPProvider{
+		urlTemplate: urlTemplate,
+		client:      &http.Client{Timeout: timeout},
+	}
+}
+
+// Lookup fetches the record for an ISBN in either form
+func (p *HTTPProvider) Lookup(ctx context.Context, value string) (*models.BookMetadata, error) {
+	canonical, err := isbn.Canonical(value)
+	if err != nil {
+		return nil, ErrNotFound
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.lookupURL(canonical), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build metadata request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("metadata request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, ErrNotFound
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("metadata service returned status %d", resp.StatusCode)
+	}
+
+	var rec record
+	if err := json.NewDecoder(resp.Body).Decode(&rec); err != nil {
+		return nil, fmt.Errorf("failed to decode metadata: %w", err)
+	}
+
+	meta, err := rec.toMetadata()
+	if err != nil {
+		return nil, err
+	}
+	meta.ISBN = canonical
+	return meta, nil
+}
+
+func (p *HTTPProvider) lookupURL(canonical string) string {
+	escaped := url.PathEscape(canonical)
+	if strings.Contains(p.urlTemplate, "{isbn}") {
+		return strings.ReplaceAll(p.urlTemplate, "{isbn}", escaped)
+	}
+	return strings.TrimRight(p.urlTemplate, "/") + "/" + escaped
+}
@@ -0,0 +1,64 @@
+package metadata
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPProvider_Lookup(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/isbn/9780261102354":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"title": "The Fellowship of the Ring", "author": "J.R.R. Tolkien", "published_at": "1954"}`))
+		case "/isbn/9781234567897":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	t.Run("lookup with placeholder", func(t *testing.T) {
+		provider := NewHTTPProvider(server.URL+"/isbn/{isbn}", time.Second)
+
+		meta, err := provider.Lookup(context.Background(), "0-261-10235-4")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "9780261102354", meta.ISBN)
+		assert.Equal(t, "J.R.R. Tolkien", meta.Author)
+		assert.Equal(t, 1954, meta.PublishedAt.Year())
+	})
+
+	t.Run("lookup appends ISBN without placeholder", func(t *testing.T) {
+		provider := NewHTTPProvider(server.URL+"/isbn/", time.Second)
+
+		meta, err := provider.Lookup(context.Background(), "9780261102354")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "The Fellowship of the Ring", meta.Title)
+	})
+
+	t.Run("unknown ISBN", func(t *testing.T) {
+		provider := NewHTTPProvider(server.URL+"/isbn/{isbn}", time.Second)
+
+		_, err := provider.Lookup(context.Background(), "9780987654328")
+
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("server error", func(t *testing.T) {
+		provider := NewHTTPProvider(server.URL+"/isbn/{isbn}", time.Second)
+
+		_, err := provider.Lookup(context.Background(), "9781234567897")
+
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, ErrNotFound)
+		assert.Contains(t, err.Error(), "status 500")
+	})
+}
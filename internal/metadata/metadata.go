@@ -0,0 +1,60 @@
+// Package metadata looks up bibliographic data for an ISBN, either from a
+// local catalog dump or from a remote HTTP service.
+package metadata
+
+import (
+	"errors"
+	"fmt"
+	"libmngmt/internal/models"
+	"strings"
+	"time"
+)
+
+// ErrNotFound is returned when a provider has no record for an ISBN
+var ErrNotFound = errors.New("metadata not found")
+
+// record is the wire format shared by catalog files and the HTTP service.
+// Dates are accepted as YYYY-MM-DD, YYYY or RFC 3339.
+type record struct {
+	ISBN        string `json:"isbn"`
+	Title       string `json:"title"`
+	Author      string `json:"author"`
+	Publisher   string `json:"publisher"`
+	Genre       string `json:"genre"`
+	PublishedAt string `json:"published_at"`
+	Pages       int    `json:"pages"`
+	Language    string `json:"language"`
+}
+
+var dateLayouts = []string{"2006-01-02", time.RFC3339, "2006"}
+
+func (r record) toMetadata() (*models.BookMetadata, error) {
+	meta := &models.BookMetadata{
+		ISBN:      strings.TrimSpace(r.ISBN),
+		Title:     strings.TrimSpace(r.Title),
+		Author:    strings.TrimSpace(r.Author),
+		Publisher: strings.TrimSpace(r.Publisher),
+		Genre:     strings.TrimSpace(r.Genre),
+		Pages:     r.Pages,
+		Language:  strings.TrimSpace(r.Language),
+	}
+
+	if published := strings.TrimSpace(r.PublishedAt); published != "" {
+		parsed, err := parseDate(published)
+		if err != nil {
+			return nil, err
+		}
+		meta.PublishedAt = parsed
+	}
+
+	return meta, nil
+}
+
+func parseDate(value string) (time.Time, error) {
+	for _, layout := range dateLayouts {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return parsed, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognised published_at %q", value)
+}
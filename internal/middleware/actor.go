@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+const ActorKey contextKey = "actor"
+
+// AnonymousActor is recorded for requests that do not say who made them
+const AnonymousActor = "anonymous"
+
+// maxActorLength matches the width of the audit trail's actor column
+const maxActorLength = 255
+
+// ActorMiddleware records who is making the request, as named by the X-Actor
+// header, for the audit trail
+func ActorMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		actor := strings.TrimSpace(r.Header.Get("X-Actor"))
+		if len(actor) > maxActorLength {
+			actor = actor[:maxActorLength]
+		}
+
+		if actor != "" {
+			ctx := context.WithValue(r.Context(), ActorKey, actor)
+			r = r.WithContext(ctx)
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// GetActor extracts the actor from context, or AnonymousActor if none was given
+func GetActor(ctx context.Context) string {
+	if actor, ok := ctx.Value(ActorKey).(string); ok {
+		return actor
+	}
+	return AnonymousActor
+}
@@ -0,0 +1,166 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"libmngmt/internal/errors"
+	"libmngmt/internal/models"
+	"log"
+	"net/http"
+	"time"
+)
+
+// IdempotencyKeyHeader names the header a client sets to make a POST safe to
+// retry
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// IdempotentReplayedHeader marks a response replayed from an earlier request
+const IdempotentReplayedHeader = "Idempotent-Replayed"
+
+// maxIdempotencyKeyLength matches the width of the idempotency key column
+const maxIdempotencyKeyLength = 255
+
+// IdempotencyStore keeps idempotency keys and the responses replayed for them
+type IdempotencyStore interface {
+	Claim(key, fingerprint string, ttl time.Duration) (*models.IdempotencyRecord, bool, error)
+	Complete(key string, response *models.IdempotentResponse) error
+	Release(key string) error
+}
+
+// IdempotencyMiddleware makes POST requests carrying an Idempotency-Key
+// header safe to retry. The first request with a key runs and its response
+// is stored for ttl; retries of the same request get that response back
+// without running again, while reusing the key for a different request is
+// rejected. Server errors and timeouts are not stored, so a retry after one
+// runs again.
+func IdempotencyMiddleware(store IdempotencyStore, ttl time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(IdempotencyKeyHeader)
+			if r.Method != http.MethodPost || key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			requestID := GetRequestID(r.Context())
+			if len(key) > maxIdempotencyKeyLength {
+				errors.WriteErrorResponse(w, errors.Validation("Invalid idempotency key",
+					"Idempotency-Key must be at most 255 characters"), requestID)
+				return
+			}
+
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				errors.WriteErrorResponse(w, errors.Validation("Invalid request body", err.Error()), requestID)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			fingerprint := requestFingerprint(r, body)
+			record, claimed, err := store.Claim(key, fingerprint, ttl)
+			if err != nil {
+				errors.WriteErrorResponse(w, err, requestID)
+				return
+			}
+			if !claimed {
+				replayIdempotent(w, record, fingerprint, requestID)
+				return
+			}
+
+			recorder := &recordingWriter{ResponseWriter: w}
+			completed := false
+			// Give the key up if the request fails or panics, so it can be retried
+			defer func() {
+				if completed {
+					return
+				}
+				if err := store.Release(key); err != nil {
+					log.Printf("Failed to release idempotency key %q: %v", key, err)
+				}
+			}()
+
+			next.ServeHTTP(recorder, r)
+
+			if !replayable(recorder.statusCode) {
+				return
+			}
+			header := w.Header().Clone()
+			header.Del("X-Request-ID")
+			err = store.Complete(key, &models.IdempotentResponse{
+				StatusCode: recorder.statusCode,
+				Header:     header,
+				Body:       recorder.body.Bytes(),
+			})
+			if err != nil {
+				log.Printf("Failed to store response for idempotency key %q: %v", key, err)
+				return
+			}
+			completed = true
+		})
+	}
+}
+
+// replayIdempotent answers a request whose key is already held: with the
+// stored response for a retry of the same request, or with an error
+func replayIdempotent(w http.ResponseWriter, record *models.IdempotencyRecord, fingerprint, requestID string) {
+	if record.Fingerprint != fingerprint {
+		errors.WriteErrorResponse(w, errors.New(errors.CodeIdempotencyKeyReused, "Idempotency key reused",
+			"Idempotency-Key was already used for a different request"), requestID)
+		return
+	}
+	if record.Response == nil {
+		errors.WriteErrorResponse(w, errors.Conflict("Request in progress",
+			"A request with this Idempotency-Key is still being processed; retry later"), requestID)
+		return
+	}
+
+	for name, values := range record.Response.Header {
+		w.Header()[name] = values
+	}
+	w.Header().Set(IdempotentReplayedHeader, "true")
+	w.WriteHeader(record.Response.StatusCode)
+	if _, err := w.Write(record.Response.Body); err != nil {
+		log.Printf("Failed to replay idempotent response: %v", err)
+	}
+}
+
+// replayable reports whether a response settles its request for good. Server
+// errors do not, and neither do timeouts: a handler that times out may still
+// finish its work in the background, which a retry must be free to find out.
+func replayable(statusCode int) bool {
+	return statusCode != 0 &&
+		statusCode != http.StatusRequestTimeout &&
+		statusCode < http.StatusInternalServerError
+}
+
+// requestFingerprint identifies a request by its method, target and body
+func requestFingerprint(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(r.Method + " " + r.URL.RequestURI() + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// recordingWriter passes a response through while keeping a copy of it
+type recordingWriter struct {
+	http.ResponseWriter
+	statusCode int
+	body       bytes.Buffer
+}
+
+func (rw *recordingWriter) WriteHeader(code int) {
+	if rw.statusCode == 0 {
+		rw.statusCode = code
+	}
+	rw.ResponseWriter.WriteHeader(code)
+}
+
+func (rw *recordingWriter) Write(b []byte) (int, error) {
+	if rw.statusCode == 0 {
+		rw.statusCode = http.StatusOK
+	}
+	rw.body.Write(b)
+	return rw.ResponseWriter.Write(b)
+}
@@ -3,10 +3,12 @@ package middleware
 import (
 	"context"
 	"encoding/json"
+	"libmngmt/internal/models"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -112,8 +114,8 @@ func TestCORSMiddleware(t *testing.T) {
 
 		// Check CORS headers
 		assert.Equal(t, "*", recorder.Header().Get("Access-Control-Allow-Origin"))
-		assert.Equal(t, "GET, POST, PUT, DELETE, OPTIONS", recorder.Header().Get("Access-Control-Allow-Methods"))
-		assert.Equal(t, "Content-Type, Authorization", recorder.Header().Get("Access-Control-Allow-Headers"))
+		assert.Equal(t, "GET, POST, PUT, PATCH, DELETE, OPTIONS", recorder.Header().Get("Access-Control-Allow-Methods"))
+		assert.Equal(t, "Content-Type, Authorization, If-Match, If-None-Match, If-Modified-Since, X-Actor, Idempotency-Key", recorder.Header().Get("Access-Control-Allow-Headers"))
 
 		assert.Equal(t, http.StatusOK, recorder.Code)
 		assert.Equal(t, "test response", recorder.Body.String())
@@ -134,8 +136,8 @@ func TestCORSMiddleware(t *testing.T) {
 
 		// Check CORS headers
 		assert.Equal(t, "*", recorder.Header().Get("Access-Control-Allow-Origin"))
-		assert.Equal(t, "GET, POST, PUT, DELETE, OPTIONS", recorder.Header().Get("Access-Control-Allow-Methods"))
-		assert.Equal(t, "Content-Type, Authorization", recorder.Header().Get("Access-Control-Allow-Headers"))
+		assert.Equal(t, "GET, POST, PUT, PATCH, DELETE, OPTIONS", recorder.Header().Get("Access-Control-Allow-Methods"))
+		assert.Equal(t, "Content-Type, Authorization, If-Match, If-None-Match, If-Modified-Since, X-Actor, Idempotency-Key", recorder.Header().Get("Access-Control-Allow-Headers"))
 
 		// OPTIONS should return 200 OK
 		assert.Equal(t, http.StatusOK, recorder.Code)
@@ -156,8 +158,8 @@ func TestCORSMiddleware(t *testing.T) {
 
 		// Check CORS headers are still present
 		assert.Equal(t, "*", recorder.Header().Get("Access-Control-Allow-Origin"))
-		assert.Equal(t, "GET, POST, PUT, DELETE, OPTIONS", recorder.Header().Get("Access-Control-Allow-Methods"))
-		assert.Equal(t, "Content-Type, Authorization", recorder.Header().Get("Access-Control-Allow-Headers"))
+		assert.Equal(t, "GET, POST, PUT, PATCH, DELETE, OPTIONS", recorder.Header().Get("Access-Control-Allow-Methods"))
+		assert.Equal(t, "Content-Type, Authorization, If-Match, If-None-Match, If-Modified-Since, X-Actor, Idempotency-Key", recorder.Header().Get("Access-Control-Allow-Headers"))
 
 		assert.Equal(t, http.StatusInternalServerError, recorder.Code)
 		assert.Equal(t, "error", recorder.Body.String())
@@ -248,8 +250,8 @@ func TestMiddlewareChaining(t *testing.T) {
 
 		// Check that all middleware effects are present
 		assert.Equal(t, "*", recorder.Header().Get("Access-Control-Allow-Origin"))
-		assert.Equal(t, "GET, POST, PUT, DELETE, OPTIONS", recorder.Header().Get("Access-Control-Allow-Methods"))
-		assert.Equal(t, "Content-Type, Authorization", recorder.Header().Get("Access-Control-Allow-Headers"))
+		assert.Equal(t, "GET, POST, PUT, PATCH, DELETE, OPTIONS", recorder.Header().Get("Access-Control-Allow-Methods"))
+		assert.Equal(t, "Content-Type, Authorization, If-Match, If-None-Match, If-Modified-Since, X-Actor, Idempotency-Key", recorder.Header().Get("Access-Control-Allow-Headers"))
 		assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
 		assert.Equal(t, http.StatusOK, recorder.Code)
 
@@ -473,3 +475,170 @@ func TestGetRequestID(t *testing.T) {
 		}
 	})
 }
+
+func TestActorMiddleware(t *testing.T) {
+	var actor string
+	handler := ActorMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		actor = GetActor(r.Context())
+	}))
+
+	t.Run("actor is taken from the header", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/api/books", nil)
+		req.Header.Set("X-Actor", " librarian-7 ")
+
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+
+		assert.Equal(t, "librarian-7", actor)
+	})
+
+	t.Run("requests without an actor are anonymous", func(t *testing.T) {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/api/books", nil))
+
+		assert.Equal(t, AnonymousActor, actor)
+	})
+
+	t.Run("long actors are truncated", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/api/books", nil)
+		req.Header.Set("X-Actor", strings.Repeat("a", 300))
+
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+
+		assert.Len(t, actor, 255)
+	})
+}
+
+// memoryIdempotencyStore keeps idempotency keys in memory for tests
+type memoryIdempotencyStore struct {
+	records map[string]*models.IdempotencyRecord
+}
+
+func newMemoryIdempotencyStore() *memoryIdempotencyStore {
+	return &memoryIdempotencyStore{records: make(map[string]*models.IdempotencyRecord)}
+}
+
+func (s *memoryIdempotencyStore) Claim(key, fingerprint string, ttl time.Duration) (*models.IdempotencyRecord, bool, error) {
+	if record, ok := s.records[key]; ok {
+		return record, false, nil
+	}
+	record := &models.IdempotencyRecord{Key: key, Fingerprint: fingerprint}
+	s.records[key] = record
+	return record, true, nil
+}
+
+func (s *memoryIdempotencyStore) Complete(key string, response *models.IdempotentResponse) error {
+	s.records[key].Response = response
+	return nil
+}
+
+func (s *memoryIdempotencyStore) Release(key string) error {
+	delete(s.records, key)
+	return nil
+}
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	newHandler := func(store IdempotencyStore, status int) (http.Handler, *int) {
+		calls := 0
+		handler := IdempotencyMiddleware(store, time.Hour)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			var body map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&body)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(status)
+			json.NewEncoder(w).Encode(map[string]interface{}{"call": calls, "title": body["title"]})
+		}))
+		return handler, &calls
+	}
+	newRequest := func(key, body string) *http.Request {
+		req := httptest.NewRequest("POST", "/api/books", strings.NewReader(body))
+		if key != "" {
+			req.Header.Set(IdempotencyKeyHeader, key)
+		}
+		return req
+	}
+
+	t.Run("a retry replays the first response", func(t *testing.T) {
+		handler, calls := newHandler(newMemoryIdempotencyStore(), http.StatusCreated)
+
+		first := httptest.NewRecorder()
+		handler.ServeHTTP(first, newRequest("import-42", `{"title": "Dune"}`))
+		retry := httptest.NewRecorder()
+		handler.ServeHTTP(retry, newRequest("import-42", `{"title": "Dune"}`))
+
+		assert.Equal(t, 1, *calls)
+		assert.Equal(t, http.StatusCreated, retry.Code)
+		assert.Equal(t, first.Body.String(), retry.Body.String())
+		assert.Equal(t, "application/json", retry.Header().Get("Content-Type"))
+		assert.Equal(t, "true", retry.Header().Get(IdempotentReplayedHeader))
+		assert.Empty(t, first.Header().Get(IdempotentReplayedHeader))
+	})
+
+	t.Run("reusing a key for a different body is rejected", func(t *testing.T) {
+		handler, calls := newHandler(newMemoryIdempotencyStore(), http.StatusCreated)
+
+		handler.ServeHTTP(httptest.NewRecorder(), newRequest("import-42", `{"title": "Dune"}`))
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, newRequest("import-42", `{"title": "Emma"}`))
+
+		assert.Equal(t, 1, *calls)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Contains(t, w.Body.String(), "IDEMPOTENCY_KEY_REUSED")
+	})
+
+	t.Run("a retry while the first request is in flight conflicts", func(t *testing.T) {
+		store := newMemoryIdempotencyStore()
+		handler, calls := newHandler(store, http.StatusCreated)
+		req := newRequest("import-42", `{"title": "Dune"}`)
+		store.Claim("import-42", requestFingerprint(req, []byte(`{"title": "Dune"}`)), time.Hour)
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		assert.Equal(t, 0, *calls)
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("server errors are not stored", func(t *testing.T) {
+		store := newMemoryIdempotencyStore()
+		handler, calls := newHandler(store, http.StatusServiceUnavailable)
+
+		handler.ServeHTTP(httptest.NewRecorder(), newRequest("import-42", `{"title": "Dune"}`))
+		handler.ServeHTTP(httptest.NewRecorder(), newRequest("import-42", `{"title": "Dune"}`))
+
+		assert.Equal(t, 2, *calls)
+		assert.Empty(t, store.records)
+	})
+
+	t.Run("a retry after a timeout runs the request again", func(t *testing.T) {
+		store := newMemoryIdempotencyStore()
+		handler, calls := newHandler(store, http.StatusRequestTimeout)
+
+		handler.ServeHTTP(httptest.NewRecorder(), newRequest("import-42", `{"title": "Dune"}`))
+		retry := httptest.NewRecorder()
+		handler.ServeHTTP(retry, newRequest("import-42", `{"title": "Dune"}`))
+
+		assert.Equal(t, 2, *calls)
+		assert.Empty(t, retry.Header().Get(IdempotentReplayedHeader))
+		assert.Empty(t, store.records)
+	})
+
+	t.Run("requests without a key are not tracked", func(t *testing.T) {
+		store := newMemoryIdempotencyStore()
+		handler, calls := newHandler(store, http.StatusCreated)
+
+		handler.ServeHTTP(httptest.NewRecorder(), newRequest("", `{"title": "Dune"}`))
+		handler.ServeHTTP(httptest.NewRecorder(), newRequest("", `{"title": "Dune"}`))
+
+		assert.Equal(t, 2, *calls)
+		assert.Empty(t, store.records)
+	})
+
+	t.Run("overlong keys are rejected", func(t *testing.T) {
+		handler, calls := newHandler(newMemoryIdempotencyStore(), http.StatusCreated)
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, newRequest(strings.Repeat("k", 256), `{"title": "Dune"}`))
+
+		assert.Equal(t, 0, *calls)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
@@ -0,0 +1,111 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditAction names the kind of write an audit entry records
+type AuditAction string
+
+// Audited book writes
+const (
+	AuditCreate  AuditAction = "create"
+	AuditUpdate  AuditAction = "update"
+	AuditDelete  AuditAction = "delete"
+	AuditRestore AuditAction = "restore"
+	AuditPurge   AuditAction = "purge"
+)
+
+// SystemActor is the actor recorded for changes made by background jobs
+const SystemActor = "system"
+
+// AuditInfo identifies who made a change and the request it was made in
+type AuditInfo struct {
+	Actor     string
+	RequestID string
+}
+
+// BookSnapshot is the catalog record of a book as it stood after a write.
+// Availability is left out: it follows circulation, which is not audited.
+type BookSnapshot struct {
+	ID          uuid.UUID `json:"id"`
+	Title       string    `json:"title"`
+	Author      string    `json:"author"`
+	ISBN        string    `json:"isbn"`
+	Publisher   string    `json:"publisher"`
+	Genre       string    `json:"genre"`
+	PublishedAt time.Time `json:"published_at"`
+	Pages       int       `json:"pages"`
+	Language    string    `json:"language"`
+	Version     int       `json:"version"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// NewBookSnapshot records the catalog fields of a book
+func NewBookSnapshot(book *Book) *BookSnapshot {
+	return &BookSnapshot{
+		ID:          book.ID,
+		Title:       book.Title,
+		Author:      book.Author,
+		ISBN:        book.ISBN,
+		Publisher:   book.Publisher,
+		Genre:       book.Genre,
+		PublishedAt: book.PublishedAt,
+		Pages:       book.Pages,
+		Language:    book.Language,
+		Version:     book.Version,
+		CreatedAt:   book.CreatedAt,
+		UpdatedAt:   book.UpdatedAt,
+	}
+}
+
+// FieldChange is a field's JSON value before and after a write; null on
+// either side means the record did not exist
+type FieldChange struct {
+	From json.RawMessage `json:"from"`
+	To   json.RawMessage `json:"to"`
+}
+
+// BookAuditEntry is one append-only record of a write to a book. Before is
+// nil for creates and restores, After for deletes and purges.
+type BookAuditEntry struct {
+	ID     int64       `json:"id" db:"id"`
+	BookID uuid.UUID   `json:"book_id" db:"book_id"`
+	Action AuditAction `json:"action" db:"action"`
+	// Revision numbers, from 1, the writes that leave a record behind; it is
+	// nil for deletes and purges
+	Revision  *int                   `json:"revision,omitempty" db:"revision"`
+	Actor     string                 `json:"actor" db:"actor"`
+	RequestID string                 `json:"request_id,omitempty" db:"request_id"`
+	Before    *BookSnapshot          `json:"before,omitempty" db:"before"`
+	After     *BookSnapshot          `json:"after,omitempty" db:"after"`
+	Changes   map[string]FieldChange `json:"changes" db:"changes"`
+	ChangedAt time.Time              `json:"changed_at" db:"changed_at"`
+}
+
+// BookRevert asks for a book to be put back to one of its revisions. Version,
+// when set, is the version the revert must apply to.
+type BookRevert struct {
+	Revision int
+	Version  *int
+}
+
+// BookHistoryResponse lists a book's audit trail, oldest first
+type BookHistoryResponse struct {
+	BookID  uuid.UUID        `json:"book_id"`
+	Entries []BookAuditEntry `json:"entries"`
+}
+
+// BookAsOfResponse is a book's catalog record at a point in time, with the
+// write that produced it
+type BookAsOfResponse struct {
+	AsOf      time.Time     `json:"as_of"`
+	Book      *BookSnapshot `json:"book"`
+	Action    AuditAction   `json:"action"`
+	ChangedBy string        `json:"changed_by"`
+	ChangedAt time.Time     `json:"changed_at"`
+}
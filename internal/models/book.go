@@ -1,6 +1,9 @@
 package models
 
 import (
+	"bytes"
+	"encoding/json"
+	"libmngmt/internal/isbn"
 	"time"
 
 	"github.com/google/uuid"
@@ -20,6 +23,115 @@ type Book struct {
 	Available   bool      `json:"available" db:"available"`
 	CreatedAt   time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+	// Version is incremented on every write to the book
+	Version int `json:"version" db:"version"`
+	// DeletedAt is set while the book is in the trash
+	DeletedAt *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+	// Copy counts are aggregated from the copies table when listing books
+	TotalCopies     int `json:"total_copies" db:"-"`
+	AvailableCopies int `json:"available_copies" db:"-"`
+	// SortKey holds the book's listing sort values, from which cursors are made
+	SortKey []string `json:"-" db:"-"`
+	// Copies are embedded when a projection includes them
+	Copies []Copy `json:"copies,omitempty" db:"-"`
+	// Fields restricts the JSON representation to the named fields; empty
+	// means every field
+	Fields []string `json:"-" db:"-"`
+}
+
+// MarshalJSON adds the ISBN-10 form, derived from the stored ISBN-13, to the
+// book's JSON representation and keeps only the projected fields, if any
+func (b Book) MarshalJSON() ([]byte, error) {
+	type book Book
+	full := struct {
+		book
+		ISBN10 string `json:"isbn10,omitempty"`
+		// Included copies are listed even when there are none
+		Copies *[]Copy `json:"copies,omitempty"`
+	}{book: book(b), ISBN10: isbn.To10(b.ISBN)}
+	if b.Copies != nil {
+		full.Copies = &b.Copies
+	}
+
+	data, err := json.Marshal(full)
+	if err != nil || len(b.Fields) == 0 {
+		return data, err
+	}
+
+	names := b.Fields
+	if b.Copies != nil {
+		names = append(names[:len(names):len(names)], BookIncludeCopies)
+	}
+	return selectJSONFields(data, names)
+}
+
+// selectJSONFields keeps the named members of a JSON object, in the order
+// given. Names the object lacks are skipped.
+func selectJSONFields(data []byte, names []string) ([]byte, error) {
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(data, &members); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	written := 0
+	for _, name := range names {
+		value, ok := members[name]
+		if !ok {
+			continue
+		}
+		if written > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(name)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+		written++
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// BookProjection shapes book responses. Fields names the book fields to
+// return, every field when empty, and Include names related resources to
+// embed in each book.
+type BookProjection struct {
+	Fields  []string `json:"fields,omitempty"`
+	Include []string `json:"include,omitempty"`
+}
+
+// BookFields lists the fields a projection may select, in response order
+var BookFields = []string{
+	"id", "title", "author", "isbn", "isbn10", "publisher", "genre", "published_at",
+	"pages", "language", "available", "created_at", "updated_at", "version", "total_copies",
+	"available_copies",
+}
+
+// BookIncludeCopies embeds each book's physical copies
+const BookIncludeCopies = "copies"
+
+// BookIncludes lists the related resources a projection may embed
+var BookIncludes = []string{BookIncludeCopies}
+
+// IsEmpty reports whether the projection returns whole books with nothing
+// embedded
+func (p BookProjection) IsEmpty() bool {
+	return len(p.Fields) == 0 && len(p.Include) == 0
+}
+
+// Includes reports whether the projection embeds the named resource
+func (p BookProjection) Includes(resource string) bool {
+	for _, include := range p.Include {
+		if include == resource {
+			return true
+		}
+	}
+	return false
 }
 
 // CreateBookRequest represents the request body for creating a book
@@ -44,7 +156,60 @@ type UpdateBookRequest struct {
 	PublishedAt *time.Time `json:"published_at,omitempty"`
 	Pages       *int       `json:"pages,omitempty" validate:"omitempty,min=1"`
 	Language    *string    `json:"language,omitempty" validate:"omitempty,max=50"`
-	Available   *bool      `json:"available,omitempty"`
+	// Available is rejected by the service; availability follows open loans
+	Available *bool `json:"available,omitempty"`
+	// Version, when set, is the version the client last read; the update is
+	// refused if the book has been written since
+	Version *int `json:"version,omitempty"`
+}
+
+// ReplaceBookRequest represents the request body for replacing a book. It is
+// the complete set of writable fields: those left out are cleared, or reset
+// to their defaults, rather than kept.
+type ReplaceBookRequest struct {
+	CreateBookRequest
+	// Available is rejected by the service; availability follows open loans
+	Available *bool `json:"available,omitempty"`
+	// Version, when set, is the version the client last read; the replacement
+	// is refused if the book has been written since
+	Version *int `json:"version,omitempty"`
+}
+
+// NewReplaceBookRequest returns the writable fields of a book at its current
+// version, the document a patch is applied to
+func NewReplaceBookRequest(book *Book) *ReplaceBookRequest {
+	version := book.Version
+	return &ReplaceBookRequest{
+		CreateBookRequest: CreateBookRequest{
+			Title:       book.Title,
+			Author:      book.Author,
+			ISBN:        book.ISBN,
+			Publisher:   book.Publisher,
+			Genre:       book.Genre,
+			PublishedAt: book.PublishedAt,
+			Pages:       book.Pages,
+			Language:    book.Language,
+		},
+		Version: &version,
+	}
+}
+
+// PatchFormat names a supported patch document format by its media type
+type PatchFormat string
+
+const (
+	// PatchMerge is a JSON Merge Patch (RFC 7396)
+	PatchMerge PatchFormat = "application/merge-patch+json"
+	// PatchJSON is a JSON Patch (RFC 6902) limited to test, replace and remove
+	PatchJSON PatchFormat = "application/json-patch+json"
+)
+
+// BookPatch is a partial update applied to a book's writable fields
+type BookPatch struct {
+	Format   PatchFormat
+	Document []byte
+	// Version, when set, pins the patch to the version the client read
+	Version *int
 }
 
 // BookFilter represents filters for listing books
@@ -53,8 +218,134 @@ type BookFilter struct {
 	Genre     string `json:"genre,omitempty"`
 	Language  string `json:"language,omitempty"`
 	Available *bool  `json:"available,omitempty"`
-	Limit     int    `json:"limit,omitempty"`
-	Offset    int    `json:"offset,omitempty"`
+	// Publisher matches by substring; ISBN matches the canonical ISBN-13 exactly
+	Publisher string `json:"publisher,omitempty"`
+	ISBN      string `json:"isbn,omitempty"`
+	// Genres and Languages match any of their values exactly, ignoring case
+	Genres    []string `json:"genres,omitempty"`
+	Languages []string `json:"languages,omitempty"`
+	// Exclusions leave out books matching any of their values. Authors and
+	// publishers are excluded by substring, genres and languages exactly.
+	ExcludeAuthors    []string `json:"exclude_authors,omitempty"`
+	ExcludePublishers []string `json:"exclude_publishers,omitempty"`
+	ExcludeGenres     []string `json:"exclude_genres,omitempty"`
+	ExcludeLanguages  []string `json:"exclude_languages,omitempty"`
+	// Inclusive ranges; nil bounds are open
+	PublishedFrom *time.Time `json:"published_from,omitempty"`
+	PublishedTo   *time.Time `json:"published_to,omitempty"`
+	MinPages      *int       `json:"min_pages,omitempty"`
+	MaxPages      *int       `json:"max_pages,omitempty"`
+	CreatedSince  *time.Time `json:"created_since,omitempty"`
+	CreatedUntil  *time.Time `json:"created_until,omitempty"`
+	UpdatedSince  *time.Time `json:"updated_since,omitempty"`
+	UpdatedUntil  *time.Time `json:"updated_until,omitempty"`
+	// Query is a catalog query such as "author:kernighan pages>300 -available",
+	// combined with the other filters
+	Query string `json:"q,omitempty"`
+	// Fuzzy matches author and genre by trigram similarity instead of substring
+	Fuzzy bool `json:"fuzzy,omitempty"`
+	// Similarity is the minimum trigram similarity (0-1] for fuzzy matches
+	Similarity float64 `json:"similarity,omitempty"`
+	// Facets requests aggregate counts for the matching books
+	Facets bool `json:"facets,omitempty"`
+	// Sort lists comma-separated fields, each prefixed with '-' to descend
+	Sort   string `json:"sort,omitempty"`
+	Limit  int    `json:"limit,omitempty"`
+	Offset int    `json:"offset,omitempty"`
+	// Cursor is an opaque next_cursor or prev_cursor; it replaces Offset
+	Cursor string `json:"cursor,omitempty"`
+	// IncludeTotal overrides whether the matching books are counted; offset
+	// pages count them by default, cursor pages do not
+	IncludeTotal *bool `json:"include_total,omitempty"`
+	// BookProjection shapes the listed books
+	BookProjection
+}
+
+// CountsTotal reports whether listing the filter counts the matching books
+func (f BookFilter) CountsTotal() bool {
+	if f.IncludeTotal != nil {
+		return *f.IncludeTotal
+	}
+	return f.Cursor == ""
+}
+
+// BookSearchFilter represents a full-text search over the catalog
+type BookSearchFilter struct {
+	Query    string `json:"q"`
+	Language string `json:"language,omitempty"`
+	Limit    int    `json:"limit,omitempty"`
+	Offset   int    `json:"offset,omitempty"`
+}
+
+// BookSearchResult is a book matching a search with its relevance rank and a
+// snippet in which matched terms are wrapped in <mark> tags
+type BookSearchResult struct {
+	Book     Book    `json:"book"`
+	Rank     float64 `json:"rank"`
+	Headline string  `json:"headline"`
+}
+
+// BookSearchResponse represents the response for a catalog search
+type BookSearchResponse struct {
+	Query   string             `json:"query"`
+	Results []BookSearchResult `json:"results"`
+	Total   int                `json:"total"`
+	Limit   int                `json:"limit"`
+	Offset  int                `json:"offset"`
+}
+
+// SuggestField is a book field offering search-as-you-type suggestions
+type SuggestField string
+
+const (
+	SuggestFieldTitle     SuggestField = "title"
+	SuggestFieldAuthor    SuggestField = "author"
+	SuggestFieldPublisher SuggestField = "publisher"
+)
+
+// SuggestFields lists every field that offers suggestions
+var SuggestFields = []SuggestField{SuggestFieldTitle, SuggestFieldAuthor, SuggestFieldPublisher}
+
+// IsValid reports whether suggestions are offered for the field
+func (f SuggestField) IsValid() bool {
+	switch f {
+	case SuggestFieldTitle, SuggestFieldAuthor, SuggestFieldPublisher:
+		return true
+	}
+	return false
+}
+
+// ValueOf returns the book's value for the field
+func (f SuggestField) ValueOf(book *Book) string {
+	switch f {
+	case SuggestFieldTitle:
+		return book.Title
+	case SuggestFieldAuthor:
+		return book.Author
+	case SuggestFieldPublisher:
+		return book.Publisher
+	}
+	return ""
+}
+
+// TrashFilter pages through the books in the trash
+type TrashFilter struct {
+	Limit  int `json:"limit,omitempty"`
+	Offset int `json:"offset,omitempty"`
+}
+
+// AutocompleteFilter represents a search-as-you-type lookup
+type AutocompleteFilter struct {
+	Field  SuggestField `json:"field"`
+	Prefix string       `json:"prefix"`
+	Limit  int          `json:"limit,omitempty"`
+}
+
+// AutocompleteResponse represents the suggestions for a typed prefix
+type AutocompleteResponse struct {
+	Field       SuggestField `json:"field"`
+	Prefix      string       `json:"prefix"`
+	Suggestions []string     `json:"suggestions"`
 }
 
 // ErrorResponse represents an error response
@@ -70,10 +361,65 @@ type SuccessResponse struct {
 	Data    interface{} `json:"data,omitempty"`
 }
 
-// BooksListResponse represents the response for listing books
+// BooksListResponse represents the response for listing books. Total is
+// omitted from cursor pages unless requested.
 type BooksListResponse struct {
 	Books  []Book `json:"books"`
-	Total  int    `json:"total"`
+	Total  *int   `json:"total,omitempty"`
 	Limit  int    `json:"limit"`
 	Offset int    `json:"offset"`
+	// Cursors for the pages either side of this one, when they exist
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+	// DidYouMean maps a filter that matched nothing to the closest stored value
+	DidYouMean map[string]string `json:"did_you_mean,omitempty"`
+	Facets     *BookFacets       `json:"facets,omitempty"`
+}
+
+// FacetCount is the number of matching books sharing a facet value
+type FacetCount struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// BookFacets holds per-value counts for the books matching a filter. Values
+// are ordered by descending count. Decades are the first year of the decade.
+type BookFacets struct {
+	Genre        []FacetCount `json:"genre"`
+	Language     []FacetCount `json:"language"`
+	Publisher    []FacetCount `json:"publisher"`
+	Author       []FacetCount `json:"author"`
+	Availability []FacetCount `json:"availability"`
+	Decade       []FacetCount `json:"decade"`
+}
+
+// NewBookFacets creates facets with every list initialised, so empty facets
+// marshal as [] rather than null
+func NewBookFacets() *BookFacets {
+	return &BookFacets{
+		Genre:        make([]FacetCount, 0),
+		Language:     make([]FacetCount, 0),
+		Publisher:    make([]FacetCount, 0),
+		Author:       make([]FacetCount, 0),
+		Availability: make([]FacetCount, 0),
+		Decade:       make([]FacetCount, 0),
+	}
+}
+
+// Add appends a count to the named facet; unknown facet names are ignored
+func (f *BookFacets) Add(facet string, count FacetCount) {
+	switch facet {
+	case "genre":
+		f.Genre = append(f.Genre, count)
+	case "language":
+		f.Language = append(f.Language, count)
+	case "publisher":
+		f.Publisher = append(f.Publisher, count)
+	case "author":
+		f.Author = append(f.Author, count)
+	case "availability":
+		f.Availability = append(f.Availability, count)
+	case "decade":
+		f.Decade = append(f.Decade, count)
+	}
 }
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/json"
 	"testing"
 	"time"
 
@@ -302,14 +303,14 @@ func TestBooksListResponse_Creation(t *testing.T) {
 
 		resp := BooksListResponse{
 			Books:  books,
-			Total:  2,
+			Total:  intPtr(2),
 			Limit:  10,
 			Offset: 0,
 		}
 
 		assert.Equal(t, books, resp.Books)
 		assert.Len(t, resp.Books, 2)
-		assert.Equal(t, 2, resp.Total)
+		assert.Equal(t, 2, *resp.Total)
 		assert.Equal(t, 10, resp.Limit)
 		assert.Equal(t, 0, resp.Offset)
 	})
@@ -317,13 +318,13 @@ func TestBooksListResponse_Creation(t *testing.T) {
 	t.Run("create empty books list response", func(t *testing.T) {
 		resp := BooksListResponse{
 			Books:  []Book{},
-			Total:  0,
+			Total:  intPtr(0),
 			Limit:  50,
 			Offset: 0,
 		}
 
 		assert.Empty(t, resp.Books)
-		assert.Equal(t, 0, resp.Total)
+		assert.Equal(t, 0, *resp.Total)
 		assert.Equal(t, 50, resp.Limit)
 		assert.Equal(t, 0, resp.Offset)
 	})
@@ -336,14 +337,101 @@ func TestBooksListResponse_Creation(t *testing.T) {
 
 		resp := BooksListResponse{
 			Books:  books,
-			Total:  100,
+			Total:  intPtr(100),
 			Limit:  10,
 			Offset: 20,
 		}
 
 		assert.Equal(t, books, resp.Books)
-		assert.Equal(t, 100, resp.Total)
+		assert.Equal(t, 100, *resp.Total)
 		assert.Equal(t, 10, resp.Limit)
 		assert.Equal(t, 20, resp.Offset)
 	})
 }
+
+func TestBook_MarshalJSON(t *testing.T) {
+	t.Run("ISBN-10 is derived from the stored ISBN-13", func(t *testing.T) {
+		data, err := json.Marshal(Book{Title: "The Hobbit", ISBN: "9780547928227"})
+		assert.NoError(t, err)
+
+		var decoded map[string]interface{}
+		assert.NoError(t, json.Unmarshal(data, &decoded))
+		assert.Equal(t, "9780547928227", decoded["isbn"])
+		assert.Equal(t, "054792822X", decoded["isbn10"])
+		assert.Equal(t, "The Hobbit", decoded["title"])
+	})
+
+	t.Run("979 ISBNs have no ISBN-10 form", func(t *testing.T) {
+		data, err := json.Marshal(Book{ISBN: "9791234567896"})
+		assert.NoError(t, err)
+		assert.NotContains(t, string(data), "isbn10")
+	})
+
+	t.Run("projected fields only, in the order given", func(t *testing.T) {
+		book := Book{Title: "The Hobbit", Author: "J.R.R. Tolkien", ISBN: "9780547928227", Fields: []string{"title", "isbn10"}}
+
+		data, err := json.Marshal(book)
+
+		assert.NoError(t, err)
+		assert.Equal(t, `{"title":"The Hobbit","isbn10":"054792822X"}`, string(data))
+	})
+
+	t.Run("included copies are listed even when there are none", func(t *testing.T) {
+		data, err := json.Marshal(Book{Title: "Dune", Copies: []Copy{}, Fields: []string{"title"}})
+		assert.NoError(t, err)
+		assert.Equal(t, `{"title":"Dune","copies":[]}`, string(data))
+
+		data, err = json.Marshal(Book{Title: "Dune"})
+		assert.NoError(t, err)
+		assert.NotContains(t, string(data), `"copies"`)
+	})
+}
+
+func TestBookProjection(t *testing.T) {
+	t.Run("empty projection and includes", func(t *testing.T) {
+		assert.True(t, BookProjection{}.IsEmpty())
+
+		projection := BookProjection{Include: []string{BookIncludeCopies}}
+		assert.False(t, projection.IsEmpty())
+		assert.True(t, projection.Includes(BookIncludeCopies))
+		assert.False(t, BookProjection{Fields: []string{"id"}}.Includes(BookIncludeCopies))
+	})
+}
+
+func TestBookFacets_Add(t *testing.T) {
+	t.Run("counts are appended to the named facet", func(t *testing.T) {
+		facets := NewBookFacets()
+
+		facets.Add("genre", FacetCount{Value: "Fantasy", Count: 3})
+		facets.Add("decade", FacetCount{Value: "1930", Count: 1})
+		facets.Add("unknown", FacetCount{Value: "ignored", Count: 9})
+
+		assert.Equal(t, []FacetCount{{Value: "Fantasy", Count: 3}}, facets.Genre)
+		assert.Equal(t, []FacetCount{{Value: "1930", Count: 1}}, facets.Decade)
+		assert.Empty(t, facets.Author)
+	})
+
+	t.Run("empty facets marshal as empty lists", func(t *testing.T) {
+		data, err := json.Marshal(NewBookFacets())
+		assert.NoError(t, err)
+		assert.Contains(t, string(data), `"publisher":[]`)
+		assert.NotContains(t, string(data), "null")
+	})
+}
+
+func TestSuggestField(t *testing.T) {
+	book := &Book{Title: "Dune", Author: "Frank Herbert", Publisher: "Chilton Books", Genre: "Science Fiction"}
+
+	for _, field := range SuggestFields {
+		assert.True(t, field.IsValid())
+		assert.NotEmpty(t, field.ValueOf(book))
+	}
+
+	assert.Equal(t, "Frank Herbert", SuggestFieldAuthor.ValueOf(book))
+	assert.False(t, SuggestField("genre").IsValid())
+	assert.Empty(t, SuggestField("genre").ValueOf(book))
+}
+
+func intPtr(value int) *int {
+	return &value
+}
@@ -0,0 +1,80 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CopyStatus represents the circulation state of a physical copy
+type CopyStatus string
+
+const (
+	CopyStatusAvailable  CopyStatus = "available"
+	CopyStatusCheckedOut CopyStatus = "checked_out"
+	CopyStatusInTransit  CopyStatus = "in_transit"
+	CopyStatusInRepair   CopyStatus = "in_repair"
+	CopyStatusLost       CopyStatus = "lost"
+	CopyStatusWithdrawn  CopyStatus = "withdrawn"
+)
+
+// IsValid reports whether the status is one of the known copy states
+func (s CopyStatus) IsValid() bool {
+	switch s {
+	case CopyStatusAvailable, CopyStatusCheckedOut, CopyStatusInTransit,
+		CopyStatusInRepair, CopyStatusLost, CopyStatusWithdrawn:
+		return true
+	}
+	return false
+}
+
+// CopyCondition describes the physical state of a copy
+type CopyCondition string
+
+const (
+	CopyConditionNew     CopyCondition = "new"
+	CopyConditionGood    CopyCondition = "good"
+	CopyConditionFair    CopyCondition = "fair"
+	CopyConditionPoor    CopyCondition = "poor"
+	CopyConditionDamaged CopyCondition = "damaged"
+)
+
+// IsValid reports whether the condition is one of the known grades
+func (c CopyCondition) IsValid() bool {
+	switch c {
+	case CopyConditionNew, CopyConditionGood, CopyConditionFair, CopyConditionPoor, CopyConditionDamaged:
+		return true
+	}
+	return false
+}
+
+// Copy represents a physical item of a book, identified by its barcode
+type Copy struct {
+	ID        uuid.UUID     `json:"id" db:"id"`
+	BookID    uuid.UUID     `json:"book_id" db:"book_id"`
+	Barcode   string        `json:"barcode" db:"barcode"`
+	Status    CopyStatus    `json:"status" db:"status"`
+	Location  string        `json:"location" db:"location"`
+	Condition CopyCondition `json:"condition" db:"condition"`
+	Notes     string        `json:"notes" db:"notes"`
+	CreatedAt time.Time     `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at" db:"updated_at"`
+}
+
+// CreateCopyRequest represents the request body for adding a copy to a book
+type CreateCopyRequest struct {
+	Barcode   string        `json:"barcode" validate:"required,max=32"`
+	Status    CopyStatus    `json:"status,omitempty"`
+	Location  string        `json:"location" validate:"max=100"`
+	Condition CopyCondition `json:"condition,omitempty"`
+	Notes     string        `json:"notes"`
+}
+
+// UpdateCopyRequest represents the request body for updating a copy
+type UpdateCopyRequest struct {
+	Barcode   *string        `json:"barcode,omitempty" validate:"omitempty,max=32"`
+	Status    *CopyStatus    `json:"status,omitempty"`
+	Location  *string        `json:"location,omitempty" validate:"omitempty,max=100"`
+	Condition *CopyCondition `json:"condition,omitempty"`
+	Notes     *string        `json:"notes,omitempty"`
+}
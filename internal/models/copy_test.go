@@ -0,0 +1,31 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCopyStatus_IsValid(t *testing.T) {
+	t.Run("known statuses are valid", func(t *testing.T) {
+		assert.True(t, CopyStatusAvailable.IsValid())
+		assert.True(t, CopyStatusCheckedOut.IsValid())
+		assert.True(t, CopyStatusWithdrawn.IsValid())
+	})
+
+	t.Run("unknown status is invalid", func(t *testing.T) {
+		assert.False(t, CopyStatus("borrowed").IsValid())
+		assert.False(t, CopyStatus("").IsValid())
+	})
+}
+
+func TestCopyCondition_IsValid(t *testing.T) {
+	t.Run("known conditions are valid", func(t *testing.T) {
+		assert.True(t, CopyConditionNew.IsValid())
+		assert.True(t, CopyConditionDamaged.IsValid())
+	})
+
+	t.Run("unknown condition is invalid", func(t *testing.T) {
+		assert.False(t, CopyCondition("mint").IsValid())
+	})
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// BookCursor marks a position in a book listing by the sort key values of
+// the book it was taken from, with the book's ID breaking ties. Sort records
+// the listing order the values belong to. Backward cursors page towards the
+// start of the listing.
+type BookCursor struct {
+	Values   []string  `json:"v"`
+	ID       uuid.UUID `json:"i"`
+	Sort     string    `json:"s,omitempty"`
+	Backward bool      `json:"b,omitempty"`
+}
+
+// NewBookCursor returns a cursor positioned at a listed book
+func NewBookCursor(book *Book, sort string, backward bool) *BookCursor {
+	return &BookCursor{Values: book.SortKey, ID: book.ID, Sort: sort, Backward: backward}
+}
+
+// Encode renders the cursor as an opaque URL-safe token
+func (c *BookCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeBookCursor parses a token produced by Encode
+func DecodeBookCursor(token string) (*BookCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor: %w", err)
+	}
+
+	var cursor BookCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, fmt.Errorf("invalid cursor: %w", err)
+	}
+	if cursor.ID == uuid.Nil || len(cursor.Values) == 0 {
+		return nil, fmt.Errorf("invalid cursor: missing position")
+	}
+
+	return &cursor, nil
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBookCursor(t *testing.T) {
+	t.Run("cursor survives a round trip", func(t *testing.T) {
+		book := &Book{ID: uuid.New(), SortKey: []string{"hobbit", "1937-09-21 00:00:00"}}
+
+		decoded, err := DecodeBookCursor(NewBookCursor(book, "title,-published_at", true).Encode())
+
+		assert.NoError(t, err)
+		assert.Equal(t, book.ID, decoded.ID)
+		assert.Equal(t, book.SortKey, decoded.Values)
+		assert.Equal(t, "title,-published_at", decoded.Sort)
+		assert.True(t, decoded.Backward)
+	})
+
+	t.Run("malformed cursors are rejected", func(t *testing.T) {
+		for _, token := range []string{"not base64!", "bm90IGpzb24", "e30"} {
+			_, err := DecodeBookCursor(token)
+			assert.Error(t, err, token)
+			assert.Contains(t, err.Error(), "invalid cursor")
+		}
+	})
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FineEntryType classifies a fine ledger entry
+type FineEntryType string
+
+const (
+	// FineEntryCharge increases the amount a borrower owes
+	FineEntryCharge FineEntryType = "charge"
+	// FineEntryPayment and FineEntryWaiver reduce it
+	FineEntryPayment FineEntryType = "payment"
+	FineEntryWaiver  FineEntryType = "waiver"
+)
+
+// FineEntry is a single line of a borrower's fine ledger. Amounts are always
+// positive; the entry type determines whether they add to or settle the balance.
+type FineEntry struct {
+	ID          uuid.UUID     `json:"id" db:"id"`
+	MemberID    uuid.UUID     `json:"member_id" db:"member_id"`
+	LoanID      *uuid.UUID    `json:"loan_id,omitempty" db:"loan_id"`
+	EntryType   FineEntryType `json:"entry_type" db:"entry_type"`
+	AmountCents int64         `json:"amount_cents" db:"amount_cents"`
+	Note        string        `json:"note" db:"note"`
+	CreatedAt   time.Time     `json:"created_at" db:"created_at"`
+}
+
+// FineLedger is a borrower's full fine history with running totals
+type FineLedger struct {
+	MemberID     uuid.UUID   `json:"member_id"`
+	Entries      []FineEntry `json:"entries"`
+	ChargedCents int64       `json:"charged_cents"`
+	PaidCents    int64       `json:"paid_cents"`
+	WaivedCents  int64       `json:"waived_cents"`
+	BalanceCents int64       `json:"balance_cents"`
+}
+
+// FineCreditRequest represents the request body for recording a payment or waiver
+type FineCreditRequest struct {
+	AmountCents int64      `json:"amount_cents"`
+	Note        string     `json:"note"`
+	LoanID      *uuid.UUID `json:"loan_id,omitempty"`
+}
+
+// OverdueLoan is a loan past its due date whose fines have not been finalised
+type OverdueLoan struct {
+	LoanID     uuid.UUID
+	BookTitle  string
+	MemberID   uuid.UUID
+	Genre      string
+	DueAt      time.Time
+	ReturnedAt *time.Time
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// HoldStatus represents the lifecycle state of a hold
+type HoldStatus string
+
+const (
+	// HoldStatusWaiting holds are queued behind the current loan
+	HoldStatusWaiting HoldStatus = "waiting"
+	// HoldStatusReady holds have the book set aside for pickup
+	HoldStatusReady     HoldStatus = "ready"
+	HoldStatusFulfilled HoldStatus = "fulfilled"
+	HoldStatusCancelled HoldStatus = "cancelled"
+	HoldStatusExpired   HoldStatus = "expired"
+)
+
+// IsActive reports whether the hold still occupies a place in the queue
+func (s HoldStatus) IsActive() bool {
+	return s == HoldStatusWaiting || s == HoldStatusReady
+}
+
+// Hold represents a member's reservation of a checked out book
+type Hold struct {
+	ID         uuid.UUID  `json:"id" db:"id"`
+	BookID     uuid.UUID  `json:"book_id" db:"book_id"`
+	MemberID   uuid.UUID  `json:"member_id" db:"member_id"`
+	Status     HoldStatus `json:"status" db:"status"`
+	Position   int        `json:"position,omitempty" db:"-"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	ReadyAt    *time.Time `json:"ready_at,omitempty" db:"ready_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty" db:"expires_at"`
+	NotifiedAt *time.Time `json:"notified_at,omitempty" db:"notified_at"`
+	// CopyID is the copy set aside while the hold is ready for pickup
+	CopyID *uuid.UUID `json:"copy_id,omitempty" db:"copy_id"`
+}
+
+// PlaceHoldRequest represents the request body for placing a hold
+type PlaceHoldRequest struct {
+	MemberID uuid.UUID `json:"member_id" validate:"required"`
+}
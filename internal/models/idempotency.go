@@ -0,0 +1,25 @@
+package models
+
+import (
+	"net/http"
+	"time"
+)
+
+// IdempotentResponse is the response to the first request made with an
+// idempotency key, replayed to retries of that request
+type IdempotentResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// IdempotencyRecord is the state of an idempotency key. Fingerprint
+// identifies the request the key was first used with; Response is nil while
+// that request is still in flight.
+type IdempotencyRecord struct {
+	Key         string
+	Fingerprint string
+	Response    *IdempotentResponse
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Loan records a single checkout of a book by a member. CopyID names the copy
+// lent and is nil for books without registered copies. BookID is cleared when
+// the book is purged; the title and ISBN lent are kept with the loan.
+type Loan struct {
+	ID           uuid.UUID  `json:"id" db:"id"`
+	BookID       *uuid.UUID `json:"book_id" db:"book_id"`
+	BookTitle    string     `json:"book_title" db:"book_title"`
+	BookISBN     string     `json:"book_isbn" db:"book_isbn"`
+	CopyID       *uuid.UUID `json:"copy_id,omitempty" db:"copy_id"`
+	MemberID     uuid.UUID  `json:"member_id" db:"member_id"`
+	CheckedOutAt time.Time  `json:"checked_out_at" db:"checked_out_at"`
+	DueAt        time.Time  `json:"due_at" db:"due_at"`
+	ReturnedAt   *time.Time `json:"returned_at,omitempty" db:"returned_at"`
+}
+
+// IsOpen reports whether the loan has not been returned yet
+func (l *Loan) IsOpen() bool {
+	return l.ReturnedAt == nil
+}
+
+// CheckoutRequest represents the request body for checking out a book
+type CheckoutRequest struct {
+	MemberID uuid.UUID  `json:"member_id" validate:"required"`
+	DueAt    *time.Time `json:"due_at,omitempty"`
+}
+
+// ReturnRequest represents the optional request body for returning a book.
+// The copy must be named when several copies of the book are checked out.
+type ReturnRequest struct {
+	CopyID *uuid.UUID `json:"copy_id,omitempty"`
+}
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MemberStatus represents the state of a patron's membership
+type MemberStatus string
+
+const (
+	MemberStatusActive    MemberStatus = "active"
+	MemberStatusSuspended MemberStatus = "suspended"
+	MemberStatusExpired   MemberStatus = "expired"
+)
+
+// IsValid reports whether the status is one of the known membership states
+func (s MemberStatus) IsValid() bool {
+	switch s {
+	case MemberStatusActive, MemberStatusSuspended, MemberStatusExpired:
+		return true
+	}
+	return false
+}
+
+// Member represents a library patron who can borrow books
+type Member struct {
+	ID         uuid.UUID    `json:"id" db:"id"`
+	CardNumber string       `json:"card_number" db:"card_number"`
+	FirstName  string       `json:"first_name" db:"first_name"`
+	LastName   string       `json:"last_name" db:"last_name"`
+	Email      string       `json:"email" db:"email"`
+	Phone      string       `json:"phone" db:"phone"`
+	Address    string       `json:"address" db:"address"`
+	Status     MemberStatus `json:"status" db:"status"`
+	ExpiresAt  time.Time    `json:"expires_at" db:"expires_at"`
+	CreatedAt  time.Time    `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time    `json:"updated_at" db:"updated_at"`
+}
+
+// CreateMemberRequest represents the request body for creating a member
+type CreateMemberRequest struct {
+	CardNumber string       `json:"card_number" validate:"required,min=1,max=32"`
+	FirstName  string       `json:"first_name" validate:"required,min=1,max=100"`
+	LastName   string       `json:"last_name" validate:"required,min=1,max=100"`
+	Email      string       `json:"email" validate:"required,email,max=255"`
+	Phone      string       `json:"phone" validate:"max=32"`
+	Address    string       `json:"address" validate:"max=255"`
+	Status     MemberStatus `json:"status,omitempty"`
+	ExpiresAt  time.Time    `json:"expires_at"`
+}
+
+// UpdateMemberRequest represents the request body for updating a member
+type UpdateMemberRequest struct {
+	CardNumber *string       `json:"card_number,omitempty" validate:"omitempty,min=1,max=32"`
+	FirstName  *string       `json:"first_name,omitempty" validate:"omitempty,min=1,max=100"`
+	LastName   *string       `json:"last_name,omitempty" validate:"omitempty,min=1,max=100"`
+	Email      *string       `json:"email,omitempty" validate:"omitempty,email,max=255"`
+	Phone      *string       `json:"phone,omitempty" validate:"omitempty,max=32"`
+	Address    *string       `json:"address,omitempty" validate:"omitempty,max=255"`
+	Status     *MemberStatus `json:"status,omitempty"`
+	ExpiresAt  *time.Time    `json:"expires_at,omitempty"`
+}
+
+// MemberFilter represents filters for listing members
+type MemberFilter struct {
+	Name   string       `json:"name,omitempty"`
+	Email  string       `json:"email,omitempty"`
+	Status MemberStatus `json:"status,omitempty"`
+	Limit  int          `json:"limit,omitempty"`
+	Offset int          `json:"offset,omitempty"`
+}
+
+// MembersListResponse represents the response for listing members
+type MembersListResponse struct {
+	Members []Member `json:"members"`
+	Total   int      `json:"total"`
+	Limit   int      `json:"limit"`
+	Offset  int      `json:"offset"`
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemberStatus_IsValid(t *testing.T) {
+	t.Run("known statuses are valid", func(t *testing.T) {
+		assert.True(t, MemberStatusActive.IsValid())
+		assert.True(t, MemberStatusSuspended.IsValid())
+		assert.True(t, MemberStatusExpired.IsValid())
+	})
+
+	t.Run("unknown status is invalid", func(t *testing.T) {
+		assert.False(t, MemberStatus("banned").IsValid())
+		assert.False(t, MemberStatus("").IsValid())
+	})
+}
+
+func TestMembersListResponse_Creation(t *testing.T) {
+	t.Run("create members list response", func(t *testing.T) {
+		response := MembersListResponse{
+			Members: []Member{{FirstName: "Ada", LastName: "Lovelace"}},
+			Total:   1,
+			Limit:   50,
+			Offset:  0,
+		}
+
+		assert.Len(t, response.Members, 1)
+		assert.Equal(t, "Ada", response.Members[0].FirstName)
+		assert.Equal(t, 1, response.Total)
+		assert.Equal(t, 50, response.Limit)
+	})
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// BookMetadata is bibliographic data about an ISBN from an external source
+type BookMetadata struct {
+	ISBN        string    `json:"isbn"`
+	Title       string    `json:"title,omitempty"`
+	Author      string    `json:"author,omitempty"`
+	Publisher   string    `json:"publisher,omitempty"`
+	Genre       string    `json:"genre,omitempty"`
+	PublishedAt time.Time `json:"published_at,omitempty"`
+	Pages       int       `json:"pages,omitempty"`
+	Language    string    `json:"language,omitempty"`
+}
+
+// EnrichBookResponse is a create request with missing fields filled from metadata
+type EnrichBookResponse struct {
+	Book CreateBookRequest `json:"book"`
+	// Filled lists the JSON names of the fields taken from the metadata
+	Filled []string `json:"filled"`
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SavedSearch is a named book filter whose owner is alerted when a newly
+// created book matches it
+type SavedSearch struct {
+	ID uuid.UUID `json:"id" db:"id"`
+	// Owner identifies whoever saved the search, e.g. a member ID or username
+	Owner string `json:"owner" db:"owner"`
+	Name  string `json:"name" db:"name"`
+	// Filter holds the matching criteria only; paging and sort fields are dropped
+	Filter BookFilter `json:"filter" db:"filter"`
+	// WebhookURL, when set, receives a POST for every alert. Alerts are kept
+	// in the owner's inbox either way.
+	WebhookURL string    `json:"webhook_url,omitempty" db:"webhook_url"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+// CreateSavedSearchRequest represents the request body for saving a search
+type CreateSavedSearchRequest struct {
+	Owner      string     `json:"owner" validate:"required,min=1,max=255"`
+	Name       string     `json:"name" validate:"required,min=1,max=100"`
+	Filter     BookFilter `json:"filter"`
+	WebhookURL string     `json:"webhook_url,omitempty" validate:"omitempty,url,max=2048"`
+}
+
+// SearchAlert tells a saved search's owner about a new book matching it. The
+// book's title and author are recorded as they were when the alert was raised.
+type SearchAlert struct {
+	ID            uuid.UUID  `json:"id" db:"id"`
+	SavedSearchID uuid.UUID  `json:"saved_search_id" db:"saved_search_id"`
+	Owner         string     `json:"owner" db:"owner"`
+	BookID        uuid.UUID  `json:"book_id" db:"book_id"`
+	BookTitle     string     `json:"book_title" db:"book_title"`
+	BookAuthor    string     `json:"book_author" db:"book_author"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	ReadAt        *time.Time `json:"read_at,omitempty" db:"read_at"`
+	// Webhook delivery outcome, for searches with a webhook
+	DeliveredAt   *time.Time `json:"delivered_at,omitempty" db:"delivered_at"`
+	DeliveryError string     `json:"delivery_error,omitempty" db:"delivery_error"`
+}
+
+// NewArrivalEvent is the event name of new-arrival webhooks
+const NewArrivalEvent = "book.new_arrival"
+
+// NewArrivalWebhook is the JSON body POSTed to a saved search's webhook
+type NewArrivalWebhook struct {
+	Event         string    `json:"event"`
+	AlertID       uuid.UUID `json:"alert_id"`
+	SavedSearchID uuid.UUID `json:"saved_search_id"`
+	SearchName    string    `json:"search_name"`
+	Owner         string    `json:"owner"`
+	Book          Book      `json:"book"`
+}
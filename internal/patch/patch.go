@@ -0,0 +1,287 @@
+// Package patch applies partial-update documents to JSON resources.
+//
+// Two formats are supported: JSON Merge Patch (RFC 7396), where the patch
+// mirrors the resource and null removes a member, and JSON Patch (RFC 6902),
+// a list of operations addressed by JSON Pointer (RFC 6901). Of the JSON
+// Patch operations only test, replace and remove are accepted.
+package patch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ErrTestFailed is returned when a JSON Patch test operation does not match
+// the document, in which case none of the patch is applied
+var ErrTestFailed = errors.New("patch test failed")
+
+// operation is a single JSON Patch operation
+type operation struct {
+	Op   string
+	Path string
+	// Value is nil when the operation has none, and "null" for a JSON null
+	Value json.RawMessage
+}
+
+// Merge applies a JSON Merge Patch to doc and returns the patched document
+func Merge(doc, patch []byte) ([]byte, error) {
+	var target, changes interface{}
+	if err := json.Unmarshal(doc, &target); err != nil {
+		return nil, fmt.Errorf("invalid document: %w", err)
+	}
+	if err := json.Unmarshal(patch, &changes); err != nil {
+		return nil, fmt.Errorf("invalid patch: %w", err)
+	}
+	return json.Marshal(mergeValue(target, changes))
+}
+
+// mergeValue is the MergePatch function of RFC 7396: objects are merged
+// member by member, anything else replaces the target outright
+func mergeValue(target, changes interface{}) interface{} {
+	patchObject, ok := changes.(map[string]interface{})
+	if !ok {
+		return changes
+	}
+
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = map[string]interface{}{}
+	}
+	for name, value := range patchObject {
+		if value == nil {
+			delete(targetObject, name)
+			continue
+		}
+		targetObject[name] = mergeValue(targetObject[name], value)
+	}
+	return targetObject
+}
+
+// Apply applies a JSON Patch to doc and returns the patched document. The
+// operations are applied in order and the patch is atomic: if any of them
+// fails, the error is returned and doc is left as it was.
+func Apply(doc, patch []byte) ([]byte, error) {
+	var target interface{}
+	if err := json.Unmarshal(doc, &target); err != nil {
+		return nil, fmt.Errorf("invalid document: %w", err)
+	}
+
+	ops, err := parseOperations(patch)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, op := range ops {
+		tokens, err := parsePointer(op.Path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid patch: operation %d: %w", i, err)
+		}
+
+		switch op.Op {
+		case "test":
+			current, err := lookup(target, tokens)
+			if err != nil {
+				return nil, fmt.Errorf("invalid patch: operation %d: %w", i, err)
+			}
+			expected, err := decodeValue(op.Value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid patch: operation %d: %w", i, err)
+			}
+			if !reflect.DeepEqual(current, expected) {
+				return nil, fmt.Errorf("%w: %s is not %s", ErrTestFailed, op.Path, op.Value)
+			}
+		case "replace":
+			value, err := decodeValue(op.Value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid patch: operation %d: %w", i, err)
+			}
+			if target, err = replace(target, tokens, value); err != nil {
+				return nil, fmt.Errorf("invalid patch: operation %d: %w", i, err)
+			}
+		case "remove":
+			if target, err = remove(target, tokens); err != nil {
+				return nil, fmt.Errorf("invalid patch: operation %d: %w", i, err)
+			}
+		default:
+			return nil, fmt.Errorf("invalid patch: operation %d: unsupported op %q (use test, replace or remove)", i, op.Op)
+		}
+	}
+
+	return json.Marshal(target)
+}
+
+// parseOperations decodes a JSON Patch document, which must be an array of
+// operations each naming its op and path
+func parseOperations(patch []byte) ([]operation, error) {
+	var raw []map[string]json.RawMessage
+	if err := json.Unmarshal(patch, &raw); err != nil {
+		return nil, fmt.Errorf("invalid patch: a JSON Patch must be an array of operations: %w", err)
+	}
+
+	ops := make([]operation, len(raw))
+	for i, fields := range raw {
+		for _, member := range []string{"op", "path"} {
+			value, ok := fields[member]
+			if !ok {
+				return nil, fmt.Errorf("invalid patch: operation %d: %s is required", i, member)
+			}
+			var s string
+			if err := json.Unmarshal(value, &s); err != nil {
+				return nil, fmt.Errorf("invalid patch: operation %d: %s must be a string", i, member)
+			}
+			if member == "op" {
+				ops[i].Op = s
+			} else {
+				ops[i].Path = s
+			}
+		}
+		if value, ok := fields["value"]; ok {
+			ops[i].Value = value
+		} else if ops[i].Op == "test" || ops[i].Op == "replace" {
+			return nil, fmt.Errorf("invalid patch: operation %d: value is required for %s", i, ops[i].Op)
+		}
+	}
+	return ops, nil
+}
+
+// decodeValue decodes an operation's value the way the document was decoded,
+// so the two compare equal when they hold the same JSON
+func decodeValue(raw json.RawMessage) (interface{}, error) {
+	var value interface{}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return nil, fmt.Errorf("invalid value: %w", err)
+	}
+	return value, nil
+}
+
+// parsePointer splits a JSON Pointer into its unescaped reference tokens.
+// The empty pointer refers to the whole document.
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("path %q must be empty or start with /", pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+	}
+	return tokens, nil
+}
+
+// lookup returns the value the pointer tokens refer to
+func lookup(doc interface{}, tokens []string) (interface{}, error) {
+	current := doc
+	for i, token := range tokens {
+		switch node := current.(type) {
+		case map[string]interface{}:
+			value, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("path %s does not exist", pointerString(tokens[:i+1]))
+			}
+			current = value
+		case []interface{}:
+			index, err := arrayIndex(token, len(node))
+			if err != nil {
+				return nil, fmt.Errorf("path %s: %w", pointerString(tokens[:i+1]), err)
+			}
+			current = node[index]
+		default:
+			return nil, fmt.Errorf("path %s does not exist", pointerString(tokens[:i+1]))
+		}
+	}
+	return current, nil
+}
+
+// replace sets the existing value the pointer tokens refer to and returns the
+// document, which is the value itself when the pointer is empty
+func replace(doc interface{}, tokens []string, value interface{}) (interface{}, error) {
+	if len(tokens) == 0 {
+		return value, nil
+	}
+
+	parent, err := lookup(doc, tokens[:len(tokens)-1])
+	if err != nil {
+		return nil, err
+	}
+	last := tokens[len(tokens)-1]
+	switch node := parent.(type) {
+	case map[string]interface{}:
+		if _, ok := node[last]; !ok {
+			return nil, fmt.Errorf("path %s does not exist", pointerString(tokens))
+		}
+		node[last] = value
+	case []interface{}:
+		index, err := arrayIndex(last, len(node))
+		if err != nil {
+			return nil, fmt.Errorf("path %s: %w", pointerString(tokens), err)
+		}
+		node[index] = value
+	default:
+		return nil, fmt.Errorf("path %s does not exist", pointerString(tokens))
+	}
+	return doc, nil
+}
+
+// remove deletes the value the pointer tokens refer to and returns the
+// document. Removing an array element shifts the elements after it.
+func remove(doc interface{}, tokens []string) (interface{}, error) {
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("the whole document cannot be removed")
+	}
+
+	parent, err := lookup(doc, tokens[:len(tokens)-1])
+	if err != nil {
+		return nil, err
+	}
+	last := tokens[len(tokens)-1]
+	switch node := parent.(type) {
+	case map[string]interface{}:
+		if _, ok := node[last]; !ok {
+			return nil, fmt.Errorf("path %s does not exist", pointerString(tokens))
+		}
+		delete(node, last)
+	case []interface{}:
+		index, err := arrayIndex(last, len(node))
+		if err != nil {
+			return nil, fmt.Errorf("path %s: %w", pointerString(tokens), err)
+		}
+		shortened := append(node[:index:index], node[index+1:]...)
+		if len(tokens) == 1 {
+			return shortened, nil
+		}
+		return replace(doc, tokens[:len(tokens)-1], shortened)
+	default:
+		return nil, fmt.Errorf("path %s does not exist", pointerString(tokens))
+	}
+	return doc, nil
+}
+
+// arrayIndex parses an array reference token, which must name an existing
+// element without leading zeros
+func arrayIndex(token string, length int) (int, error) {
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	if index >= length {
+		return 0, fmt.Errorf("array index %d out of range", index)
+	}
+	return index, nil
+}
+
+// pointerString re-escapes reference tokens into a JSON Pointer
+func pointerString(tokens []string) string {
+	var b strings.Builder
+	for _, token := range tokens {
+		b.WriteString("/")
+		b.WriteString(strings.NewReplacer("~", "~0", "/", "~1").Replace(token))
+	}
+	return b.String()
+}
@@ -0,0 +1,114 @@
+package patch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMerge(t *testing.T) {
+	t.Run("members are replaced and null removes them", func(t *testing.T) {
+		doc := `{"title":"Dune","publisher":"Chilton","pages":412}`
+
+		patched, err := Merge([]byte(doc), []byte(`{"publisher":null,"pages":500}`))
+
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"title":"Dune","pages":500}`, string(patched))
+	})
+
+	t.Run("nested objects merge recursively", func(t *testing.T) {
+		doc := `{"a":{"b":1,"c":2},"d":[1,2]}`
+
+		patched, err := Merge([]byte(doc), []byte(`{"a":{"c":null,"e":3},"d":[3]}`))
+
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"a":{"b":1,"e":3},"d":[3]}`, string(patched))
+	})
+
+	t.Run("a non-object patch replaces the document", func(t *testing.T) {
+		patched, err := Merge([]byte(`{"a":1}`), []byte(`["x"]`))
+
+		assert.NoError(t, err)
+		assert.JSONEq(t, `["x"]`, string(patched))
+	})
+
+	t.Run("malformed patch", func(t *testing.T) {
+		_, err := Merge([]byte(`{}`), []byte(`{"a":`))
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid patch")
+	})
+}
+
+func TestApply(t *testing.T) {
+	doc := []byte(`{"title":"Dune","publisher":"Chilton","pages":412,"tags":["sf","classic"],"a/b":{"~c":1}}`)
+
+	t.Run("test, replace and remove in order", func(t *testing.T) {
+		patched, err := Apply(doc, []byte(`[
+			{"op":"test","path":"/pages","value":412},
+			{"op":"replace","path":"/title","value":"Dune Messiah"},
+			{"op":"remove","path":"/publisher"}
+		]`))
+
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"title":"Dune Messiah","pages":412,"tags":["sf","classic"],"a/b":{"~c":1}}`, string(patched))
+	})
+
+	t.Run("array elements and escaped pointers", func(t *testing.T) {
+		patched, err := Apply(doc, []byte(`[
+			{"op":"remove","path":"/tags/0"},
+			{"op":"replace","path":"/a~1b/~0c","value":2}
+		]`))
+
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"title":"Dune","publisher":"Chilton","pages":412,"tags":["classic"],"a/b":{"~c":2}}`, string(patched))
+	})
+
+	t.Run("failed test applies nothing", func(t *testing.T) {
+		patched, err := Apply(doc, []byte(`[
+			{"op":"replace","path":"/title","value":"Dune Messiah"},
+			{"op":"test","path":"/pages","value":500}
+		]`))
+
+		assert.ErrorIs(t, err, ErrTestFailed)
+		assert.Nil(t, patched)
+	})
+
+	t.Run("replace requires an existing member", func(t *testing.T) {
+		_, err := Apply(doc, []byte(`[{"op":"replace","path":"/rating","value":5}]`))
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "path /rating does not exist")
+	})
+
+	t.Run("unsupported operations are rejected", func(t *testing.T) {
+		_, err := Apply(doc, []byte(`[{"op":"add","path":"/rating","value":5}]`))
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), `unsupported op "add"`)
+	})
+
+	t.Run("replace needs a value, which may be null", func(t *testing.T) {
+		_, err := Apply(doc, []byte(`[{"op":"replace","path":"/title"}]`))
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "value is required")
+
+		patched, err := Apply(doc, []byte(`[{"op":"replace","path":"/publisher","value":null}]`))
+		assert.NoError(t, err)
+		assert.Contains(t, string(patched), `"publisher":null`)
+	})
+
+	t.Run("invalid pointers and indexes", func(t *testing.T) {
+		for _, path := range []string{"title", "/tags/01", "/tags/2", "/tags/-"} {
+			_, err := Apply(doc, []byte(`[{"op":"remove","path":"`+path+`"}]`))
+			assert.Error(t, err, path)
+		}
+	})
+
+	t.Run("a patch must be an array", func(t *testing.T) {
+		_, err := Apply(doc, []byte(`{"op":"remove","path":"/title"}`))
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "array of operations")
+	})
+}
@@ -0,0 +1,432 @@
+package query
+
+import (
+	"fmt"
+	"libmngmt/internal/errors"
+	"libmngmt/internal/isbn"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	// MaxLength caps the number of characters in a query
+	MaxLength = 1000
+	// maxDepth caps how deeply groups and negations may nest
+	maxDepth = 32
+)
+
+// Parse parses a catalog query into an AST. Errors are *errors.AppError
+// validation errors whose Position locates the problem in the query.
+func Parse(input string) (Expr, error) {
+	p := &parser{input: []rune(input)}
+	if len(p.input) > MaxLength {
+		return nil, p.errorAt(MaxLength+1, "query is longer than %d characters", MaxLength)
+	}
+
+	p.skipSpace()
+	if p.eof() {
+		return nil, p.errorAt(1, "query is empty")
+	}
+
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipSpace()
+	if !p.eof() {
+		// parseAnd only stops early at a closing parenthesis
+		return nil, p.errorAt(p.pos+1, `unexpected ")"`)
+	}
+	return expr, nil
+}
+
+type parser struct {
+	input []rune
+	pos   int
+	depth int
+}
+
+func (p *parser) eof() bool {
+	return p.pos >= len(p.input)
+}
+
+func (p *parser) peek() rune {
+	if p.eof() {
+		return 0
+	}
+	return p.input[p.pos]
+}
+
+func (p *parser) skipSpace() {
+	for !p.eof() && unicode.IsSpace(p.peek()) {
+		p.pos++
+	}
+}
+
+// isDelimiter reports whether r ends a bare word
+func isDelimiter(r rune) bool {
+	return unicode.IsSpace(r) || r == '(' || r == ')'
+}
+
+// errorAt builds a syntax error at a 1-based position
+func (p *parser) errorAt(position int, format string, args ...interface{}) error {
+	message := fmt.Sprintf(format, args...)
+	return errors.Syntax("invalid query", fmt.Sprintf("%s at position %d", message, position), position)
+}
+
+// keyword reports whether the upper-case keyword starts at the current
+// position as a word of its own, consuming it if so
+func (p *parser) keyword(kw string) bool {
+	end := p.pos + len(kw)
+	if end > len(p.input) || string(p.input[p.pos:end]) != kw {
+		return false
+	}
+	if end < len(p.input) && !isDelimiter(p.input[end]) {
+		return false
+	}
+	p.pos = end
+	return true
+}
+
+// parseOr parses terms joined by OR
+func (p *parser) parseOr() (Expr, error) {
+	start := p.pos + 1
+	first, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	terms := []Expr{first}
+	for {
+		p.skipSpace()
+		if !p.keyword("OR") {
+			break
+		}
+		term, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, term)
+	}
+
+	if len(terms) == 1 {
+		return first, nil
+	}
+	return &Or{Terms: terms, Position: start}, nil
+}
+
+// parseAnd parses a run of terms, optionally separated by AND, up to the end
+// of the query, a closing parenthesis or OR
+func (p *parser) parseAnd() (Expr, error) {
+	p.skipSpace()
+	start := p.pos + 1
+
+	var terms []Expr
+	for {
+		p.skipSpace()
+		if p.eof() || p.peek() == ')' {
+			break
+		}
+		if len(terms) > 0 {
+			save := p.pos
+			if p.keyword("OR") {
+				p.pos = save
+				break
+			}
+			p.keyword("AND")
+		}
+
+		term, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, term)
+	}
+
+	switch len(terms) {
+	case 0:
+		if p.eof() {
+			return nil, p.errorAt(p.pos+1, "expected a term")
+		}
+		return nil, p.errorAt(p.pos+1, `unexpected ")"`)
+	case 1:
+		return terms[0], nil
+	}
+	return &And{Terms: terms, Position: start}, nil
+}
+
+// parseUnary parses a term negated by '-' or NOT, or a plain term
+func (p *parser) parseUnary() (Expr, error) {
+	p.skipSpace()
+	start := p.pos + 1
+
+	negated := false
+	if p.peek() == '-' && p.pos+1 < len(p.input) && !unicode.IsSpace(p.input[p.pos+1]) {
+		p.pos++
+		negated = true
+	} else if p.keyword("NOT") {
+		negated = true
+	}
+	if !negated {
+		return p.parsePrimary()
+	}
+
+	if err := p.enter(start); err != nil {
+		return nil, err
+	}
+	defer p.leave()
+
+	term, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	return &Not{Term: term, Position: start}, nil
+}
+
+// enter and leave track nesting so hostile queries cannot exhaust the stack
+func (p *parser) enter(position int) error {
+	p.depth++
+	if p.depth > maxDepth {
+		return p.errorAt(position, "query is nested more than %d levels deep", maxDepth)
+	}
+	return nil
+}
+
+func (p *parser) leave() {
+	p.depth--
+}
+
+// parsePrimary parses a parenthesised group, a quoted phrase, a field term or
+// a bare word
+func (p *parser) parsePrimary() (Expr, error) {
+	p.skipSpace()
+	start := p.pos + 1
+
+	switch p.peek() {
+	case 0:
+		return nil, p.errorAt(start, "expected a term")
+	case ')':
+		return nil, p.errorAt(start, `unexpected ")"`)
+	case '(':
+		if err := p.enter(start); err != nil {
+			return nil, err
+		}
+		defer p.leave()
+
+		p.pos++
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		if p.peek() != ')' {
+			return nil, p.errorAt(start, `missing closing ")" for group`)
+		}
+		p.pos++
+		return expr, nil
+	case '"':
+		text, err := p.parseQuoted()
+		if err != nil {
+			return nil, err
+		}
+		if strings.TrimSpace(text) == "" {
+			return nil, p.errorAt(start, "empty phrase")
+		}
+		return &Text{Text: text, Position: start}, nil
+	}
+
+	// A field name directly followed by an operator starts a field term
+	nameEnd := p.pos
+	for nameEnd < len(p.input) && (unicode.IsLetter(p.input[nameEnd]) || unicode.IsDigit(p.input[nameEnd]) || p.input[nameEnd] == '_') {
+		nameEnd++
+	}
+	if nameEnd > p.pos && nameEnd < len(p.input) && strings.ContainsRune(":=<>", p.input[nameEnd]) {
+		return p.parseFieldTerm(nameEnd)
+	}
+
+	word := p.readWord()
+	if field, ok := lookupField(word); ok && field.Kind == KindBool {
+		return &Comparison{Field: field, Op: OpMatch, Value: Value{Bool: true}, Position: start}, nil
+	}
+	return &Text{Text: word, Position: start}, nil
+}
+
+// readWord consumes a bare word
+func (p *parser) readWord() string {
+	start := p.pos
+	for !p.eof() && !isDelimiter(p.peek()) {
+		p.pos++
+	}
+	return string(p.input[start:p.pos])
+}
+
+// parseQuoted consumes a double-quoted string in which \" and \\ are escapes
+func (p *parser) parseQuoted() (string, error) {
+	start := p.pos + 1
+	p.pos++
+
+	var b strings.Builder
+	for !p.eof() {
+		r := p.peek()
+		p.pos++
+		switch r {
+		case '"':
+			return b.String(), nil
+		case '\\':
+			if !p.eof() && (p.peek() == '"' || p.peek() == '\\') {
+				r = p.peek()
+				p.pos++
+			}
+		}
+		b.WriteRune(r)
+	}
+	return "", p.errorAt(start, "unterminated quoted string")
+}
+
+// parseFieldTerm parses field, operator and value; the field name ends at nameEnd
+func (p *parser) parseFieldTerm(nameEnd int) (Expr, error) {
+	start := p.pos + 1
+	name := string(p.input[p.pos:nameEnd])
+	field, ok := lookupField(name)
+	if !ok {
+		return nil, p.errorAt(start, "unknown field %q (fields are %s)", name, fieldNames())
+	}
+	p.pos = nameEnd
+
+	opPos := p.pos + 1
+	op := Op(p.peek())
+	p.pos++
+	if (op == OpGt || op == OpLt) && p.peek() == '=' {
+		op += "="
+		p.pos++
+	}
+	if op != OpMatch && op != OpEqual && field.Kind != KindNumber && field.Kind != KindDate {
+		return nil, p.errorAt(opPos, "operator %q cannot be used with field %q", op, field.Name)
+	}
+
+	valuePos := p.pos + 1
+	if p.eof() || isDelimiter(p.peek()) {
+		return nil, p.errorAt(valuePos, "expected a value after %s%s", name, op)
+	}
+
+	if p.peek() == '"' {
+		raw, err := p.parseQuoted()
+		if err != nil {
+			return nil, err
+		}
+		value, err := p.parseValue(field, raw, valuePos)
+		if err != nil {
+			return nil, err
+		}
+		return &Comparison{Field: field, Op: op, Value: value, Position: start}, nil
+	}
+
+	raw := p.readWord()
+	if from, to, isRange := strings.Cut(raw, ".."); isRange && op == OpMatch && (field.Kind == KindNumber || field.Kind == KindDate) {
+		return p.parseRange(field, from, to, start, valuePos)
+	}
+
+	value, err := p.parseValue(field, raw, valuePos)
+	if err != nil {
+		return nil, err
+	}
+	return &Comparison{Field: field, Op: op, Value: value, Position: start}, nil
+}
+
+// parseRange parses the bounds of a from..to range, either of which may be empty
+func (p *parser) parseRange(field Field, from, to string, start, valuePos int) (Expr, error) {
+	if from == "" && to == "" {
+		return nil, p.errorAt(valuePos, "range for %q needs at least one bound", field.Name)
+	}
+
+	r := &Range{Field: field, Position: start}
+	if from != "" {
+		value, err := p.parseValue(field, from, valuePos)
+		if err != nil {
+			return nil, err
+		}
+		r.From = &value
+	}
+	if to != "" {
+		toPos := valuePos + len([]rune(from)) + 2
+		value, err := p.parseValue(field, to, toPos)
+		if err != nil {
+			return nil, err
+		}
+		r.To = &value
+	}
+	if r.From != nil && r.To != nil && rangeInverted(field, *r.From, *r.To) {
+		return nil, p.errorAt(valuePos, "range for %q starts after it ends", field.Name)
+	}
+	return r, nil
+}
+
+func rangeInverted(field Field, from, to Value) bool {
+	if field.Kind == KindNumber {
+		return from.Number > to.Number
+	}
+	return !from.Start.Before(to.End)
+}
+
+// parseValue converts raw according to the field's kind
+func (p *parser) parseValue(field Field, raw string, position int) (Value, error) {
+	switch field.Kind {
+	case KindNumber:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return Value{}, p.errorAt(position, "invalid number %q for field %q", raw, field.Name)
+		}
+		return Value{Number: n}, nil
+	case KindDate:
+		start, end, ok := parsePeriod(raw)
+		if !ok {
+			return Value{}, p.errorAt(position, "invalid date %q for field %q (use YYYY, YYYY-MM, YYYY-MM-DD or RFC 3339)", raw, field.Name)
+		}
+		return Value{Start: start, End: end}, nil
+	case KindBool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return Value{}, p.errorAt(position, "invalid boolean %q for field %q", raw, field.Name)
+		}
+		return Value{Bool: b}, nil
+	}
+
+	if strings.TrimSpace(raw) == "" {
+		return Value{}, p.errorAt(position, "expected a value for field %q", field.Name)
+	}
+	if field.Name == "isbn" {
+		canonical, err := isbn.Canonical(raw)
+		if err != nil {
+			canonical = isbn.Strip(raw)
+		}
+		return Value{Text: canonical}, nil
+	}
+	return Value{Text: raw}, nil
+}
+
+// parsePeriod parses a year, month, day or instant into the period it covers
+func parsePeriod(raw string) (start, end time.Time, ok bool) {
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, t.Add(time.Microsecond), true
+	}
+	periods := []struct {
+		layout string
+		next   func(time.Time) time.Time
+	}{
+		{"2006", func(t time.Time) time.Time { return t.AddDate(1, 0, 0) }},
+		{"2006-01", func(t time.Time) time.Time { return t.AddDate(0, 1, 0) }},
+		{"2006-01-02", func(t time.Time) time.Time { return t.AddDate(0, 0, 1) }},
+	}
+	for _, period := range periods {
+		if len(raw) != len(period.layout) {
+			continue
+		}
+		if t, err := time.Parse(period.layout, raw); err == nil {
+			return t, period.next(t), true
+		}
+	}
+	return time.Time{}, time.Time{}, false
+}
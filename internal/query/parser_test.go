@@ -0,0 +1,127 @@
+package query
+
+import (
+	"libmngmt/internal/errors"
+	"libmngmt/internal/models"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	t.Run("terms are implicitly joined with AND", func(t *testing.T) {
+		expr, err := Parse("author:kernighan genre:programming pages>300 -available published:2015..2020")
+
+		assert.NoError(t, err)
+		assert.Equal(t, `(author:"kernighan" AND genre:"programming" AND pages>300 AND NOT available:true AND published:2015-01-01/2016-01-01..2020-01-01/2021-01-01)`, expr.String())
+	})
+
+	t.Run("OR binds looser than AND and groups nest", func(t *testing.T) {
+		expr, err := Parse(`title:go (author:"Rob Pike" OR author:kernighan) AND NOT language:fr`)
+
+		assert.NoError(t, err)
+		assert.Equal(t, `(title:"go" AND (author:"Rob Pike" OR author:"kernighan") AND NOT language:"fr")`, expr.String())
+
+		expr, err = Parse("hobbit OR silmarillion tolkien")
+		assert.NoError(t, err)
+		assert.Equal(t, `("hobbit" OR ("silmarillion" AND "tolkien"))`, expr.String())
+	})
+
+	t.Run("values are typed by field", func(t *testing.T) {
+		expr, err := Parse("pages<=500 published>=2015-03 isbn:0-306-40615-2 available:false updated_at:..2024-03-01")
+		assert.NoError(t, err)
+
+		terms := expr.(*And).Terms
+		assert.Equal(t, 500, terms[0].(*Comparison).Value.Number)
+		assert.Equal(t, OpLte, terms[0].(*Comparison).Op)
+		assert.Equal(t, time.Date(2015, 3, 1, 0, 0, 0, 0, time.UTC), terms[1].(*Comparison).Value.Start)
+		assert.Equal(t, time.Date(2015, 4, 1, 0, 0, 0, 0, time.UTC), terms[1].(*Comparison).Value.End)
+		assert.Equal(t, "9780306406157", terms[2].(*Comparison).Value.Text)
+		assert.False(t, terms[3].(*Comparison).Value.Bool)
+
+		updated := terms[4].(*Range)
+		assert.Equal(t, "updated", updated.Field.Name)
+		assert.Nil(t, updated.From)
+		assert.Equal(t, time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC), updated.To.End)
+	})
+
+	t.Run("quoted values keep spaces and escapes", func(t *testing.T) {
+		expr, err := Parse(`"the go \"programming\" language"`)
+
+		assert.NoError(t, err)
+		assert.Equal(t, &Text{Text: `the go "programming" language`, Position: 1}, expr)
+	})
+
+	t.Run("syntax errors report their position", func(t *testing.T) {
+		tests := []struct {
+			query    string
+			position int
+			details  string
+		}{
+			{"", 1, "query is empty"},
+			{"author:kernighan rating>4", 18, `unknown field "rating"`},
+			{"pages>lots", 7, `invalid number "lots" for field "pages"`},
+			{"author>k", 7, `operator ">" cannot be used with field "author"`},
+			{"published:2015-13", 11, `invalid date "2015-13"`},
+			{"published:2020..2015", 11, `range for "published" starts after it ends`},
+			{"pages:100..x", 12, `invalid number "x"`},
+			{"pages:..", 7, "needs at least one bound"},
+			{"genre: fantasy", 7, "expected a value after genre:"},
+			{`title:"unterminated`, 7, "unterminated quoted string"},
+			{"(author:pike OR", 16, "expected a term"},
+			{"(author:pike", 1, `missing closing ")"`},
+			{"author:pike)", 12, `unexpected ")"`},
+			{"()", 2, `unexpected ")"`},
+			{"go -", 4, ""},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.query, func(t *testing.T) {
+				expr, err := Parse(tt.query)
+
+				if tt.details == "" {
+					// A lone '-' is free text rather than a negation
+					assert.NoError(t, err)
+					assert.NotNil(t, expr)
+					return
+				}
+
+				assert.Error(t, err)
+				appErr, ok := err.(*errors.AppError)
+				assert.True(t, ok)
+				assert.Equal(t, errors.CodeValidation, appErr.Code)
+				assert.Equal(t, tt.position, appErr.Position)
+				assert.Contains(t, appErr.Details, tt.details)
+			})
+		}
+	})
+
+	t.Run("oversized and deeply nested queries are rejected", func(t *testing.T) {
+		_, err := Parse(strings.Repeat("a", MaxLength+1))
+		assert.Error(t, err)
+
+		_, err = Parse(strings.Repeat("(", 40) + "go" + strings.Repeat(")", 40))
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "nested")
+
+		_, err = Parse(strings.Repeat("-", 40) + "go")
+		assert.Error(t, err)
+	})
+}
+
+func TestFields(t *testing.T) {
+	t.Run("every field reads a column of the Book model", func(t *testing.T) {
+		columns := map[string]bool{}
+		bookType := reflect.TypeOf(models.Book{})
+		for i := 0; i < bookType.NumField(); i++ {
+			columns[bookType.Field(i).Tag.Get("db")] = true
+		}
+
+		for _, field := range Fields() {
+			assert.True(t, columns[field.Column], field.Name)
+		}
+	})
+}
@@ -0,0 +1,212 @@
+// Package query parses the catalog query language accepted by the q parameter
+// of book listings, for example:
+//
+//	author:kernighan genre:programming pages>300 -available published:2015..2020
+//
+// A query is a sequence of terms that must all match. Terms may be joined
+// with OR, negated with a leading '-' or NOT, and grouped with parentheses.
+// A term is either a field comparison (field:value, field=value, field>value,
+// field>=value, field<value, field<=value or a field:from..to range with
+// either end optional) or free text, which matches titles and authors.
+// Values containing spaces are double-quoted. A boolean field on its own,
+// such as available, is shorthand for available:true.
+//
+// Parse returns an AST of Expr nodes whose fields and values have been
+// checked against the Book model; compiling it to SQL is left to the
+// repository. Syntax errors are *errors.AppError values carrying the
+// 1-based character position of the problem.
+package query
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Kind is the type of value a field holds
+type Kind int
+
+const (
+	// KindText fields match by case-insensitive substring with ':' and
+	// exactly, ignoring case, with '='
+	KindText Kind = iota
+	// KindExact fields always match exactly, ignoring case
+	KindExact
+	KindNumber
+	KindDate
+	KindBool
+)
+
+// Field is a book field that can be queried
+type Field struct {
+	Name string
+	// Column is the books column the field reads
+	Column string
+	Kind   Kind
+}
+
+// fields lists the queryable fields under their query names. Aliases
+// matching the JSON names of the Book model are accepted too.
+var fields = []Field{
+	{Name: "title", Column: "title", Kind: KindText},
+	{Name: "author", Column: "author", Kind: KindText},
+	{Name: "publisher", Column: "publisher", Kind: KindText},
+	{Name: "genre", Column: "genre", Kind: KindText},
+	{Name: "language", Column: "language", Kind: KindExact},
+	{Name: "isbn", Column: "isbn", Kind: KindExact},
+	{Name: "pages", Column: "pages", Kind: KindNumber},
+	{Name: "published", Column: "published_at", Kind: KindDate},
+	{Name: "created", Column: "created_at", Kind: KindDate},
+	{Name: "updated", Column: "updated_at", Kind: KindDate},
+	{Name: "available", Column: "available", Kind: KindBool},
+}
+
+var fieldAliases = map[string]string{
+	"published_at": "published",
+	"created_at":   "created",
+	"updated_at":   "updated",
+}
+
+// Fields returns the queryable fields
+func Fields() []Field {
+	return append([]Field(nil), fields...)
+}
+
+// lookupField finds a field by name or alias, ignoring case
+func lookupField(name string) (Field, bool) {
+	name = strings.ToLower(name)
+	if alias, ok := fieldAliases[name]; ok {
+		name = alias
+	}
+	for _, f := range fields {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return Field{}, false
+}
+
+// fieldNames lists the field names for error messages
+func fieldNames() string {
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		names[i] = f.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// Op is a comparison operator
+type Op string
+
+const (
+	OpMatch Op = ":"
+	OpEqual Op = "="
+	OpGt    Op = ">"
+	OpGte   Op = ">="
+	OpLt    Op = "<"
+	OpLte   Op = "<="
+)
+
+// Value is a field value parsed according to the field's kind
+type Value struct {
+	Text   string
+	Number int
+	Bool   bool
+	// Dates are periods: a year, month or day. End is exclusive.
+	Start, End time.Time
+}
+
+// Expr is a node of a parsed query
+type Expr interface {
+	// Pos returns the 1-based character position the node starts at
+	Pos() int
+	String() string
+}
+
+// And matches books matching every one of its terms
+type And struct {
+	Terms    []Expr
+	Position int
+}
+
+// Or matches books matching any of its terms
+type Or struct {
+	Terms    []Expr
+	Position int
+}
+
+// Not matches books not matching its term
+type Not struct {
+	Term     Expr
+	Position int
+}
+
+// Comparison matches a field against a value
+type Comparison struct {
+	Field    Field
+	Op       Op
+	Value    Value
+	Position int
+}
+
+// Range matches a field between two inclusive bounds; a nil bound is open
+type Range struct {
+	Field    Field
+	From, To *Value
+	Position int
+}
+
+// Text matches free text against titles and authors
+type Text struct {
+	Text     string
+	Position int
+}
+
+func (e *And) Pos() int        { return e.Position }
+func (e *Or) Pos() int         { return e.Position }
+func (e *Not) Pos() int        { return e.Position }
+func (e *Comparison) Pos() int { return e.Position }
+func (e *Range) Pos() int      { return e.Position }
+func (e *Text) Pos() int       { return e.Position }
+
+func (e *And) String() string { return "(" + joinExprs(e.Terms, " AND ") + ")" }
+func (e *Or) String() string  { return "(" + joinExprs(e.Terms, " OR ") + ")" }
+func (e *Not) String() string { return "NOT " + e.Term.String() }
+
+func (e *Comparison) String() string {
+	return e.Field.Name + string(e.Op) + e.Value.format(e.Field.Kind)
+}
+
+func (e *Range) String() string {
+	var from, to string
+	if e.From != nil {
+		from = e.From.format(e.Field.Kind)
+	}
+	if e.To != nil {
+		to = e.To.format(e.Field.Kind)
+	}
+	return e.Field.Name + ":" + from + ".." + to
+}
+
+func (e *Text) String() string { return fmt.Sprintf("%q", e.Text) }
+
+func joinExprs(exprs []Expr, sep string) string {
+	parts := make([]string, len(exprs))
+	for i, e := range exprs {
+		parts[i] = e.String()
+	}
+	return strings.Join(parts, sep)
+}
+
+// format renders the value for String
+func (v Value) format(kind Kind) string {
+	switch kind {
+	case KindNumber:
+		return fmt.Sprint(v.Number)
+	case KindBool:
+		return fmt.Sprint(v.Bool)
+	case KindDate:
+		return v.Start.Format("2006-01-02") + "/" + v.End.Format("2006-01-02")
+	}
+	return fmt.Sprintf("%q", v.Text)
+}
@@ -1,24 +1,70 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"libmngmt/internal/database"
 	"libmngmt/internal/models"
+	"libmngmt/internal/query"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
+// ErrVersionConflict is returned by a versioned update when the book has been
+// written since the expected version
+var ErrVersionConflict = errors.New("book version conflict")
+
+// BatchItemError reports the book of a batch whose insert failed, by its
+// index in the batch
+type BatchItemError struct {
+	Index int
+	Err   error
+	// Duplicate marks a book whose ISBN was taken by another book meanwhile
+	Duplicate bool
+}
+
+func (e *BatchItemError) Error() string {
+	return fmt.Sprintf("book at index %d: %v", e.Index, e.Err)
+}
+
+func (e *BatchItemError) Unwrap() error {
+	return e.Err
+}
+
+// isUniqueViolation reports whether err is a Postgres unique constraint violation
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
 // BookRepository defines the interface for book data operations
 type BookRepository interface {
-	Create(book *models.CreateBookRequest) (*models.Book, error)
+	Create(book *models.CreateBookRequest, audit models.AuditInfo) (*models.Book, error)
+	CreateBatch(ctx context.Context, books []*models.CreateBookRequest, audit models.AuditInfo) ([]*models.Book, error)
 	GetByID(id uuid.UUID) (*models.Book, error)
-	GetAll(filter models.BookFilter) ([]models.Book, int, error)
-	Update(id uuid.UUID, book *models.UpdateBookRequest) (*models.Book, error)
-	Delete(id uuid.UUID) error
+	GetProjected(id uuid.UUID, projection models.BookProjection) (*models.Book, error)
+	GetAll(filter models.BookFilter) ([]models.Book, bool, error)
+	GetPage(filter models.BookFilter, cursor *models.BookCursor) ([]models.Book, bool, error)
+	Count(filter models.BookFilter) (int, error)
+	Update(id uuid.UUID, book *models.UpdateBookRequest, audit models.AuditInfo) (*models.Book, error)
+	Delete(id uuid.UUID, version *int, audit models.AuditInfo) error
+	GetTrash(limit, offset int) ([]models.Book, int, error)
+	Restore(id uuid.UUID, audit models.AuditInfo) (*models.Book, error)
+	Purge(deletedBefore time.Time) (int, error)
+	GetHistory(id uuid.UUID) ([]models.BookAuditEntry, error)
+	GetAsOf(id uuid.UUID, at time.Time) (*models.BookAuditEntry, error)
+	GetRevision(id uuid.UUID, revision int) (*models.BookAuditEntry, error)
 	ExistsByISBN(isbn string, excludeID *uuid.UUID) (bool, error)
+	Search(filter models.BookSearchFilter) ([]models.BookSearchResult, int, error)
+	SuggestSimilar(field, term string, threshold float64) (string, error)
+	GetFacets(filter models.BookFilter) (*models.BookFacets, error)
+	SuggestByPrefix(field models.SuggestField, prefix string, limit int) ([]string, error)
+	SuggestionCounts(field models.SuggestField) (map[string]int, error)
 }
 
 // bookRepository implements BookRepository interface
@@ -31,8 +77,87 @@ func NewBookRepository(db *database.DB) BookRepository {
 	return &bookRepository{db: db}
 }
 
-// Create creates a new book
-func (r *bookRepository) Create(req *models.CreateBookRequest) (*models.Book, error) {
+const bookRowColumns = "id, title, author, isbn, publisher, genre, published_at, pages, language, available, created_at, updated_at, version"
+
+// bookScanner is satisfied by both *sql.Row and *sql.Rows
+type bookScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanBookRow scans the bookRowColumns of a book
+func scanBookRow(row bookScanner, book *models.Book) error {
+	return row.Scan(
+		&book.ID, &book.Title, &book.Author, &book.ISBN, &book.Publisher, &book.Genre,
+		&book.PublishedAt, &book.Pages, &book.Language, &book.Available, &book.CreatedAt, &book.UpdatedAt,
+		&book.Version,
+	)
+}
+
+// lockBook reads a live book and locks its row for the rest of the transaction
+func lockBook(tx *sql.Tx, id uuid.UUID) (*models.Book, error) {
+	book := &models.Book{}
+	err := scanBookRow(tx.QueryRow("SELECT "+bookRowColumns+" FROM books WHERE id = $1 AND deleted_at IS NULL FOR UPDATE", id), book)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("book not found")
+		}
+		return nil, fmt.Errorf("failed to lock book: %w", err)
+	}
+	return book, nil
+}
+
+// Create creates a new book and records it in the audit trail
+func (r *bookRepository) Create(req *models.CreateBookRequest, audit models.AuditInfo) (*models.Book, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	book, err := insertBook(tx, req, audit)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit book creation: %w", err)
+	}
+
+	return book, nil
+}
+
+// CreateBatch creates books in a single transaction, each recorded in the
+// audit trail. If any insert fails, or ctx is done before the commit, the
+// whole batch is rolled back. A failed insert is reported as a
+// BatchItemError; an ISBN taken since it was checked fails as a duplicate.
+func (r *bookRepository) CreateBatch(ctx context.Context, reqs []*models.CreateBookRequest, audit models.AuditInfo) ([]*models.Book, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	books := make([]*models.Book, len(reqs))
+	for i, req := range reqs {
+		book, err := insertBook(tx, req, audit)
+		if isUniqueViolation(err) {
+			return nil, &BatchItemError{Index: i, Err: fmt.Errorf("book with ISBN %s already exists", req.ISBN), Duplicate: true}
+		}
+		if err != nil {
+			return nil, &BatchItemError{Index: i, Err: err}
+		}
+		books[i] = book
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit batch creation: %w", err)
+	}
+
+	return books, nil
+}
+
+// insertBook inserts a new book and its audit entry in the caller's transaction
+func insertBook(tx *sql.Tx, req *models.CreateBookRequest, audit models.AuditInfo) (*models.Book, error) {
 	book := &models.Book{
 		ID:          uuid.New(),
 		Title:       req.Title,
@@ -46,6 +171,7 @@ func (r *bookRepository) Create(req *models.CreateBookRequest) (*models.Book, er
 		Available:   true,
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
+		Version:     1,
 	}
 
 	if book.Language == "" {
@@ -58,7 +184,7 @@ func (r *bookRepository) Create(req *models.CreateBookRequest) (*models.Book, er
 		RETURNING id, created_at, updated_at
 	`
 
-	err := r.db.QueryRow(
+	err := tx.QueryRow(
 		query,
 		book.ID, book.Title, book.Author, book.ISBN, book.Publisher, book.Genre,
 		book.PublishedAt, book.Pages, book.Language, book.Available, book.CreatedAt, book.UpdatedAt,
@@ -68,6 +194,10 @@ func (r *bookRepository) Create(req *models.CreateBookRequest) (*models.Book, er
 		return nil, fmt.Errorf("failed to create book: %w", err)
 	}
 
+	if err = recordAudit(tx, models.AuditCreate, audit, nil, book); err != nil {
+		return nil, err
+	}
+
 	return book, nil
 }
 
@@ -75,14 +205,15 @@ func (r *bookRepository) Create(req *models.CreateBookRequest) (*models.Book, er
 func (r *bookRepository) GetByID(id uuid.UUID) (*models.Book, error) {
 	book := &models.Book{}
 	query := `
-		SELECT id, title, author, isbn, publisher, genre, published_at, pages, language, available, created_at, updated_at
+		SELECT id, title, author, isbn, publisher, genre, published_at, pages, language, available, created_at, updated_at, version
 		FROM books
-		WHERE id = $1
+		WHERE id = $1 AND deleted_at IS NULL
 	`
 
 	err := r.db.QueryRow(query, id).Scan(
 		&book.ID, &book.Title, &book.Author, &book.ISBN, &book.Publisher, &book.Genre,
 		&book.PublishedAt, &book.Pages, &book.Language, &book.Available, &book.CreatedAt, &book.UpdatedAt,
+		&book.Version,
 	)
 
 	if err != nil {
@@ -95,52 +226,193 @@ func (r *bookRepository) GetByID(id uuid.UUID) (*models.Book, error) {
 	return book, nil
 }
 
-// GetAll retrieves all books with optional filtering
-func (r *bookRepository) GetAll(filter models.BookFilter) ([]models.Book, int, error) {
-	books := make([]models.Book, 0) // Initialize as empty slice, not nil slice
-	var total int
+// GetProjected retrieves a book reading only the projected columns, with the
+// related resources the projection includes
+func (r *bookRepository) GetProjected(id uuid.UUID, projection models.BookProjection) (*models.Book, error) {
+	var book *models.Book
+	if len(projection.Fields) == 0 {
+		found, err := r.GetByID(id)
+		if err != nil {
+			return nil, err
+		}
+		book = found
+	} else {
+		columns, err := projectBookColumns(projection.Fields)
+		if err != nil {
+			return nil, err
+		}
 
-	// Build WHERE clause
-	var whereConditions []string
-	var args []interface{}
-	argCount := 0
+		book = &models.Book{}
+		query := fmt.Sprintf("SELECT %s FROM books WHERE id = $1 AND deleted_at IS NULL", selectList(columns))
+		if err := r.db.QueryRow(query, id).Scan(scanDest(columns, book)...); err != nil {
+			if err == sql.ErrNoRows {
+				return nil, fmt.Errorf("book not found")
+			}
+			return nil, fmt.Errorf("failed to get book: %w", err)
+		}
+	}
 
-	if filter.Author != "" {
-		argCount++
-		whereConditions = append(whereConditions, fmt.Sprintf("LOWER(author) LIKE LOWER($%d)", argCount))
-		args = append(args, "%"+filter.Author+"%")
+	if projection.Includes(models.BookIncludeCopies) {
+		books := []models.Book{*book}
+		if err := r.includeCopies(books); err != nil {
+			return nil, err
+		}
+		book = &books[0]
 	}
 
-	if filter.Genre != "" {
-		argCount++
-		whereConditions = append(whereConditions, fmt.Sprintf("LOWER(genre) LIKE LOWER($%d)", argCount))
-		args = append(args, "%"+filter.Genre+"%")
+	return book, nil
+}
+
+// includeCopies embeds each book's copies, read with a single query. Books
+// without copies get an empty list.
+func (r *bookRepository) includeCopies(books []models.Book) error {
+	if len(books) == 0 {
+		return nil
 	}
 
-	if filter.Language != "" {
-		argCount++
-		whereConditions = append(whereConditions, fmt.Sprintf("LOWER(language) = LOWER($%d)", argCount))
-		args = append(args, filter.Language)
+	placeholders := make([]string, len(books))
+	args := make([]interface{}, len(books))
+	for i := range books {
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+		args[i] = books[i].ID
+		books[i].Copies = make([]models.Copy, 0)
 	}
 
-	if filter.Available != nil {
-		argCount++
-		whereConditions = append(whereConditions, fmt.Sprintf("available = $%d", argCount))
-		args = append(args, *filter.Available)
+	query := fmt.Sprintf("SELECT %s FROM copies WHERE book_id IN (%s) ORDER BY barcode",
+		copyColumns, strings.Join(placeholders, ", "))
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to query copies: %w", err)
+	}
+	defer rows.Close()
+
+	index := make(map[uuid.UUID]int, len(books))
+	for i := range books {
+		index[books[i].ID] = i
+	}
+	for rows.Next() {
+		var item models.Copy
+		if err := scanCopy(rows, &item); err != nil {
+			return fmt.Errorf("failed to scan copy: %w", err)
+		}
+		if i, ok := index[item.BookID]; ok {
+			books[i].Copies = append(books[i].Copies, item)
+		}
 	}
 
-	whereClause := ""
-	if len(whereConditions) > 0 {
-		whereClause = "WHERE " + strings.Join(whereConditions, " AND ")
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("failed to iterate rows: %w", err)
 	}
 
-	// Get total count
-	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM books %s", whereClause)
-	err := r.db.QueryRow(countQuery, args...).Scan(&total)
+	return nil
+}
+
+// bookColumn is a projectable book field: the SQL expression it is read from
+// and where it scans into
+type bookColumn struct {
+	field string
+	expr  string
+	dest  func(book *models.Book) interface{}
+}
+
+// bookListColumns selects a listed book with its per-title copy counts, in
+// the order a full listing returns them
+var bookListColumns = []bookColumn{
+	{"id", "id", func(b *models.Book) interface{} { return &b.ID }},
+	{"title", "title", func(b *models.Book) interface{} { return &b.Title }},
+	{"author", "author", func(b *models.Book) interface{} { return &b.Author }},
+	{"isbn", "isbn", func(b *models.Book) interface{} { return &b.ISBN }},
+	{"publisher", "publisher", func(b *models.Book) interface{} { return &b.Publisher }},
+	{"genre", "genre", func(b *models.Book) interface{} { return &b.Genre }},
+	{"published_at", "published_at", func(b *models.Book) interface{} { return &b.PublishedAt }},
+	{"pages", "pages", func(b *models.Book) interface{} { return &b.Pages }},
+	{"language", "language", func(b *models.Book) interface{} { return &b.Language }},
+	{"available", "available", func(b *models.Book) interface{} { return &b.Available }},
+	{"created_at", "created_at", func(b *models.Book) interface{} { return &b.CreatedAt }},
+	{"updated_at", "updated_at", func(b *models.Book) interface{} { return &b.UpdatedAt }},
+	{"version", "version", func(b *models.Book) interface{} { return &b.Version }},
+	{"total_copies", "(SELECT COUNT(*) FROM copies WHERE copies.book_id = books.id) AS total_copies",
+		func(b *models.Book) interface{} { return &b.TotalCopies }},
+	{"available_copies", "(SELECT COUNT(*) FROM copies c WHERE c.book_id = books.id AND " + freeCopyCondition + ") AS available_copies",
+		func(b *models.Book) interface{} { return &b.AvailableCopies }},
+}
+
+// derivedBookFields maps fields computed from another column to that column
+var derivedBookFields = map[string]string{"isbn10": "isbn"}
+
+// projectBookColumns picks the columns a projection reads, in listing order.
+// The ID is always read since cursors and included resources need it, and
+// so is the update time, which dates the response; an empty projection
+// reads every column.
+func projectBookColumns(fields []string) ([]bookColumn, error) {
+	if len(fields) == 0 {
+		return bookListColumns, nil
+	}
+
+	selected := map[string]bool{"id": true, "updated_at": true}
+	for _, field := range fields {
+		if source, ok := derivedBookFields[field]; ok {
+			field = source
+		}
+		known := false
+		for _, column := range bookListColumns {
+			if column.field == field {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return nil, fmt.Errorf("invalid fields: unknown field %q", field)
+		}
+		selected[field] = true
+	}
+
+	columns := make([]bookColumn, 0, len(selected))
+	for _, column := range bookListColumns {
+		if selected[column.field] {
+			columns = append(columns, column)
+		}
+	}
+	return columns, nil
+}
+
+// selectList renders the columns for a SELECT clause
+func selectList(columns []bookColumn) string {
+	exprs := make([]string, len(columns))
+	for i, column := range columns {
+		exprs[i] = column.expr
+	}
+	return strings.Join(exprs, ",\n\t\t\t")
+}
+
+// scanDest returns the scan destinations of the columns in book
+func scanDest(columns []bookColumn, book *models.Book) []interface{} {
+	dest := make([]interface{}, len(columns))
+	for i, column := range columns {
+		dest[i] = column.dest(book)
+	}
+	return dest
+}
+
+// GetAll retrieves the page of books at filter.Offset with optional filtering
+// and reports whether more books lie beyond the page. Use Count for the total.
+func (r *bookRepository) GetAll(filter models.BookFilter) ([]models.Book, bool, error) {
+	order, err := parseBookSort(filter.Sort)
+	if err != nil {
+		return nil, false, err
+	}
+
+	columns, err := projectBookColumns(filter.Fields)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to count books: %w", err)
+		return nil, false, err
 	}
 
+	whereClause, args, similarityScores, err := buildBookFilter(filter)
+	if err != nil {
+		return nil, false, err
+	}
+	argCount := len(args)
+
 	// Set default pagination
 	if filter.Limit <= 0 {
 		filter.Limit = 50 // Default limit
@@ -149,176 +421,1169 @@ func (r *bookRepository) GetAll(filter models.BookFilter) ([]models.Book, int, e
 		filter.Offset = 0
 	}
 
-	// Get books
+	orderBy := order.orderBy(false)
+	if len(similarityScores) > 0 {
+		orderBy = "(" + strings.Join(similarityScores, " + ") + ") DESC, " + orderBy
+	}
+
+	// Get books with per-title copy counts, plus one extra row to learn
+	// whether another page follows
 	query := fmt.Sprintf(`
-		SELECT id, title, author, isbn, publisher, genre, published_at, pages, language, available, created_at, updated_at
+		SELECT %s%s
 		FROM books %s
-		ORDER BY created_at DESC
+		ORDER BY %s
 		LIMIT $%d OFFSET $%d
-	`, whereClause, argCount+1, argCount+2)
+	`, selectList(columns), order.keyColumns(), whereClause, orderBy, argCount+1, argCount+2)
 
-	args = append(args, filter.Limit, filter.Offset)
+	args = append(args, filter.Limit+1, filter.Offset)
 
-	rows, err := r.db.Query(query, args...)
+	books, err := r.queryBookList(columns, order, query, args...)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to query books: %w", err)
+		return nil, false, err
 	}
-	defer rows.Close()
 
-	for rows.Next() {
-		var book models.Book
-		err := rows.Scan(
-			&book.ID, &book.Title, &book.Author, &book.ISBN, &book.Publisher, &book.Genre,
-			&book.PublishedAt, &book.Pages, &book.Language, &book.Available, &book.CreatedAt, &book.UpdatedAt,
-		)
-		if err != nil {
-			return nil, 0, fmt.Errorf("failed to scan book: %w", err)
-		}
-		books = append(books, book)
+	hasMore := len(books) > filter.Limit
+	if hasMore {
+		books = books[:filter.Limit]
 	}
 
-	if err = rows.Err(); err != nil {
-		return nil, 0, fmt.Errorf("failed to iterate rows: %w", err)
+	if filter.Includes(models.BookIncludeCopies) {
+		if err := r.includeCopies(books); err != nil {
+			return nil, false, err
+		}
 	}
 
-	return books, total, nil
+	return books, hasMore, nil
 }
 
-// Update updates a book by its ID
-func (r *bookRepository) Update(id uuid.UUID, req *models.UpdateBookRequest) (*models.Book, error) {
-	// First, get the current book
-	currentBook, err := r.GetByID(id)
+// GetPage retrieves up to filter.Limit books following cursor in listing
+// order and reports whether more books lie beyond the page. Backward cursors
+// page towards the start of the listing; a nil cursor starts at the
+// beginning. Keyset paging stays stable when books are inserted between
+// requests, unlike offsets.
+func (r *bookRepository) GetPage(filter models.BookFilter, cursor *models.BookCursor) ([]models.Book, bool, error) {
+	order, err := parseBookSort(filter.Sort)
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 
-	// Build update query dynamically
-	var setParts []string
-	var args []interface{}
-	argCount := 0
-
-	if req.Title != nil {
-		argCount++
-		setParts = append(setParts, fmt.Sprintf("title = $%d", argCount))
-		args = append(args, *req.Title)
+	columns, err := projectBookColumns(filter.Fields)
+	if err != nil {
+		return nil, false, err
 	}
 
-	if req.Author != nil {
-		argCount++
-		setParts = append(setParts, fmt.Sprintf("author = $%d", argCount))
-		args = append(args, *req.Author)
+	whereClause, args, _, err := buildBookFilter(filter)
+	if err != nil {
+		return nil, false, err
 	}
+	argCount := len(args)
 
-	if req.ISBN != nil {
-		argCount++
-		setParts = append(setParts, fmt.Sprintf("isbn = $%d", argCount))
-		args = append(args, *req.ISBN)
+	if filter.Limit <= 0 {
+		filter.Limit = 50
 	}
 
-	if req.Publisher != nil {
-		argCount++
-		setParts = append(setParts, fmt.Sprintf("publisher = $%d", argCount))
-		args = append(args, *req.Publisher)
+	backward := cursor != nil && cursor.Backward
+	if cursor != nil {
+		condition, cursorArgs, err := order.after(cursor, argCount)
+		if err != nil {
+			return nil, false, err
+		}
+		whereClause += " AND " + condition
+		args = append(args, cursorArgs...)
+		argCount += len(cursorArgs)
 	}
 
-	if req.Genre != nil {
-		argCount++
-		setParts = append(setParts, fmt.Sprintf("genre = $%d", argCount))
-		args = append(args, *req.Genre)
-	}
+	// Fetch one extra row to learn whether another page follows
+	query := fmt.Sprintf(`
+		SELECT %s%s
+		FROM books %s
+		ORDER BY %s
+		LIMIT $%d
+	`, selectList(columns), order.keyColumns(), whereClause, order.orderBy(backward), argCount+1)
 
-	if req.PublishedAt != nil {
-		argCount++
-		setParts = append(setParts, fmt.Sprintf("published_at = $%d", argCount))
-		args = append(args, *req.PublishedAt)
-	}
+	args = append(args, filter.Limit+1)
 
-	if req.Pages != nil {
-		argCount++
-		setParts = append(setParts, fmt.Sprintf("pages = $%d", argCount))
-		args = append(args, *req.Pages)
+	books, err := r.queryBookList(columns, order, query, args...)
+	if err != nil {
+		return nil, false, err
 	}
 
-	if req.Language != nil {
-		argCount++
-		setParts = append(setParts, fmt.Sprintf("language = $%d", argCount))
-		args = append(args, *req.Language)
+	hasMore := len(books) > filter.Limit
+	if hasMore {
+		books = books[:filter.Limit]
 	}
 
-	if req.Available != nil {
-		argCount++
-		setParts = append(setParts, fmt.Sprintf("available = $%d", argCount))
-		args = append(args, *req.Available)
+	// Backward pages are read in reverse; restore listing order
+	if backward {
+		for i, j := 0, len(books)-1; i < j; i, j = i+1, j-1 {
+			books[i], books[j] = books[j], books[i]
+		}
 	}
 
-	if len(setParts) == 0 {
-		return currentBook, nil // No updates requested
+	if filter.Includes(models.BookIncludeCopies) {
+		if err := r.includeCopies(books); err != nil {
+			return nil, false, err
+		}
 	}
 
-	// Add updated_at
-	argCount++
-	setParts = append(setParts, fmt.Sprintf("updated_at = $%d", argCount))
-	args = append(args, time.Now())
+	return books, hasMore, nil
+}
 
-	// Add ID for WHERE clause
-	argCount++
-	args = append(args, id)
+// sortColumn is a sortable book field: the SQL expression ordered on and
+// the type its text form is cast back to when compared against a cursor.
+// Expressions never yield NULL so keyset comparisons hold.
+type sortColumn struct {
+	expr    string
+	sqlType string
+}
 
-	query := fmt.Sprintf(`
-		UPDATE books 
-		SET %s
-		WHERE id = $%d
-		RETURNING id, title, author, isbn, publisher, genre, published_at, pages, language, available, created_at, updated_at
-	`, strings.Join(setParts, ", "), argCount)
+// sortableColumns whitelists the fields clients may sort by. Titles sort in
+// the database collation with leading articles ignored.
+var sortableColumns = map[string]sortColumn{
+	"title":        {"book_sort_title(title)", "text"},
+	"author":       {"LOWER(author)", "text"},
+	"publisher":    {"LOWER(COALESCE(publisher, ''))", "text"},
+	"genre":        {"LOWER(COALESCE(genre, ''))", "text"},
+	"language":     {"LOWER(COALESCE(language, ''))", "text"},
+	"isbn":         {"isbn", "text"},
+	"published_at": {"COALESCE(published_at, '-infinity')", "timestamp"},
+	"pages":        {"COALESCE(pages, 0)", "integer"},
+	"created_at":   {"created_at", "timestamp"},
+	"updated_at":   {"updated_at", "timestamp"},
+}
 
-	book := &models.Book{}
-	err = r.db.QueryRow(query, args...).Scan(
-		&book.ID, &book.Title, &book.Author, &book.ISBN, &book.Publisher, &book.Genre,
-		&book.PublishedAt, &book.Pages, &book.Language, &book.Available, &book.CreatedAt, &book.UpdatedAt,
-	)
+// defaultBookSort lists the newest books first
+const defaultBookSort = "-created_at"
 
-	if err != nil {
-		return nil, fmt.Errorf("failed to update book: %w", err)
+// sortKey is one column of a listing order
+type sortKey struct {
+	column sortColumn
+	desc   bool
+}
+
+// bookOrder is a parsed listing order. Its last key is always the book ID,
+// which makes the order total so cursors have an exact position.
+type bookOrder []sortKey
+
+// parseBookSort parses a comma-separated list of sortable fields, each
+// optionally prefixed with '-' for descending order
+func parseBookSort(sort string) (bookOrder, error) {
+	if strings.TrimSpace(sort) == "" {
+		sort = defaultBookSort
 	}
 
-	return book, nil
+	var order bookOrder
+	seen := make(map[string]bool)
+	for _, field := range strings.Split(sort, ",") {
+		field = strings.TrimSpace(field)
+		desc := strings.HasPrefix(field, "-")
+		field = strings.TrimPrefix(field, "-")
+
+		column, ok := sortableColumns[field]
+		if !ok {
+			return nil, fmt.Errorf("invalid sort field: %q", field)
+		}
+		if seen[field] {
+			return nil, fmt.Errorf("invalid sort: %s is listed more than once", field)
+		}
+		seen[field] = true
+		order = append(order, sortKey{column: column, desc: desc})
+	}
+
+	// Ties are broken by ID in the direction of the last key
+	order = append(order, sortKey{column: sortColumn{"id", "uuid"}, desc: order[len(order)-1].desc})
+	return order, nil
 }
 
-// Delete deletes a book by its ID
-func (r *bookRepository) Delete(id uuid.UUID) error {
-	query := "DELETE FROM books WHERE id = $1"
-	result, err := r.db.Exec(query, id)
-	if err != nil {
-		return fmt.Errorf("failed to delete book: %w", err)
+// orderBy renders the ORDER BY list, reversed for backward pages
+func (o bookOrder) orderBy(reverse bool) string {
+	parts := make([]string, len(o))
+	for i, key := range o {
+		direction := "ASC"
+		if key.desc != reverse {
+			direction = "DESC"
+		}
+		parts[i] = key.column.expr + " " + direction
 	}
+	return strings.Join(parts, ", ")
+}
 
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %w", err)
+// keyColumns selects the text form of each sort value except the ID
+func (o bookOrder) keyColumns() string {
+	var columns strings.Builder
+	for i, key := range o[:len(o)-1] {
+		fmt.Fprintf(&columns, ",\n\t\t\t(%s)::text AS sort_key_%d", key.column.expr, i)
 	}
+	return columns.String()
+}
 
-	if rowsAffected == 0 {
-		return fmt.Errorf("book not found")
+// after builds the condition selecting the rows past cursor in its direction
+// of travel, with placeholders numbered from argCount+1
+func (o bookOrder) after(cursor *models.BookCursor, argCount int) (string, []interface{}, error) {
+	if len(cursor.Values) != len(o)-1 {
+		return "", nil, fmt.Errorf("invalid cursor: it does not match the sort order")
 	}
 
-	return nil
-}
+	args := make([]interface{}, 0, len(o))
+	placeholders := make([]string, len(o))
+	for i, key := range o {
+		argCount++
+		placeholders[i] = fmt.Sprintf("$%d::%s", argCount, key.column.sqlType)
+		if i < len(cursor.Values) {
+			args = append(args, cursor.Values[i])
+		} else {
+			args = append(args, cursor.ID)
+		}
+	}
 
-// ExistsByISBN checks if a book with the given ISBN exists
-func (r *bookRepository) ExistsByISBN(isbn string, excludeID *uuid.UUID) (bool, error) {
-	var count int
-	query := "SELECT COUNT(*) FROM books WHERE isbn = $1"
-	args := []interface{}{isbn}
+	// A row is past the cursor when it matches the cursor on a prefix of the
+	// keys and lies beyond it on the next key
+	comparison := func(key sortKey) string {
+		if key.desc != cursor.Backward {
+			return "<"
+		}
+		return ">"
+	}
 
-	if excludeID != nil {
-		query += " AND id != $2"
-		args = append(args, *excludeID)
+	// Keys sharing one direction compare as a single row value, which the
+	// database can serve from an index
+	uniform := true
+	for _, key := range o[1:] {
+		if key.desc != o[0].desc {
+			uniform = false
+		}
+	}
+	if uniform {
+		exprs := make([]string, len(o))
+		for i, key := range o {
+			exprs[i] = key.column.expr
+		}
+		condition := fmt.Sprintf("(%s) %s (%s)", strings.Join(exprs, ", "), comparison(o[0]), strings.Join(placeholders, ", "))
+		return condition, args, nil
 	}
 
-	err := r.db.QueryRow(query, args...).Scan(&count)
+	var alternatives []string
+	for i, key := range o {
+		var terms []string
+		for j := 0; j < i; j++ {
+			terms = append(terms, fmt.Sprintf("%s = %s", o[j].column.expr, placeholders[j]))
+		}
+		terms = append(terms, fmt.Sprintf("%s %s %s", key.column.expr, comparison(key), placeholders[i]))
+		alternatives = append(alternatives, "("+strings.Join(terms, " AND ")+")")
+	}
+	return "(" + strings.Join(alternatives, " OR ") + ")", args, nil
+}
+
+// Count returns the number of books matching filter
+func (r *bookRepository) Count(filter models.BookFilter) (int, error) {
+	whereClause, args, _, err := buildBookFilter(filter)
 	if err != nil {
-		return false, fmt.Errorf("failed to check ISBN existence: %w", err)
+		return 0, err
 	}
 
-	return count > 0, nil
+	var total int
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM books %s", whereClause)
+	if err := r.db.QueryRow(countQuery, args...).Scan(&total); err != nil {
+		return 0, fmt.Errorf("failed to count books: %w", err)
+	}
+
+	return total, nil
+}
+
+// queryBookList runs a query selecting columns followed by the order's key
+// columns
+func (r *bookRepository) queryBookList(columns []bookColumn, order bookOrder, query string, args ...interface{}) ([]models.Book, error) {
+	books := make([]models.Book, 0) // Initialize as empty slice, not nil slice
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query books: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var book models.Book
+		book.SortKey = make([]string, len(order)-1)
+		dest := scanDest(columns, &book)
+		for i := range book.SortKey {
+			dest = append(dest, &book.SortKey[i])
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("failed to scan book: %w", err)
+		}
+		books = append(books, book)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate rows: %w", err)
+	}
+
+	return books, nil
+}
+
+// buildBookFilter builds the WHERE clause and arguments shared by listing and
+// facet queries. Trashed books are always left out. In fuzzy mode author and
+// genre match by trigram word similarity and the returned scores let callers
+// order by closeness. A catalog query in filter.Query is parsed and compiled
+// alongside the other filters.
+func buildBookFilter(filter models.BookFilter) (whereClause string, args []interface{}, similarityScores []string, err error) {
+	whereConditions := []string{"deleted_at IS NULL"}
+	argCount := 0
+
+	thresholdArg := 0
+	if filter.Fuzzy && (filter.Author != "" || filter.Genre != "") {
+		argCount++
+		thresholdArg = argCount
+		args = append(args, filter.Similarity)
+	}
+
+	fuzzyColumns := []struct{ column, term string }{
+		{"author", filter.Author},
+		{"genre", filter.Genre},
+	}
+	for _, c := range fuzzyColumns {
+		if c.term == "" {
+			continue
+		}
+		argCount++
+		if filter.Fuzzy {
+			score := fmt.Sprintf("word_similarity(LOWER($%d), LOWER(COALESCE(%s, '')))", argCount, c.column)
+			whereConditions = append(whereConditions, fmt.Sprintf("%s >= $%d", score, thresholdArg))
+			similarityScores = append(similarityScores, score)
+			args = append(args, c.term)
+		} else {
+			whereConditions = append(whereConditions, fmt.Sprintf("LOWER(%s) LIKE LOWER($%d)", c.column, argCount))
+			args = append(args, "%"+c.term+"%")
+		}
+	}
+
+	if filter.Language != "" {
+		argCount++
+		whereConditions = append(whereConditions, fmt.Sprintf("LOWER(language) = LOWER($%d)", argCount))
+		args = append(args, filter.Language)
+	}
+
+	if filter.Available != nil {
+		argCount++
+		whereConditions = append(whereConditions, fmt.Sprintf("available = $%d", argCount))
+		args = append(args, *filter.Available)
+	}
+
+	if filter.Publisher != "" {
+		argCount++
+		whereConditions = append(whereConditions, fmt.Sprintf("LOWER(publisher) LIKE LOWER($%d)", argCount))
+		args = append(args, "%"+filter.Publisher+"%")
+	}
+
+	if filter.ISBN != "" {
+		argCount++
+		whereConditions = append(whereConditions, fmt.Sprintf("isbn = $%d", argCount))
+		args = append(args, filter.ISBN)
+	}
+
+	// Value sets compare case-insensitively against the listed values
+	valueSets := []struct {
+		column  string
+		values  []string
+		exclude bool
+	}{
+		{"genre", filter.Genres, false},
+		{"language", filter.Languages, false},
+		{"genre", filter.ExcludeGenres, true},
+		{"language", filter.ExcludeLanguages, true},
+	}
+	for _, set := range valueSets {
+		if len(set.values) == 0 {
+			continue
+		}
+		placeholders := make([]string, len(set.values))
+		for i, value := range set.values {
+			argCount++
+			placeholders[i] = fmt.Sprintf("LOWER($%d)", argCount)
+			args = append(args, value)
+		}
+		if set.exclude {
+			// Books without a value are not excluded
+			whereConditions = append(whereConditions, fmt.Sprintf("(%[1]s IS NULL OR LOWER(%[1]s) NOT IN (%[2]s))", set.column, strings.Join(placeholders, ", ")))
+		} else {
+			whereConditions = append(whereConditions, fmt.Sprintf("LOWER(%s) IN (%s)", set.column, strings.Join(placeholders, ", ")))
+		}
+	}
+
+	substringExclusions := []struct {
+		column string
+		values []string
+	}{
+		{"author", filter.ExcludeAuthors},
+		{"publisher", filter.ExcludePublishers},
+	}
+	for _, exclusion := range substringExclusions {
+		for _, value := range exclusion.values {
+			argCount++
+			whereConditions = append(whereConditions, fmt.Sprintf("(%[1]s IS NULL OR LOWER(%[1]s) NOT LIKE LOWER($%[2]d))", exclusion.column, argCount))
+			args = append(args, "%"+value+"%")
+		}
+	}
+
+	// Range bounds are inclusive
+	addBound := func(condition string, bound interface{}) {
+		argCount++
+		whereConditions = append(whereConditions, fmt.Sprintf(condition, argCount))
+		args = append(args, bound)
+	}
+	if filter.PublishedFrom != nil {
+		addBound("published_at >= $%d", *filter.PublishedFrom)
+	}
+	if filter.PublishedTo != nil {
+		addBound("published_at <= $%d", *filter.PublishedTo)
+	}
+	if filter.MinPages != nil {
+		addBound("pages >= $%d", *filter.MinPages)
+	}
+	if filter.MaxPages != nil {
+		addBound("pages <= $%d", *filter.MaxPages)
+	}
+	if filter.CreatedSince != nil {
+		addBound("created_at >= $%d", *filter.CreatedSince)
+	}
+	if filter.CreatedUntil != nil {
+		addBound("created_at <= $%d", *filter.CreatedUntil)
+	}
+	if filter.UpdatedSince != nil {
+		addBound("updated_at >= $%d", *filter.UpdatedSince)
+	}
+	if filter.UpdatedUntil != nil {
+		addBound("updated_at <= $%d", *filter.UpdatedUntil)
+	}
+
+	if filter.Query != "" {
+		expr, err := query.Parse(filter.Query)
+		if err != nil {
+			return "", nil, nil, err
+		}
+		whereConditions = append(whereConditions, compileQuery(expr, &args))
+	}
+
+	whereClause = "WHERE " + strings.Join(whereConditions, " AND ")
+	return whereClause, args, similarityScores, nil
+}
+
+// compileQuery translates a parsed catalog query into a SQL condition. Field
+// columns come from the query package's whitelist and every value is bound
+// as an argument appended to args.
+func compileQuery(expr query.Expr, args *[]interface{}) string {
+	bind := func(value interface{}) string {
+		*args = append(*args, value)
+		return fmt.Sprintf("$%d", len(*args))
+	}
+
+	switch e := expr.(type) {
+	case *query.And:
+		return "(" + compileQueryTerms(e.Terms, " AND ", args) + ")"
+	case *query.Or:
+		return "(" + compileQueryTerms(e.Terms, " OR ", args) + ")"
+	case *query.Not:
+		// Comparisons against NULL columns yield NULL; treat them as not
+		// matching so negations include books without a value
+		return fmt.Sprintf("NOT COALESCE(%s, FALSE)", compileQuery(e.Term, args))
+	case *query.Text:
+		placeholder := bind("%" + escapeLike(e.Text) + "%")
+		return fmt.Sprintf("(LOWER(title) LIKE LOWER(%[1]s) OR LOWER(author) LIKE LOWER(%[1]s))", placeholder)
+	case *query.Range:
+		var bounds []string
+		if e.From != nil {
+			bounds = append(bounds, fmt.Sprintf("%s >= %s", e.Field.Column, bind(rangeStart(e.Field, *e.From))))
+		}
+		if e.To != nil {
+			if e.Field.Kind == query.KindDate {
+				bounds = append(bounds, fmt.Sprintf("%s < %s", e.Field.Column, bind(e.To.End)))
+			} else {
+				bounds = append(bounds, fmt.Sprintf("%s <= %s", e.Field.Column, bind(e.To.Number)))
+			}
+		}
+		return "(" + strings.Join(bounds, " AND ") + ")"
+	case *query.Comparison:
+		return compileComparison(e, bind)
+	}
+	return "FALSE"
+}
+
+func compileQueryTerms(terms []query.Expr, sep string, args *[]interface{}) string {
+	compiled := make([]string, len(terms))
+	for i, term := range terms {
+		compiled[i] = compileQuery(term, args)
+	}
+	return strings.Join(compiled, sep)
+}
+
+// rangeStart is the bound value a range or comparison starts at
+func rangeStart(field query.Field, value query.Value) interface{} {
+	if field.Kind == query.KindDate {
+		return value.Start
+	}
+	return value.Number
+}
+
+// compileComparison compiles a single field comparison. Dates cover periods,
+// so equality means falling within the period.
+func compileComparison(c *query.Comparison, bind func(interface{}) string) string {
+	column := c.Field.Column
+	switch c.Field.Kind {
+	case query.KindText:
+		if c.Op == query.OpEqual {
+			return fmt.Sprintf("LOWER(%s) = LOWER(%s)", column, bind(c.Value.Text))
+		}
+		return fmt.Sprintf("LOWER(%s) LIKE LOWER(%s)", column, bind("%"+escapeLike(c.Value.Text)+"%"))
+	case query.KindExact:
+		if column == "isbn" {
+			// ISBNs are stored canonically, so the unique index serves the lookup
+			return fmt.Sprintf("isbn = %s", bind(c.Value.Text))
+		}
+		return fmt.Sprintf("LOWER(%s) = LOWER(%s)", column, bind(c.Value.Text))
+	case query.KindBool:
+		return fmt.Sprintf("%s = %s", column, bind(c.Value.Bool))
+	case query.KindNumber:
+		op := string(c.Op)
+		if c.Op == query.OpMatch {
+			op = "="
+		}
+		return fmt.Sprintf("%s %s %s", column, op, bind(c.Value.Number))
+	case query.KindDate:
+		switch c.Op {
+		case query.OpGt:
+			return fmt.Sprintf("%s >= %s", column, bind(c.Value.End))
+		case query.OpGte:
+			return fmt.Sprintf("%s >= %s", column, bind(c.Value.Start))
+		case query.OpLt:
+			return fmt.Sprintf("%s < %s", column, bind(c.Value.Start))
+		case query.OpLte:
+			return fmt.Sprintf("%s < %s", column, bind(c.Value.End))
+		}
+		return fmt.Sprintf("(%[1]s >= %[2]s AND %[1]s < %[3]s)", column, bind(c.Value.Start), bind(c.Value.End))
+	}
+	return "FALSE"
+}
+
+// maxFacetValues caps how many values are returned per facet
+const maxFacetValues = 20
+
+// GetFacets counts the books matching filter per genre, language, publisher,
+// author, availability and publication decade. All facets are aggregated in
+// a single grouping-sets query; each keeps its most frequent values.
+func (r *bookRepository) GetFacets(filter models.BookFilter) (*models.BookFacets, error) {
+	whereClause, args, _, err := buildBookFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// Within each grouping set the other columns are NULL, so COALESCE yields
+	// the value of the column being grouped
+	query := fmt.Sprintf(`
+		SELECT facet, value, count FROM (
+			SELECT facet, value, count,
+				ROW_NUMBER() OVER (PARTITION BY facet ORDER BY count DESC, value) AS position
+			FROM (
+				SELECT
+					CASE
+						WHEN GROUPING(genre) = 0 THEN 'genre'
+						WHEN GROUPING(language) = 0 THEN 'language'
+						WHEN GROUPING(publisher) = 0 THEN 'publisher'
+						WHEN GROUPING(author) = 0 THEN 'author'
+						WHEN GROUPING(available) = 0 THEN 'availability'
+						ELSE 'decade'
+					END AS facet,
+					COALESCE(genre, language, publisher, author, available::text, decade::text) AS value,
+					COUNT(*) AS count
+				FROM (
+					SELECT genre, language, publisher, author, available,
+						(EXTRACT(YEAR FROM published_at)::int / 10 * 10) AS decade
+					FROM books %s
+				) AS filtered
+				GROUP BY GROUPING SETS ((genre), (language), (publisher), (author), (available), (decade))
+			) AS grouped
+			WHERE value IS NOT NULL AND value <> ''
+		) AS ranked
+		WHERE position <= %d
+		ORDER BY facet, count DESC, value
+	`, whereClause, maxFacetValues)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query facets: %w", err)
+	}
+	defer rows.Close()
+
+	facets := models.NewBookFacets()
+	for rows.Next() {
+		var facet string
+		var count models.FacetCount
+		if err := rows.Scan(&facet, &count.Value, &count.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan facet: %w", err)
+		}
+		facets.Add(facet, count)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate rows: %w", err)
+	}
+
+	return facets, nil
+}
+
+// Update updates a book by its ID and records the change in the audit trail
+func (r *bookRepository) Update(id uuid.UUID, req *models.UpdateBookRequest, audit models.AuditInfo) (*models.Book, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// First, get the current book, which the audit entry records as before
+	currentBook, err := lockBook(tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Build update query dynamically
+	var setParts []string
+	var args []interface{}
+	argCount := 0
+
+	if req.Title != nil {
+		argCount++
+		setParts = append(setParts, fmt.Sprintf("title = $%d", argCount))
+		args = append(args, *req.Title)
+	}
+
+	if req.Author != nil {
+		argCount++
+		setParts = append(setParts, fmt.Sprintf("author = $%d", argCount))
+		args = append(args, *req.Author)
+	}
+
+	if req.ISBN != nil {
+		argCount++
+		setParts = append(setParts, fmt.Sprintf("isbn = $%d", argCount))
+		args = append(args, *req.ISBN)
+	}
+
+	if req.Publisher != nil {
+		argCount++
+		setParts = append(setParts, fmt.Sprintf("publisher = $%d", argCount))
+		args = append(args, *req.Publisher)
+	}
+
+	if req.Genre != nil {
+		argCount++
+		setParts = append(setParts, fmt.Sprintf("genre = $%d", argCount))
+		args = append(args, *req.Genre)
+	}
+
+	if req.PublishedAt != nil {
+		argCount++
+		setParts = append(setParts, fmt.Sprintf("published_at = $%d", argCount))
+		args = append(args, *req.PublishedAt)
+	}
+
+	if req.Pages != nil {
+		argCount++
+		setParts = append(setParts, fmt.Sprintf("pages = $%d", argCount))
+		args = append(args, *req.Pages)
+	}
+
+	if req.Language != nil {
+		argCount++
+		setParts = append(setParts, fmt.Sprintf("language = $%d", argCount))
+		args = append(args, *req.Language)
+	}
+
+	if len(setParts) == 0 {
+		if req.Version != nil && *req.Version != currentBook.Version {
+			return nil, ErrVersionConflict
+		}
+		return currentBook, nil // No updates requested
+	}
+
+	// Add updated_at
+	argCount++
+	setParts = append(setParts, fmt.Sprintf("updated_at = $%d", argCount))
+	args = append(args, time.Now())
+
+	// Add ID for WHERE clause
+	argCount++
+	args = append(args, id)
+	where := fmt.Sprintf("id = $%d", argCount)
+
+	// A versioned update only applies to the version the client read
+	if req.Version != nil {
+		argCount++
+		args = append(args, *req.Version)
+		where += fmt.Sprintf(" AND version = $%d", argCount)
+	}
+
+	query := fmt.Sprintf(`
+		UPDATE books 
+		SET %s
+		WHERE %s
+		RETURNING %s
+	`, strings.Join(setParts, ", "), where, bookRowColumns)
+
+	book := &models.Book{}
+	err = scanBookRow(tx.QueryRow(query, args...), book)
+
+	if err != nil {
+		if err == sql.ErrNoRows && req.Version != nil {
+			return nil, ErrVersionConflict
+		}
+		return nil, fmt.Errorf("failed to update book: %w", err)
+	}
+
+	if err = recordAudit(tx, models.AuditUpdate, audit, currentBook, book); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit book update: %w", err)
+	}
+
+	return book, nil
+}
+
+// Delete moves a book to the trash and records it in the audit trail. A book
+// out on loan cannot be deleted; its waiting and ready holds are cancelled.
+// A non-nil version makes the delete conditional: ErrVersionConflict is
+// returned if the book has been written since that version.
+func (r *bookRepository) Delete(id uuid.UUID, version *int, audit models.AuditInfo) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	book, err := lockBook(tx, id)
+	if err != nil {
+		return err
+	}
+
+	var onLoan bool
+	err = tx.QueryRow("SELECT EXISTS (SELECT 1 FROM loans WHERE book_id = $1 AND returned_at IS NULL)", id).Scan(&onLoan)
+	if err != nil {
+		return fmt.Errorf("failed to check open loans: %w", err)
+	}
+	if onLoan {
+		return fmt.Errorf("book is still checked out and cannot be deleted")
+	}
+
+	if _, err = tx.Exec("UPDATE holds SET status = 'cancelled' WHERE book_id = $1 AND status IN ('waiting', 'ready')", id); err != nil {
+		return fmt.Errorf("failed to cancel holds: %w", err)
+	}
+
+	// With no loan and no ready hold left the book is on the shelf if restored,
+	// provided it has a copy to lend
+	query := "UPDATE books SET deleted_at = $1, available = " + bookAvailableExpr + " WHERE id = $2"
+	args := []interface{}{time.Now(), id}
+	if version != nil {
+		query += " AND version = $3"
+		args = append(args, *version)
+	}
+
+	result, err := tx.Exec(query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to delete book: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 && version != nil {
+		return ErrVersionConflict
+	}
+
+	if err = recordAudit(tx, models.AuditDelete, audit, book, nil); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit book deletion: %w", err)
+	}
+
+	return nil
+}
+
+// GetTrash lists trashed books, most recently deleted first, with the total
+// number in the trash
+func (r *bookRepository) GetTrash(limit, offset int) ([]models.Book, int, error) {
+	var total int
+	if err := r.db.QueryRow("SELECT COUNT(*) FROM books WHERE deleted_at IS NOT NULL").Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count trashed books: %w", err)
+	}
+
+	query := `
+		SELECT id, title, author, isbn, publisher, genre, published_at, pages, language, available, created_at, updated_at, version, deleted_at
+		FROM books
+		WHERE deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC, id DESC
+		LIMIT $1 OFFSET $2
+	`
+
+	rows, err := r.db.Query(query, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get trashed books: %w", err)
+	}
+	defer rows.Close()
+
+	books := make([]models.Book, 0)
+	for rows.Next() {
+		var book models.Book
+		err := rows.Scan(
+			&book.ID, &book.Title, &book.Author, &book.ISBN, &book.Publisher, &book.Genre,
+			&book.PublishedAt, &book.Pages, &book.Language, &book.Available, &book.CreatedAt, &book.UpdatedAt,
+			&book.Version, &book.DeletedAt,
+		)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan trashed book: %w", err)
+		}
+		books = append(books, book)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to iterate rows: %w", err)
+	}
+
+	return books, total, nil
+}
+
+// Restore takes a book out of the trash inside a transaction and records it
+// in the audit trail. It fails if a live book has taken the ISBN since the
+// book was deleted.
+func (r *bookRepository) Restore(id uuid.UUID, audit models.AuditInfo) (*models.Book, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var isbn string
+	err = tx.QueryRow("SELECT isbn FROM books WHERE id = $1 AND deleted_at IS NOT NULL FOR UPDATE", id).Scan(&isbn)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("book not found in trash")
+		}
+		return nil, fmt.Errorf("failed to lock book: %w", err)
+	}
+
+	var taken bool
+	err = tx.QueryRow("SELECT EXISTS (SELECT 1 FROM books WHERE isbn = $1 AND deleted_at IS NULL)", isbn).Scan(&taken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check ISBN uniqueness: %w", err)
+	}
+	if taken {
+		return nil, fmt.Errorf("book with ISBN %s already exists", isbn)
+	}
+
+	book := &models.Book{}
+	err = scanBookRow(tx.QueryRow("UPDATE books SET deleted_at = NULL WHERE id = $1 RETURNING "+bookRowColumns, id), book)
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore book: %w", err)
+	}
+
+	if err = recordAudit(tx, models.AuditRestore, audit, nil, book); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit restore: %w", err)
+	}
+
+	return book, nil
+}
+
+// Purge permanently removes books trashed before deletedBefore, with their
+// copies and holds, and returns how many were removed. Their loans are kept,
+// unlinked from the book but with the title and ISBN lent. Each removal is
+// recorded in the audit trail as made by the system.
+func (r *bookRepository) Purge(deletedBefore time.Time) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(
+		"DELETE FROM books WHERE deleted_at IS NOT NULL AND deleted_at < $1 RETURNING "+bookRowColumns,
+		deletedBefore,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge trashed books: %w", err)
+	}
+
+	var purged []models.Book
+	for rows.Next() {
+		var book models.Book
+		if err := scanBookRow(rows, &book); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan purged book: %w", err)
+		}
+		purged = append(purged, book)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to iterate rows: %w", err)
+	}
+
+	audit := models.AuditInfo{Actor: models.SystemActor}
+	for i := range purged {
+		if err = recordAudit(tx, models.AuditPurge, audit, &purged[i], nil); err != nil {
+			return 0, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit purge: %w", err)
+	}
+
+	return len(purged), nil
+}
+
+// ExistsByISBN checks if a live book with the given ISBN exists; trashed
+// books do not hold on to their ISBNs
+func (r *bookRepository) ExistsByISBN(isbn string, excludeID *uuid.UUID) (bool, error) {
+	var count int
+	query := "SELECT COUNT(*) FROM books WHERE isbn = $1 AND deleted_at IS NULL"
+	args := []interface{}{isbn}
+
+	if excludeID != nil {
+		query += " AND id != $2"
+		args = append(args, *excludeID)
+	}
+
+	err := r.db.QueryRow(query, args...).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("failed to check ISBN existence: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+// searchConfigs are the text search configurations book_search_config maps
+// languages to; the two lists must be kept in step
+var searchConfigs = []string{
+	"danish", "dutch", "english", "finnish", "french", "german", "hungarian", "italian",
+	"norwegian", "portuguese", "romanian", "russian", "spanish", "swedish", "turkish", "simple",
+}
+
+// searchConfig mirrors book_search_config for a single language
+func searchConfig(language string) string {
+	language = strings.ToLower(strings.TrimSpace(language))
+	for _, cfg := range searchConfigs {
+		if cfg == language {
+			return cfg
+		}
+	}
+	return "simple"
+}
+
+// searchMatchCondition matches books against the query in $1. The query is
+// parsed with a constant configuration in each arm, which the planner can
+// look up in idx_books_search_vector, and each arm keeps only the books
+// indexed with that configuration.
+func searchMatchCondition(configs []string) string {
+	arms := make([]string, len(configs))
+	for i, cfg := range configs {
+		arms[i] = fmt.Sprintf(
+			"(search_vector @@ websearch_to_tsquery('%[1]s'::regconfig, $1) AND book_search_config(language) = '%[1]s'::regconfig)",
+			cfg,
+		)
+	}
+	return "(" + strings.Join(arms, " OR ") + ")"
+}
+
+// Search runs a ranked full-text search over title, author, publisher and
+// genre. The query is parsed with each book's own language configuration so
+// stemming matches the way the book was indexed.
+func (r *bookRepository) Search(filter models.BookSearchFilter) ([]models.BookSearchResult, int, error) {
+	results := make([]models.BookSearchResult, 0)
+	var total int
+
+	configs := searchConfigs
+	if filter.Language != "" {
+		configs = []string{searchConfig(filter.Language)}
+	}
+
+	whereClause := "WHERE " + searchMatchCondition(configs) + " AND deleted_at IS NULL"
+	args := []interface{}{filter.Query}
+	argCount := 1
+
+	if filter.Language != "" {
+		argCount++
+		whereClause += fmt.Sprintf(" AND LOWER(language) = LOWER($%d)", argCount)
+		args = append(args, filter.Language)
+	}
+
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM books %s", whereClause)
+	if err := r.db.QueryRow(countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count search results: %w", err)
+	}
+
+	if filter.Limit <= 0 {
+		filter.Limit = 50
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, title, author, isbn, publisher, genre, published_at, pages, language, available, created_at, updated_at, version,
+			ts_rank_cd(search_vector, websearch_to_tsquery(book_search_config(language), $1), 32) AS rank,
+			ts_headline(book_search_config(language), concat_ws(' - ', title, author, publisher, genre),
+				websearch_to_tsquery(book_search_config(language), $1),
+				'StartSel=<mark>, StopSel=</mark>, MaxWords=35, MinWords=15') AS headline
+		FROM books %s
+		ORDER BY rank DESC, title ASC
+		LIMIT $%d OFFSET $%d
+	`, whereClause, argCount+1, argCount+2)
+
+	args = append(args, filter.Limit, filter.Offset)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to search books: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var result models.BookSearchResult
+		book := &result.Book
+		err := rows.Scan(
+			&book.ID, &book.Title, &book.Author, &book.ISBN, &book.Publisher, &book.Genre,
+			&book.PublishedAt, &book.Pages, &book.Language, &book.Available, &book.CreatedAt, &book.UpdatedAt,
+			&book.Version, &result.Rank, &result.Headline,
+		)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan search result: %w", err)
+		}
+		results = append(results, result)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to iterate rows: %w", err)
+	}
+
+	return results, total, nil
+}
+
+// suggestableFields are the book columns SuggestSimilar may search
+var suggestableFields = map[string]bool{"author": true, "genre": true}
+
+// SuggestSimilar returns the stored value of field closest to term by trigram
+// word similarity, or "" when nothing reaches the threshold
+func (r *bookRepository) SuggestSimilar(field, term string, threshold float64) (string, error) {
+	if !suggestableFields[field] {
+		return "", fmt.Errorf("invalid suggestion field: %s", field)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %[1]s
+		FROM books
+		WHERE %[1]s IS NOT NULL AND deleted_at IS NULL AND word_similarity(LOWER($1), LOWER(%[1]s)) >= $2
+		GROUP BY %[1]s
+		ORDER BY MAX(word_similarity(LOWER($1), LOWER(%[1]s))) DESC, COUNT(*) DESC
+		LIMIT 1
+	`, field)
+
+	var suggestion string
+	err := r.db.QueryRow(query, term, threshold).Scan(&suggestion)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to suggest %s: %w", field, err)
+	}
+
+	return suggestion, nil
+}
+
+// SuggestByPrefix returns up to limit distinct values of field starting with
+// prefix, ignoring case, the ones carried by the most books first. The match
+// is served by the field's prefix index.
+func (r *bookRepository) SuggestByPrefix(field models.SuggestField, prefix string, limit int) ([]string, error) {
+	if !field.IsValid() {
+		return nil, fmt.Errorf("invalid suggestion field: %s", field)
+	}
+
+	// Ties are broken byte-wise, as the cache does
+	query := fmt.Sprintf(`
+		SELECT %[1]s
+		FROM books
+		WHERE LOWER(%[1]s) LIKE $1 AND deleted_at IS NULL
+		GROUP BY %[1]s
+		ORDER BY COUNT(*) DESC, LOWER(%[1]s) COLLATE "C", %[1]s COLLATE "C"
+		LIMIT $2
+	`, field)
+
+	rows, err := r.db.Query(query, escapeLike(strings.ToLower(prefix))+"%", limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to suggest %s: %w", field, err)
+	}
+	defer rows.Close()
+
+	suggestions := make([]string, 0, limit)
+	for rows.Next() {
+		var suggestion string
+		if err := rows.Scan(&suggestion); err != nil {
+			return nil, fmt.Errorf("failed to scan suggestion: %w", err)
+		}
+		suggestions = append(suggestions, suggestion)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate rows: %w", err)
+	}
+
+	return suggestions, nil
+}
+
+// SuggestionCounts returns every non-empty value of field with the number of
+// books carrying it, for seeding the suggestion cache
+func (r *bookRepository) SuggestionCounts(field models.SuggestField) (map[string]int, error) {
+	if !field.IsValid() {
+		return nil, fmt.Errorf("invalid suggestion field: %s", field)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %[1]s, COUNT(*)
+		FROM books
+		WHERE %[1]s IS NOT NULL AND %[1]s <> '' AND deleted_at IS NULL
+		GROUP BY %[1]s
+	`, field)
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count %s suggestions: %w", field, err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var value string
+		var count int
+		if err := rows.Scan(&value, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan suggestion count: %w", err)
+		}
+		counts[value] = count
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate rows: %w", err)
+	}
+
+	return counts, nil
+}
+
+// likeEscaper escapes the LIKE wildcards and Postgres' default escape character
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// escapeLike makes value match literally inside a LIKE pattern
+func escapeLike(value string) string {
+	return likeEscaper.Replace(value)
 }
@@ -0,0 +1,209 @@
+package repository
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"libmngmt/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const bookAuditColumns = "id, book_id, action, revision, actor, request_id, before, after, changes, changed_at"
+
+// recordAudit appends a write to the book audit trail. It must be called in
+// the transaction making the write so the two commit or roll back together.
+// before is nil for creates and restores, after for deletes and purges.
+// Writes with an after snapshot are numbered as the book's next revision;
+// callers hold the book's row lock, so the numbering cannot race.
+func recordAudit(tx *sql.Tx, action models.AuditAction, audit models.AuditInfo, before, after *models.Book) error {
+	var bookID uuid.UUID
+	var beforeSnapshot, afterSnapshot *models.BookSnapshot
+	if before != nil {
+		bookID = before.ID
+		beforeSnapshot = models.NewBookSnapshot(before)
+	}
+	if after != nil {
+		bookID = after.ID
+		afterSnapshot = models.NewBookSnapshot(after)
+	}
+
+	beforeJSON, err := snapshotJSON(beforeSnapshot)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit snapshot: %w", err)
+	}
+	afterJSON, err := snapshotJSON(afterSnapshot)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit snapshot: %w", err)
+	}
+	changes, err := auditChanges(beforeSnapshot, afterSnapshot)
+	if err != nil {
+		return fmt.Errorf("failed to diff audit snapshots: %w", err)
+	}
+	changesJSON, err := json.Marshal(changes)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit changes: %w", err)
+	}
+
+	_, err = tx.Exec(
+		`INSERT INTO book_audit (book_id, action, actor, request_id, before, after, changes, revision)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, CASE WHEN $6::jsonb IS NULL THEN NULL
+			ELSE (SELECT COALESCE(MAX(revision), 0) + 1 FROM book_audit WHERE book_id = $1) END)`,
+		bookID, action, audit.Actor, audit.RequestID, beforeJSON, afterJSON, string(changesJSON),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record audit entry: %w", err)
+	}
+	return nil
+}
+
+// snapshotJSON encodes a snapshot as a JSONB parameter, NULL when absent
+func snapshotJSON(snapshot *models.BookSnapshot) (interface{}, error) {
+	if snapshot == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// auditChanges lists the fields whose JSON value differs between two
+// snapshots. Against a missing snapshot every field has changed.
+func auditChanges(before, after *models.BookSnapshot) (map[string]models.FieldChange, error) {
+	from, err := snapshotFields(before)
+	if err != nil {
+		return nil, err
+	}
+	to, err := snapshotFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(map[string]models.FieldChange)
+	for name, value := range from {
+		if !bytes.Equal(value, to[name]) {
+			changes[name] = models.FieldChange{From: value, To: to[name]}
+		}
+	}
+	for name, value := range to {
+		if _, ok := from[name]; !ok {
+			changes[name] = models.FieldChange{To: value}
+		}
+	}
+	return changes, nil
+}
+
+// snapshotFields splits a snapshot into its JSON members
+func snapshotFields(snapshot *models.BookSnapshot) (map[string]json.RawMessage, error) {
+	if snapshot == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// auditScanner is satisfied by both *sql.Row and *sql.Rows
+type auditScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanAuditEntry(row auditScanner, entry *models.BookAuditEntry) error {
+	var before, after, changes []byte
+	err := row.Scan(
+		&entry.ID, &entry.BookID, &entry.Action, &entry.Revision, &entry.Actor, &entry.RequestID,
+		&before, &after, &changes, &entry.ChangedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	if before != nil {
+		if err := json.Unmarshal(before, &entry.Before); err != nil {
+			return fmt.Errorf("failed to decode audit snapshot: %w", err)
+		}
+	}
+	if after != nil {
+		if err := json.Unmarshal(after, &entry.After); err != nil {
+			return fmt.Errorf("failed to decode audit snapshot: %w", err)
+		}
+	}
+	if err := json.Unmarshal(changes, &entry.Changes); err != nil {
+		return fmt.Errorf("failed to decode audit changes: %w", err)
+	}
+	return nil
+}
+
+// GetHistory lists a book's audit trail, oldest first. Trashed and purged
+// books keep their history.
+func (r *bookRepository) GetHistory(id uuid.UUID) ([]models.BookAuditEntry, error) {
+	rows, err := r.db.Query(
+		"SELECT "+bookAuditColumns+" FROM book_audit WHERE book_id = $1 ORDER BY changed_at, id",
+		id,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get book history: %w", err)
+	}
+	defer rows.Close()
+
+	entries := make([]models.BookAuditEntry, 0)
+	for rows.Next() {
+		var entry models.BookAuditEntry
+		if err := scanAuditEntry(rows, &entry); err != nil {
+			return nil, fmt.Errorf("failed to scan audit entry: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate rows: %w", err)
+	}
+
+	return entries, nil
+}
+
+// GetAsOf returns the last audit entry for a book written at or before at,
+// whose After snapshot is the book as it stood then
+func (r *bookRepository) GetAsOf(id uuid.UUID, at time.Time) (*models.BookAuditEntry, error) {
+	var entry models.BookAuditEntry
+	err := scanAuditEntry(r.db.QueryRow(
+		"SELECT "+bookAuditColumns+" FROM book_audit WHERE book_id = $1 AND changed_at <= $2 ORDER BY changed_at DESC, id DESC LIMIT 1",
+		id, at,
+	), &entry)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("book not found as of %s", at.Format(time.RFC3339))
+		}
+		return nil, fmt.Errorf("failed to get book as of %s: %w", at.Format(time.RFC3339), err)
+	}
+
+	return &entry, nil
+}
+
+// GetRevision returns the audit entry that produced a numbered revision of a
+// book, whose After snapshot is the book at that revision
+func (r *bookRepository) GetRevision(id uuid.UUID, revision int) (*models.BookAuditEntry, error) {
+	var entry models.BookAuditEntry
+	err := scanAuditEntry(r.db.QueryRow(
+		"SELECT "+bookAuditColumns+" FROM book_audit WHERE book_id = $1 AND revision = $2",
+		id, revision,
+	), &entry)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("revision %d of book not found", revision)
+		}
+		return nil, fmt.Errorf("failed to get book revision %d: %w", revision, err)
+	}
+
+	return &entry, nil
+}
@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"libmngmt/internal/database"
+	"libmngmt/internal/models"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditChanges(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	before := &models.BookSnapshot{ID: uuid.New(), Title: "Dune", Author: "Frank Herbert", Pages: 412, Version: 1, UpdatedAt: now}
+
+	t.Run("only changed fields are listed", func(t *testing.T) {
+		after := *before
+		after.Title = "Dune Messiah"
+		after.Version = 2
+
+		changes, err := auditChanges(before, &after)
+
+		assert.NoError(t, err)
+		assert.Len(t, changes, 2)
+		assert.JSONEq(t, `"Dune"`, string(changes["title"].From))
+		assert.JSONEq(t, `"Dune Messiah"`, string(changes["title"].To))
+		assert.JSONEq(t, `2`, string(changes["version"].To))
+	})
+
+	t.Run("a create changes every field from null", func(t *testing.T) {
+		changes, err := auditChanges(nil, before)
+
+		assert.NoError(t, err)
+		assert.Contains(t, changes, "isbn")
+		data, err := json.Marshal(changes["title"])
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"from":null,"to":"Dune"}`, string(data))
+	})
+
+	t.Run("a delete changes every field to null", func(t *testing.T) {
+		changes, err := auditChanges(before, nil)
+
+		assert.NoError(t, err)
+		data, err := json.Marshal(changes["pages"])
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"from":412,"to":null}`, string(data))
+	})
+}
+
+func TestBookRepository_History(t *testing.T) {
+	columns := []string{"id", "book_id", "action", "revision", "actor", "request_id", "before", "after", "changes", "changed_at"}
+
+	t.Run("history is listed oldest first", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewBookRepository(&database.DB{DB: db})
+
+		id := uuid.New()
+		now := time.Now()
+		created := `{"id":"` + id.String() + `","title":"Dune","version":1}`
+		updated := `{"id":"` + id.String() + `","title":"Dune Messiah","version":2}`
+
+		mock.ExpectQuery(`SELECT id, book_id, (.+) FROM book_audit WHERE book_id = \$1 ORDER BY changed_at, id`).
+			WithArgs(id).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(1, id, "create", 1, "librarian-7", "req-1", nil, []byte(created), []byte(`{"title":{"from":null,"to":"Dune"}}`), now).
+				AddRow(2, id, "update", 2, "librarian-9", "req-2", []byte(created), []byte(updated), []byte(`{"title":{"from":"Dune","to":"Dune Messiah"}}`), now))
+
+		entries, err := repo.GetHistory(id)
+
+		assert.NoError(t, err)
+		assert.Len(t, entries, 2)
+		assert.Equal(t, models.AuditCreate, entries[0].Action)
+		assert.Nil(t, entries[0].Before)
+		assert.Equal(t, "Dune", entries[0].After.Title)
+		assert.Equal(t, 2, *entries[1].Revision)
+		assert.Equal(t, "librarian-9", entries[1].Actor)
+		assert.Equal(t, "Dune", entries[1].Before.Title)
+		assert.JSONEq(t, `"Dune Messiah"`, string(entries[1].Changes["title"].To))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("as of returns the last write at or before the time", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewBookRepository(&database.DB{DB: db})
+
+		id := uuid.New()
+		at := time.Now()
+		snapshot := `{"id":"` + id.String() + `","title":"Dune","version":1}`
+
+		mock.ExpectQuery(`SELECT (.+) FROM book_audit WHERE book_id = \$1 AND changed_at <= \$2 ORDER BY changed_at DESC, id DESC LIMIT 1`).
+			WithArgs(id, at).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(1, id, "create", 1, "librarian-7", "", nil, []byte(snapshot), []byte(`{}`), at.Add(-time.Hour)))
+
+		entry, err := repo.GetAsOf(id, at)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Dune", entry.After.Title)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("as of before the book existed", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewBookRepository(&database.DB{DB: db})
+
+		id := uuid.New()
+		at := time.Now()
+
+		mock.ExpectQuery(`FROM book_audit WHERE book_id = \$1 AND changed_at <= \$2`).
+			WithArgs(id, at).
+			WillReturnError(sql.ErrNoRows)
+
+		entry, err := repo.GetAsOf(id, at)
+
+		assert.Nil(t, entry)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "book not found as of")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("revision is looked up by number", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewBookRepository(&database.DB{DB: db})
+
+		id := uuid.New()
+		snapshot := `{"id":"` + id.String() + `","title":"Dune","version":1}`
+
+		mock.ExpectQuery(`SELECT (.+) FROM book_audit WHERE book_id = \$1 AND revision = \$2`).
+			WithArgs(id, 1).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(1, id, "create", 1, "librarian-7", "", nil, []byte(snapshot), []byte(`{}`), time.Now()))
+
+		entry, err := repo.GetRevision(id, 1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, *entry.Revision)
+		assert.Equal(t, "Dune", entry.After.Title)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("unknown revision", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewBookRepository(&database.DB{DB: db})
+
+		id := uuid.New()
+
+		mock.ExpectQuery(`FROM book_audit WHERE book_id = \$1 AND revision = \$2`).
+			WithArgs(id, 9).
+			WillReturnError(sql.ErrNoRows)
+
+		entry, err := repo.GetRevision(id, 9)
+
+		assert.Nil(t, entry)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "revision 9 of book not found")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -1,7 +1,10 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
+	"errors"
 	"fmt"
 	"libmngmt/internal/database"
 	"libmngmt/internal/models"
@@ -11,6 +14,7 @@ import (
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -49,6 +53,9 @@ func TestBookRepository_Create(t *testing.T) {
 			Language:    "English",
 		}
 
+		audit := models.AuditInfo{Actor: "librarian-7", RequestID: "req-1"}
+
+		mock.ExpectBegin()
 		expectedQuery := `INSERT INTO books`
 		mock.ExpectQuery(regexp.QuoteMeta(expectedQuery)).
 			WithArgs(
@@ -70,8 +77,13 @@ func TestBookRepository_Create(t *testing.T) {
 			}).AddRow(
 				id, now, now,
 			))
+		// The audit entry is written in the same transaction
+		mock.ExpectExec(`INSERT INTO book_audit`).
+			WithArgs(id, models.AuditCreate, audit.Actor, audit.RequestID, nil, sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
 
-		book, err := repo.Create(req)
+		book, err := repo.Create(req, audit)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, book)
@@ -103,14 +115,16 @@ func TestBookRepository_Create(t *testing.T) {
 			Language: "English",
 		}
 
+		mock.ExpectBegin()
 		expectedQuery := `INSERT INTO books`
 		mock.ExpectQuery(regexp.QuoteMeta(expectedQuery)).
 			WithArgs(sqlmock.AnyArg(), req.Title, req.Author, req.ISBN, req.Publisher,
 				req.Genre, req.PublishedAt, req.Pages, req.Language, true,
 				sqlmock.AnyArg(), sqlmock.AnyArg()).
 			WillReturnError(fmt.Errorf("database error"))
+		mock.ExpectRollback()
 
-		book, err := repo.Create(req)
+		book, err := repo.Create(req, models.AuditInfo{})
 
 		assert.Error(t, err)
 		assert.Nil(t, book)
@@ -120,6 +134,109 @@ func TestBookRepository_Create(t *testing.T) {
 	})
 }
 
+func TestBookRepository_CreateBatch(t *testing.T) {
+	reqs := []*models.CreateBookRequest{
+		{Title: "Dune", Author: "Frank Herbert", ISBN: "9780441013593", Pages: 412},
+		{Title: "Emma", Author: "Jane Austen", ISBN: "9780141439587", Pages: 474},
+	}
+
+	t.Run("the batch is inserted in one transaction", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewBookRepository(&database.DB{DB: db})
+
+		now := time.Now()
+		mock.ExpectBegin()
+		for _, req := range reqs {
+			id := uuid.New()
+			mock.ExpectQuery(`INSERT INTO books`).
+				WithArgs(sqlmock.AnyArg(), req.Title, req.Author, req.ISBN, "", "", time.Time{}, req.Pages, "English",
+					true, sqlmock.AnyArg(), sqlmock.AnyArg()).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow(id, now, now))
+			mock.ExpectExec(`INSERT INTO book_audit`).
+				WithArgs(id, models.AuditCreate, "librarian-7", "req-1", nil, sqlmock.AnyArg(), sqlmock.AnyArg()).
+				WillReturnResult(sqlmock.NewResult(1, 1))
+		}
+		mock.ExpectCommit()
+
+		books, err := repo.CreateBatch(context.Background(), reqs, models.AuditInfo{Actor: "librarian-7", RequestID: "req-1"})
+
+		assert.NoError(t, err)
+		assert.Len(t, books, 2)
+		assert.Equal(t, "Emma", books[1].Title)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("a failed insert rolls the whole batch back", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewBookRepository(&database.DB{DB: db})
+
+		id := uuid.New()
+		now := time.Now()
+		mock.ExpectBegin()
+		mock.ExpectQuery(`INSERT INTO books`).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow(id, now, now))
+		mock.ExpectExec(`INSERT INTO book_audit`).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectQuery(`INSERT INTO books`).
+			WillReturnError(fmt.Errorf("connection lost"))
+		mock.ExpectRollback()
+
+		books, err := repo.CreateBatch(context.Background(), reqs, models.AuditInfo{})
+
+		assert.Nil(t, books)
+		var itemErr *BatchItemError
+		assert.True(t, errors.As(err, &itemErr))
+		assert.Equal(t, 1, itemErr.Index)
+		assert.Contains(t, err.Error(), "book at index 1")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("an ISBN taken concurrently is a duplicate", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewBookRepository(&database.DB{DB: db})
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`INSERT INTO books`).
+			WillReturnError(&pq.Error{Code: "23505", Message: "duplicate key value violates unique constraint"})
+		mock.ExpectRollback()
+
+		_, err = repo.CreateBatch(context.Background(), reqs, models.AuditInfo{})
+
+		var itemErr *BatchItemError
+		assert.True(t, errors.As(err, &itemErr))
+		assert.Equal(t, 0, itemErr.Index)
+		assert.True(t, itemErr.Duplicate)
+		assert.Contains(t, itemErr.Err.Error(), "book with ISBN 9780441013593 already exists")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("a cancelled request creates nothing", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewBookRepository(&database.DB{DB: db})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		books, err := repo.CreateBatch(ctx, reqs, models.AuditInfo{})
+
+		assert.Nil(t, books)
+		assert.Error(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
 func TestBookRepository_GetByID(t *testing.T) {
 	t.Run("get book by ID successfully", func(t *testing.T) {
 		db, mock, err := sqlmock.New()
@@ -133,15 +250,15 @@ func TestBookRepository_GetByID(t *testing.T) {
 		now := time.Now()
 		publishedAt := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
 
-		expectedQuery := `SELECT id, title, author, isbn, publisher, genre, published_at, pages, language, available, created_at, updated_at FROM books WHERE id = \$1`
+		expectedQuery := `SELECT id, title, author, isbn, publisher, genre, published_at, pages, language, available, created_at, updated_at, version FROM books WHERE id = \$1 AND deleted_at IS NULL`
 		mock.ExpectQuery(expectedQuery).
 			WithArgs(id).
 			WillReturnRows(sqlmock.NewRows([]string{
 				"id", "title", "author", "isbn", "publisher", "genre",
-				"published_at", "pages", "language", "available", "created_at", "updated_at",
+				"published_at", "pages", "language", "available", "created_at", "updated_at", "version",
 			}).AddRow(
 				id, "Test Book", "Test Author", "9781234567890", "Test Publisher", "Fiction",
-				publishedAt, 300, "English", true, now, now,
+				publishedAt, 300, "English", true, now, now, 1,
 			))
 
 		book, err := repo.GetByID(id)
@@ -172,7 +289,7 @@ func TestBookRepository_GetByID(t *testing.T) {
 
 		id := uuid.New()
 
-		expectedQuery := `SELECT id, title, author, isbn, publisher, genre, published_at, pages, language, available, created_at, updated_at FROM books WHERE id = \$1`
+		expectedQuery := `SELECT id, title, author, isbn, publisher, genre, published_at, pages, language, available, created_at, updated_at, version FROM books WHERE id = \$1 AND deleted_at IS NULL`
 		mock.ExpectQuery(expectedQuery).
 			WithArgs(id).
 			WillReturnError(sql.ErrNoRows)
@@ -206,16 +323,17 @@ func TestBookRepository_Update(t *testing.T) {
 			Author: &newAuthor,
 		}
 
-		// First expect GetByID call
-		selectQuery := `SELECT id, title, author, isbn, publisher, genre, published_at, pages, language, available, created_at, updated_at FROM books WHERE id = \$1`
+		// First expect the current book to be locked
+		mock.ExpectBegin()
+		selectQuery := `SELECT id, title, author, isbn, publisher, genre, published_at, pages, language, available, created_at, updated_at, version FROM books WHERE id = \$1 AND deleted_at IS NULL FOR UPDATE`
 		mock.ExpectQuery(selectQuery).
 			WithArgs(id).
 			WillReturnRows(sqlmock.NewRows([]string{
 				"id", "title", "author", "isbn", "publisher", "genre",
-				"published_at", "pages", "language", "available", "created_at", "updated_at",
+				"published_at", "pages", "language", "available", "created_at", "updated_at", "version",
 			}).AddRow(
 				id, "Original Title", "Original Author", "9781234567890", "Test Publisher", "Fiction",
-				time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), 300, "English", true, now, now,
+				time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), 300, "English", true, now, now, 1,
 			))
 
 		// Then expect UPDATE query
@@ -224,13 +342,17 @@ func TestBookRepository_Update(t *testing.T) {
 			WithArgs(newTitle, newAuthor, sqlmock.AnyArg(), id).
 			WillReturnRows(sqlmock.NewRows([]string{
 				"id", "title", "author", "isbn", "publisher", "genre",
-				"published_at", "pages", "language", "available", "created_at", "updated_at",
+				"published_at", "pages", "language", "available", "created_at", "updated_at", "version",
 			}).AddRow(
 				id, newTitle, newAuthor, "9781234567890", "Test Publisher", "Fiction",
-				time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), 300, "English", true, now, now,
+				time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), 300, "English", true, now, now, 1,
 			))
+		mock.ExpectExec(`INSERT INTO book_audit`).
+			WithArgs(id, models.AuditUpdate, "librarian-7", "", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
 
-		book, err := repo.Update(id, req)
+		book, err := repo.Update(id, req, models.AuditInfo{Actor: "librarian-7"})
 
 		assert.NoError(t, err)
 		assert.NotNil(t, book)
@@ -253,19 +375,22 @@ func TestBookRepository_Update(t *testing.T) {
 		now := time.Now()
 		req := &models.UpdateBookRequest{}
 
-		// Expect GetByID call since Update calls GetByID first
-		selectQuery := `SELECT id, title, author, isbn, publisher, genre, published_at, pages, language, available, created_at, updated_at FROM books WHERE id = \$1`
+		// Expect the book to be read since Update locks it first; nothing is
+		// written or audited
+		mock.ExpectBegin()
+		selectQuery := `SELECT id, title, author, isbn, publisher, genre, published_at, pages, language, available, created_at, updated_at, version FROM books WHERE id = \$1 AND deleted_at IS NULL FOR UPDATE`
 		mock.ExpectQuery(selectQuery).
 			WithArgs(id).
 			WillReturnRows(sqlmock.NewRows([]string{
 				"id", "title", "author", "isbn", "publisher", "genre",
-				"published_at", "pages", "language", "available", "created_at", "updated_at",
+				"published_at", "pages", "language", "available", "created_at", "updated_at", "version",
 			}).AddRow(
 				id, "Original Title", "Original Author", "9781234567890", "Test Publisher", "Fiction",
-				time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), 300, "English", true, now, now,
+				time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), 300, "English", true, now, now, 1,
 			))
+		mock.ExpectRollback()
 
-		book, err := repo.Update(id, req)
+		book, err := repo.Update(id, req, models.AuditInfo{})
 
 		assert.NoError(t, err)
 		assert.NotNil(t, book)
@@ -274,10 +399,112 @@ func TestBookRepository_Update(t *testing.T) {
 
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
+
+	bookColumns := []string{
+		"id", "title", "author", "isbn", "publisher", "genre",
+		"published_at", "pages", "language", "available", "created_at", "updated_at", "version",
+	}
+	selectQuery := `SELECT id, title, (.+), version FROM books WHERE id = \$1 AND deleted_at IS NULL FOR UPDATE`
+
+	t.Run("versioned update applies to the expected version", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewBookRepository(&database.DB{DB: db})
+
+		id := uuid.New()
+		now := time.Now()
+		newTitle := "Updated Title"
+		version := 3
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(selectQuery).
+			WithArgs(id).
+			WillReturnRows(sqlmock.NewRows(bookColumns).AddRow(
+				id, "Original Title", "Author", "9781234567890", "Publisher", "Fiction",
+				now, 300, "English", true, now, now, 3,
+			))
+		mock.ExpectQuery(`UPDATE books SET title = \$1, updated_at = \$2 WHERE id = \$3 AND version = \$4 RETURNING (.+), version`).
+			WithArgs(newTitle, sqlmock.AnyArg(), id, version).
+			WillReturnRows(sqlmock.NewRows(bookColumns).AddRow(
+				id, newTitle, "Author", "9781234567890", "Publisher", "Fiction",
+				now, 300, "English", true, now, now, 4,
+			))
+		mock.ExpectExec(`INSERT INTO book_audit`).
+			WithArgs(id, models.AuditUpdate, "", "", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		book, err := repo.Update(id, &models.UpdateBookRequest{Title: &newTitle, Version: &version}, models.AuditInfo{})
+
+		assert.NoError(t, err)
+		assert.Equal(t, newTitle, book.Title)
+		assert.Equal(t, 4, book.Version)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("versioned update loses a race", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewBookRepository(&database.DB{DB: db})
+
+		id := uuid.New()
+		now := time.Now()
+		newTitle := "Updated Title"
+		version := 3
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(selectQuery).
+			WithArgs(id).
+			WillReturnRows(sqlmock.NewRows(bookColumns).AddRow(
+				id, "Original Title", "Author", "9781234567890", "Publisher", "Fiction",
+				now, 300, "English", true, now, now, 3,
+			))
+		mock.ExpectQuery(`UPDATE books SET (.+) WHERE id = \$3 AND version = \$4`).
+			WithArgs(newTitle, sqlmock.AnyArg(), id, version).
+			WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
+
+		book, err := repo.Update(id, &models.UpdateBookRequest{Title: &newTitle, Version: &version}, models.AuditInfo{})
+
+		assert.ErrorIs(t, err, ErrVersionConflict)
+		assert.Nil(t, book)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("empty request against a stale version", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewBookRepository(&database.DB{DB: db})
+
+		id := uuid.New()
+		now := time.Now()
+		version := 1
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(selectQuery).
+			WithArgs(id).
+			WillReturnRows(sqlmock.NewRows(bookColumns).AddRow(
+				id, "Original Title", "Author", "9781234567890", "Publisher", "Fiction",
+				now, 300, "English", true, now, now, 2,
+			))
+		mock.ExpectRollback()
+
+		book, err := repo.Update(id, &models.UpdateBookRequest{Version: &version}, models.AuditInfo{})
+
+		assert.ErrorIs(t, err, ErrVersionConflict)
+		assert.Nil(t, book)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
 }
 
 func TestBookRepository_Delete(t *testing.T) {
-	t.Run("delete book successfully", func(t *testing.T) {
+	t.Run("delete moves the book to the trash", func(t *testing.T) {
 		db, mock, err := sqlmock.New()
 		assert.NoError(t, err)
 		defer db.Close()
@@ -286,15 +513,107 @@ func TestBookRepository_Delete(t *testing.T) {
 		repo := NewBookRepository(dbWrapper)
 
 		id := uuid.New()
+		now := time.Now()
+		audit := models.AuditInfo{Actor: "librarian-7", RequestID: "req-1"}
 
-		expectedQuery := `DELETE FROM books WHERE id = \$1`
-		mock.ExpectExec(expectedQuery).
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT id, title, (.+) FROM books WHERE id = \$1 AND deleted_at IS NULL FOR UPDATE`).
+			WithArgs(id).
+			WillReturnRows(sqlmock.NewRows([]string{
+				"id", "title", "author", "isbn", "publisher", "genre",
+				"published_at", "pages", "language", "available", "created_at", "updated_at", "version",
+			}).AddRow(
+				id, "Dune", "Frank Herbert", "9780441172719", "Chilton", "Science Fiction",
+				now, 412, "English", true, now, now, 2,
+			))
+		mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM loans WHERE book_id = \$1 AND returned_at IS NULL\)`).
+			WithArgs(id).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		mock.ExpectExec(`UPDATE holds SET status = 'cancelled' WHERE book_id = \$1 AND status IN \('waiting', 'ready'\)`).
 			WithArgs(id).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectExec(`UPDATE books SET deleted_at = \$1, available = CASE (.+) END WHERE id = \$2`).
+			WithArgs(sqlmock.AnyArg(), id).
 			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`INSERT INTO book_audit`).
+			WithArgs(id, models.AuditDelete, audit.Actor, audit.RequestID, sqlmock.AnyArg(), nil, sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		err = repo.Delete(id, nil, audit)
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("a delete pinned to an outdated version is a conflict", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewBookRepository(&database.DB{DB: db})
+
+		id := uuid.New()
+		now := time.Now()
+		version := 1
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT id, title, (.+) FROM books WHERE id = \$1 AND deleted_at IS NULL FOR UPDATE`).
+			WithArgs(id).
+			WillReturnRows(sqlmock.NewRows([]string{
+				"id", "title", "author", "isbn", "publisher", "genre",
+				"published_at", "pages", "language", "available", "created_at", "updated_at", "version",
+			}).AddRow(
+				id, "Dune", "Frank Herbert", "9780441172719", "Chilton", "Science Fiction",
+				now, 412, "English", true, now, now, 2,
+			))
+		mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM loans`).
+			WithArgs(id).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		mock.ExpectExec(`UPDATE holds SET status = 'cancelled'`).
+			WithArgs(id).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(`UPDATE books SET deleted_at = \$1, available = CASE (.+) END WHERE id = \$2 AND version = \$3`).
+			WithArgs(sqlmock.AnyArg(), id, version).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		err = repo.Delete(id, &version, models.AuditInfo{})
 
-		err = repo.Delete(id)
+		assert.ErrorIs(t, err, ErrVersionConflict)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
 
+	t.Run("a book out on loan is not deleted", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
 		assert.NoError(t, err)
+		defer db.Close()
+
+		dbWrapper := &database.DB{DB: db}
+		repo := NewBookRepository(dbWrapper)
+
+		id := uuid.New()
+		now := time.Now()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT id, title, (.+) FROM books WHERE id = \$1 AND deleted_at IS NULL FOR UPDATE`).
+			WithArgs(id).
+			WillReturnRows(sqlmock.NewRows([]string{
+				"id", "title", "author", "isbn", "publisher", "genre",
+				"published_at", "pages", "language", "available", "created_at", "updated_at", "version",
+			}).AddRow(
+				id, "Dune", "Frank Herbert", "9780441172719", "Chilton", "Science Fiction",
+				now, 412, "English", false, now, now, 2,
+			))
+		mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM loans`).
+			WithArgs(id).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+		mock.ExpectRollback()
+
+		err = repo.Delete(id, nil, models.AuditInfo{})
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "still checked out")
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
@@ -308,12 +627,13 @@ func TestBookRepository_Delete(t *testing.T) {
 
 		id := uuid.New()
 
-		expectedQuery := `DELETE FROM books WHERE id = \$1`
-		mock.ExpectExec(expectedQuery).
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT id, title, (.+) FROM books WHERE id = \$1 AND deleted_at IS NULL FOR UPDATE`).
 			WithArgs(id).
-			WillReturnResult(sqlmock.NewResult(0, 0))
+			WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
 
-		err = repo.Delete(id)
+		err = repo.Delete(id, nil, models.AuditInfo{})
 
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "book not found")
@@ -322,6 +642,160 @@ func TestBookRepository_Delete(t *testing.T) {
 	})
 }
 
+func TestBookRepository_Trash(t *testing.T) {
+	columns := []string{
+		"id", "title", "author", "isbn", "publisher", "genre",
+		"published_at", "pages", "language", "available", "created_at", "updated_at", "version",
+	}
+
+	t.Run("list trashed books", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewBookRepository(&database.DB{DB: db})
+
+		id := uuid.New()
+		now := time.Now()
+
+		mock.ExpectQuery(`SELECT COUNT\(\*\) FROM books WHERE deleted_at IS NOT NULL`).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+		mock.ExpectQuery(`SELECT id, title, (.+), version, deleted_at FROM books WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC, id DESC LIMIT \$1 OFFSET \$2`).
+			WithArgs(20, 0).
+			WillReturnRows(sqlmock.NewRows(append(columns, "deleted_at")).AddRow(
+				id, "Dune", "Frank Herbert", "9780441172719", "Chilton", "Science Fiction",
+				now, 412, "English", true, now, now, 2, now,
+			))
+
+		books, total, err := repo.GetTrash(20, 0)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, total)
+		assert.Len(t, books, 1)
+		assert.Equal(t, id, books[0].ID)
+		assert.NotNil(t, books[0].DeletedAt)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("restore a trashed book", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewBookRepository(&database.DB{DB: db})
+
+		id := uuid.New()
+		now := time.Now()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT isbn FROM books WHERE id = \$1 AND deleted_at IS NOT NULL FOR UPDATE`).
+			WithArgs(id).
+			WillReturnRows(sqlmock.NewRows([]string{"isbn"}).AddRow("9780441172719"))
+		mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM books WHERE isbn = \$1 AND deleted_at IS NULL\)`).
+			WithArgs("9780441172719").
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		mock.ExpectQuery(`UPDATE books SET deleted_at = NULL WHERE id = \$1 RETURNING`).
+			WithArgs(id).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(
+				id, "Dune", "Frank Herbert", "9780441172719", "Chilton", "Science Fiction",
+				now, 412, "English", true, now, now, 3,
+			))
+		mock.ExpectExec(`INSERT INTO book_audit`).
+			WithArgs(id, models.AuditRestore, "librarian-7", "", nil, sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		book, err := repo.Restore(id, models.AuditInfo{Actor: "librarian-7"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, id, book.ID)
+		assert.Nil(t, book.DeletedAt)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("restore is refused when the ISBN was taken", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewBookRepository(&database.DB{DB: db})
+
+		id := uuid.New()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT isbn FROM books WHERE id = \$1 AND deleted_at IS NOT NULL FOR UPDATE`).
+			WithArgs(id).
+			WillReturnRows(sqlmock.NewRows([]string{"isbn"}).AddRow("9780441172719"))
+		mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM books WHERE isbn = \$1 AND deleted_at IS NULL\)`).
+			WithArgs("9780441172719").
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+		mock.ExpectRollback()
+
+		book, err := repo.Restore(id, models.AuditInfo{})
+
+		assert.Nil(t, book)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "already exists")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("restore of a book not in the trash", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewBookRepository(&database.DB{DB: db})
+
+		id := uuid.New()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT isbn FROM books WHERE id = \$1 AND deleted_at IS NOT NULL FOR UPDATE`).
+			WithArgs(id).
+			WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
+
+		_, err = repo.Restore(id, models.AuditInfo{})
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "book not found in trash")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("purge removes books trashed before the cutoff", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewBookRepository(&database.DB{DB: db})
+
+		cutoff := time.Now().Add(-30 * 24 * time.Hour)
+		now := time.Now()
+		first, second := uuid.New(), uuid.New()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`DELETE FROM books WHERE deleted_at IS NOT NULL AND deleted_at < \$1 RETURNING`).
+			WithArgs(cutoff).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(first, "Dune", "Frank Herbert", "9780441172719", "Chilton", "Science Fiction",
+					now, 412, "English", true, now, now, 3).
+				AddRow(second, "Emma", "Jane Austen", "9780141439587", "Penguin", "Classics",
+					now, 474, "English", true, now, now, 1))
+		// Purges are audited as made by the system
+		for _, id := range []uuid.UUID{first, second} {
+			mock.ExpectExec(`INSERT INTO book_audit`).
+				WithArgs(id, models.AuditPurge, models.SystemActor, "", sqlmock.AnyArg(), nil, sqlmock.AnyArg()).
+				WillReturnResult(sqlmock.NewResult(1, 1))
+		}
+		mock.ExpectCommit()
+
+		purged, err := repo.Purge(cutoff)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, purged)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
 func TestBookRepository_GetAll(t *testing.T) {
 	t.Run("get all books successfully", func(t *testing.T) {
 		db, mock, err := sqlmock.New()
@@ -340,35 +814,32 @@ func TestBookRepository_GetAll(t *testing.T) {
 			Offset: 0,
 		}
 
-		// Count query
-		expectedCountQuery := `SELECT COUNT\(\*\) FROM books`
-		mock.ExpectQuery(expectedCountQuery).
-			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
-
-		// Main query
-		expectedQuery := `SELECT id, title, author, isbn, publisher, genre, published_at, pages, language, available, created_at, updated_at FROM books ORDER BY created_at DESC LIMIT \$1 OFFSET \$2`
+		expectedQuery := `SELECT id, title, author, isbn, publisher, genre, published_at, pages, language, available, created_at, updated_at, (.+) AS total_copies, (.+) AS available_copies, \(created_at\)::text AS sort_key_0 FROM books WHERE deleted_at IS NULL ORDER BY created_at DESC, id DESC LIMIT \$1 OFFSET \$2`
 		mock.ExpectQuery(expectedQuery).
-			WithArgs(10, 0).
+			WithArgs(11, 0).
 			WillReturnRows(sqlmock.NewRows([]string{
 				"id", "title", "author", "isbn", "publisher", "genre",
-				"published_at", "pages", "language", "available", "created_at", "updated_at",
+				"published_at", "pages", "language", "available", "created_at", "updated_at", "version",
+				"total_copies", "available_copies", "sort_key_0",
 			}).
 				AddRow(id1, "Book 1", "Author 1", "9781111111111", "Publisher 1", "Fiction",
-					time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), 200, "English", true, now, now).
+					time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), 200, "English", true, now, now, 1, 5, 2, "2023-01-01 00:00:00").
 				AddRow(id2, "Book 2", "Author 2", "9782222222222", "Publisher 2", "Non-Fiction",
-					time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC), 250, "English", false, now, now))
+					time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC), 250, "English", false, now, now, 1, 1, 0, "2023-02-01 00:00:00"))
 
-		books, total, err := repo.GetAll(filter)
+		books, hasMore, err := repo.GetAll(filter)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, books)
 		assert.Len(t, books, 2)
-		assert.Equal(t, 2, total)
+		assert.False(t, hasMore)
 
 		// Check first book
 		assert.Equal(t, id1, books[0].ID)
 		assert.Equal(t, "Book 1", books[0].Title)
 		assert.Equal(t, "Author 1", books[0].Author)
+		assert.Equal(t, 5, books[0].TotalCopies)
+		assert.Equal(t, 2, books[0].AvailableCopies)
 
 		// Check second book
 		assert.Equal(t, id2, books[1].ID)
@@ -396,29 +867,23 @@ func TestBookRepository_GetAll(t *testing.T) {
 			Offset:    10,
 		}
 
-		// Count query with WHERE clause
-		expectedCountQuery := `SELECT COUNT\(\*\) FROM books WHERE LOWER\(author\) LIKE LOWER\(\$1\) AND LOWER\(genre\) LIKE LOWER\(\$2\) AND LOWER\(language\) = LOWER\(\$3\) AND available = \$4`
-		mock.ExpectQuery(expectedCountQuery).
-			WithArgs("%tolkien%", "%fantasy%", "English", true).
-			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
-
-		// Main query with WHERE clause
-		expectedQuery := `SELECT id, title, author, isbn, publisher, genre, published_at, pages, language, available, created_at, updated_at FROM books WHERE LOWER\(author\) LIKE LOWER\(\$1\) AND LOWER\(genre\) LIKE LOWER\(\$2\) AND LOWER\(language\) = LOWER\(\$3\) AND available = \$4 ORDER BY created_at DESC LIMIT \$5 OFFSET \$6`
+		expectedQuery := `SELECT id, title, author, isbn, publisher, genre, published_at, pages, language, available, created_at, updated_at, (.+) AS total_copies, (.+) AS available_copies, \(created_at\)::text AS sort_key_0 FROM books WHERE deleted_at IS NULL AND LOWER\(author\) LIKE LOWER\(\$1\) AND LOWER\(genre\) LIKE LOWER\(\$2\) AND LOWER\(language\) = LOWER\(\$3\) AND available = \$4 ORDER BY created_at DESC, id DESC LIMIT \$5 OFFSET \$6`
 		mock.ExpectQuery(expectedQuery).
-			WithArgs("%tolkien%", "%fantasy%", "English", true, 5, 10).
+			WithArgs("%tolkien%", "%fantasy%", "English", true, 6, 10).
 			WillReturnRows(sqlmock.NewRows([]string{
 				"id", "title", "author", "isbn", "publisher", "genre",
-				"published_at", "pages", "language", "available", "created_at", "updated_at",
+				"published_at", "pages", "language", "available", "created_at", "updated_at", "version",
+				"total_copies", "available_copies", "sort_key_0",
 			}).
 				AddRow(uuid.New(), "The Hobbit", "J.R.R. Tolkien", "9780547928227", "Houghton Mifflin", "Fantasy",
-					time.Date(1937, 9, 21, 0, 0, 0, 0, time.UTC), 310, "English", true, time.Now(), time.Now()))
+					time.Date(1937, 9, 21, 0, 0, 0, 0, time.UTC), 310, "English", true, time.Now(), time.Now(), 1, 3, 3, "2024-01-01 00:00:00"))
 
-		books, total, err := repo.GetAll(filter)
+		books, hasMore, err := repo.GetAll(filter)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, books)
 		assert.Len(t, books, 1)
-		assert.Equal(t, 1, total)
+		assert.False(t, hasMore)
 
 		assert.Equal(t, "The Hobbit", books[0].Title)
 		assert.Equal(t, "J.R.R. Tolkien", books[0].Author)
@@ -426,7 +891,7 @@ func TestBookRepository_GetAll(t *testing.T) {
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
-	t.Run("get all books empty result", func(t *testing.T) {
+	t.Run("get all books with fuzzy author", func(t *testing.T) {
 		db, mock, err := sqlmock.New()
 		assert.NoError(t, err)
 		defer db.Close()
@@ -434,34 +899,408 @@ func TestBookRepository_GetAll(t *testing.T) {
 		dbWrapper := &database.DB{DB: db}
 		repo := NewBookRepository(dbWrapper)
 
-		filter := models.BookFilter{
-			Limit:  10,
-			Offset: 0,
-		}
-
-		// Count query
-		expectedCountQuery := `SELECT COUNT\(\*\) FROM books`
-		mock.ExpectQuery(expectedCountQuery).
-			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		filter := models.BookFilter{Author: "Kernigan", Fuzzy: true, Similarity: 0.4, Limit: 10}
 
-		// Main query
-		expectedQuery := `SELECT id, title, author, isbn, publisher, genre, published_at, pages, language, available, created_at, updated_at FROM books ORDER BY created_at DESC LIMIT \$1 OFFSET \$2`
-		mock.ExpectQuery(expectedQuery).
-			WithArgs(10, 0).
+		mock.ExpectQuery(`FROM books WHERE (.+) ORDER BY \(word_similarity\(LOWER\(\$2\), LOWER\(COALESCE\(author, ''\)\)\)\) DESC, created_at DESC, id DESC LIMIT \$3 OFFSET \$4`).
+			WithArgs(0.4, "Kernigan", 11, 0).
 			WillReturnRows(sqlmock.NewRows([]string{
 				"id", "title", "author", "isbn", "publisher", "genre",
-				"published_at", "pages", "language", "available", "created_at", "updated_at",
-			}))
+				"published_at", "pages", "language", "available", "created_at", "updated_at", "version",
+				"total_copies", "available_copies", "sort_key_0",
+			}).
+				AddRow(uuid.New(), "The C Programming Language", "Brian W. Kernighan", "9780131103627", "Prentice Hall", "Computing",
+					time.Date(1978, 2, 22, 0, 0, 0, 0, time.UTC), 272, "English", true, time.Now(), time.Now(), 1, 1, 1, "2024-01-01 00:00:00"))
 
-		books, total, err := repo.GetAll(filter)
+		books, hasMore, err := repo.GetAll(filter)
 
 		assert.NoError(t, err)
-		assert.NotNil(t, books)
-		assert.Len(t, books, 0)
-		assert.Equal(t, 0, total)
+		assert.False(t, hasMore)
+		assert.Equal(t, "Brian W. Kernighan", books[0].Author)
 
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
+
+	t.Run("get all books empty result", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		dbWrapper := &database.DB{DB: db}
+		repo := NewBookRepository(dbWrapper)
+
+		filter := models.BookFilter{
+			Limit:  10,
+			Offset: 0,
+		}
+
+		expectedQuery := `SELECT id, title, author, isbn, publisher, genre, published_at, pages, language, available, created_at, updated_at, (.+) AS total_copies, (.+) AS available_copies, \(created_at\)::text AS sort_key_0 FROM books WHERE deleted_at IS NULL ORDER BY created_at DESC, id DESC LIMIT \$1 OFFSET \$2`
+		mock.ExpectQuery(expectedQuery).
+			WithArgs(11, 0).
+			WillReturnRows(sqlmock.NewRows([]string{
+				"id", "title", "author", "isbn", "publisher", "genre",
+				"published_at", "pages", "language", "available", "created_at", "updated_at", "version",
+				"total_copies", "available_copies", "sort_key_0",
+			}))
+
+		books, hasMore, err := repo.GetAll(filter)
+
+		assert.NoError(t, err)
+		assert.NotNil(t, books)
+		assert.Len(t, books, 0)
+		assert.False(t, hasMore)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("extra row reports another page", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewBookRepository(&database.DB{DB: db})
+
+		mock.ExpectQuery(`FROM books WHERE deleted_at IS NULL ORDER BY created_at DESC, id DESC LIMIT \$1 OFFSET \$2`).
+			WithArgs(2, 4).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "title", "updated_at", "sort_key_0"}).
+				AddRow(uuid.New(), "Book 5", time.Now(), "2024-03-02 00:00:00").
+				AddRow(uuid.New(), "Book 6", time.Now(), "2024-03-01 00:00:00"))
+
+		books, hasMore, err := repo.GetAll(models.BookFilter{
+			Limit:          1,
+			Offset:         4,
+			BookProjection: models.BookProjection{Fields: []string{"title"}},
+		})
+
+		assert.NoError(t, err)
+		assert.True(t, hasMore)
+		assert.Len(t, books, 1)
+		assert.Equal(t, "Book 5", books[0].Title)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestBookRepository_GetPage(t *testing.T) {
+	columns := []string{
+		"id", "title", "author", "isbn", "publisher", "genre",
+		"published_at", "pages", "language", "available", "created_at", "updated_at", "version",
+		"total_copies", "available_copies", "sort_key_0",
+	}
+	addBook := func(rows *sqlmock.Rows, title string, createdAt time.Time) *sqlmock.Rows {
+		return rows.AddRow(uuid.New(), title, "Author", "9781111111111", "Publisher", "Fiction",
+			createdAt, 200, "English", true, createdAt, createdAt, 1, 1, 1, createdAt.Format("2006-01-02 15:04:05.999999"))
+	}
+
+	t.Run("forward page after cursor", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewBookRepository(&database.DB{DB: db})
+
+		now := time.Now()
+		cursor := &models.BookCursor{Values: []string{"2024-03-01 12:00:00"}, ID: uuid.New()}
+
+		rows := sqlmock.NewRows(columns)
+		addBook(rows, "Book 2", now.Add(-time.Hour))
+		addBook(rows, "Book 3", now.Add(-2*time.Hour))
+		addBook(rows, "Book 4", now.Add(-3*time.Hour))
+
+		// One row beyond the limit reveals that another page follows
+		mock.ExpectQuery(`FROM books WHERE deleted_at IS NULL AND LOWER\(genre\) LIKE LOWER\(\$1\) AND \(created_at, id\) < \(\$2::timestamp, \$3::uuid\) ORDER BY created_at DESC, id DESC LIMIT \$4`).
+			WithArgs("%fiction%", "2024-03-01 12:00:00", cursor.ID, 3).
+			WillReturnRows(rows)
+
+		books, hasMore, err := repo.GetPage(models.BookFilter{Genre: "fiction", Limit: 2}, cursor)
+
+		assert.NoError(t, err)
+		assert.True(t, hasMore)
+		assert.Len(t, books, 2)
+		assert.Equal(t, "Book 2", books[0].Title)
+		assert.Len(t, books[1].SortKey, 1)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("backward page is returned in listing order", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewBookRepository(&database.DB{DB: db})
+
+		now := time.Now()
+		cursor := &models.BookCursor{Values: []string{"2024-03-01 12:00:00"}, ID: uuid.New(), Backward: true}
+
+		rows := sqlmock.NewRows(columns)
+		addBook(rows, "Book 2", now.Add(time.Hour))
+		addBook(rows, "Book 1", now.Add(2*time.Hour))
+
+		mock.ExpectQuery(`FROM books WHERE deleted_at IS NULL AND \(created_at, id\) > \(\$1::timestamp, \$2::uuid\) ORDER BY created_at ASC, id ASC LIMIT \$3`).
+			WithArgs("2024-03-01 12:00:00", cursor.ID, 3).
+			WillReturnRows(rows)
+
+		books, hasMore, err := repo.GetPage(models.BookFilter{Limit: 2}, cursor)
+
+		assert.NoError(t, err)
+		assert.False(t, hasMore)
+		assert.Equal(t, "Book 1", books[0].Title)
+		assert.Equal(t, "Book 2", books[1].Title)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("mixed directions expand the keyset condition", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewBookRepository(&database.DB{DB: db})
+
+		cursor := &models.BookCursor{Values: []string{"hobbit", "310"}, ID: uuid.New(), Sort: "title,-pages"}
+
+		mock.ExpectQuery(`WHERE deleted_at IS NULL AND \(\(book_sort_title\(title\) > \$1::text\) OR \(book_sort_title\(title\) = \$1::text AND COALESCE\(pages, 0\) < \$2::integer\) OR \(book_sort_title\(title\) = \$1::text AND COALESCE\(pages, 0\) = \$2::integer AND id < \$3::uuid\)\) ORDER BY book_sort_title\(title\) ASC, COALESCE\(pages, 0\) DESC, id DESC LIMIT \$4`).
+			WithArgs("hobbit", "310", cursor.ID, 11).
+			WillReturnRows(sqlmock.NewRows(append(columns, "sort_key_1")))
+
+		books, hasMore, err := repo.GetPage(models.BookFilter{Sort: "title,-pages", Limit: 10}, cursor)
+
+		assert.NoError(t, err)
+		assert.False(t, hasMore)
+		assert.Empty(t, books)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("cursor from another sort is rejected", func(t *testing.T) {
+		db, _, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewBookRepository(&database.DB{DB: db})
+
+		cursor := &models.BookCursor{Values: []string{"2024-03-01 12:00:00"}, ID: uuid.New()}
+
+		_, _, err = repo.GetPage(models.BookFilter{Sort: "title,pages"}, cursor)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid cursor")
+	})
+}
+
+func TestBookRepository_GetAllSorted(t *testing.T) {
+	t.Run("sort keys are applied in order with an ID tiebreaker", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewBookRepository(&database.DB{DB: db})
+
+		mock.ExpectQuery(`AS available_copies, \(book_sort_title\(title\)\)::text AS sort_key_0, \(COALESCE\(published_at, '-infinity'\)\)::text AS sort_key_1 FROM books WHERE deleted_at IS NULL ORDER BY book_sort_title\(title\) ASC, COALESCE\(published_at, '-infinity'\) DESC, id DESC LIMIT \$1 OFFSET \$2`).
+			WithArgs(11, 0).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		_, _, err = repo.GetAll(models.BookFilter{Sort: "title, -published_at", Limit: 10})
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("unknown and repeated fields are rejected", func(t *testing.T) {
+		db, _, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewBookRepository(&database.DB{DB: db})
+
+		for _, sort := range []string{"title; DROP TABLE books", "search_vector", "title,-title", "title,"} {
+			_, _, err = repo.GetAll(models.BookFilter{Sort: sort})
+			assert.Error(t, err, sort)
+			assert.Contains(t, err.Error(), "invalid sort", sort)
+		}
+	})
+}
+
+func TestBookRepository_GetAllFiltered(t *testing.T) {
+	t.Run("sets, exclusions and ranges are parameterized", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewBookRepository(&database.DB{DB: db})
+
+		from := time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)
+		to := time.Date(2020, 12, 31, 0, 0, 0, 0, time.UTC)
+		minPages := 300
+		filter := models.BookFilter{
+			Publisher:      "O'Reilly",
+			ISBN:           "9781234567897",
+			Genres:         []string{"programming", "databases"},
+			ExcludeGenres:  []string{"fiction"},
+			ExcludeAuthors: []string{"smith"},
+			PublishedFrom:  &from,
+			PublishedTo:    &to,
+			MinPages:       &minPages,
+			Limit:          10,
+		}
+
+		where := `WHERE deleted_at IS NULL AND LOWER\(publisher\) LIKE LOWER\(\$1\) AND isbn = \$2 AND LOWER\(genre\) IN \(LOWER\(\$3\), LOWER\(\$4\)\) ` +
+			`AND \(genre IS NULL OR LOWER\(genre\) NOT IN \(LOWER\(\$5\)\)\) AND \(author IS NULL OR LOWER\(author\) NOT LIKE LOWER\(\$6\)\) ` +
+			`AND published_at >= \$7 AND published_at <= \$8 AND pages >= \$9`
+		args := []driver.Value{"%O'Reilly%", "9781234567897", "programming", "databases", "fiction", "%smith%", from, to, minPages}
+
+		mock.ExpectQuery(`FROM books ` + where + ` ORDER BY created_at DESC, id DESC LIMIT \$10 OFFSET \$11`).
+			WithArgs(append(args, 11, 0)...).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		_, hasMore, err := repo.GetAll(filter)
+
+		assert.NoError(t, err)
+		assert.False(t, hasMore)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestBookRepository_GetAllProjected(t *testing.T) {
+	t.Run("only projected columns are selected", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewBookRepository(&database.DB{DB: db})
+		id := uuid.New()
+
+		mock.ExpectQuery(`SELECT id, title, isbn, updated_at, \(created_at\)::text AS sort_key_0 FROM books WHERE deleted_at IS NULL ORDER BY created_at DESC, id DESC LIMIT \$1 OFFSET \$2`).
+			WithArgs(11, 0).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "title", "isbn", "updated_at", "sort_key_0"}).
+				AddRow(id, "The Hobbit", "9780547928227", time.Now(), "2024-03-01 00:00:00"))
+
+		books, _, err := repo.GetAll(models.BookFilter{
+			Limit:          10,
+			BookProjection: models.BookProjection{Fields: []string{"title", "isbn10"}},
+		})
+
+		assert.NoError(t, err)
+		assert.Len(t, books, 1)
+		assert.Equal(t, id, books[0].ID)
+		assert.Equal(t, "9780547928227", books[0].ISBN)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("unknown field is rejected", func(t *testing.T) {
+		db, _, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewBookRepository(&database.DB{DB: db})
+
+		_, _, err = repo.GetAll(models.BookFilter{BookProjection: models.BookProjection{Fields: []string{"search_vector"}}})
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid fields")
+	})
+}
+
+func TestBookRepository_GetProjected(t *testing.T) {
+	t.Run("copies are included with one query", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewBookRepository(&database.DB{DB: db})
+		id := uuid.New()
+		now := time.Now()
+
+		mock.ExpectQuery(`SELECT id, author, updated_at FROM books WHERE id = \$1 AND deleted_at IS NULL`).
+			WithArgs(id).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "author", "updated_at"}).AddRow(id, "J.R.R. Tolkien", now))
+		mock.ExpectQuery(`FROM copies WHERE book_id IN \(\$1\) ORDER BY barcode`).
+			WithArgs(id).
+			WillReturnRows(sqlmock.NewRows([]string{
+				"id", "book_id", "barcode", "status", "location", "condition", "notes", "created_at", "updated_at",
+			}).AddRow(uuid.New(), id, "LIB-0001", "available", "Main", "good", "", now, now))
+
+		book, err := repo.GetProjected(id, models.BookProjection{
+			Fields:  []string{"author"},
+			Include: []string{models.BookIncludeCopies},
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "J.R.R. Tolkien", book.Author)
+		assert.Len(t, book.Copies, 1)
+		assert.Equal(t, "LIB-0001", book.Copies[0].Barcode)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("missing book is not found", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewBookRepository(&database.DB{DB: db})
+		id := uuid.New()
+
+		mock.ExpectQuery(`SELECT id, title, updated_at FROM books WHERE id = \$1 AND deleted_at IS NULL`).
+			WithArgs(id).
+			WillReturnError(sql.ErrNoRows)
+
+		_, err = repo.GetProjected(id, models.BookProjection{Fields: []string{"title"}})
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "book not found")
+	})
+}
+
+func TestBookRepository_GetAllQuery(t *testing.T) {
+	t.Run("catalog query compiles to parameterized SQL", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewBookRepository(&database.DB{DB: db})
+
+		where := `WHERE deleted_at IS NULL AND \(LOWER\(author\) LIKE LOWER\(\$1\) AND pages > \$2 AND NOT COALESCE\(available = \$3, FALSE\) ` +
+			`AND \(published_at >= \$4 AND published_at < \$5\) AND \(LOWER\(title\) LIKE LOWER\(\$6\) OR LOWER\(author\) LIKE LOWER\(\$6\)\)\)`
+		args := []driver.Value{"%kernighan%", 300, true,
+			time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), `%100\%%`}
+
+		mock.ExpectQuery(`FROM books ` + where + ` ORDER BY created_at DESC, id DESC LIMIT \$7 OFFSET \$8`).
+			WithArgs(append(args, 11, 0)...).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		_, _, err = repo.GetAll(models.BookFilter{Query: "author:kernighan pages>300 -available published:2015..2020 100%", Limit: 10})
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("OR groups and exact fields", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewBookRepository(&database.DB{DB: db})
+
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*) FROM books WHERE deleted_at IS NULL AND available = $1 AND ((LOWER(language) = LOWER($2) OR isbn = $3) AND pages <= $4)`)).
+			WithArgs(true, "en", "9780306406157", 500).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(4))
+
+		available := true
+		total, err := repo.Count(models.BookFilter{Available: &available, Query: "(language:en OR isbn:0306406152) pages<=500"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 4, total)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("malformed query is rejected before querying", func(t *testing.T) {
+		db, _, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewBookRepository(&database.DB{DB: db})
+
+		_, _, err = repo.GetAll(models.BookFilter{Query: "pages>many"})
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid number")
+	})
 }
 
 func TestBookRepository_ExistsByISBN(t *testing.T) {
@@ -475,7 +1314,7 @@ func TestBookRepository_ExistsByISBN(t *testing.T) {
 
 		isbn := "9781234567890"
 
-		expectedQuery := `SELECT COUNT\(\*\) FROM books WHERE isbn = \$1`
+		expectedQuery := `SELECT COUNT\(\*\) FROM books WHERE isbn = \$1 AND deleted_at IS NULL`
 		mock.ExpectQuery(expectedQuery).
 			WithArgs(isbn).
 			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
@@ -497,7 +1336,7 @@ func TestBookRepository_ExistsByISBN(t *testing.T) {
 
 		isbn := "9781234567890"
 
-		expectedQuery := `SELECT COUNT\(\*\) FROM books WHERE isbn = \$1`
+		expectedQuery := `SELECT COUNT\(\*\) FROM books WHERE isbn = \$1 AND deleted_at IS NULL`
 		mock.ExpectQuery(expectedQuery).
 			WithArgs(isbn).
 			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
@@ -520,7 +1359,7 @@ func TestBookRepository_ExistsByISBN(t *testing.T) {
 		isbn := "9781234567890"
 		excludeID := uuid.New()
 
-		expectedQuery := `SELECT COUNT\(\*\) FROM books WHERE isbn = \$1 AND id != \$2`
+		expectedQuery := `SELECT COUNT\(\*\) FROM books WHERE isbn = \$1 AND deleted_at IS NULL AND id != \$2`
 		mock.ExpectQuery(expectedQuery).
 			WithArgs(isbn, excludeID).
 			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
@@ -532,3 +1371,260 @@ func TestBookRepository_ExistsByISBN(t *testing.T) {
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 }
+
+func TestBookRepository_Search(t *testing.T) {
+	t.Run("search books with ranking and headlines", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		dbWrapper := &database.DB{DB: db}
+		repo := NewBookRepository(dbWrapper)
+
+		id := uuid.New()
+		now := time.Now()
+
+		filter := models.BookSearchFilter{Query: "hobbit", Language: "english", Limit: 10}
+
+		mock.ExpectQuery(`SELECT COUNT\(\*\) FROM books WHERE \(\(search_vector @@ websearch_to_tsquery\('english'::regconfig, \$1\) AND book_search_config\(language\) = 'english'::regconfig\)\) AND deleted_at IS NULL AND LOWER\(language\) = LOWER\(\$2\)`).
+			WithArgs("hobbit", "english").
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+		mock.ExpectQuery(`SELECT id, title, (.+) AS rank, (.+) AS headline FROM books WHERE (.+) ORDER BY rank DESC, title ASC LIMIT \$3 OFFSET \$4`).
+			WithArgs("hobbit", "english", 10, 0).
+			WillReturnRows(sqlmock.NewRows([]string{
+				"id", "title", "author", "isbn", "publisher", "genre",
+				"published_at", "pages", "language", "available", "created_at", "updated_at", "version",
+				"rank", "headline",
+			}).
+				AddRow(id, "The Hobbit", "J.R.R. Tolkien", "9780261102217", "HarperCollins", "Fantasy",
+					time.Date(1937, 9, 21, 0, 0, 0, 0, time.UTC), 310, "English", true, now, now, 1,
+					0.5, "The <mark>Hobbit</mark> - J.R.R. Tolkien"))
+
+		results, total, err := repo.Search(filter)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, total)
+		assert.Len(t, results, 1)
+		assert.Equal(t, id, results[0].Book.ID)
+		assert.Equal(t, 0.5, results[0].Rank)
+		assert.Contains(t, results[0].Headline, "<mark>Hobbit</mark>")
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("search without a language parses the query once per configuration", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewBookRepository(&database.DB{DB: db})
+
+		mock.ExpectQuery(`SELECT COUNT\(\*\) FROM books WHERE \(\(search_vector @@ websearch_to_tsquery\('danish'::regconfig, \$1\) (.+) OR \(search_vector @@ websearch_to_tsquery\('simple'::regconfig, \$1\) AND book_search_config\(language\) = 'simple'::regconfig\)\) AND deleted_at IS NULL$`).
+			WithArgs("hobbit").
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		mock.ExpectQuery(`SELECT id, title, (.+) FROM books WHERE`).
+			WithArgs("hobbit", 50, 0).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		_, total, err := repo.Search(models.BookSearchFilter{Query: "hobbit"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0, total)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("search count failure", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		dbWrapper := &database.DB{DB: db}
+		repo := NewBookRepository(dbWrapper)
+
+		mock.ExpectQuery(`SELECT COUNT\(\*\) FROM books`).
+			WithArgs("hobbit").
+			WillReturnError(fmt.Errorf("connection lost"))
+
+		_, _, err = repo.Search(models.BookSearchFilter{Query: "hobbit"})
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to count search results")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestBookRepository_SuggestSimilar(t *testing.T) {
+	t.Run("suggest closest author", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		dbWrapper := &database.DB{DB: db}
+		repo := NewBookRepository(dbWrapper)
+
+		mock.ExpectQuery(`SELECT author FROM books WHERE author IS NOT NULL AND deleted_at IS NULL AND word_similarity\(LOWER\(\$1\), LOWER\(author\)\) >= \$2 GROUP BY author`).
+			WithArgs("Kernigan", 0.3).
+			WillReturnRows(sqlmock.NewRows([]string{"author"}).AddRow("Brian W. Kernighan"))
+
+		suggestion, err := repo.SuggestSimilar("author", "Kernigan", 0.3)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Brian W. Kernighan", suggestion)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("no similar value", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		dbWrapper := &database.DB{DB: db}
+		repo := NewBookRepository(dbWrapper)
+
+		mock.ExpectQuery(`SELECT genre FROM books`).
+			WithArgs("zzz", 0.3).
+			WillReturnError(sql.ErrNoRows)
+
+		suggestion, err := repo.SuggestSimilar("genre", "zzz", 0.3)
+
+		assert.NoError(t, err)
+		assert.Empty(t, suggestion)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("unknown field is rejected", func(t *testing.T) {
+		db, _, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewBookRepository(&database.DB{DB: db})
+
+		_, err = repo.SuggestSimilar("isbn; DROP TABLE books", "x", 0.3)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid suggestion field")
+	})
+}
+
+func TestBookRepository_SuggestByPrefix(t *testing.T) {
+	t.Run("distinct values matching the prefix", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewBookRepository(&database.DB{DB: db})
+
+		// Wildcards in the prefix are escaped so they match literally
+		mock.ExpectQuery(`SELECT title FROM books WHERE LOWER\(title\) LIKE \$1 AND deleted_at IS NULL GROUP BY title ORDER BY COUNT\(\*\) DESC`).
+			WithArgs(`the 100\%%`, 5).
+			WillReturnRows(sqlmock.NewRows([]string{"title"}).AddRow("The 100% Solution"))
+
+		suggestions, err := repo.SuggestByPrefix(models.SuggestFieldTitle, "The 100%", 5)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"The 100% Solution"}, suggestions)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("unknown field is rejected", func(t *testing.T) {
+		db, _, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewBookRepository(&database.DB{DB: db})
+
+		_, err = repo.SuggestByPrefix("genre; DROP TABLE books", "x", 5)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid suggestion field")
+	})
+}
+
+func TestBookRepository_SuggestionCounts(t *testing.T) {
+	t.Run("values are counted per book", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewBookRepository(&database.DB{DB: db})
+
+		mock.ExpectQuery(`SELECT author, COUNT\(\*\) FROM books WHERE author IS NOT NULL AND author <> '' AND deleted_at IS NULL GROUP BY author`).
+			WillReturnRows(sqlmock.NewRows([]string{"author", "count"}).
+				AddRow("J.R.R. Tolkien", 3).
+				AddRow("Frank Herbert", 1))
+
+		counts, err := repo.SuggestionCounts(models.SuggestFieldAuthor)
+
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]int{"J.R.R. Tolkien": 3, "Frank Herbert": 1}, counts)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("count query failure", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewBookRepository(&database.DB{DB: db})
+
+		mock.ExpectQuery(`FROM books`).WillReturnError(fmt.Errorf("connection lost"))
+
+		_, err = repo.SuggestionCounts(models.SuggestFieldPublisher)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to count publisher suggestions")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestBookRepository_GetFacets(t *testing.T) {
+	t.Run("facets are aggregated in one query", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		dbWrapper := &database.DB{DB: db}
+		repo := NewBookRepository(dbWrapper)
+
+		filter := models.BookFilter{Genre: "fantasy"}
+
+		mock.ExpectQuery(`GROUP BY GROUPING SETS \(\(genre\), \(language\), \(publisher\), \(author\), \(available\), \(decade\)\)`).
+			WithArgs("%fantasy%").
+			WillReturnRows(sqlmock.NewRows([]string{"facet", "value", "count"}).
+				AddRow("author", "J.R.R. Tolkien", 2).
+				AddRow("availability", "true", 1).
+				AddRow("availability", "false", 1).
+				AddRow("decade", "1930", 1).
+				AddRow("decade", "1950", 1).
+				AddRow("genre", "Fantasy", 2).
+				AddRow("language", "English", 2).
+				AddRow("publisher", "HarperCollins", 2))
+
+		facets, err := repo.GetFacets(filter)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []models.FacetCount{{Value: "Fantasy", Count: 2}}, facets.Genre)
+		assert.Len(t, facets.Availability, 2)
+		assert.Len(t, facets.Decade, 2)
+		assert.Equal(t, "J.R.R. Tolkien", facets.Author[0].Value)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("facet query failure", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewBookRepository(&database.DB{DB: db})
+
+		mock.ExpectQuery(`GROUPING SETS`).WillReturnError(fmt.Errorf("connection lost"))
+
+		_, err = repo.GetFacets(models.BookFilter{})
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to query facets")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,244 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"libmngmt/internal/database"
+	"libmngmt/internal/models"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CopyRepository defines the interface for physical copy data operations
+type CopyRepository interface {
+	Create(bookID uuid.UUID, item *models.CreateCopyRequest) (*models.Copy, error)
+	GetByID(id uuid.UUID) (*models.Copy, error)
+	GetByBook(bookID uuid.UUID) ([]models.Copy, error)
+	Update(id uuid.UUID, item *models.UpdateCopyRequest) (*models.Copy, error)
+	Delete(id uuid.UUID) error
+	ExistsByBarcode(barcode string, excludeID *uuid.UUID) (bool, error)
+}
+
+// copyRepository implements CopyRepository interface
+type copyRepository struct {
+	db *database.DB
+}
+
+// NewCopyRepository creates a new copy repository
+func NewCopyRepository(db *database.DB) CopyRepository {
+	return &copyRepository{db: db}
+}
+
+const copyColumns = "id, book_id, barcode, status, location, condition, notes, created_at, updated_at"
+
+// copyScanner is satisfied by both *sql.Row and *sql.Rows
+type copyScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanCopy(row copyScanner, item *models.Copy) error {
+	return row.Scan(
+		&item.ID, &item.BookID, &item.Barcode, &item.Status, &item.Location,
+		&item.Condition, &item.Notes, &item.CreatedAt, &item.UpdatedAt,
+	)
+}
+
+// Create adds a physical copy to a book
+func (r *copyRepository) Create(bookID uuid.UUID, req *models.CreateCopyRequest) (*models.Copy, error) {
+	item := &models.Copy{
+		ID:        uuid.New(),
+		BookID:    bookID,
+		Barcode:   req.Barcode,
+		Status:    req.Status,
+		Location:  req.Location,
+		Condition: req.Condition,
+		Notes:     req.Notes,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	if item.Status == "" {
+		item.Status = models.CopyStatusAvailable
+	}
+	if item.Condition == "" {
+		item.Condition = models.CopyConditionGood
+	}
+
+	query := `
+		INSERT INTO copies (id, book_id, barcode, status, location, condition, notes, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := r.db.QueryRow(
+		query,
+		item.ID, item.BookID, item.Barcode, item.Status, item.Location,
+		item.Condition, item.Notes, item.CreatedAt, item.UpdatedAt,
+	).Scan(&item.ID, &item.CreatedAt, &item.UpdatedAt)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create copy: %w", err)
+	}
+
+	return item, nil
+}
+
+// GetByID retrieves a copy by its ID
+func (r *copyRepository) GetByID(id uuid.UUID) (*models.Copy, error) {
+	query := fmt.Sprintf("SELECT %s FROM copies WHERE id = $1", copyColumns)
+
+	item := &models.Copy{}
+	if err := scanCopy(r.db.QueryRow(query, id), item); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("copy not found")
+		}
+		return nil, fmt.Errorf("failed to get copy: %w", err)
+	}
+
+	return item, nil
+}
+
+// GetByBook lists the copies of a book ordered by barcode
+func (r *copyRepository) GetByBook(bookID uuid.UUID) ([]models.Copy, error) {
+	query := fmt.Sprintf("SELECT %s FROM copies WHERE book_id = $1 ORDER BY barcode", copyColumns)
+
+	rows, err := r.db.Query(query, bookID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query copies: %w", err)
+	}
+	defer rows.Close()
+
+	copies := make([]models.Copy, 0)
+	for rows.Next() {
+		var item models.Copy
+		if err := scanCopy(rows, &item); err != nil {
+			return nil, fmt.Errorf("failed to scan copy: %w", err)
+		}
+		copies = append(copies, item)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate rows: %w", err)
+	}
+
+	return copies, nil
+}
+
+// Update updates a copy by its ID
+func (r *copyRepository) Update(id uuid.UUID, req *models.UpdateCopyRequest) (*models.Copy, error) {
+	currentCopy, err := r.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var setParts []string
+	var args []interface{}
+	argCount := 0
+
+	set := func(column string, value interface{}) {
+		argCount++
+		setParts = append(setParts, fmt.Sprintf("%s = $%d", column, argCount))
+		args = append(args, value)
+	}
+
+	if req.Barcode != nil {
+		set("barcode", *req.Barcode)
+	}
+	if req.Status != nil {
+		set("status", *req.Status)
+	}
+	if req.Location != nil {
+		set("location", *req.Location)
+	}
+	if req.Condition != nil {
+		set("condition", *req.Condition)
+	}
+	if req.Notes != nil {
+		set("notes", *req.Notes)
+	}
+
+	if len(setParts) == 0 {
+		return currentCopy, nil // No updates requested
+	}
+
+	set("updated_at", time.Now())
+
+	argCount++
+	args = append(args, id)
+
+	query := fmt.Sprintf(`
+		UPDATE copies
+		SET %s
+		WHERE id = $%d
+		RETURNING %s
+	`, strings.Join(setParts, ", "), argCount, copyColumns)
+
+	item := &models.Copy{}
+	if err := scanCopy(r.db.QueryRow(query, args...), item); err != nil {
+		return nil, fmt.Errorf("failed to update copy: %w", err)
+	}
+
+	return item, nil
+}
+
+// Delete removes a copy by its ID. The copy row is locked so a concurrent
+// checkout cannot take it, and a copy out on loan or set aside for a hold is
+// kept.
+func (r *copyRepository) Delete(id uuid.UUID) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var onLoan, setAside bool
+	err = tx.QueryRow(`
+		SELECT EXISTS (SELECT 1 FROM loans WHERE loans.copy_id = copies.id AND loans.returned_at IS NULL),
+			EXISTS (SELECT 1 FROM holds WHERE holds.copy_id = copies.id AND holds.status = 'ready')
+		FROM copies
+		WHERE id = $1
+		FOR UPDATE
+	`, id).Scan(&onLoan, &setAside)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("copy not found")
+		}
+		return fmt.Errorf("failed to lock copy: %w", err)
+	}
+	if onLoan {
+		return fmt.Errorf("copy is still checked out and cannot be deleted")
+	}
+	if setAside {
+		return fmt.Errorf("copy is set aside for a hold and cannot be deleted")
+	}
+
+	if _, err = tx.Exec("DELETE FROM copies WHERE id = $1", id); err != nil {
+		return fmt.Errorf("failed to delete copy: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit copy deletion: %w", err)
+	}
+
+	return nil
+}
+
+// ExistsByBarcode checks if a copy with the given barcode exists
+func (r *copyRepository) ExistsByBarcode(barcode string, excludeID *uuid.UUID) (bool, error) {
+	var count int
+	query := "SELECT COUNT(*) FROM copies WHERE barcode = $1"
+	args := []interface{}{barcode}
+
+	if excludeID != nil {
+		query += " AND id != $2"
+		args = append(args, *excludeID)
+	}
+
+	if err := r.db.QueryRow(query, args...).Scan(&count); err != nil {
+		return false, fmt.Errorf("failed to check barcode existence: %w", err)
+	}
+
+	return count > 0, nil
+}
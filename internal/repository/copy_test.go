@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"database/sql"
+	"libmngmt/internal/database"
+	"libmngmt/internal/models"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+var copyRowColumns = []string{
+	"id", "book_id", "barcode", "status", "location", "condition", "notes", "created_at", "updated_at",
+}
+
+func TestCopyRepository_Create(t *testing.T) {
+	t.Run("create copy with defaults", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewCopyRepository(&database.DB{DB: db})
+
+		id := uuid.New()
+		bookID := uuid.New()
+		now := time.Now()
+		req := &models.CreateCopyRequest{Barcode: "B0001", Location: "Main stacks"}
+
+		mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO copies`)).
+			WithArgs(sqlmock.AnyArg(), bookID, "B0001", models.CopyStatusAvailable, "Main stacks",
+				models.CopyConditionGood, "", sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow(id, now, now))
+
+		item, err := repo.Create(bookID, req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, id, item.ID)
+		assert.Equal(t, models.CopyStatusAvailable, item.Status)
+		assert.Equal(t, models.CopyConditionGood, item.Condition)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestCopyRepository_GetByID(t *testing.T) {
+	t.Run("get copy not found", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewCopyRepository(&database.DB{DB: db})
+		id := uuid.New()
+
+		mock.ExpectQuery(`SELECT (.+) FROM copies WHERE id = \$1`).
+			WithArgs(id).
+			WillReturnError(sql.ErrNoRows)
+
+		item, err := repo.GetByID(id)
+
+		assert.Error(t, err)
+		assert.Nil(t, item)
+		assert.Contains(t, err.Error(), "copy not found")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestCopyRepository_GetByBook(t *testing.T) {
+	t.Run("list copies of a book", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewCopyRepository(&database.DB{DB: db})
+		bookID := uuid.New()
+		now := time.Now()
+
+		mock.ExpectQuery(`SELECT (.+) FROM copies WHERE book_id = \$1 ORDER BY barcode`).
+			WithArgs(bookID).
+			WillReturnRows(sqlmock.NewRows(copyRowColumns).
+				AddRow(uuid.New(), bookID, "B0001", "available", "Main stacks", "good", "", now, now).
+				AddRow(uuid.New(), bookID, "B0002", "in_repair", "Bindery", "damaged", "Loose spine", now, now))
+
+		copies, err := repo.GetByBook(bookID)
+
+		assert.NoError(t, err)
+		assert.Len(t, copies, 2)
+		assert.Equal(t, models.CopyStatusInRepair, copies[1].Status)
+		assert.Equal(t, "Loose spine", copies[1].Notes)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestCopyRepository_Update(t *testing.T) {
+	t.Run("update copy status", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewCopyRepository(&database.DB{DB: db})
+		id := uuid.New()
+		bookID := uuid.New()
+		now := time.Now()
+		status := models.CopyStatusLost
+
+		mock.ExpectQuery(`SELECT (.+) FROM copies WHERE id = \$1`).
+			WithArgs(id).
+			WillReturnRows(sqlmock.NewRows(copyRowColumns).
+				AddRow(id, bookID, "B0001", "available", "Main stacks", "good", "", now, now))
+		mock.ExpectQuery(`UPDATE copies SET status = \$1, updated_at = \$2 WHERE id = \$3`).
+			WithArgs(status, sqlmock.AnyArg(), id).
+			WillReturnRows(sqlmock.NewRows(copyRowColumns).
+				AddRow(id, bookID, "B0001", "lost", "Main stacks", "good", "", now, now))
+
+		item, err := repo.Update(id, &models.UpdateCopyRequest{Status: &status})
+
+		assert.NoError(t, err)
+		assert.Equal(t, models.CopyStatusLost, item.Status)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestCopyRepository_Delete(t *testing.T) {
+	t.Run("copy on loan is kept", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewCopyRepository(&database.DB{DB: db})
+		id := uuid.New()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT EXISTS (.+) FROM copies WHERE id = \$1 FOR UPDATE`).
+			WithArgs(id).
+			WillReturnRows(sqlmock.NewRows([]string{"on_loan", "set_aside"}).AddRow(true, false))
+		mock.ExpectRollback()
+
+		err = repo.Delete(id)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "still checked out")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestCopyRepository_ExistsByBarcode(t *testing.T) {
+	t.Run("barcode exists", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewCopyRepository(&database.DB{DB: db})
+
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM copies WHERE barcode = $1")).
+			WithArgs("B0001").
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+		exists, err := repo.ExistsByBarcode("B0001", nil)
+
+		assert.NoError(t, err)
+		assert.True(t, exists)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
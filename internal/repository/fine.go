@@ -0,0 +1,213 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"libmngmt/internal/database"
+	"libmngmt/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FineRepository defines the interface for overdue fine data operations
+type FineRepository interface {
+	GetAccruing(now time.Time) ([]models.OverdueLoan, error)
+	Accrue(loan models.OverdueLoan, totalCents int64, closed bool) (int64, error)
+	RecordCredit(memberID uuid.UUID, entryType models.FineEntryType, req *models.FineCreditRequest) (*models.FineEntry, error)
+	GetLedger(memberID uuid.UUID) ([]models.FineEntry, error)
+}
+
+// fineRepository implements FineRepository interface
+type fineRepository struct {
+	db *database.DB
+}
+
+// NewFineRepository creates a new fine repository
+func NewFineRepository(db *database.DB) FineRepository {
+	return &fineRepository{db: db}
+}
+
+const fineEntryColumns = "id, member_id, loan_id, entry_type, amount_cents, note, created_at"
+
+// GetAccruing lists loans that are past due and whose fines are still open:
+// loans not yet returned, and late returns the sweep has not finalised. Loans
+// of purged books have no genre and accrue at the default rate.
+func (r *fineRepository) GetAccruing(now time.Time) ([]models.OverdueLoan, error) {
+	query := `
+		SELECT l.id, l.book_title, l.member_id, COALESCE(b.genre, ''), l.due_at, l.returned_at
+		FROM loans l
+		LEFT JOIN books b ON b.id = l.book_id
+		WHERE l.due_at < $1
+			AND NOT l.fines_closed
+			AND (l.returned_at IS NULL OR l.returned_at > l.due_at)
+		ORDER BY l.due_at
+	`
+
+	rows, err := r.db.Query(query, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query overdue loans: %w", err)
+	}
+	defer rows.Close()
+
+	loans := make([]models.OverdueLoan, 0)
+	for rows.Next() {
+		var loan models.OverdueLoan
+		if err := rows.Scan(
+			&loan.LoanID, &loan.BookTitle, &loan.MemberID, &loan.Genre, &loan.DueAt, &loan.ReturnedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan overdue loan: %w", err)
+		}
+		loans = append(loans, loan)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate rows: %w", err)
+	}
+
+	return loans, nil
+}
+
+// Accrue brings the charges posted for a loan up to totalCents, inserting a
+// charge for the difference. The loan row is locked so overlapping sweeps
+// cannot double-charge. When closed is set the loan's fine is finalised and
+// later sweeps skip it. It returns the amount newly charged.
+func (r *fineRepository) Accrue(loan models.OverdueLoan, totalCents int64, closed bool) (int64, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var finesClosed bool
+	err = tx.QueryRow("SELECT fines_closed FROM loans WHERE id = $1 FOR UPDATE", loan.LoanID).Scan(&finesClosed)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, fmt.Errorf("loan not found")
+		}
+		return 0, fmt.Errorf("failed to lock loan: %w", err)
+	}
+	if finesClosed {
+		return 0, nil
+	}
+
+	var charged int64
+	err = tx.QueryRow(
+		"SELECT COALESCE(SUM(amount_cents), 0) FROM fine_entries WHERE loan_id = $1 AND entry_type = 'charge'",
+		loan.LoanID,
+	).Scan(&charged)
+	if err != nil {
+		return 0, fmt.Errorf("failed to sum charges: %w", err)
+	}
+
+	delta := totalCents - charged
+	if delta > 0 {
+		_, err = tx.Exec(
+			"INSERT INTO fine_entries (id, member_id, loan_id, entry_type, amount_cents, note, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7)",
+			uuid.New(), loan.MemberID, loan.LoanID, models.FineEntryCharge, delta,
+			fmt.Sprintf("Overdue fine for %q due %s", loan.BookTitle, loan.DueAt.Format("2006-01-02")), time.Now(),
+		)
+		if err != nil {
+			return 0, fmt.Errorf("failed to post charge: %w", err)
+		}
+	} else {
+		delta = 0
+	}
+
+	if closed {
+		if _, err = tx.Exec("UPDATE loans SET fines_closed = true WHERE id = $1", loan.LoanID); err != nil {
+			return 0, fmt.Errorf("failed to close loan fines: %w", err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit accrual: %w", err)
+	}
+
+	return delta, nil
+}
+
+// RecordCredit posts a payment or waiver against a borrower's balance. The
+// member row is locked so concurrent credits cannot overdraw the balance.
+func (r *fineRepository) RecordCredit(memberID uuid.UUID, entryType models.FineEntryType, req *models.FineCreditRequest) (*models.FineEntry, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var lockedID uuid.UUID
+	err = tx.QueryRow("SELECT id FROM members WHERE id = $1 FOR UPDATE", memberID).Scan(&lockedID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("member not found")
+		}
+		return nil, fmt.Errorf("failed to lock member: %w", err)
+	}
+
+	var balance int64
+	err = tx.QueryRow(`
+		SELECT COALESCE(SUM(CASE WHEN entry_type = 'charge' THEN amount_cents ELSE -amount_cents END), 0)
+		FROM fine_entries
+		WHERE member_id = $1
+	`, memberID).Scan(&balance)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute balance: %w", err)
+	}
+	if req.AmountCents > balance {
+		return nil, fmt.Errorf("invalid amount_cents: exceeds outstanding balance of %d", balance)
+	}
+
+	entry := &models.FineEntry{
+		ID:          uuid.New(),
+		MemberID:    memberID,
+		LoanID:      req.LoanID,
+		EntryType:   entryType,
+		AmountCents: req.AmountCents,
+		Note:        req.Note,
+		CreatedAt:   time.Now(),
+	}
+
+	_, err = tx.Exec(
+		"INSERT INTO fine_entries (id, member_id, loan_id, entry_type, amount_cents, note, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7)",
+		entry.ID, entry.MemberID, entry.LoanID, entry.EntryType, entry.AmountCents, entry.Note, entry.CreatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record %s: %w", entryType, err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit %s: %w", entryType, err)
+	}
+
+	return entry, nil
+}
+
+// GetLedger returns every fine entry for a borrower, oldest first
+func (r *fineRepository) GetLedger(memberID uuid.UUID) ([]models.FineEntry, error) {
+	query := fmt.Sprintf("SELECT %s FROM fine_entries WHERE member_id = $1 ORDER BY created_at, id", fineEntryColumns)
+
+	rows, err := r.db.Query(query, memberID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query fine entries: %w", err)
+	}
+	defer rows.Close()
+
+	entries := make([]models.FineEntry, 0)
+	for rows.Next() {
+		var entry models.FineEntry
+		if err := rows.Scan(
+			&entry.ID, &entry.MemberID, &entry.LoanID, &entry.EntryType,
+			&entry.AmountCents, &entry.Note, &entry.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan fine entry: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate rows: %w", err)
+	}
+
+	return entries, nil
+}
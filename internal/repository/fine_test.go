@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"libmngmt/internal/database"
+	"libmngmt/internal/models"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFineRepository_Accrue(t *testing.T) {
+	t.Run("accrue posts only the uncharged difference", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewFineRepository(&database.DB{DB: db})
+		loan := models.OverdueLoan{
+			LoanID:    uuid.New(),
+			BookTitle: "Matilda",
+			MemberID:  uuid.New(),
+			DueAt:     time.Now().AddDate(0, 0, -4),
+		}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT fines_closed FROM loans WHERE id = $1 FOR UPDATE")).
+			WithArgs(loan.LoanID).
+			WillReturnRows(sqlmock.NewRows([]string{"fines_closed"}).AddRow(false))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT COALESCE(SUM(amount_cents), 0) FROM fine_entries")).
+			WithArgs(loan.LoanID).
+			WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(75))
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO fine_entries")).
+			WithArgs(sqlmock.AnyArg(), loan.MemberID, loan.LoanID, models.FineEntryCharge, int64(25), sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		charged, err := repo.Accrue(loan, 100, false)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(25), charged)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("accrue closes a returned loan without new charges", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewFineRepository(&database.DB{DB: db})
+		loan := models.OverdueLoan{LoanID: uuid.New(), MemberID: uuid.New()}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT fines_closed FROM loans")).
+			WithArgs(loan.LoanID).
+			WillReturnRows(sqlmock.NewRows([]string{"fines_closed"}).AddRow(false))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT COALESCE(SUM(amount_cents), 0) FROM fine_entries")).
+			WithArgs(loan.LoanID).
+			WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(100))
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE loans SET fines_closed = true WHERE id = $1")).
+			WithArgs(loan.LoanID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		charged, err := repo.Accrue(loan, 100, true)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), charged)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestFineRepository_RecordCredit(t *testing.T) {
+	t.Run("payment larger than balance is rejected", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewFineRepository(&database.DB{DB: db})
+		memberID := uuid.New()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT id FROM members WHERE id = $1 FOR UPDATE")).
+			WithArgs(memberID).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(memberID))
+		mock.ExpectQuery(`SELECT COALESCE\(SUM\(CASE WHEN entry_type = 'charge'`).
+			WithArgs(memberID).
+			WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow(50))
+		mock.ExpectRollback()
+
+		_, err = repo.RecordCredit(memberID, models.FineEntryPayment, &models.FineCreditRequest{AmountCents: 80})
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "exceeds outstanding balance")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("record payment", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewFineRepository(&database.DB{DB: db})
+		memberID := uuid.New()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT id FROM members WHERE id = $1 FOR UPDATE")).
+			WithArgs(memberID).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(memberID))
+		mock.ExpectQuery(`SELECT COALESCE\(SUM\(CASE WHEN entry_type = 'charge'`).
+			WithArgs(memberID).
+			WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow(50))
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO fine_entries")).
+			WithArgs(sqlmock.AnyArg(), memberID, nil, models.FineEntryPayment, int64(50), "cash", sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		entry, err := repo.RecordCredit(memberID, models.FineEntryPayment, &models.FineCreditRequest{AmountCents: 50, Note: "cash"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, models.FineEntryPayment, entry.EntryType)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestFineRepository_GetAccruing(t *testing.T) {
+	t.Run("list overdue loans with genre", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewFineRepository(&database.DB{DB: db})
+		now := time.Now()
+
+		mock.ExpectQuery(`SELECT (.+) FROM loans l LEFT JOIN books b ON b.id = l.book_id WHERE l.due_at < \$1`).
+			WithArgs(now).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "book_title", "member_id", "genre", "due_at", "returned_at"}).
+				AddRow(uuid.New(), "Matilda", uuid.New(), "Children", now.AddDate(0, 0, -3), nil))
+
+		loans, err := repo.GetAccruing(now)
+
+		assert.NoError(t, err)
+		assert.Len(t, loans, 1)
+		assert.Equal(t, "Children", loans[0].Genre)
+		assert.Nil(t, loans[0].ReturnedAt)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
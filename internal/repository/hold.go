@@ -0,0 +1,425 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"libmngmt/internal/database"
+	"libmngmt/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// HoldRepository defines the interface for hold (reservation queue) operations
+type HoldRepository interface {
+	Place(bookID, memberID uuid.UUID) (*models.Hold, error)
+	GetByID(id uuid.UUID) (*models.Hold, error)
+	GetByBook(bookID uuid.UUID) ([]models.Hold, error)
+	Cancel(id uuid.UUID, pickupWindow time.Duration) (*models.Hold, *models.Hold, error)
+	ExpireReady(now time.Time, pickupWindow time.Duration) (int, []models.Hold, error)
+	MarkNotified(id uuid.UUID) error
+	Release(bookID uuid.UUID, pickupWindow time.Duration) (*models.Hold, error)
+}
+
+// holdRepository implements HoldRepository interface
+type holdRepository struct {
+	db *database.DB
+}
+
+// NewHoldRepository creates a new hold repository
+func NewHoldRepository(db *database.DB) HoldRepository {
+	return &holdRepository{db: db}
+}
+
+const holdColumns = "id, book_id, member_id, status, created_at, ready_at, expires_at, notified_at, copy_id"
+
+// holdScanner is satisfied by both *sql.Row and *sql.Rows
+type holdScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanHold(row holdScanner, hold *models.Hold) error {
+	return row.Scan(
+		&hold.ID, &hold.BookID, &hold.MemberID, &hold.Status,
+		&hold.CreatedAt, &hold.ReadyAt, &hold.ExpiresAt, &hold.NotifiedAt, &hold.CopyID,
+	)
+}
+
+// Place queues a hold for a checked out book. The book row is locked so the
+// availability check and the insert happen atomically with checkouts and returns.
+func (r *holdRepository) Place(bookID, memberID uuid.UUID) (*models.Hold, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var available bool
+	err = tx.QueryRow("SELECT available FROM books WHERE id = $1 AND deleted_at IS NULL FOR UPDATE", bookID).Scan(&available)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("book not found")
+		}
+		return nil, fmt.Errorf("failed to lock book: %w", err)
+	}
+	if available {
+		return nil, fmt.Errorf("book is available for checkout, no hold needed")
+	}
+
+	var count int
+	err = tx.QueryRow(
+		"SELECT COUNT(*) FROM loans WHERE book_id = $1 AND member_id = $2 AND returned_at IS NULL",
+		bookID, memberID,
+	).Scan(&count)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check open loans: %w", err)
+	}
+	if count > 0 {
+		return nil, fmt.Errorf("member already has this book checked out")
+	}
+
+	err = tx.QueryRow(
+		"SELECT COUNT(*) FROM holds WHERE book_id = $1 AND member_id = $2 AND status IN ('waiting', 'ready')",
+		bookID, memberID,
+	).Scan(&count)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check existing holds: %w", err)
+	}
+	if count > 0 {
+		return nil, fmt.Errorf("member already has a hold on this book")
+	}
+
+	hold := &models.Hold{
+		ID:        uuid.New(),
+		BookID:    bookID,
+		MemberID:  memberID,
+		Status:    models.HoldStatusWaiting,
+		CreatedAt: time.Now(),
+	}
+
+	_, err = tx.Exec(
+		"INSERT INTO holds (id, book_id, member_id, status, created_at) VALUES ($1, $2, $3, $4, $5)",
+		hold.ID, hold.BookID, hold.MemberID, hold.Status, hold.CreatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create hold: %w", err)
+	}
+
+	err = tx.QueryRow(
+		"SELECT COUNT(*) FROM holds WHERE book_id = $1 AND status = 'waiting'",
+		bookID,
+	).Scan(&hold.Position)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute queue position: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit hold: %w", err)
+	}
+
+	return hold, nil
+}
+
+// GetByID retrieves a hold with its current queue position
+func (r *holdRepository) GetByID(id uuid.UUID) (*models.Hold, error) {
+	query := fmt.Sprintf(`
+		SELECT %s,
+			CASE WHEN h.status = 'waiting' THEN (
+				SELECT COUNT(*) FROM holds q
+				WHERE q.book_id = h.book_id AND q.status = 'waiting'
+					AND (q.created_at, q.id) <= (h.created_at, h.id)
+			) ELSE 0 END AS position
+		FROM holds h
+		WHERE h.id = $1
+	`, holdColumns)
+
+	hold := &models.Hold{}
+	err := r.db.QueryRow(query, id).Scan(
+		&hold.ID, &hold.BookID, &hold.MemberID, &hold.Status,
+		&hold.CreatedAt, &hold.ReadyAt, &hold.ExpiresAt, &hold.NotifiedAt, &hold.CopyID, &hold.Position,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("hold not found")
+		}
+		return nil, fmt.Errorf("failed to get hold: %w", err)
+	}
+
+	return hold, nil
+}
+
+// GetByBook returns the active queue for a book in pickup order
+func (r *holdRepository) GetByBook(bookID uuid.UUID) ([]models.Hold, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM holds
+		WHERE book_id = $1 AND status IN ('waiting', 'ready')
+		ORDER BY status = 'ready' DESC, created_at, id
+	`, holdColumns)
+
+	rows, err := r.db.Query(query, bookID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query holds: %w", err)
+	}
+	defer rows.Close()
+
+	holds := make([]models.Hold, 0)
+	position := 0
+	for rows.Next() {
+		var hold models.Hold
+		if err := scanHold(rows, &hold); err != nil {
+			return nil, fmt.Errorf("failed to scan hold: %w", err)
+		}
+		if hold.Status == models.HoldStatusWaiting {
+			position++
+			hold.Position = position
+		}
+		holds = append(holds, hold)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate rows: %w", err)
+	}
+
+	return holds, nil
+}
+
+// Cancel withdraws an active hold. Cancelling a hold that was ready for pickup
+// passes the book on to the next patron in the queue, whose hold is returned.
+func (r *holdRepository) Cancel(id uuid.UUID, pickupWindow time.Duration) (*models.Hold, *models.Hold, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	hold := &models.Hold{}
+	err = scanHold(tx.QueryRow(fmt.Sprintf("SELECT %s FROM holds WHERE id = $1 FOR UPDATE", holdColumns), id), hold)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil, fmt.Errorf("hold not found")
+		}
+		return nil, nil, fmt.Errorf("failed to get hold: %w", err)
+	}
+	if !hold.Status.IsActive() {
+		return nil, nil, fmt.Errorf("hold is already %s", hold.Status)
+	}
+
+	wasReady := hold.Status == models.HoldStatusReady
+	if _, err = tx.Exec("UPDATE holds SET status = 'cancelled' WHERE id = $1", id); err != nil {
+		return nil, nil, fmt.Errorf("failed to cancel hold: %w", err)
+	}
+	hold.Status = models.HoldStatusCancelled
+
+	var promoted *models.Hold
+	if wasReady {
+		if promoted, err = releaseBook(tx, hold.BookID, pickupWindow); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, nil, fmt.Errorf("failed to commit cancellation: %w", err)
+	}
+
+	return hold, promoted, nil
+}
+
+// ExpireReady expires holds that were not picked up before their deadline and
+// passes each book on to the next patron. It returns the number of expired
+// holds and the holds that became ready as a result.
+func (r *holdRepository) ExpireReady(now time.Time, pickupWindow time.Duration) (int, []models.Hold, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(`
+		UPDATE holds
+		SET status = 'expired'
+		WHERE id IN (
+			SELECT id FROM holds
+			WHERE status = 'ready' AND expires_at < $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING book_id
+	`, now)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to expire holds: %w", err)
+	}
+
+	var bookIDs []uuid.UUID
+	for rows.Next() {
+		var bookID uuid.UUID
+		if err := rows.Scan(&bookID); err != nil {
+			rows.Close()
+			return 0, nil, fmt.Errorf("failed to scan expired hold: %w", err)
+		}
+		bookIDs = append(bookIDs, bookID)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return 0, nil, fmt.Errorf("failed to iterate rows: %w", err)
+	}
+
+	promoted := make([]models.Hold, 0)
+	for _, bookID := range bookIDs {
+		hold, err := releaseBook(tx, bookID, pickupWindow)
+		if err != nil {
+			return 0, nil, err
+		}
+		if hold != nil {
+			promoted = append(promoted, *hold)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, nil, fmt.Errorf("failed to commit hold expiry: %w", err)
+	}
+
+	return len(bookIDs), promoted, nil
+}
+
+// MarkNotified records that the patron was told their hold is ready
+func (r *holdRepository) MarkNotified(id uuid.UUID) error {
+	_, err := r.db.Exec("UPDATE holds SET notified_at = $1 WHERE id = $2", time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("failed to mark hold notified: %w", err)
+	}
+	return nil
+}
+
+// Release sets a free copy of a book aside for the first waiting hold, which
+// is returned, and refreshes the book's availability. It is run after copies
+// are added or put back into circulation.
+func (r *holdRepository) Release(bookID uuid.UUID, pickupWindow time.Duration) (*models.Hold, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	hold, err := releaseBook(tx, bookID, pickupWindow)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit release: %w", err)
+	}
+
+	return hold, nil
+}
+
+// freeCopyCondition matches copies c that can be lent or set aside: shelved
+// as available, not out on an open loan and not held for pickup
+const freeCopyCondition = "c.status = 'available'" +
+	" AND NOT EXISTS (SELECT 1 FROM loans l WHERE l.copy_id = c.id AND l.returned_at IS NULL)" +
+	" AND NOT EXISTS (SELECT 1 FROM holds h WHERE h.copy_id = c.id AND h.status = 'ready')"
+
+// bookAvailableExpr derives books.available. A book with copies is available
+// while any of them is free; a book without copies circulates as a single
+// item and is available while it is neither on loan nor set aside.
+const bookAvailableExpr = "CASE WHEN EXISTS (SELECT 1 FROM copies c WHERE c.book_id = books.id)" +
+	" THEN EXISTS (SELECT 1 FROM copies c WHERE c.book_id = books.id AND " + freeCopyCondition + ")" +
+	" ELSE NOT EXISTS (SELECT 1 FROM loans l WHERE l.book_id = books.id AND l.returned_at IS NULL)" +
+	" AND NOT EXISTS (SELECT 1 FROM holds h WHERE h.book_id = books.id AND h.status = 'ready') END"
+
+// findFreeCopy locks a free copy of a book, skipping copies locked by other
+// transactions. A book without copies is reported free, with a nil copy,
+// while it is neither on loan nor set aside for a hold.
+func findFreeCopy(tx *sql.Tx, bookID uuid.UUID) (*uuid.UUID, bool, error) {
+	var copyID uuid.UUID
+	err := tx.QueryRow(fmt.Sprintf(`
+		SELECT c.id FROM copies c
+		WHERE c.book_id = $1 AND %s
+		ORDER BY c.barcode
+		LIMIT 1
+		FOR UPDATE SKIP LOCKED
+	`, freeCopyCondition), bookID).Scan(&copyID)
+	if err == nil {
+		return &copyID, true, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, false, fmt.Errorf("failed to find a free copy: %w", err)
+	}
+
+	var free bool
+	err = tx.QueryRow(`
+		SELECT NOT EXISTS (SELECT 1 FROM copies WHERE book_id = $1)
+			AND NOT EXISTS (SELECT 1 FROM loans WHERE book_id = $1 AND returned_at IS NULL)
+			AND NOT EXISTS (SELECT 1 FROM holds WHERE book_id = $1 AND status = 'ready')
+	`, bookID).Scan(&free)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to check book circulation: %w", err)
+	}
+
+	return nil, free, nil
+}
+
+// refreshAvailability recomputes a book's available flag from its copies,
+// writing the row only when the flag changes
+func refreshAvailability(tx *sql.Tx, bookID uuid.UUID) error {
+	query := fmt.Sprintf("UPDATE books SET available = %[1]s WHERE id = $1 AND available IS DISTINCT FROM %[1]s", bookAvailableExpr)
+	if _, err := tx.Exec(query, bookID); err != nil {
+		return fmt.Errorf("failed to update book availability: %w", err)
+	}
+	return nil
+}
+
+// releaseBook hands a copy of a book that came free to the first waiting hold,
+// setting it aside for pickup, and refreshes the book's availability. The book
+// row is locked so concurrent returns promote one hold per freed copy. Must be
+// called inside the transaction that freed the copy.
+func releaseBook(tx *sql.Tx, bookID uuid.UUID, pickupWindow time.Duration) (*models.Hold, error) {
+	var lockedID uuid.UUID
+	if err := tx.QueryRow("SELECT id FROM books WHERE id = $1 FOR UPDATE", bookID).Scan(&lockedID); err != nil {
+		return nil, fmt.Errorf("failed to lock book: %w", err)
+	}
+
+	copyID, free, err := findFreeCopy(tx, bookID)
+	if err != nil {
+		return nil, err
+	}
+
+	var promoted *models.Hold
+	if free {
+		next := &models.Hold{}
+		err = scanHold(tx.QueryRow(fmt.Sprintf(`
+			SELECT %s FROM holds
+			WHERE book_id = $1 AND status = 'waiting'
+			ORDER BY created_at, id
+			LIMIT 1
+			FOR UPDATE
+		`, holdColumns), bookID), next)
+
+		switch {
+		case err == sql.ErrNoRows:
+		case err != nil:
+			return nil, fmt.Errorf("failed to get next hold: %w", err)
+		default:
+			now := time.Now()
+			expiresAt := now.Add(pickupWindow)
+			_, err = tx.Exec(
+				"UPDATE holds SET status = 'ready', ready_at = $1, expires_at = $2, copy_id = $3 WHERE id = $4",
+				now, expiresAt, copyID, next.ID,
+			)
+			if err != nil {
+				return nil, fmt.Errorf("failed to mark hold ready: %w", err)
+			}
+
+			next.Status = models.HoldStatusReady
+			next.ReadyAt = &now
+			next.ExpiresAt = &expiresAt
+			next.CopyID = copyID
+			promoted = next
+		}
+	}
+
+	if err := refreshAvailability(tx, bookID); err != nil {
+		return nil, err
+	}
+
+	return promoted, nil
+}
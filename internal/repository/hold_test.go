@@ -0,0 +1,216 @@
+package repository
+
+import (
+	"libmngmt/internal/database"
+	"libmngmt/internal/models"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+var holdRowColumns = []string{
+	"id", "book_id", "member_id", "status", "created_at", "ready_at", "expires_at", "notified_at", "copy_id",
+}
+
+func TestHoldRepository_Place(t *testing.T) {
+	t.Run("place hold on checked out book", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewHoldRepository(&database.DB{DB: db})
+		bookID := uuid.New()
+		memberID := uuid.New()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT available FROM books WHERE id = $1 AND deleted_at IS NULL FOR UPDATE")).
+			WithArgs(bookID).
+			WillReturnRows(sqlmock.NewRows([]string{"available"}).AddRow(false))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM loans WHERE book_id = $1 AND member_id = $2")).
+			WithArgs(bookID, memberID).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM holds WHERE book_id = $1 AND member_id = $2")).
+			WithArgs(bookID, memberID).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO holds")).
+			WithArgs(sqlmock.AnyArg(), bookID, memberID, models.HoldStatusWaiting, sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM holds WHERE book_id = $1 AND status = 'waiting'")).
+			WithArgs(bookID).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+		mock.ExpectCommit()
+
+		hold, err := repo.Place(bookID, memberID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, models.HoldStatusWaiting, hold.Status)
+		assert.Equal(t, 3, hold.Position)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("place hold on available book", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewHoldRepository(&database.DB{DB: db})
+		bookID := uuid.New()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT available FROM books WHERE id = $1 AND deleted_at IS NULL FOR UPDATE")).
+			WithArgs(bookID).
+			WillReturnRows(sqlmock.NewRows([]string{"available"}).AddRow(true))
+		mock.ExpectRollback()
+
+		_, err = repo.Place(bookID, uuid.New())
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "no hold needed")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestHoldRepository_GetByBook(t *testing.T) {
+	t.Run("queue positions count only waiting holds", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewHoldRepository(&database.DB{DB: db})
+		bookID := uuid.New()
+		now := time.Now()
+		expires := now.Add(72 * time.Hour)
+
+		mock.ExpectQuery(`SELECT (.+) FROM holds WHERE book_id = \$1 AND status IN \('waiting', 'ready'\)`).
+			WithArgs(bookID).
+			WillReturnRows(sqlmock.NewRows(holdRowColumns).
+				AddRow(uuid.New(), bookID, uuid.New(), "ready", now.Add(-2*time.Hour), now, expires, nil, uuid.New()).
+				AddRow(uuid.New(), bookID, uuid.New(), "waiting", now.Add(-time.Hour), nil, nil, nil, nil).
+				AddRow(uuid.New(), bookID, uuid.New(), "waiting", now, nil, nil, nil, nil))
+
+		holds, err := repo.GetByBook(bookID)
+
+		assert.NoError(t, err)
+		assert.Len(t, holds, 3)
+		assert.Equal(t, 0, holds[0].Position)
+		assert.Equal(t, 1, holds[1].Position)
+		assert.Equal(t, 2, holds[2].Position)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestHoldRepository_ExpireReady(t *testing.T) {
+	t.Run("expired hold with empty queue frees the copy", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewHoldRepository(&database.DB{DB: db})
+		bookID := uuid.New()
+		now := time.Now()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`UPDATE holds SET status = 'expired'`).
+			WithArgs(now).
+			WillReturnRows(sqlmock.NewRows([]string{"book_id"}).AddRow(bookID))
+		expectReleaseWithFreeCopy(mock, bookID, uuid.New())
+		mock.ExpectQuery(`SELECT (.+) FROM holds WHERE book_id = \$1 AND status = 'waiting'`).
+			WithArgs(bookID).
+			WillReturnRows(sqlmock.NewRows(holdRowColumns))
+		expectRefreshAvailability(mock, bookID)
+		mock.ExpectCommit()
+
+		expired, promoted, err := repo.ExpireReady(now, 72*time.Hour)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, expired)
+		assert.Empty(t, promoted)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestHoldRepository_Release(t *testing.T) {
+	t.Run("free copy is set aside for the first waiting hold", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewHoldRepository(&database.DB{DB: db})
+		bookID := uuid.New()
+		copyID := uuid.New()
+		holdID := uuid.New()
+		now := time.Now()
+
+		mock.ExpectBegin()
+		expectReleaseWithFreeCopy(mock, bookID, copyID)
+		mock.ExpectQuery(`SELECT (.+) FROM holds WHERE book_id = \$1 AND status = 'waiting'`).
+			WithArgs(bookID).
+			WillReturnRows(sqlmock.NewRows(holdRowColumns).
+				AddRow(holdID, bookID, uuid.New(), "waiting", now.Add(-time.Hour), nil, nil, nil, nil))
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE holds SET status = 'ready', ready_at = $1, expires_at = $2, copy_id = $3 WHERE id = $4")).
+			WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), copyID, holdID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		expectRefreshAvailability(mock, bookID)
+		mock.ExpectCommit()
+
+		hold, err := repo.Release(bookID, 72*time.Hour)
+
+		assert.NoError(t, err)
+		assert.Equal(t, holdID, hold.ID)
+		assert.Equal(t, models.HoldStatusReady, hold.Status)
+		assert.Equal(t, copyID, *hold.CopyID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("no hold is promoted while every copy is out", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewHoldRepository(&database.DB{DB: db})
+		bookID := uuid.New()
+
+		mock.ExpectBegin()
+		expectBookLock(mock, bookID)
+		mock.ExpectQuery(`SELECT c.id FROM copies c WHERE c.book_id = \$1 AND c.status = 'available' (.+) FOR UPDATE SKIP LOCKED`).
+			WithArgs(bookID).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		mock.ExpectQuery(`SELECT NOT EXISTS \(SELECT 1 FROM copies WHERE book_id = \$1\)`).
+			WithArgs(bookID).
+			WillReturnRows(sqlmock.NewRows([]string{"free"}).AddRow(false))
+		expectRefreshAvailability(mock, bookID)
+		mock.ExpectCommit()
+
+		hold, err := repo.Release(bookID, 72*time.Hour)
+
+		assert.NoError(t, err)
+		assert.Nil(t, hold)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+// expectBookLock expects releaseBook to lock the book row
+func expectBookLock(mock sqlmock.Sqlmock, bookID uuid.UUID) {
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id FROM books WHERE id = $1 FOR UPDATE")).
+		WithArgs(bookID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(bookID))
+}
+
+// expectReleaseWithFreeCopy expects releaseBook to lock the book and find copyID free
+func expectReleaseWithFreeCopy(mock sqlmock.Sqlmock, bookID, copyID uuid.UUID) {
+	expectBookLock(mock, bookID)
+	mock.ExpectQuery(`SELECT c.id FROM copies c WHERE c.book_id = \$1 AND c.status = 'available' (.+) FOR UPDATE SKIP LOCKED`).
+		WithArgs(bookID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(copyID))
+}
+
+// expectRefreshAvailability expects the book's available flag to be recomputed
+func expectRefreshAvailability(mock sqlmock.Sqlmock, bookID uuid.UUID) {
+	mock.ExpectExec(`UPDATE books SET available = CASE (.+) WHERE id = \$1 AND available IS DISTINCT FROM CASE`).
+		WithArgs(bookID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+}
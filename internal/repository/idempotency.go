@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"libmngmt/internal/database"
+	"libmngmt/internal/models"
+	"time"
+)
+
+// IdempotencyRepository defines the interface for idempotency keys and the
+// responses replayed to retried requests
+type IdempotencyRepository interface {
+	Claim(key, fingerprint string, ttl time.Duration) (*models.IdempotencyRecord, bool, error)
+	Complete(key string, response *models.IdempotentResponse) error
+	Release(key string) error
+	DeleteExpired(before time.Time) (int, error)
+}
+
+// idempotencyRepository implements IdempotencyRepository interface
+type idempotencyRepository struct {
+	db *database.DB
+}
+
+// NewIdempotencyRepository creates a new idempotency key repository
+func NewIdempotencyRepository(db *database.DB) IdempotencyRepository {
+	return &idempotencyRepository{db: db}
+}
+
+const idempotencyColumns = "key, fingerprint, status_code, response_header, response_body, created_at, expires_at"
+
+// Claim takes an idempotency key for a new request, keeping it for ttl. A key
+// that is held and has not expired is not taken: its record is returned
+// instead, with claimed false, for the caller to replay or reject.
+func (r *idempotencyRepository) Claim(key, fingerprint string, ttl time.Duration) (*models.IdempotencyRecord, bool, error) {
+	// A key released between the insert and the read can be claimed again
+	for attempt := 0; attempt < 2; attempt++ {
+		now := time.Now()
+		result, err := r.db.Exec(
+			`INSERT INTO idempotency_keys (key, fingerprint, created_at, expires_at) VALUES ($1, $2, $3, $4)
+			ON CONFLICT (key) DO UPDATE SET
+				fingerprint = EXCLUDED.fingerprint, status_code = NULL, response_header = NULL, response_body = NULL,
+				created_at = EXCLUDED.created_at, expires_at = EXCLUDED.expires_at
+			WHERE idempotency_keys.expires_at <= EXCLUDED.created_at`,
+			key, fingerprint, now, now.Add(ttl),
+		)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to claim idempotency key: %w", err)
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		if rowsAffected == 1 {
+			return &models.IdempotencyRecord{Key: key, Fingerprint: fingerprint, CreatedAt: now, ExpiresAt: now.Add(ttl)}, true, nil
+		}
+
+		record, err := r.get(key)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to get idempotency key: %w", err)
+		}
+		return record, false, nil
+	}
+
+	return nil, false, fmt.Errorf("failed to claim idempotency key: key was released concurrently")
+}
+
+func (r *idempotencyRepository) get(key string) (*models.IdempotencyRecord, error) {
+	record := &models.IdempotencyRecord{}
+	var statusCode sql.NullInt64
+	var header, body []byte
+	err := r.db.QueryRow("SELECT "+idempotencyColumns+" FROM idempotency_keys WHERE key = $1", key).Scan(
+		&record.Key, &record.Fingerprint, &statusCode, &header, &body, &record.CreatedAt, &record.ExpiresAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if statusCode.Valid {
+		record.Response = &models.IdempotentResponse{StatusCode: int(statusCode.Int64), Body: body}
+		if header != nil {
+			if err := json.Unmarshal(header, &record.Response.Header); err != nil {
+				return nil, fmt.Errorf("failed to decode stored response header: %w", err)
+			}
+		}
+	}
+
+	return record, nil
+}
+
+// Complete stores the response to the request holding a key, to be replayed
+// to its retries
+func (r *idempotencyRepository) Complete(key string, response *models.IdempotentResponse) error {
+	header, err := json.Marshal(response.Header)
+	if err != nil {
+		return fmt.Errorf("failed to encode response header: %w", err)
+	}
+
+	_, err = r.db.Exec(
+		"UPDATE idempotency_keys SET status_code = $2, response_header = $3, response_body = $4 WHERE key = $1 AND status_code IS NULL",
+		key, response.StatusCode, string(header), response.Body,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to store idempotent response: %w", err)
+	}
+	return nil
+}
+
+// Release gives up a key whose request produced no response worth replaying,
+// so a retry runs the request again
+func (r *idempotencyRepository) Release(key string) error {
+	_, err := r.db.Exec("DELETE FROM idempotency_keys WHERE key = $1 AND status_code IS NULL", key)
+	if err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpired removes the keys that expired before the given time
+func (r *idempotencyRepository) DeleteExpired(before time.Time) (int, error) {
+	result, err := r.db.Exec("DELETE FROM idempotency_keys WHERE expires_at <= $1", before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired idempotency keys: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return int(rowsAffected), nil
+}
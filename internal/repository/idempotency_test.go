@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"libmngmt/internal/database"
+	"libmngmt/internal/models"
+	"net/http"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+var idempotencyRowColumns = []string{"key", "fingerprint", "status_code", "response_header", "response_body", "created_at", "expires_at"}
+
+func TestIdempotencyRepository_Claim(t *testing.T) {
+	t.Run("a new key is claimed", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewIdempotencyRepository(&database.DB{DB: db})
+
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO idempotency_keys (key, fingerprint, created_at, expires_at)")).
+			WithArgs("import-42", "abc", sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		record, claimed, err := repo.Claim("import-42", "abc", time.Hour)
+
+		assert.NoError(t, err)
+		assert.True(t, claimed)
+		assert.Nil(t, record.Response)
+		assert.Equal(t, time.Hour, record.ExpiresAt.Sub(record.CreatedAt))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("a completed key returns its stored response", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewIdempotencyRepository(&database.DB{DB: db})
+
+		now := time.Now()
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO idempotency_keys")).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(regexp.QuoteMeta("FROM idempotency_keys WHERE key = $1")).
+			WithArgs("import-42").
+			WillReturnRows(sqlmock.NewRows(idempotencyRowColumns).
+				AddRow("import-42", "abc", 201, []byte(`{"Etag":["\"v1\""]}`), []byte(`{"data":{}}`), now, now.Add(time.Hour)))
+
+		record, claimed, err := repo.Claim("import-42", "abc", time.Hour)
+
+		assert.NoError(t, err)
+		assert.False(t, claimed)
+		assert.Equal(t, http.StatusCreated, record.Response.StatusCode)
+		assert.Equal(t, `"v1"`, record.Response.Header.Get("ETag"))
+		assert.Equal(t, `{"data":{}}`, string(record.Response.Body))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("a key in flight has no response", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewIdempotencyRepository(&database.DB{DB: db})
+
+		now := time.Now()
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO idempotency_keys")).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(regexp.QuoteMeta("FROM idempotency_keys WHERE key = $1")).
+			WithArgs("import-42").
+			WillReturnRows(sqlmock.NewRows(idempotencyRowColumns).
+				AddRow("import-42", "abc", nil, nil, nil, now, now.Add(time.Hour)))
+
+		record, claimed, err := repo.Claim("import-42", "abc", time.Hour)
+
+		assert.NoError(t, err)
+		assert.False(t, claimed)
+		assert.Nil(t, record.Response)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestIdempotencyRepository_Complete(t *testing.T) {
+	t.Run("the response is stored for the key in flight", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewIdempotencyRepository(&database.DB{DB: db})
+
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE idempotency_keys SET status_code = $2, response_header = $3, response_body = $4 WHERE key = $1 AND status_code IS NULL")).
+			WithArgs("import-42", 201, `{"Content-Type":["application/json"]}`, []byte(`{}`)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err = repo.Complete("import-42", &models.IdempotentResponse{
+			StatusCode: http.StatusCreated,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       []byte(`{}`),
+		})
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestIdempotencyRepository_DeleteExpired(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewIdempotencyRepository(&database.DB{DB: db})
+
+	now := time.Now()
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM idempotency_keys WHERE expires_at <= $1")).
+		WithArgs(now).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	deleted, err := repo.DeleteExpired(now)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, deleted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
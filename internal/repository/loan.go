@@ -0,0 +1,275 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"libmngmt/internal/database"
+	"libmngmt/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LoanRepository defines the interface for loan data operations
+type LoanRepository interface {
+	Checkout(bookID, memberID uuid.UUID, dueAt time.Time) (*models.Loan, error)
+	Return(bookID uuid.UUID, copyID *uuid.UUID, pickupWindow time.Duration) (*models.Loan, *models.Hold, error)
+	GetOpenByBook(bookID uuid.UUID) (*models.Loan, error)
+	GetByMember(memberID uuid.UUID, openOnly bool) ([]models.Loan, error)
+}
+
+// loanRepository implements LoanRepository interface
+type loanRepository struct {
+	db *database.DB
+}
+
+// NewLoanRepository creates a new loan repository
+func NewLoanRepository(db *database.DB) LoanRepository {
+	return &loanRepository{db: db}
+}
+
+const loanColumns = "id, book_id, book_title, book_isbn, copy_id, member_id, checked_out_at, due_at, returned_at"
+
+// loanScanner is satisfied by both *sql.Row and *sql.Rows
+type loanScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanLoan(row loanScanner, loan *models.Loan) error {
+	return row.Scan(
+		&loan.ID, &loan.BookID, &loan.BookTitle, &loan.BookISBN, &loan.CopyID,
+		&loan.MemberID, &loan.CheckedOutAt, &loan.DueAt, &loan.ReturnedAt,
+	)
+}
+
+// Checkout lends a copy of a book inside a transaction. The book row is locked
+// with SELECT ... FOR UPDATE so concurrent checkouts of the same book
+// serialize, and the copy is taken from the free ones with SKIP LOCKED. A
+// member collecting a ready hold gets the copy set aside for them, which
+// fulfils the hold. Books without registered copies are lent as a single item.
+func (r *loanRepository) Checkout(bookID, memberID uuid.UUID, dueAt time.Time) (*models.Loan, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	loan := &models.Loan{
+		ID:           uuid.New(),
+		BookID:       &bookID,
+		MemberID:     memberID,
+		CheckedOutAt: time.Now(),
+		DueAt:        dueAt,
+	}
+
+	err = tx.QueryRow(
+		"SELECT title, isbn FROM books WHERE id = $1 AND deleted_at IS NULL FOR UPDATE", bookID,
+	).Scan(&loan.BookTitle, &loan.BookISBN)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("book not found")
+		}
+		return nil, fmt.Errorf("failed to lock book: %w", err)
+	}
+
+	var openLoans int
+	err = tx.QueryRow(
+		"SELECT COUNT(*) FROM loans WHERE book_id = $1 AND member_id = $2 AND returned_at IS NULL",
+		bookID, memberID,
+	).Scan(&openLoans)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check open loans: %w", err)
+	}
+	if openLoans > 0 {
+		return nil, fmt.Errorf("member already has this book checked out")
+	}
+
+	var holdID uuid.UUID
+	var copyID *uuid.UUID
+	err = tx.QueryRow(
+		"SELECT id, copy_id FROM holds WHERE book_id = $1 AND member_id = $2 AND status = 'ready' FOR UPDATE",
+		bookID, memberID,
+	).Scan(&holdID, &copyID)
+	switch {
+	case err == sql.ErrNoRows:
+	case err != nil:
+		return nil, fmt.Errorf("failed to check ready holds: %w", err)
+	default:
+		if _, err = tx.Exec("UPDATE holds SET status = 'fulfilled' WHERE id = $1", holdID); err != nil {
+			return nil, fmt.Errorf("failed to fulfil hold: %w", err)
+		}
+	}
+
+	if copyID == nil {
+		var free bool
+		if copyID, free, err = findFreeCopy(tx, bookID); err != nil {
+			return nil, err
+		}
+		if !free {
+			return nil, checkoutRefusal(tx, bookID)
+		}
+	}
+
+	loan.CopyID = copyID
+	_, err = tx.Exec(
+		`INSERT INTO loans (id, book_id, book_title, book_isbn, copy_id, member_id, checked_out_at, due_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		loan.ID, loan.BookID, loan.BookTitle, loan.BookISBN, loan.CopyID, loan.MemberID, loan.CheckedOutAt, loan.DueAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create loan: %w", err)
+	}
+
+	if copyID != nil {
+		if _, err = tx.Exec("UPDATE copies SET status = 'checked_out' WHERE id = $1", *copyID); err != nil {
+			return nil, fmt.Errorf("failed to update copy status: %w", err)
+		}
+	}
+
+	if err = refreshAvailability(tx, bookID); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit checkout: %w", err)
+	}
+
+	return loan, nil
+}
+
+// checkoutRefusal explains why no copy of a book can be lent
+func checkoutRefusal(tx *sql.Tx, bookID uuid.UUID) error {
+	var onHold, onLoan bool
+	err := tx.QueryRow(`
+		SELECT EXISTS (SELECT 1 FROM holds WHERE book_id = $1 AND status = 'ready'),
+			EXISTS (SELECT 1 FROM loans WHERE book_id = $1 AND returned_at IS NULL)
+	`, bookID).Scan(&onHold, &onLoan)
+
+	switch {
+	case err != nil:
+		return fmt.Errorf("failed to check book circulation: %w", err)
+	case onHold:
+		return fmt.Errorf("book is on hold for another member")
+	case onLoan:
+		return fmt.Errorf("book is already checked out")
+	default:
+		return fmt.Errorf("book has no copy available for checkout")
+	}
+}
+
+// Return closes an open loan of a book and puts the lent copy back into
+// circulation. The copy must be named when several copies are out. If patrons
+// are waiting the copy is set aside for the first hold in the queue, which is
+// returned; otherwise it becomes available again.
+func (r *loanRepository) Return(bookID uuid.UUID, copyID *uuid.UUID, pickupWindow time.Duration) (*models.Loan, *models.Hold, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := "SELECT id FROM loans WHERE book_id = $1 AND returned_at IS NULL"
+	args := []interface{}{bookID}
+	if copyID != nil {
+		query += " AND copy_id = $2"
+		args = append(args, *copyID)
+	}
+	query += " LIMIT 2 FOR UPDATE"
+
+	rows, err := tx.Query(query, args...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to find open loan: %w", err)
+	}
+	var loanIDs []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, nil, fmt.Errorf("failed to scan open loan: %w", err)
+		}
+		loanIDs = append(loanIDs, id)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to iterate rows: %w", err)
+	}
+
+	switch {
+	case len(loanIDs) == 0 && copyID != nil:
+		return nil, nil, fmt.Errorf("copy is not checked out")
+	case len(loanIDs) == 0:
+		return nil, nil, fmt.Errorf("book is not checked out")
+	case len(loanIDs) > 1:
+		return nil, nil, fmt.Errorf("copy_id is required when several copies of the book are on loan")
+	}
+
+	query = fmt.Sprintf("UPDATE loans SET returned_at = $1 WHERE id = $2 RETURNING %s", loanColumns)
+
+	loan := &models.Loan{}
+	if err = scanLoan(tx.QueryRow(query, time.Now(), loanIDs[0]), loan); err != nil {
+		return nil, nil, fmt.Errorf("failed to close loan: %w", err)
+	}
+
+	if loan.CopyID != nil {
+		_, err = tx.Exec("UPDATE copies SET status = 'available' WHERE id = $1 AND status = 'checked_out'", *loan.CopyID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to update copy status: %w", err)
+		}
+	}
+
+	hold, err := releaseBook(tx, bookID, pickupWindow)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, nil, fmt.Errorf("failed to commit return: %w", err)
+	}
+
+	return loan, hold, nil
+}
+
+// GetOpenByBook retrieves the oldest open loan for a book
+func (r *loanRepository) GetOpenByBook(bookID uuid.UUID) (*models.Loan, error) {
+	query := fmt.Sprintf("SELECT %s FROM loans WHERE book_id = $1 AND returned_at IS NULL ORDER BY checked_out_at LIMIT 1", loanColumns)
+
+	loan := &models.Loan{}
+	if err := scanLoan(r.db.QueryRow(query, bookID), loan); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("loan not found")
+		}
+		return nil, fmt.Errorf("failed to get loan: %w", err)
+	}
+
+	return loan, nil
+}
+
+// GetByMember lists a member's loans, most recent first
+func (r *loanRepository) GetByMember(memberID uuid.UUID, openOnly bool) ([]models.Loan, error) {
+	query := fmt.Sprintf("SELECT %s FROM loans WHERE member_id = $1", loanColumns)
+	if openOnly {
+		query += " AND returned_at IS NULL"
+	}
+	query += " ORDER BY checked_out_at DESC"
+
+	rows, err := r.db.Query(query, memberID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query loans: %w", err)
+	}
+	defer rows.Close()
+
+	loans := make([]models.Loan, 0)
+	for rows.Next() {
+		var loan models.Loan
+		if err := scanLoan(rows, &loan); err != nil {
+			return nil, fmt.Errorf("failed to scan loan: %w", err)
+		}
+		loans = append(loans, loan)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate rows: %w", err)
+	}
+
+	return loans, nil
+}
@@ -0,0 +1,348 @@
+package repository
+
+import (
+	"libmngmt/internal/database"
+	"libmngmt/internal/models"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+var loanRowColumns = []string{
+	"id", "book_id", "book_title", "book_isbn", "copy_id", "member_id", "checked_out_at", "due_at", "returned_at",
+}
+
+// expectCheckoutStart expects the book lock and the checks for the member's
+// own open loan and ready hold, neither of which exists
+func expectCheckoutStart(mock sqlmock.Sqlmock, bookID, memberID uuid.UUID) {
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT title, isbn FROM books WHERE id = $1 AND deleted_at IS NULL FOR UPDATE")).
+		WithArgs(bookID).
+		WillReturnRows(sqlmock.NewRows([]string{"title", "isbn"}).AddRow("Dune", "9780441172719"))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM loans WHERE book_id = $1 AND member_id = $2 AND returned_at IS NULL")).
+		WithArgs(bookID, memberID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, copy_id FROM holds WHERE book_id = $1 AND member_id = $2 AND status = 'ready' FOR UPDATE")).
+		WithArgs(bookID, memberID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "copy_id"}))
+}
+
+// expectNoFreeCopy expects the search for a free copy of a book with copies to come up empty
+func expectNoFreeCopy(mock sqlmock.Sqlmock, bookID uuid.UUID) {
+	mock.ExpectQuery(`SELECT c.id FROM copies c WHERE c.book_id = \$1 (.+) FOR UPDATE SKIP LOCKED`).
+		WithArgs(bookID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery(`SELECT NOT EXISTS \(SELECT 1 FROM copies WHERE book_id = \$1\)`).
+		WithArgs(bookID).
+		WillReturnRows(sqlmock.NewRows([]string{"free"}).AddRow(false))
+}
+
+func TestLoanRepository_Checkout(t *testing.T) {
+	t.Run("checkout takes a free copy", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewLoanRepository(&database.DB{DB: db})
+
+		bookID := uuid.New()
+		copyID := uuid.New()
+		memberID := uuid.New()
+		dueAt := time.Now().Add(14 * 24 * time.Hour)
+
+		expectCheckoutStart(mock, bookID, memberID)
+		mock.ExpectQuery(`SELECT c.id FROM copies c WHERE c.book_id = \$1 (.+) FOR UPDATE SKIP LOCKED`).
+			WithArgs(bookID).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(copyID))
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO loans (id, book_id, book_title, book_isbn, copy_id, member_id, checked_out_at, due_at)")).
+			WithArgs(sqlmock.AnyArg(), &bookID, "Dune", "9780441172719", &copyID, memberID, sqlmock.AnyArg(), dueAt).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE copies SET status = 'checked_out' WHERE id = $1")).
+			WithArgs(copyID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		expectRefreshAvailability(mock, bookID)
+		mock.ExpectCommit()
+
+		loan, err := repo.Checkout(bookID, memberID, dueAt)
+
+		assert.NoError(t, err)
+		assert.Equal(t, bookID, *loan.BookID)
+		assert.Equal(t, "Dune", loan.BookTitle)
+		assert.Equal(t, copyID, *loan.CopyID)
+		assert.Equal(t, memberID, loan.MemberID)
+		assert.True(t, loan.IsOpen())
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("checkout with every copy on loan rolls back", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewLoanRepository(&database.DB{DB: db})
+		bookID := uuid.New()
+		memberID := uuid.New()
+
+		expectCheckoutStart(mock, bookID, memberID)
+		expectNoFreeCopy(mock, bookID)
+		mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM holds`).
+			WithArgs(bookID).
+			WillReturnRows(sqlmock.NewRows([]string{"on_hold", "on_loan"}).AddRow(false, true))
+		mock.ExpectRollback()
+
+		loan, err := repo.Checkout(bookID, memberID, time.Now().Add(time.Hour))
+
+		assert.Error(t, err)
+		assert.Nil(t, loan)
+		assert.Contains(t, err.Error(), "already checked out")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("member cannot borrow a second copy", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewLoanRepository(&database.DB{DB: db})
+		bookID := uuid.New()
+		memberID := uuid.New()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT title, isbn FROM books WHERE id = $1 AND deleted_at IS NULL FOR UPDATE")).
+			WithArgs(bookID).
+			WillReturnRows(sqlmock.NewRows([]string{"title", "isbn"}).AddRow("Dune", "9780441172719"))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM loans WHERE book_id = $1 AND member_id = $2")).
+			WithArgs(bookID, memberID).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+		mock.ExpectRollback()
+
+		_, err = repo.Checkout(bookID, memberID, time.Now().Add(time.Hour))
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "member already has this book checked out")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("checkout missing book", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewLoanRepository(&database.DB{DB: db})
+		bookID := uuid.New()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT title, isbn FROM books WHERE id = $1 AND deleted_at IS NULL FOR UPDATE")).
+			WithArgs(bookID).
+			WillReturnRows(sqlmock.NewRows([]string{"title", "isbn"}))
+		mock.ExpectRollback()
+
+		_, err = repo.Checkout(bookID, uuid.New(), time.Now().Add(time.Hour))
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "book not found")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestLoanRepository_Return(t *testing.T) {
+	t.Run("return puts the copy back on the shelf", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewLoanRepository(&database.DB{DB: db})
+
+		bookID := uuid.New()
+		copyID := uuid.New()
+		loanID := uuid.New()
+		now := time.Now()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT id FROM loans WHERE book_id = $1 AND returned_at IS NULL AND copy_id = $2 LIMIT 2 FOR UPDATE")).
+			WithArgs(bookID, copyID).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(loanID))
+		mock.ExpectQuery(regexp.QuoteMeta("UPDATE loans SET returned_at = $1 WHERE id = $2 RETURNING")).
+			WithArgs(sqlmock.AnyArg(), loanID).
+			WillReturnRows(sqlmock.NewRows(loanRowColumns).
+				AddRow(loanID, bookID, "Dune", "9780441172719", copyID, uuid.New(), now.Add(-time.Hour), now.Add(time.Hour), now))
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE copies SET status = 'available' WHERE id = $1 AND status = 'checked_out'")).
+			WithArgs(copyID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		expectReleaseWithFreeCopy(mock, bookID, copyID)
+		mock.ExpectQuery(`SELECT (.+) FROM holds WHERE book_id = \$1 AND status = 'waiting'`).
+			WithArgs(bookID).
+			WillReturnRows(sqlmock.NewRows(holdRowColumns))
+		expectRefreshAvailability(mock, bookID)
+		mock.ExpectCommit()
+
+		loan, hold, err := repo.Return(bookID, &copyID, 72*time.Hour)
+
+		assert.NoError(t, err)
+		assert.False(t, loan.IsOpen())
+		assert.Equal(t, copyID, *loan.CopyID)
+		assert.Nil(t, hold)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("return book that is not on loan", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewLoanRepository(&database.DB{DB: db})
+		bookID := uuid.New()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT id FROM loans WHERE book_id = $1 AND returned_at IS NULL LIMIT 2 FOR UPDATE")).
+			WithArgs(bookID).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		mock.ExpectRollback()
+
+		_, _, err = repo.Return(bookID, nil, 72*time.Hour)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "book is not checked out")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("return must name the copy when several are on loan", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewLoanRepository(&database.DB{DB: db})
+		bookID := uuid.New()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT id FROM loans WHERE book_id = $1 AND returned_at IS NULL LIMIT 2 FOR UPDATE")).
+			WithArgs(bookID).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()).AddRow(uuid.New()))
+		mock.ExpectRollback()
+
+		_, _, err = repo.Return(bookID, nil, 72*time.Hour)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "copy_id is required")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestLoanRepository_ReturnWithHolds(t *testing.T) {
+	t.Run("return sets the copy aside for the first waiting hold", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewLoanRepository(&database.DB{DB: db})
+
+		bookID := uuid.New()
+		copyID := uuid.New()
+		loanID := uuid.New()
+		holdID := uuid.New()
+		memberID := uuid.New()
+		now := time.Now()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT id FROM loans WHERE book_id = \$1 AND returned_at IS NULL`).
+			WithArgs(bookID).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(loanID))
+		mock.ExpectQuery(`UPDATE loans SET returned_at`).
+			WithArgs(sqlmock.AnyArg(), loanID).
+			WillReturnRows(sqlmock.NewRows(loanRowColumns).
+				AddRow(loanID, bookID, "Dune", "9780441172719", copyID, uuid.New(), now.Add(-time.Hour), now.Add(time.Hour), now))
+		mock.ExpectExec(`UPDATE copies SET status = 'available'`).
+			WithArgs(copyID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		expectReleaseWithFreeCopy(mock, bookID, copyID)
+		mock.ExpectQuery(`SELECT (.+) FROM holds WHERE book_id = \$1 AND status = 'waiting'`).
+			WithArgs(bookID).
+			WillReturnRows(sqlmock.NewRows(holdRowColumns).
+				AddRow(holdID, bookID, memberID, "waiting", now.Add(-time.Hour), nil, nil, nil, nil))
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE holds SET status = 'ready', ready_at = $1, expires_at = $2, copy_id = $3 WHERE id = $4")).
+			WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), copyID, holdID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		expectRefreshAvailability(mock, bookID)
+		mock.ExpectCommit()
+
+		_, hold, err := repo.Return(bookID, nil, 72*time.Hour)
+
+		assert.NoError(t, err)
+		assert.NotNil(t, hold)
+		assert.Equal(t, holdID, hold.ID)
+		assert.Equal(t, models.HoldStatusReady, hold.Status)
+		assert.Equal(t, copyID, *hold.CopyID)
+		assert.WithinDuration(t, now.Add(72*time.Hour), *hold.ExpiresAt, time.Minute)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestLoanRepository_CheckoutWithReadyHold(t *testing.T) {
+	t.Run("member collecting a ready hold gets the copy set aside", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewLoanRepository(&database.DB{DB: db})
+		bookID := uuid.New()
+		copyID := uuid.New()
+		holdID := uuid.New()
+		memberID := uuid.New()
+		dueAt := time.Now().Add(time.Hour)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT title, isbn FROM books WHERE id = $1 AND deleted_at IS NULL FOR UPDATE")).
+			WithArgs(bookID).
+			WillReturnRows(sqlmock.NewRows([]string{"title", "isbn"}).AddRow("Dune", "9780441172719"))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM loans")).
+			WithArgs(bookID, memberID).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT id, copy_id FROM holds")).
+			WithArgs(bookID, memberID).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "copy_id"}).AddRow(holdID, copyID))
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE holds SET status = 'fulfilled' WHERE id = $1")).
+			WithArgs(holdID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO loans")).
+			WithArgs(sqlmock.AnyArg(), &bookID, "Dune", "9780441172719", &copyID, memberID, sqlmock.AnyArg(), dueAt).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE copies SET status = 'checked_out'")).
+			WithArgs(copyID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		expectRefreshAvailability(mock, bookID)
+		mock.ExpectCommit()
+
+		loan, err := repo.Checkout(bookID, memberID, dueAt)
+
+		assert.NoError(t, err)
+		assert.Equal(t, copyID, *loan.CopyID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("book on hold for another member cannot be checked out", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewLoanRepository(&database.DB{DB: db})
+		bookID := uuid.New()
+		memberID := uuid.New()
+
+		expectCheckoutStart(mock, bookID, memberID)
+		expectNoFreeCopy(mock, bookID)
+		mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM holds`).
+			WithArgs(bookID).
+			WillReturnRows(sqlmock.NewRows([]string{"on_hold", "on_loan"}).AddRow(true, false))
+		mock.ExpectRollback()
+
+		_, err = repo.Checkout(bookID, memberID, time.Now().Add(time.Hour))
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "on hold for another member")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
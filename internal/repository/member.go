@@ -0,0 +1,289 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"libmngmt/internal/database"
+	"libmngmt/internal/models"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MemberRepository defines the interface for member data operations
+type MemberRepository interface {
+	Create(member *models.CreateMemberRequest) (*models.Member, error)
+	GetByID(id uuid.UUID) (*models.Member, error)
+	GetAll(filter models.MemberFilter) ([]models.Member, int, error)
+	Update(id uuid.UUID, member *models.UpdateMemberRequest) (*models.Member, error)
+	Delete(id uuid.UUID) error
+	ExistsByCardNumber(cardNumber string, excludeID *uuid.UUID) (bool, error)
+}
+
+// memberRepository implements MemberRepository interface
+type memberRepository struct {
+	db *database.DB
+}
+
+// NewMemberRepository creates a new member repository
+func NewMemberRepository(db *database.DB) MemberRepository {
+	return &memberRepository{db: db}
+}
+
+const memberColumns = "id, card_number, first_name, last_name, email, phone, address, status, expires_at, created_at, updated_at"
+
+// Create creates a new member
+func (r *memberRepository) Create(req *models.CreateMemberRequest) (*models.Member, error) {
+	member := &models.Member{
+		ID:         uuid.New(),
+		CardNumber: req.CardNumber,
+		FirstName:  req.FirstName,
+		LastName:   req.LastName,
+		Email:      req.Email,
+		Phone:      req.Phone,
+		Address:    req.Address,
+		Status:     req.Status,
+		ExpiresAt:  req.ExpiresAt,
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
+	}
+
+	if member.Status == "" {
+		member.Status = models.MemberStatusActive
+	}
+
+	query := `
+		INSERT INTO members (id, card_number, first_name, last_name, email, phone, address, status, expires_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := r.db.QueryRow(
+		query,
+		member.ID, member.CardNumber, member.FirstName, member.LastName, member.Email, member.Phone,
+		member.Address, member.Status, member.ExpiresAt, member.CreatedAt, member.UpdatedAt,
+	).Scan(&member.ID, &member.CreatedAt, &member.UpdatedAt)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create member: %w", err)
+	}
+
+	return member, nil
+}
+
+// GetByID retrieves a member by its ID
+func (r *memberRepository) GetByID(id uuid.UUID) (*models.Member, error) {
+	member := &models.Member{}
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM members
+		WHERE id = $1
+	`, memberColumns)
+
+	err := r.db.QueryRow(query, id).Scan(
+		&member.ID, &member.CardNumber, &member.FirstName, &member.LastName, &member.Email, &member.Phone,
+		&member.Address, &member.Status, &member.ExpiresAt, &member.CreatedAt, &member.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("member not found")
+		}
+		return nil, fmt.Errorf("failed to get member: %w", err)
+	}
+
+	return member, nil
+}
+
+// GetAll retrieves all members with optional filtering
+func (r *memberRepository) GetAll(filter models.MemberFilter) ([]models.Member, int, error) {
+	members := make([]models.Member, 0)
+	var total int
+
+	// Build WHERE clause
+	var whereConditions []string
+	var args []interface{}
+	argCount := 0
+
+	if filter.Name != "" {
+		argCount++
+		whereConditions = append(whereConditions, fmt.Sprintf("LOWER(first_name || ' ' || last_name) LIKE LOWER($%d)", argCount))
+		args = append(args, "%"+filter.Name+"%")
+	}
+
+	if filter.Email != "" {
+		argCount++
+		whereConditions = append(whereConditions, fmt.Sprintf("LOWER(email) = LOWER($%d)", argCount))
+		args = append(args, filter.Email)
+	}
+
+	if filter.Status != "" {
+		argCount++
+		whereConditions = append(whereConditions, fmt.Sprintf("status = $%d", argCount))
+		args = append(args, filter.Status)
+	}
+
+	whereClause := ""
+	if len(whereConditions) > 0 {
+		whereClause = "WHERE " + strings.Join(whereConditions, " AND ")
+	}
+
+	// Get total count
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM members %s", whereClause)
+	err := r.db.QueryRow(countQuery, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count members: %w", err)
+	}
+
+	// Set default pagination
+	if filter.Limit <= 0 {
+		filter.Limit = 50
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM members %s
+		ORDER BY last_name, first_name
+		LIMIT $%d OFFSET $%d
+	`, memberColumns, whereClause, argCount+1, argCount+2)
+
+	args = append(args, filter.Limit, filter.Offset)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query members: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var member models.Member
+		err := rows.Scan(
+			&member.ID, &member.CardNumber, &member.FirstName, &member.LastName, &member.Email, &member.Phone,
+			&member.Address, &member.Status, &member.ExpiresAt, &member.CreatedAt, &member.UpdatedAt,
+		)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan member: %w", err)
+		}
+		members = append(members, member)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to iterate rows: %w", err)
+	}
+
+	return members, total, nil
+}
+
+// Update updates a member by its ID
+func (r *memberRepository) Update(id uuid.UUID, req *models.UpdateMemberRequest) (*models.Member, error) {
+	currentMember, err := r.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var setParts []string
+	var args []interface{}
+	argCount := 0
+
+	set := func(column string, value interface{}) {
+		argCount++
+		setParts = append(setParts, fmt.Sprintf("%s = $%d", column, argCount))
+		args = append(args, value)
+	}
+
+	if req.CardNumber != nil {
+		set("card_number", *req.CardNumber)
+	}
+	if req.FirstName != nil {
+		set("first_name", *req.FirstName)
+	}
+	if req.LastName != nil {
+		set("last_name", *req.LastName)
+	}
+	if req.Email != nil {
+		set("email", *req.Email)
+	}
+	if req.Phone != nil {
+		set("phone", *req.Phone)
+	}
+	if req.Address != nil {
+		set("address", *req.Address)
+	}
+	if req.Status != nil {
+		set("status", *req.Status)
+	}
+	if req.ExpiresAt != nil {
+		set("expires_at", *req.ExpiresAt)
+	}
+
+	if len(setParts) == 0 {
+		return currentMember, nil // No updates requested
+	}
+
+	set("updated_at", time.Now())
+
+	argCount++
+	args = append(args, id)
+
+	query := fmt.Sprintf(`
+		UPDATE members
+		SET %s
+		WHERE id = $%d
+		RETURNING %s
+	`, strings.Join(setParts, ", "), argCount, memberColumns)
+
+	member := &models.Member{}
+	err = r.db.QueryRow(query, args...).Scan(
+		&member.ID, &member.CardNumber, &member.FirstName, &member.LastName, &member.Email, &member.Phone,
+		&member.Address, &member.Status, &member.ExpiresAt, &member.CreatedAt, &member.UpdatedAt,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to update member: %w", err)
+	}
+
+	return member, nil
+}
+
+// Delete deletes a member by its ID
+func (r *memberRepository) Delete(id uuid.UUID) error {
+	query := "DELETE FROM members WHERE id = $1"
+	result, err := r.db.Exec(query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete member: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("member not found")
+	}
+
+	return nil
+}
+
+// ExistsByCardNumber checks if a member with the given card number exists
+func (r *memberRepository) ExistsByCardNumber(cardNumber string, excludeID *uuid.UUID) (bool, error) {
+	var count int
+	query := "SELECT COUNT(*) FROM members WHERE card_number = $1"
+	args := []interface{}{cardNumber}
+
+	if excludeID != nil {
+		query += " AND id != $2"
+		args = append(args, *excludeID)
+	}
+
+	err := r.db.QueryRow(query, args...).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("failed to check card number existence: %w", err)
+	}
+
+	return count > 0, nil
+}
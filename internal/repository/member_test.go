@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"libmngmt/internal/database"
+	"libmngmt/internal/models"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+var memberRowColumns = []string{
+	"id", "card_number", "first_name", "last_name", "email", "phone",
+	"address", "status", "expires_at", "created_at", "updated_at",
+}
+
+func TestMemberRepository_Create(t *testing.T) {
+	t.Run("create member successfully", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewMemberRepository(&database.DB{DB: db})
+
+		id := uuid.New()
+		now := time.Now()
+		req := &models.CreateMemberRequest{
+			CardNumber: "LIB-0001",
+			FirstName:  "Ada",
+			LastName:   "Lovelace",
+			Email:      "ada@example.com",
+			ExpiresAt:  now.AddDate(1, 0, 0),
+		}
+
+		mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO members`)).
+			WithArgs(sqlmock.AnyArg(), req.CardNumber, req.FirstName, req.LastName, req.Email, req.Phone,
+				req.Address, models.MemberStatusActive, req.ExpiresAt, sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow(id, now, now))
+
+		member, err := repo.Create(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, id, member.ID)
+		assert.Equal(t, models.MemberStatusActive, member.Status)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestMemberRepository_GetByID(t *testing.T) {
+	t.Run("get member not found", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewMemberRepository(&database.DB{DB: db})
+		id := uuid.New()
+
+		mock.ExpectQuery(`SELECT (.+) FROM members WHERE id = \$1`).
+			WithArgs(id).
+			WillReturnRows(sqlmock.NewRows(memberRowColumns))
+
+		member, err := repo.GetByID(id)
+
+		assert.Error(t, err)
+		assert.Nil(t, member)
+		assert.Contains(t, err.Error(), "member not found")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestMemberRepository_GetAll(t *testing.T) {
+	t.Run("get members filtered by status", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewMemberRepository(&database.DB{DB: db})
+		now := time.Now()
+
+		mock.ExpectQuery(`SELECT COUNT\(\*\) FROM members WHERE status = \$1`).
+			WithArgs(models.MemberStatusActive).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+		mock.ExpectQuery(`SELECT (.+) FROM members WHERE status = \$1 ORDER BY last_name, first_name LIMIT \$2 OFFSET \$3`).
+			WithArgs(models.MemberStatusActive, 50, 0).
+			WillReturnRows(sqlmock.NewRows(memberRowColumns).AddRow(
+				uuid.New(), "LIB-0001", "Ada", "Lovelace", "ada@example.com", "", "",
+				"active", now.AddDate(1, 0, 0), now, now,
+			))
+
+		members, total, err := repo.GetAll(models.MemberFilter{Status: models.MemberStatusActive})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, total)
+		assert.Len(t, members, 1)
+		assert.Equal(t, "Lovelace", members[0].LastName)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestMemberRepository_Delete(t *testing.T) {
+	t.Run("delete member not found", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewMemberRepository(&database.DB{DB: db})
+		id := uuid.New()
+
+		mock.ExpectExec(`DELETE FROM members WHERE id = \$1`).
+			WithArgs(id).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err = repo.Delete(id)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "member not found")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,300 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"libmngmt/internal/database"
+	"libmngmt/internal/models"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// SavedSearchRepository defines the interface for saved searches and the
+// alerts raised when new books match them
+type SavedSearchRepository interface {
+	Create(req *models.CreateSavedSearchRequest) (*models.SavedSearch, error)
+	GetByID(id uuid.UUID) (*models.SavedSearch, error)
+	GetByOwner(owner string) ([]models.SavedSearch, error)
+	GetAll() ([]models.SavedSearch, error)
+	Delete(id uuid.UUID) error
+	MatchingSearches(searches []models.SavedSearch, bookID uuid.UUID) (map[uuid.UUID]bool, error)
+	CreateAlert(alert *models.SearchAlert) (bool, error)
+	MarkAlertDelivered(id uuid.UUID, deliveredAt *time.Time, deliveryError string) error
+	GetAlerts(owner string, unreadOnly bool) ([]models.SearchAlert, error)
+	MarkAlertRead(id uuid.UUID) (*models.SearchAlert, error)
+}
+
+// savedSearchRepository implements SavedSearchRepository interface
+type savedSearchRepository struct {
+	db *database.DB
+}
+
+// NewSavedSearchRepository creates a new saved search repository
+func NewSavedSearchRepository(db *database.DB) SavedSearchRepository {
+	return &savedSearchRepository{db: db}
+}
+
+const savedSearchColumns = "id, owner, name, filter, webhook_url, created_at"
+
+const searchAlertColumns = "id, saved_search_id, owner, book_id, book_title, book_author, created_at, read_at, delivered_at, delivery_error"
+
+// savedSearchScanner is satisfied by both *sql.Row and *sql.Rows
+type savedSearchScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanSavedSearch(row savedSearchScanner, search *models.SavedSearch) error {
+	var filter []byte
+	if err := row.Scan(&search.ID, &search.Owner, &search.Name, &filter, &search.WebhookURL, &search.CreatedAt); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(filter, &search.Filter); err != nil {
+		return fmt.Errorf("failed to decode saved filter: %w", err)
+	}
+	return nil
+}
+
+func scanSearchAlert(row savedSearchScanner, alert *models.SearchAlert) error {
+	return row.Scan(
+		&alert.ID, &alert.SavedSearchID, &alert.Owner, &alert.BookID, &alert.BookTitle, &alert.BookAuthor,
+		&alert.CreatedAt, &alert.ReadAt, &alert.DeliveredAt, &alert.DeliveryError,
+	)
+}
+
+// Create saves a search; the filter is stored as JSON
+func (r *savedSearchRepository) Create(req *models.CreateSavedSearchRequest) (*models.SavedSearch, error) {
+	search := &models.SavedSearch{
+		ID:         uuid.New(),
+		Owner:      req.Owner,
+		Name:       req.Name,
+		Filter:     req.Filter,
+		WebhookURL: req.WebhookURL,
+		CreatedAt:  time.Now(),
+	}
+
+	filter, err := json.Marshal(search.Filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode filter: %w", err)
+	}
+
+	_, err = r.db.Exec(
+		"INSERT INTO saved_searches (id, owner, name, filter, webhook_url, created_at) VALUES ($1, $2, $3, $4, $5, $6)",
+		search.ID, search.Owner, search.Name, filter, search.WebhookURL, search.CreatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create saved search: %w", err)
+	}
+
+	return search, nil
+}
+
+// GetByID retrieves a saved search by its ID
+func (r *savedSearchRepository) GetByID(id uuid.UUID) (*models.SavedSearch, error) {
+	search := &models.SavedSearch{}
+	row := r.db.QueryRow(fmt.Sprintf("SELECT %s FROM saved_searches WHERE id = $1", savedSearchColumns), id)
+	if err := scanSavedSearch(row, search); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("saved search not found")
+		}
+		return nil, fmt.Errorf("failed to get saved search: %w", err)
+	}
+	return search, nil
+}
+
+// GetByOwner lists an owner's saved searches, oldest first
+func (r *savedSearchRepository) GetByOwner(owner string) ([]models.SavedSearch, error) {
+	return r.querySavedSearches(fmt.Sprintf("SELECT %s FROM saved_searches WHERE owner = $1 ORDER BY created_at, id", savedSearchColumns), owner)
+}
+
+// GetAll lists every saved search, for matching against new books
+func (r *savedSearchRepository) GetAll() ([]models.SavedSearch, error) {
+	return r.querySavedSearches(fmt.Sprintf("SELECT %s FROM saved_searches ORDER BY created_at, id", savedSearchColumns))
+}
+
+func (r *savedSearchRepository) querySavedSearches(query string, args ...interface{}) ([]models.SavedSearch, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query saved searches: %w", err)
+	}
+	defer rows.Close()
+
+	searches := make([]models.SavedSearch, 0)
+	for rows.Next() {
+		var search models.SavedSearch
+		if err := scanSavedSearch(rows, &search); err != nil {
+			return nil, fmt.Errorf("failed to scan saved search: %w", err)
+		}
+		searches = append(searches, search)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read saved searches: %w", err)
+	}
+
+	return searches, nil
+}
+
+// Delete removes a saved search together with its alerts
+func (r *savedSearchRepository) Delete(id uuid.UUID) error {
+	result, err := r.db.Exec("DELETE FROM saved_searches WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete saved search: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("saved search not found")
+	}
+
+	return nil
+}
+
+// matchBatchSize is how many saved searches are tested in one query
+const matchBatchSize = 100
+
+var placeholderPattern = regexp.MustCompile(`\$(\d+)`)
+
+// MatchingSearches reports which of the searches the book satisfies, keyed by
+// search ID. The filters are applied by the same SQL as book listings, so
+// alerts agree with search results, and are tested matchBatchSize at a time
+// in a single query rather than one query per search. A filter that no longer
+// compiles matches nothing.
+func (r *savedSearchRepository) MatchingSearches(searches []models.SavedSearch, bookID uuid.UUID) (map[uuid.UUID]bool, error) {
+	matching := make(map[uuid.UUID]bool)
+
+	for start := 0; start < len(searches); start += matchBatchSize {
+		end := start + matchBatchSize
+		if end > len(searches) {
+			end = len(searches)
+		}
+
+		args := []interface{}{bookID}
+		var ids []uuid.UUID
+		var tests []string
+		for _, search := range searches[start:end] {
+			whereClause, filterArgs, _, err := buildBookFilter(search.Filter)
+			if err != nil {
+				continue
+			}
+
+			// Shift the filter's placeholders past the arguments already taken
+			offset := len(args)
+			whereClause = placeholderPattern.ReplaceAllStringFunc(whereClause, func(placeholder string) string {
+				n, _ := strconv.Atoi(placeholder[1:])
+				return fmt.Sprintf("$%d", n+offset)
+			})
+
+			ids = append(ids, search.ID)
+			tests = append(tests, fmt.Sprintf("EXISTS (SELECT 1 FROM books %s AND id = $1)", whereClause))
+			args = append(args, filterArgs...)
+		}
+		if len(tests) == 0 {
+			continue
+		}
+
+		var matches pq.BoolArray
+		query := fmt.Sprintf("SELECT ARRAY[%s]", strings.Join(tests, ", "))
+		if err := r.db.QueryRow(query, args...).Scan(&matches); err != nil {
+			return nil, fmt.Errorf("failed to match saved searches: %w", err)
+		}
+		for i, id := range ids {
+			if i < len(matches) && matches[i] {
+				matching[id] = true
+			}
+		}
+	}
+
+	return matching, nil
+}
+
+// CreateAlert stores an alert in the owner's inbox. It reports false when
+// the search already alerted about the book, as happens when a job is retried.
+func (r *savedSearchRepository) CreateAlert(alert *models.SearchAlert) (bool, error) {
+	if alert.ID == uuid.Nil {
+		alert.ID = uuid.New()
+	}
+	if alert.CreatedAt.IsZero() {
+		alert.CreatedAt = time.Now()
+	}
+
+	result, err := r.db.Exec(`
+		INSERT INTO search_alerts (id, saved_search_id, owner, book_id, book_title, book_author, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (saved_search_id, book_id) DO NOTHING
+	`, alert.ID, alert.SavedSearchID, alert.Owner, alert.BookID, alert.BookTitle, alert.BookAuthor, alert.CreatedAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to create alert: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
+
+// MarkAlertDelivered records the outcome of an alert's webhook delivery
+func (r *savedSearchRepository) MarkAlertDelivered(id uuid.UUID, deliveredAt *time.Time, deliveryError string) error {
+	_, err := r.db.Exec(
+		"UPDATE search_alerts SET delivered_at = $1, delivery_error = $2 WHERE id = $3",
+		deliveredAt, deliveryError, id,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record alert delivery: %w", err)
+	}
+	return nil
+}
+
+// GetAlerts lists an owner's inbox, newest first
+func (r *savedSearchRepository) GetAlerts(owner string, unreadOnly bool) ([]models.SearchAlert, error) {
+	query := fmt.Sprintf("SELECT %s FROM search_alerts WHERE owner = $1", searchAlertColumns)
+	if unreadOnly {
+		query += " AND read_at IS NULL"
+	}
+	query += " ORDER BY created_at DESC, id"
+
+	rows, err := r.db.Query(query, owner)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query alerts: %w", err)
+	}
+	defer rows.Close()
+
+	alerts := make([]models.SearchAlert, 0)
+	for rows.Next() {
+		var alert models.SearchAlert
+		if err := scanSearchAlert(rows, &alert); err != nil {
+			return nil, fmt.Errorf("failed to scan alert: %w", err)
+		}
+		alerts = append(alerts, alert)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read alerts: %w", err)
+	}
+
+	return alerts, nil
+}
+
+// MarkAlertRead marks an alert as read; alerts already read keep their read time
+func (r *savedSearchRepository) MarkAlertRead(id uuid.UUID) (*models.SearchAlert, error) {
+	alert := &models.SearchAlert{}
+	row := r.db.QueryRow(fmt.Sprintf(`
+		UPDATE search_alerts SET read_at = COALESCE(read_at, $1)
+		WHERE id = $2
+		RETURNING %s
+	`, searchAlertColumns), time.Now(), id)
+	if err := scanSearchAlert(row, alert); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("alert not found")
+		}
+		return nil, fmt.Errorf("failed to mark alert read: %w", err)
+	}
+	return alert, nil
+}
@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"libmngmt/internal/database"
+	"libmngmt/internal/models"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+var savedSearchRowColumns = []string{"id", "owner", "name", "filter", "webhook_url", "created_at"}
+
+var searchAlertRowColumns = []string{
+	"id", "saved_search_id", "owner", "book_id", "book_title", "book_author",
+	"created_at", "read_at", "delivered_at", "delivery_error",
+}
+
+func TestSavedSearchRepository_Create(t *testing.T) {
+	t.Run("filter is stored as JSON", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewSavedSearchRepository(&database.DB{DB: db})
+
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO saved_searches (id, owner, name, filter, webhook_url, created_at)")).
+			WithArgs(sqlmock.AnyArg(), "member-42", "New Go books", []byte(`{"genre":"programming","q":"title:go"}`), "", sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		search, err := repo.Create(&models.CreateSavedSearchRequest{
+			Owner:  "member-42",
+			Name:   "New Go books",
+			Filter: models.BookFilter{Genre: "programming", Query: "title:go"},
+		})
+
+		assert.NoError(t, err)
+		assert.NotEqual(t, uuid.Nil, search.ID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestSavedSearchRepository_GetByOwner(t *testing.T) {
+	t.Run("filters are decoded", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewSavedSearchRepository(&database.DB{DB: db})
+
+		rows := sqlmock.NewRows(savedSearchRowColumns).
+			AddRow(uuid.New(), "member-42", "Long reads", []byte(`{"min_pages":500}`), "https://example.com/hook", time.Now())
+		mock.ExpectQuery(regexp.QuoteMeta("FROM saved_searches WHERE owner = $1")).
+			WithArgs("member-42").
+			WillReturnRows(rows)
+
+		searches, err := repo.GetByOwner("member-42")
+
+		assert.NoError(t, err)
+		assert.Len(t, searches, 1)
+		assert.Equal(t, 500, *searches[0].Filter.MinPages)
+		assert.Equal(t, "https://example.com/hook", searches[0].WebhookURL)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestSavedSearchRepository_GetByID(t *testing.T) {
+	t.Run("missing search is not found", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewSavedSearchRepository(&database.DB{DB: db})
+		id := uuid.New()
+
+		mock.ExpectQuery(regexp.QuoteMeta("FROM saved_searches WHERE id = $1")).
+			WithArgs(id).
+			WillReturnRows(sqlmock.NewRows(savedSearchRowColumns))
+
+		_, err = repo.GetByID(id)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "not found")
+	})
+}
+
+func TestSavedSearchRepository_MatchingSearches(t *testing.T) {
+	t.Run("searches are checked together with the listing filter", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewSavedSearchRepository(&database.DB{DB: db})
+		bookID := uuid.New()
+		books := models.SavedSearch{ID: uuid.New(), Filter: models.BookFilter{Genre: "programming", Query: "pages>300"}}
+		everything := models.SavedSearch{ID: uuid.New()}
+		poetry := models.SavedSearch{ID: uuid.New(), Filter: models.BookFilter{Genre: "poetry"}}
+
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT ARRAY["+
+			"EXISTS (SELECT 1 FROM books WHERE deleted_at IS NULL AND LOWER(genre) LIKE LOWER($2) AND pages > $3 AND id = $1), "+
+			"EXISTS (SELECT 1 FROM books WHERE deleted_at IS NULL AND id = $1), "+
+			"EXISTS (SELECT 1 FROM books WHERE deleted_at IS NULL AND LOWER(genre) LIKE LOWER($4) AND id = $1)]")).
+			WithArgs(bookID, "%programming%", 300, "%poetry%").
+			WillReturnRows(sqlmock.NewRows([]string{"array"}).AddRow("{t,t,f}"))
+
+		matching, err := repo.MatchingSearches([]models.SavedSearch{books, everything, poetry}, bookID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, map[uuid.UUID]bool{books.ID: true, everything.ID: true}, matching)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("no searches need no query", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewSavedSearchRepository(&database.DB{DB: db})
+
+		matching, err := repo.MatchingSearches(nil, uuid.New())
+
+		assert.NoError(t, err)
+		assert.Empty(t, matching)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestSavedSearchRepository_CreateAlert(t *testing.T) {
+	t.Run("repeated alert for a book is skipped", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewSavedSearchRepository(&database.DB{DB: db})
+
+		mock.ExpectExec(regexp.QuoteMeta("ON CONFLICT (saved_search_id, book_id) DO NOTHING")).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		created, err := repo.CreateAlert(&models.SearchAlert{SavedSearchID: uuid.New(), Owner: "member-42", BookID: uuid.New()})
+
+		assert.NoError(t, err)
+		assert.False(t, created)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestSavedSearchRepository_GetAlerts(t *testing.T) {
+	t.Run("unread alerts only", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		repo := NewSavedSearchRepository(&database.DB{DB: db})
+
+		rows := sqlmock.NewRows(searchAlertRowColumns).
+			AddRow(uuid.New(), uuid.New(), "member-42", uuid.New(), "The Go Programming Language", "Alan Donovan", time.Now(), nil, nil, "")
+		mock.ExpectQuery(regexp.QuoteMeta("FROM search_alerts WHERE owner = $1 AND read_at IS NULL ORDER BY created_at DESC")).
+			WithArgs("member-42").
+			WillReturnRows(rows)
+
+		alerts, err := repo.GetAlerts("member-42", true)
+
+		assert.NoError(t, err)
+		assert.Len(t, alerts, 1)
+		assert.Nil(t, alerts[0].ReadAt)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
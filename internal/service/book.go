@@ -1,12 +1,20 @@
 package service
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"libmngmt/internal/cache"
+	apperrors "libmngmt/internal/errors"
+	"libmngmt/internal/isbn"
 	"libmngmt/internal/models"
+	"libmngmt/internal/patch"
+	"libmngmt/internal/query"
 	"libmngmt/internal/repository"
 	"libmngmt/internal/workers"
+	"log"
 	"strings"
 	"sync"
 	"time"
@@ -16,12 +24,24 @@ import (
 
 // BookService defines the interface for book business logic
 type BookService interface {
-	CreateBook(req *models.CreateBookRequest) (*models.Book, error)
-	GetBookByID(id uuid.UUID) (*models.Book, error)
+	CreateBook(req *models.CreateBookRequest, audit models.AuditInfo) (*models.Book, error)
+	GetBookByID(id uuid.UUID, projection models.BookProjection) (*models.Book, error)
 	GetAllBooks(filter models.BookFilter) (*models.BooksListResponse, error)
-	UpdateBook(id uuid.UUID, req *models.UpdateBookRequest) (*models.Book, error)
-	DeleteBook(id uuid.UUID) error
-	BulkCreateBooks(requests []*models.CreateBookRequest) ([]*models.Book, []error)
+	SearchBooks(filter models.BookSearchFilter) (*models.BookSearchResponse, error)
+	AutocompleteBooks(filter models.AutocompleteFilter) (*models.AutocompleteResponse, error)
+	UpdateBook(id uuid.UUID, req *models.UpdateBookRequest, audit models.AuditInfo) (*models.Book, error)
+	ReplaceBook(id uuid.UUID, req *models.ReplaceBookRequest, audit models.AuditInfo) (*models.Book, error)
+	PatchBook(id uuid.UUID, p *models.BookPatch, audit models.AuditInfo) (*models.Book, error)
+	DeleteBook(id uuid.UUID, version *int, audit models.AuditInfo) error
+	GetTrash(filter models.TrashFilter) (*models.BooksListResponse, error)
+	RestoreBook(id uuid.UUID, audit models.AuditInfo) (*models.Book, error)
+	PurgeTrash(retention time.Duration) (int, error)
+	GetBookHistory(id uuid.UUID) (*models.BookHistoryResponse, error)
+	GetBookAsOf(id uuid.UUID, at time.Time) (*models.BookAsOfResponse, error)
+	RevertBook(id uuid.UUID, revert *models.BookRevert, audit models.AuditInfo) (*models.Book, error)
+	BulkCreateBooks(requests []*models.CreateBookRequest, audit models.AuditInfo) ([]*models.Book, []error)
+	BulkCreateBooksAtomic(ctx context.Context, requests []*models.CreateBookRequest, audit models.AuditInfo) ([]*models.Book, []error, error)
+	EnrichBook(req *models.CreateBookRequest) (*models.EnrichBookResponse, error)
 	GetMetrics() ServiceMetrics
 	Shutdown(ctx context.Context) error
 }
@@ -40,37 +60,36 @@ type bookService struct {
 	bookRepo  repository.BookRepository
 	cache     *cache.BookCache
 	processor *workers.BookProcessor
+	metadata  MetadataProvider
+	arrivals  ArrivalMatcher
 	metrics   *ServiceMetrics
+	// suggestionsMu serialises seeding the suggestion cache
+	suggestionsMu sync.Mutex
 }
 
-// NewBookService creates a new enhanced book service
-func NewBookService(bookRepo repository.BookRepository, cache *cache.BookCache, processor *workers.BookProcessor) BookService {
+// NewBookService creates a new enhanced book service. The metadata provider
+// is optional; when set, CreateBook fills missing fields from it. The arrival
+// matcher is optional too; when set, new books are matched against saved
+// searches by the background notify job.
+func NewBookService(bookRepo repository.BookRepository, cache *cache.BookCache, processor *workers.BookProcessor, metadata MetadataProvider, arrivals ArrivalMatcher) BookService {
 	return &bookService{
 		bookRepo:  bookRepo,
 		cache:     cache,
 		processor: processor,
+		metadata:  metadata,
+		arrivals:  arrivals,
 		metrics:   &ServiceMetrics{},
 	}
 }
 
-// CreateBook creates a new book with enhanced concurrent processing
-func (s *bookService) CreateBook(req *models.CreateBookRequest) (*models.Book, error) {
+// CreateBook creates a new book with enhanced concurrent processing. The
+// audit info is recorded in the book's audit trail.
+func (s *bookService) CreateBook(req *models.CreateBookRequest, audit models.AuditInfo) (*models.Book, error) {
 	start := time.Now()
 	defer s.recordMetrics(start)
 
-	// Use channels for validation pipeline
-	validationChan := make(chan error, 3)
-
-	// Concurrent validation checks
-	go s.validateTitle(req.Title, validationChan)
-	go s.validateAuthor(req.Author, validationChan)
-	go s.validateISBN(req.ISBN, validationChan)
-
-	// Collect validation results
-	for i := 0; i < 3; i++ {
-		if err := <-validationChan; err != nil {
-			return nil, err
-		}
+	if err := s.prepareCreateRequest(req); err != nil {
+		return nil, err
 	}
 
 	// Check ISBN uniqueness concurrently
@@ -97,18 +116,48 @@ func (s *bookService) CreateBook(req *models.CreateBookRequest) (*models.Book, e
 		return nil, fmt.Errorf("validation timeout")
 	}
 
-	// Normalize data
-	s.normalizeBookData(req)
-
 	// Create the book
-	book, err := s.bookRepo.Create(req)
+	book, err := s.bookRepo.Create(req, audit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create book: %w", err)
 	}
 
+	s.bookCreated(req, book)
+
+	return book, nil
+}
+
+// prepareCreateRequest fills in and validates a create request, then
+// normalizes it so uniqueness is checked against the canonical ISBN-13
+func (s *bookService) prepareCreateRequest(req *models.CreateBookRequest) error {
+	// Fill fields left blank from the ISBN's metadata, if a provider is configured
+	s.enrichFromMetadata(req)
+
+	// Use channels for validation pipeline
+	validationChan := make(chan error, 3)
+
+	// Concurrent validation checks
+	go s.validateTitle(req.Title, validationChan)
+	go s.validateAuthor(req.Author, validationChan)
+	go s.validateISBN(req.ISBN, validationChan)
+
+	// Collect validation results
+	for i := 0; i < 3; i++ {
+		if err := <-validationChan; err != nil {
+			return err
+		}
+	}
+
+	s.normalizeBookData(req)
+	return nil
+}
+
+// bookCreated caches a new book and queues its new-arrival matching
+func (s *bookService) bookCreated(req *models.CreateBookRequest, book *models.Book) {
 	// Cache the new book
 	if s.cache != nil {
 		s.cache.SetBook(book)
+		s.cache.AddBookSuggestions(book)
 		// Invalidate book list caches since we added a new book
 		s.cache.Clear() // Clear all cached book lists
 	}
@@ -120,14 +169,37 @@ func (s *bookService) CreateBook(req *models.CreateBookRequest) (*models.Book, e
 			Type:     workers.JobTypeNotify,
 			BookData: req,
 		}
-		s.processor.SubmitJob(job)
+		if s.arrivals != nil {
+			job.Task = newArrivalTask(s.arrivals, book)
+		}
+		if err := s.processor.SubmitJob(job); err != nil {
+			log.Printf("Failed to submit new arrival job for book %s: %v", book.ID, err)
+		}
 	}
+}
 
-	return book, nil
+// newArrivalTask matches a newly created book against saved searches
+func newArrivalTask(arrivals ArrivalMatcher, book *models.Book) func(ctx context.Context) (string, error) {
+	return func(ctx context.Context) (string, error) {
+		alerted, err := arrivals.MatchNewArrival(ctx, book)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Book %s matched %d saved searches", book.ID, alerted), nil
+	}
 }
 
-// GetBookByID retrieves a book with Redis caching
-func (s *bookService) GetBookByID(id uuid.UUID) (*models.Book, error) {
+// GetBookByID retrieves a book with Redis caching. A non-empty projection
+// reads only the fields it selects and embeds the resources it includes.
+func (s *bookService) GetBookByID(id uuid.UUID, projection models.BookProjection) (*models.Book, error) {
+	projection, err := normalizeBookProjection(projection)
+	if err != nil {
+		return nil, err
+	}
+	if !projection.IsEmpty() {
+		return s.getProjectedBook(id, projection)
+	}
+
 	start := time.Now()
 	defer s.recordMetrics(start)
 
@@ -159,18 +231,116 @@ func (s *bookService) GetBookByID(id uuid.UUID) (*models.Book, error) {
 	return book, nil
 }
 
+// getProjectedBook retrieves a book in the shape of a projection. Each shape
+// is cached apart from whole books.
+func (s *bookService) getProjectedBook(id uuid.UUID, projection models.BookProjection) (*models.Book, error) {
+	start := time.Now()
+	defer s.recordMetrics(start)
+
+	if s.cache != nil {
+		if book, found := s.cache.GetProjectedBook(id, projection); found {
+			s.metrics.mu.Lock()
+			s.metrics.CacheHits++
+			s.metrics.mu.Unlock()
+			return shapeBook(book, projection.Fields), nil
+		}
+
+		s.metrics.mu.Lock()
+		s.metrics.CacheMisses++
+		s.metrics.mu.Unlock()
+	}
+
+	book, err := s.bookRepo.GetProjected(id, projection)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get book: %w", err)
+	}
+
+	if s.cache != nil {
+		s.cache.SetProjectedBook(book, projection)
+	}
+
+	return shapeBook(book, projection.Fields), nil
+}
+
+// shapeBook returns a copy of book that marshals only the projected fields.
+// Cached books are shared, so they are never marked themselves.
+func shapeBook(book *models.Book, fields []string) *models.Book {
+	if len(fields) == 0 {
+		return book
+	}
+	shaped := *book
+	shaped.Fields = fields
+	return &shaped
+}
+
+// shapeBookList returns a copy of response whose books marshal only the
+// projected fields
+func shapeBookList(response *models.BooksListResponse, fields []string) *models.BooksListResponse {
+	if len(fields) == 0 {
+		return response
+	}
+	shaped := *response
+	shaped.Books = make([]models.Book, len(response.Books))
+	for i := range response.Books {
+		shaped.Books[i] = *shapeBook(&response.Books[i], fields)
+	}
+	return &shaped
+}
+
 // GetAllBooks retrieves books with Redis caching for enhanced performance
 func (s *bookService) GetAllBooks(filter models.BookFilter) (*models.BooksListResponse, error) {
+	// Facet counts are cached independently of pagination, so list the page
+	// on its own and attach the facets to a copy of the response
+	if filter.Facets {
+		filter.Facets = false
+		response, err := s.GetAllBooks(filter)
+		if err != nil {
+			return nil, err
+		}
+
+		facets, err := s.getFacets(filter)
+		if err != nil {
+			return nil, err
+		}
+
+		withFacets := *response
+		withFacets.Facets = facets
+		return &withFacets, nil
+	}
+
 	start := time.Now()
 	defer s.recordMetrics(start)
 
+	// Spaces in the sort are insignificant; drop them so equivalent sorts share
+	// cache entries and cursors
+	filter.Sort = strings.ReplaceAll(filter.Sort, " ", "")
+	if filter.ISBN != "" {
+		filter.ISBN = normalizeISBN(filter.ISBN)
+	}
+	if err := validateBookFilterRanges(filter); err != nil {
+		return nil, err
+	}
+	// Malformed queries are reported with their position before touching the
+	// cache or database
+	filter.Query = strings.TrimSpace(filter.Query)
+	if filter.Query != "" {
+		if _, err := query.Parse(filter.Query); err != nil {
+			return nil, err
+		}
+	}
+	projection, err := normalizeBookProjection(filter.BookProjection)
+	if err != nil {
+		return nil, err
+	}
+	filter.BookProjection = projection
+
 	// Try cache first (Redis + in-memory fallback)
 	if s.cache != nil {
 		if response, found := s.cache.GetBookList(filter); found {
 			s.metrics.mu.Lock()
 			s.metrics.CacheHits++
 			s.metrics.mu.Unlock()
-			return response, nil
+			return shapeBookList(response, filter.Fields), nil
 		}
 
 		// Cache miss
@@ -183,37 +353,329 @@ func (s *bookService) GetAllBooks(filter models.BookFilter) (*models.BooksListRe
 	if filter.Limit <= 0 || filter.Limit > 100 {
 		filter.Limit = 50
 	}
-	if filter.Offset < 0 {
+	if filter.Offset < 0 || filter.Cursor != "" {
 		filter.Offset = 0
 	}
+	if filter.Fuzzy && (filter.Similarity <= 0 || filter.Similarity > 1) {
+		filter.Similarity = defaultSimilarityThreshold
+	}
 
-	books, total, err := s.bookRepo.GetAll(filter)
+	var response *models.BooksListResponse
+	if filter.Cursor != "" {
+		response, err = s.getBookPage(filter)
+	} else {
+		response, err = s.getBookOffsetPage(filter)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Cache the results in both Redis and in-memory
+	if s.cache != nil {
+		s.cache.SetBookList(filter, response)
+	}
+
+	return shapeBookList(response, filter.Fields), nil
+}
+
+// getBookOffsetPage lists the page of books at filter.Offset. Outside fuzzy
+// mode the response carries cursors so clients can switch to keyset paging.
+// The total is counted unless the client opted out.
+func (s *bookService) getBookOffsetPage(filter models.BookFilter) (*models.BooksListResponse, error) {
+	books, hasMore, err := s.bookRepo.GetAll(filter)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get books: %w", err)
 	}
 
 	response := &models.BooksListResponse{
 		Books:  books,
-		Total:  total,
 		Limit:  filter.Limit,
 		Offset: filter.Offset,
 	}
 
-	// Cache the results in both Redis and in-memory
-	if s.cache != nil {
-		s.cache.SetBookList(filter, response)
+	if filter.CountsTotal() {
+		total, err := s.bookRepo.Count(filter)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get books: %w", err)
+		}
+		response.Total = &total
+	}
+
+	if !filter.Fuzzy && len(books) > 0 {
+		if hasMore {
+			response.NextCursor = models.NewBookCursor(&books[len(books)-1], filter.Sort, false).Encode()
+		}
+		if filter.Offset > 0 {
+			response.PrevCursor = models.NewBookCursor(&books[0], filter.Sort, true).Encode()
+		}
+	}
+
+	// Offer the closest stored values when an exact filter matched nothing
+	noMatch := len(books) == 0 && filter.Offset == 0
+	if response.Total != nil {
+		noMatch = *response.Total == 0
+	}
+	if noMatch && !filter.Fuzzy {
+		response.DidYouMean = s.suggestFilters(filter)
+	}
+
+	return response, nil
+}
+
+// getBookPage lists the page of books following filter.Cursor. The total is
+// only counted when requested, since keyset pages avoid a full scan.
+func (s *bookService) getBookPage(filter models.BookFilter) (*models.BooksListResponse, error) {
+	if filter.Fuzzy {
+		return nil, fmt.Errorf("invalid cursor: fuzzy listings are ordered by similarity and only support offsets")
+	}
+
+	cursor, err := models.DecodeBookCursor(filter.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	if cursor.Sort != filter.Sort {
+		return nil, fmt.Errorf("invalid cursor: it was issued for a different sort order")
+	}
+
+	books, hasMore, err := s.bookRepo.GetPage(filter, cursor)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get books: %w", err)
+	}
+
+	response := &models.BooksListResponse{
+		Books: books,
+		Limit: filter.Limit,
+	}
+
+	// The cursor's own page lies behind us, so that direction always has more
+	if len(books) > 0 {
+		hasNext, hasPrev := hasMore, true
+		if cursor.Backward {
+			hasNext, hasPrev = true, hasMore
+		}
+		if hasNext {
+			response.NextCursor = models.NewBookCursor(&books[len(books)-1], filter.Sort, false).Encode()
+		}
+		if hasPrev {
+			response.PrevCursor = models.NewBookCursor(&books[0], filter.Sort, true).Encode()
+		}
+	}
+
+	if filter.CountsTotal() {
+		total, err := s.bookRepo.Count(filter)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get books: %w", err)
+		}
+		response.Total = &total
 	}
 
 	return response, nil
 }
 
+// getFacets returns the facet counts for the books matching filter
+func (s *bookService) getFacets(filter models.BookFilter) (*models.BookFacets, error) {
+	filter.Limit, filter.Offset = 0, 0
+	filter.Cursor, filter.IncludeTotal = "", nil
+	if filter.Fuzzy && (filter.Similarity <= 0 || filter.Similarity > 1) {
+		filter.Similarity = defaultSimilarityThreshold
+	}
+
+	if s.cache != nil {
+		if facets, found := s.cache.GetBookFacets(filter); found {
+			s.metrics.mu.Lock()
+			s.metrics.CacheHits++
+			s.metrics.mu.Unlock()
+			return facets, nil
+		}
+
+		s.metrics.mu.Lock()
+		s.metrics.CacheMisses++
+		s.metrics.mu.Unlock()
+	}
+
+	facets, err := s.bookRepo.GetFacets(filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get book facets: %w", err)
+	}
+
+	if s.cache != nil {
+		s.cache.SetBookFacets(filter, facets)
+	}
+
+	return facets, nil
+}
+
+// defaultSimilarityThreshold is the trigram similarity fuzzy matches and
+// suggestions must reach when the request does not set one
+const defaultSimilarityThreshold = 0.3
+
+// suggestFilters looks up the closest stored author and genre for the filters
+// in use. Suggestions are best-effort; lookup failures are logged and skipped.
+func (s *bookService) suggestFilters(filter models.BookFilter) map[string]string {
+	threshold := filter.Similarity
+	if threshold <= 0 || threshold > 1 {
+		threshold = defaultSimilarityThreshold
+	}
+
+	suggestions := make(map[string]string)
+
+	terms := map[string]string{"author": filter.Author, "genre": filter.Genre}
+	for field, term := range terms {
+		if term == "" {
+			continue
+		}
+		suggestion, err := s.bookRepo.SuggestSimilar(field, term, threshold)
+		if err != nil {
+			log.Printf("Failed to suggest %s for %q: %v", field, term, err)
+			continue
+		}
+		if suggestion != "" && !strings.EqualFold(suggestion, term) {
+			suggestions[field] = suggestion
+		}
+	}
+
+	if len(suggestions) == 0 {
+		return nil
+	}
+	return suggestions
+}
+
+// maxSearchQueryLength bounds the size of a full-text search query
+const maxSearchQueryLength = 200
+
+// SearchBooks runs a ranked full-text search over the catalog
+func (s *bookService) SearchBooks(filter models.BookSearchFilter) (*models.BookSearchResponse, error) {
+	start := time.Now()
+	defer s.recordMetrics(start)
+
+	filter.Query = strings.TrimSpace(filter.Query)
+	if filter.Query == "" {
+		return nil, fmt.Errorf("search query is required")
+	}
+	if len(filter.Query) > maxSearchQueryLength {
+		return nil, fmt.Errorf("invalid search query: must be at most %d characters", maxSearchQueryLength)
+	}
+
+	if filter.Limit <= 0 || filter.Limit > 100 {
+		filter.Limit = 50
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+
+	results, total, err := s.bookRepo.Search(filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search books: %w", err)
+	}
+
+	return &models.BookSearchResponse{
+		Query:   filter.Query,
+		Results: results,
+		Total:   total,
+		Limit:   filter.Limit,
+		Offset:  filter.Offset,
+	}, nil
+}
+
+// Autocomplete limits and defaults
+const (
+	defaultSuggestionLimit    = 10
+	maxSuggestionLimit        = 25
+	maxSuggestionPrefixLength = 100
+)
+
+// AutocompleteBooks returns the distinct values of a field starting with the
+// typed prefix. Suggestions come from the cache's suggestion sets, which are
+// seeded on first use; the database prefix indexes serve them otherwise.
+func (s *bookService) AutocompleteBooks(filter models.AutocompleteFilter) (*models.AutocompleteResponse, error) {
+	start := time.Now()
+	defer s.recordMetrics(start)
+
+	if filter.Field == "" {
+		filter.Field = models.SuggestFieldTitle
+	}
+	if !filter.Field.IsValid() {
+		return nil, fmt.Errorf("invalid suggestion field: %s", filter.Field)
+	}
+
+	filter.Prefix = strings.TrimSpace(filter.Prefix)
+	if filter.Prefix == "" {
+		return nil, fmt.Errorf("prefix is required")
+	}
+	if len(filter.Prefix) > maxSuggestionPrefixLength {
+		return nil, fmt.Errorf("invalid prefix: must be at most %d characters", maxSuggestionPrefixLength)
+	}
+
+	if filter.Limit <= 0 || filter.Limit > maxSuggestionLimit {
+		filter.Limit = defaultSuggestionLimit
+	}
+
+	if s.cache != nil {
+		if !s.cache.SuggestionsLoaded() {
+			if err := s.loadSuggestions(); err != nil {
+				log.Printf("Failed to load suggestions: %v", err)
+			}
+		}
+
+		if suggestions, found := s.cache.Suggest(filter.Field, filter.Prefix, filter.Limit); found {
+			s.metrics.mu.Lock()
+			s.metrics.CacheHits++
+			s.metrics.mu.Unlock()
+			return newAutocompleteResponse(filter, suggestions), nil
+		}
+
+		s.metrics.mu.Lock()
+		s.metrics.CacheMisses++
+		s.metrics.mu.Unlock()
+	}
+
+	suggestions, err := s.bookRepo.SuggestByPrefix(filter.Field, filter.Prefix, filter.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get suggestions: %w", err)
+	}
+
+	return newAutocompleteResponse(filter, suggestions), nil
+}
+
+// loadSuggestions seeds the cache's suggestion sets from the catalog
+func (s *bookService) loadSuggestions() error {
+	s.suggestionsMu.Lock()
+	defer s.suggestionsMu.Unlock()
+
+	// Another request may have finished loading while this one waited
+	if s.cache.SuggestionsLoaded() {
+		return nil
+	}
+
+	counts := make(map[models.SuggestField]map[string]int, len(models.SuggestFields))
+	for _, field := range models.SuggestFields {
+		values, err := s.bookRepo.SuggestionCounts(field)
+		if err != nil {
+			return err
+		}
+		counts[field] = values
+	}
+
+	s.cache.LoadSuggestions(counts)
+	return nil
+}
+
+// newAutocompleteResponse wraps suggestions with the lookup they answer
+func newAutocompleteResponse(filter models.AutocompleteFilter, suggestions []string) *models.AutocompleteResponse {
+	return &models.AutocompleteResponse{
+		Field:       filter.Field,
+		Prefix:      filter.Prefix,
+		Suggestions: suggestions,
+	}
+}
+
 // UpdateBook updates a book with enhanced validation and caching
-func (s *bookService) UpdateBook(id uuid.UUID, req *models.UpdateBookRequest) (*models.Book, error) {
+func (s *bookService) UpdateBook(id uuid.UUID, req *models.UpdateBookRequest, audit models.AuditInfo) (*models.Book, error) {
 	start := time.Now()
 	defer s.recordMetrics(start)
 
 	// Check if book exists
-	_, err := s.bookRepo.GetByID(id)
+	current, err := s.bookRepo.GetByID(id)
 	if err != nil {
 		return nil, fmt.Errorf("book not found: %w", err)
 	}
@@ -223,8 +685,17 @@ func (s *bookService) UpdateBook(id uuid.UUID, req *models.UpdateBookRequest) (*
 		return nil, err
 	}
 
+	// Refuse edits made against a version that has already been superseded
+	if req.Version != nil && *req.Version != current.Version {
+		return nil, versionConflict(current, *req.Version)
+	}
+
 	// Check ISBN uniqueness if being updated
 	if req.ISBN != nil {
+		// Normalize ISBN to its canonical ISBN-13 form first
+		normalizedISBN := normalizeISBN(*req.ISBN)
+		req.ISBN = &normalizedISBN
+
 		exists, err := s.bookRepo.ExistsByISBN(*req.ISBN, &id)
 		if err != nil {
 			return nil, fmt.Errorf("failed to check ISBN uniqueness: %w", err)
@@ -232,9 +703,6 @@ func (s *bookService) UpdateBook(id uuid.UUID, req *models.UpdateBookRequest) (*
 		if exists {
 			return nil, fmt.Errorf("book with ISBN %s already exists", *req.ISBN)
 		}
-		// Normalize ISBN
-		normalizedISBN := normalizeISBN(*req.ISBN)
-		req.ISBN = &normalizedISBN
 	}
 
 	// Normalize other fields
@@ -260,7 +728,18 @@ func (s *bookService) UpdateBook(id uuid.UUID, req *models.UpdateBookRequest) (*
 	}
 
 	// Update the book
-	book, err := s.bookRepo.Update(id, req)
+	book, err := s.bookRepo.Update(id, req, audit)
+	if errors.Is(err, repository.ErrVersionConflict) {
+		// Another write landed between the read above and the update
+		latest, err := s.bookRepo.GetByID(id)
+		if err != nil {
+			return nil, fmt.Errorf("book not found: %w", err)
+		}
+		if s.cache != nil {
+			s.cache.InvalidateBook(id)
+		}
+		return nil, versionConflict(latest, *req.Version)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to update book: %w", err)
 	}
@@ -268,6 +747,7 @@ func (s *bookService) UpdateBook(id uuid.UUID, req *models.UpdateBookRequest) (*
 	// Update cache
 	if s.cache != nil {
 		s.cache.SetBook(book)
+		s.cache.ReplaceBookSuggestions(current, book)
 		// Invalidate related cache entries (also invalidates book lists)
 		s.cache.InvalidateBook(book.ID)
 	}
@@ -275,23 +755,109 @@ func (s *bookService) UpdateBook(id uuid.UUID, req *models.UpdateBookRequest) (*
 	return book, nil
 }
 
-// DeleteBook deletes a book with cache invalidation
-func (s *bookService) DeleteBook(id uuid.UUID) error {
+// ReplaceBook replaces every writable field of a book. Optional fields the
+// request leaves out are cleared and the language resets to its default.
+func (s *bookService) ReplaceBook(id uuid.UUID, req *models.ReplaceBookRequest, audit models.AuditInfo) (*models.Book, error) {
+	if err := s.validateCreateRequest(&req.CreateBookRequest); err != nil {
+		return nil, err
+	}
+
+	language := strings.TrimSpace(req.Language)
+	if language == "" {
+		language = "English"
+	}
+
+	return s.UpdateBook(id, &models.UpdateBookRequest{
+		Title:       &req.Title,
+		Author:      &req.Author,
+		ISBN:        &req.ISBN,
+		Publisher:   &req.Publisher,
+		Genre:       &req.Genre,
+		PublishedAt: &req.PublishedAt,
+		Pages:       &req.Pages,
+		Language:    &language,
+		Available:   req.Available,
+		Version:     req.Version,
+	}, audit)
+}
+
+// PatchBook applies a merge patch or JSON patch to a book's writable fields
+// and replaces the book with the result. The patch is applied to the version
+// read here unless it pins one, so a concurrent write makes it conflict
+// rather than be silently undone.
+func (s *bookService) PatchBook(id uuid.UUID, p *models.BookPatch, audit models.AuditInfo) (*models.Book, error) {
+	current, err := s.bookRepo.GetByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("book not found: %w", err)
+	}
+
+	doc := models.NewReplaceBookRequest(current)
+	if p.Version != nil {
+		doc.Version = p.Version
+	}
+	original, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to patch book: %w", err)
+	}
+
+	var patched []byte
+	switch p.Format {
+	case models.PatchMerge:
+		patched, err = patch.Merge(original, p.Document)
+	case models.PatchJSON:
+		patched, err = patch.Apply(original, p.Document)
+	default:
+		return nil, fmt.Errorf("invalid patch format %q", p.Format)
+	}
+	if errors.Is(err, patch.ErrTestFailed) {
+		return nil, apperrors.Conflict("patch test failed", err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// The patched document must still be a book; unknown members are errors
+	var req models.ReplaceBookRequest
+	decoder := json.NewDecoder(bytes.NewReader(patched))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		return nil, fmt.Errorf("invalid patch result: %w", err)
+	}
+
+	return s.ReplaceBook(id, &req, audit)
+}
+
+// DeleteBook moves a book to the trash with cache invalidation. A non-nil
+// version pins the delete to that version of the book.
+func (s *bookService) DeleteBook(id uuid.UUID, version *int, audit models.AuditInfo) error {
 	start := time.Now()
 	defer s.recordMetrics(start)
 
 	// Check if book exists
-	_, err := s.bookRepo.GetByID(id)
+	book, err := s.bookRepo.GetByID(id)
 	if err != nil {
 		return fmt.Errorf("book not found: %w", err)
 	}
 
-	if err := s.bookRepo.Delete(id); err != nil {
+	err = s.bookRepo.Delete(id, version, audit)
+	if errors.Is(err, repository.ErrVersionConflict) {
+		// Another write landed since the version the caller saw
+		latest, err := s.bookRepo.GetByID(id)
+		if err != nil {
+			return fmt.Errorf("book not found: %w", err)
+		}
+		if s.cache != nil {
+			s.cache.InvalidateBook(id)
+		}
+		return versionConflict(latest, *version)
+	}
+	if err != nil {
 		return fmt.Errorf("failed to delete book: %w", err)
 	}
 
 	// Remove from cache
 	if s.cache != nil {
+		s.cache.RemoveBookSuggestions(book)
 		// InvalidateBook will handle both individual book and book list invalidation
 		s.cache.InvalidateBook(id)
 	}
@@ -299,8 +865,151 @@ func (s *bookService) DeleteBook(id uuid.UUID) error {
 	return nil
 }
 
+// GetTrash lists the books in the trash, most recently deleted first
+func (s *bookService) GetTrash(filter models.TrashFilter) (*models.BooksListResponse, error) {
+	start := time.Now()
+	defer s.recordMetrics(start)
+
+	if filter.Limit <= 0 || filter.Limit > 100 {
+		filter.Limit = 50
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+
+	books, total, err := s.bookRepo.GetTrash(filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trash: %w", err)
+	}
+
+	return &models.BooksListResponse{
+		Books:  books,
+		Total:  &total,
+		Limit:  filter.Limit,
+		Offset: filter.Offset,
+	}, nil
+}
+
+// RestoreBook takes a book out of the trash and makes it visible again
+func (s *bookService) RestoreBook(id uuid.UUID, audit models.AuditInfo) (*models.Book, error) {
+	start := time.Now()
+	defer s.recordMetrics(start)
+
+	book, err := s.bookRepo.Restore(id, audit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore book: %w", err)
+	}
+
+	if s.cache != nil {
+		s.cache.AddBookSuggestions(book)
+		s.cache.InvalidateBook(id)
+	}
+
+	return book, nil
+}
+
+// PurgeTrash permanently removes books that have been in the trash longer
+// than retention
+func (s *bookService) PurgeTrash(retention time.Duration) (int, error) {
+	purged, err := s.bookRepo.Purge(time.Now().Add(-retention))
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge trash: %w", err)
+	}
+	return purged, nil
+}
+
+// TrashPurgeJob builds the worker job that purges books kept in the trash
+// past the retention period
+func TrashPurgeJob(books BookService, retention time.Duration) workers.BookJob {
+	return workers.BookJob{
+		ID:   uuid.New().String(),
+		Type: workers.JobTypeTrashPurge,
+		Task: func(ctx context.Context) (string, error) {
+			purged, err := books.PurgeTrash(retention)
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("Purged %d books from the trash", purged), nil
+		},
+	}
+}
+
+// GetBookHistory returns a book's audit trail, oldest first. Books in the
+// trash or already purged keep their history.
+func (s *bookService) GetBookHistory(id uuid.UUID) (*models.BookHistoryResponse, error) {
+	start := time.Now()
+	defer s.recordMetrics(start)
+
+	entries, err := s.bookRepo.GetHistory(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get book history: %w", err)
+	}
+
+	// Books that predate the audit trail have no entries yet
+	if len(entries) == 0 {
+		if _, err := s.bookRepo.GetByID(id); err != nil {
+			return nil, fmt.Errorf("book not found: %w", err)
+		}
+	}
+
+	return &models.BookHistoryResponse{BookID: id, Entries: entries}, nil
+}
+
+// GetBookAsOf returns a book's catalog record as it stood at the given time,
+// rebuilt from its audit trail
+func (s *bookService) GetBookAsOf(id uuid.UUID, at time.Time) (*models.BookAsOfResponse, error) {
+	start := time.Now()
+	defer s.recordMetrics(start)
+
+	entry, err := s.bookRepo.GetAsOf(id, at)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get book as of %s: %w", at.Format(time.RFC3339), err)
+	}
+	// The last write before that time took the book out of the catalog
+	if entry.After == nil {
+		return nil, fmt.Errorf("book not found as of %s (%s at %s)",
+			at.Format(time.RFC3339), entry.Action, entry.ChangedAt.Format(time.RFC3339))
+	}
+
+	return &models.BookAsOfResponse{
+		AsOf:      at,
+		Book:      entry.After,
+		Action:    entry.Action,
+		ChangedBy: entry.Actor,
+		ChangedAt: entry.ChangedAt,
+	}, nil
+}
+
+// RevertBook puts a book back to the catalog record of one of its revisions.
+// The record is written as an ordinary update, so it is validated and
+// normalized like any other and becomes a new revision; history is never
+// rewritten. A book in the trash must be restored before it is reverted.
+func (s *bookService) RevertBook(id uuid.UUID, revert *models.BookRevert, audit models.AuditInfo) (*models.Book, error) {
+	if revert.Revision < 1 {
+		return nil, fmt.Errorf("invalid revision: must be at least 1")
+	}
+
+	entry, err := s.bookRepo.GetRevision(id, revert.Revision)
+	if err != nil {
+		return nil, fmt.Errorf("failed to revert book: %w", err)
+	}
+
+	snapshot := entry.After
+	return s.UpdateBook(id, &models.UpdateBookRequest{
+		Title:       &snapshot.Title,
+		Author:      &snapshot.Author,
+		ISBN:        &snapshot.ISBN,
+		Publisher:   &snapshot.Publisher,
+		Genre:       &snapshot.Genre,
+		PublishedAt: &snapshot.PublishedAt,
+		Pages:       &snapshot.Pages,
+		Language:    &snapshot.Language,
+		Version:     revert.Version,
+	}, audit)
+}
+
 // BulkCreateBooks demonstrates concurrent bulk operations
-func (s *bookService) BulkCreateBooks(requests []*models.CreateBookRequest) ([]*models.Book, []error) {
+func (s *bookService) BulkCreateBooks(requests []*models.CreateBookRequest, audit models.AuditInfo) ([]*models.Book, []error) {
 	if len(requests) == 0 {
 		return nil, nil
 	}
@@ -329,7 +1038,7 @@ func (s *bookService) BulkCreateBooks(requests []*models.CreateBookRequest) ([]*
 			semaphore <- struct{}{}
 			defer func() { <-semaphore }()
 
-			book, err := s.CreateBook(request)
+			book, err := s.CreateBook(request, audit)
 			resultChan <- struct {
 				index int
 				book  *models.Book
@@ -356,6 +1065,96 @@ func (s *bookService) BulkCreateBooks(requests []*models.CreateBookRequest) ([]*
 	return books, errors
 }
 
+// BulkCreateBooksAtomic creates a batch of books all together or not at all.
+// Every book is validated before any is written, against the catalog and
+// against the other books in the batch; if any is invalid nothing is created
+// and the returned errors hold each book's validation error by index. A
+// valid batch is inserted in a single transaction bound to ctx, so a request
+// that times out rolls the batch back rather than completing it unseen.
+func (s *bookService) BulkCreateBooksAtomic(ctx context.Context, requests []*models.CreateBookRequest, audit models.AuditInfo) ([]*models.Book, []error, error) {
+	if len(requests) == 0 {
+		return nil, nil, nil
+	}
+
+	start := time.Now()
+	defer s.recordMetrics(start)
+
+	// Validate and check the catalog concurrently, as BulkCreateBooks does
+	semaphore := make(chan struct{}, 10)
+	errs := make([]error, len(requests))
+	lookupErrs := make([]error, len(requests))
+	var wg sync.WaitGroup
+	for i, req := range requests {
+		wg.Add(1)
+		go func(index int, request *models.CreateBookRequest) {
+			defer wg.Done()
+
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			if err := s.prepareCreateRequest(request); err != nil {
+				errs[index] = err
+				return
+			}
+			exists, err := s.bookRepo.ExistsByISBN(request.ISBN, nil)
+			if err != nil {
+				lookupErrs[index] = err
+				return
+			}
+			if exists {
+				errs[index] = fmt.Errorf("book with ISBN %s already exists", request.ISBN)
+			}
+		}(i, req)
+	}
+	wg.Wait()
+
+	for _, err := range lookupErrs {
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to check ISBN uniqueness: %w", err)
+		}
+	}
+
+	// The first book with an ISBN keeps it; later ones in the batch are duplicates
+	firstIndex := make(map[string]int)
+	for i, req := range requests {
+		if errs[i] != nil {
+			continue
+		}
+		if first, ok := firstIndex[req.ISBN]; ok {
+			errs[i] = fmt.Errorf("book with ISBN %s already exists in the batch at index %d", req.ISBN, first)
+			continue
+		}
+		firstIndex[req.ISBN] = i
+	}
+
+	invalid := 0
+	for _, err := range errs {
+		if err != nil {
+			invalid++
+		}
+	}
+	if invalid > 0 {
+		return nil, errs, fmt.Errorf("invalid batch: %d of %d books failed validation", invalid, len(requests))
+	}
+
+	books, err := s.bookRepo.CreateBatch(ctx, requests, audit)
+	// Another request took an ISBN after it was checked above
+	var itemErr *repository.BatchItemError
+	if errors.As(err, &itemErr) && itemErr.Duplicate {
+		errs[itemErr.Index] = itemErr.Err
+		return nil, errs, fmt.Errorf("invalid batch: 1 of %d books failed validation", len(requests))
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create books: %w", err)
+	}
+
+	for i, book := range books {
+		s.bookCreated(requests[i], book)
+	}
+
+	return books, nil, nil
+}
+
 // GetMetrics returns service metrics safely (without copying mutex)
 func (s *bookService) GetMetrics() ServiceMetrics {
 	s.metrics.mu.RLock()
@@ -402,20 +1201,13 @@ func (s *bookService) validateAuthor(author string, errChan chan<- error) {
 	errChan <- nil
 }
 
-func (s *bookService) validateISBN(isbn string, errChan chan<- error) {
-	if strings.TrimSpace(isbn) == "" {
+func (s *bookService) validateISBN(value string, errChan chan<- error) {
+	if strings.TrimSpace(value) == "" {
 		errChan <- fmt.Errorf("ISBN is required")
 		return
 	}
 
-	// Add ISBN format validation
-	normalized := normalizeISBN(isbn)
-	if !isValidISBN(normalized) {
-		errChan <- fmt.Errorf("invalid ISBN format")
-		return
-	}
-
-	errChan <- nil
+	errChan <- validateISBNChecksum(value)
 }
 
 func (s *bookService) normalizeBookData(req *models.CreateBookRequest) {
@@ -454,8 +1246,8 @@ func (s *bookService) validateCreateRequest(req *models.CreateBookRequest) error
 	if strings.TrimSpace(req.ISBN) == "" {
 		return fmt.Errorf("ISBN is required")
 	}
-	if !isValidISBN(req.ISBN) {
-		return fmt.Errorf("invalid ISBN format")
+	if err := validateISBNChecksum(req.ISBN); err != nil {
+		return err
 	}
 	if req.Pages <= 0 {
 		return fmt.Errorf("pages must be greater than 0")
@@ -475,28 +1267,114 @@ func (s *bookService) validateUpdateRequest(req *models.UpdateBookRequest) error
 		if strings.TrimSpace(*req.ISBN) == "" {
 			return fmt.Errorf("ISBN cannot be empty")
 		}
-		if !isValidISBN(*req.ISBN) {
-			return fmt.Errorf("invalid ISBN format")
+		if err := validateISBNChecksum(*req.ISBN); err != nil {
+			return err
 		}
 	}
 	if req.Pages != nil && *req.Pages <= 0 {
 		return fmt.Errorf("pages must be greater than 0")
 	}
+	if req.Available != nil {
+		return fmt.Errorf("invalid field available: availability is derived from loans, use checkout and return")
+	}
+	if req.Version != nil && *req.Version < 1 {
+		return fmt.Errorf("invalid version: must be at least 1")
+	}
 	return nil
 }
 
-// normalizeISBN removes hyphens and spaces from ISBN
-func normalizeISBN(isbn string) string {
-	return strings.ReplaceAll(strings.ReplaceAll(isbn, "-", ""), " ", "")
+// versionConflict reports an update made against an outdated version of a
+// book, returning the current copy for the client to reconcile with
+func versionConflict(current *models.Book, expected int) *apperrors.AppError {
+	return apperrors.VersionConflict(
+		"book has been modified",
+		fmt.Sprintf("expected version %d, current version is %d", expected, current.Version),
+		current,
+	)
 }
 
-// isValidISBN checks if the ISBN format is valid (basic validation)
-func isValidISBN(isbn string) bool {
-	normalized := normalizeISBN(isbn)
-	// Basic validation: ISBN-10 (10 digits) or ISBN-13 (13 digits)
-	if len(normalized) != 10 && len(normalized) != 13 {
-		return false
+// validateBookFilterRanges rejects range filters whose lower bound lies after
+// their upper bound, which could never match a book
+func validateBookFilterRanges(filter models.BookFilter) error {
+	timeRanges := []struct {
+		name     string
+		from, to *time.Time
+	}{
+		{"published", filter.PublishedFrom, filter.PublishedTo},
+		{"created", filter.CreatedSince, filter.CreatedUntil},
+		{"updated", filter.UpdatedSince, filter.UpdatedUntil},
 	}
-	// Additional validation logic can be added here (checksum validation)
-	return true
+	for _, r := range timeRanges {
+		if r.from != nil && r.to != nil && r.from.After(*r.to) {
+			return fmt.Errorf("invalid filter: %s range starts after it ends", r.name)
+		}
+	}
+	if filter.MinPages != nil && filter.MaxPages != nil && *filter.MinPages > *filter.MaxPages {
+		return fmt.Errorf("invalid filter: min_pages is greater than max_pages")
+	}
+	return nil
+}
+
+// normalizeBookProjection validates a projection and lists its fields and
+// included resources in canonical order without repeats, so equivalent
+// projections share cache entries
+func normalizeBookProjection(projection models.BookProjection) (models.BookProjection, error) {
+	fields, unknown := canonicalNames(projection.Fields, models.BookFields)
+	if unknown != "" {
+		return projection, fmt.Errorf("invalid fields: unknown field %q", unknown)
+	}
+	include, unknown := canonicalNames(projection.Include, models.BookIncludes)
+	if unknown != "" {
+		return projection, fmt.Errorf("invalid include: unknown resource %q", unknown)
+	}
+	return models.BookProjection{Fields: fields, Include: include}, nil
+}
+
+// canonicalNames keeps the requested names, ignoring case and blanks, in the
+// order known lists them. It also returns a requested name that is not
+// known, if any.
+func canonicalNames(requested, known []string) (names []string, unknown string) {
+	wanted := make(map[string]bool, len(requested))
+	for _, name := range requested {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name != "" {
+			wanted[name] = true
+		}
+	}
+	if len(wanted) == 0 {
+		return nil, ""
+	}
+
+	isKnown := make(map[string]bool, len(known))
+	for _, name := range known {
+		isKnown[name] = true
+		if wanted[name] {
+			names = append(names, name)
+		}
+	}
+	for _, name := range requested {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name != "" && !isKnown[name] {
+			return nil, name
+		}
+	}
+	return names, ""
+}
+
+// normalizeISBN converts a valid ISBN-10 or ISBN-13 to its canonical ISBN-13
+// form. Invalid input is only stripped of hyphens and spaces.
+func normalizeISBN(value string) string {
+	canonical, err := isbn.Canonical(value)
+	if err != nil {
+		return isbn.Strip(value)
+	}
+	return canonical
+}
+
+// validateISBNChecksum verifies the length, characters and check digit of an ISBN
+func validateISBNChecksum(value string) error {
+	if _, err := isbn.Parse(value); err != nil {
+		return fmt.Errorf("invalid ISBN format: %w", err)
+	}
+	return nil
 }
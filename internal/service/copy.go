@@ -0,0 +1,208 @@
+package service
+
+import (
+	"fmt"
+	"libmngmt/internal/cache"
+	"libmngmt/internal/models"
+	"libmngmt/internal/repository"
+	"libmngmt/internal/workers"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// CopyService defines the interface for managing the physical copies of a book
+type CopyService interface {
+	AddCopy(bookID uuid.UUID, req *models.CreateCopyRequest) (*models.Copy, error)
+	GetCopy(id uuid.UUID) (*models.Copy, error)
+	GetBookCopies(bookID uuid.UUID) ([]models.Copy, error)
+	UpdateCopy(id uuid.UUID, req *models.UpdateCopyRequest) (*models.Copy, error)
+	DeleteCopy(id uuid.UUID) error
+}
+
+// copyService implements CopyService interface
+type copyService struct {
+	copyRepo repository.CopyRepository
+	bookRepo repository.BookRepository
+	holdRepo repository.HoldRepository
+	cache    *cache.BookCache
+	notifier *holdNotifier
+	policy   CirculationPolicy
+}
+
+// NewCopyService creates a new copy service
+func NewCopyService(copyRepo repository.CopyRepository, bookRepo repository.BookRepository, holdRepo repository.HoldRepository, cache *cache.BookCache, processor *workers.BookProcessor, policy CirculationPolicy) CopyService {
+	return &copyService{
+		copyRepo: copyRepo,
+		bookRepo: bookRepo,
+		holdRepo: holdRepo,
+		cache:    cache,
+		notifier: &holdNotifier{holdRepo: holdRepo, processor: processor},
+		policy:   policy,
+	}
+}
+
+// AddCopy registers a new physical copy of a book
+func (s *copyService) AddCopy(bookID uuid.UUID, req *models.CreateCopyRequest) (*models.Copy, error) {
+	req.Barcode = normalizeBarcode(req.Barcode)
+	req.Location = strings.TrimSpace(req.Location)
+
+	if req.Barcode == "" {
+		return nil, fmt.Errorf("barcode is required")
+	}
+	if req.Status != "" {
+		if err := checkCopyStatus(req.Status); err != nil {
+			return nil, err
+		}
+	}
+	if req.Condition != "" && !req.Condition.IsValid() {
+		return nil, fmt.Errorf("invalid copy condition: %s", req.Condition)
+	}
+
+	if _, err := s.bookRepo.GetByID(bookID); err != nil {
+		return nil, fmt.Errorf("failed to get book: %w", err)
+	}
+
+	exists, err := s.copyRepo.ExistsByBarcode(req.Barcode, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check barcode uniqueness: %w", err)
+	}
+	if exists {
+		return nil, fmt.Errorf("copy with barcode %s already exists", req.Barcode)
+	}
+
+	item, err := s.copyRepo.Create(bookID, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create copy: %w", err)
+	}
+
+	if err := s.releaseBook(bookID); err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+// GetCopy retrieves a copy by ID
+func (s *copyService) GetCopy(id uuid.UUID) (*models.Copy, error) {
+	item, err := s.copyRepo.GetByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get copy: %w", err)
+	}
+	return item, nil
+}
+
+// GetBookCopies lists the copies of a book
+func (s *copyService) GetBookCopies(bookID uuid.UUID) ([]models.Copy, error) {
+	if _, err := s.bookRepo.GetByID(bookID); err != nil {
+		return nil, fmt.Errorf("failed to get book: %w", err)
+	}
+
+	copies, err := s.copyRepo.GetByBook(bookID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get copies: %w", err)
+	}
+	return copies, nil
+}
+
+// UpdateCopy validates and applies a partial update to a copy
+func (s *copyService) UpdateCopy(id uuid.UUID, req *models.UpdateCopyRequest) (*models.Copy, error) {
+	if _, err := s.copyRepo.GetByID(id); err != nil {
+		return nil, fmt.Errorf("copy not found: %w", err)
+	}
+
+	if req.Barcode != nil {
+		normalized := normalizeBarcode(*req.Barcode)
+		if normalized == "" {
+			return nil, fmt.Errorf("barcode cannot be empty")
+		}
+		req.Barcode = &normalized
+
+		exists, err := s.copyRepo.ExistsByBarcode(normalized, &id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check barcode uniqueness: %w", err)
+		}
+		if exists {
+			return nil, fmt.Errorf("copy with barcode %s already exists", normalized)
+		}
+	}
+	if req.Status != nil {
+		if err := checkCopyStatus(*req.Status); err != nil {
+			return nil, err
+		}
+	}
+	if req.Condition != nil && !req.Condition.IsValid() {
+		return nil, fmt.Errorf("invalid copy condition: %s", *req.Condition)
+	}
+	if req.Location != nil {
+		trimmed := strings.TrimSpace(*req.Location)
+		req.Location = &trimmed
+	}
+
+	item, err := s.copyRepo.Update(id, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update copy: %w", err)
+	}
+
+	if req.Status == nil {
+		s.invalidateBook(item.BookID)
+	} else if err := s.releaseBook(item.BookID); err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+// DeleteCopy removes a copy from the collection
+func (s *copyService) DeleteCopy(id uuid.UUID) error {
+	item, err := s.copyRepo.GetByID(id)
+	if err != nil {
+		return fmt.Errorf("copy not found: %w", err)
+	}
+
+	if err := s.copyRepo.Delete(id); err != nil {
+		return fmt.Errorf("failed to delete copy: %w", err)
+	}
+
+	return s.releaseBook(item.BookID)
+}
+
+// releaseBook sets a copy that came free aside for the book's next hold and
+// refreshes the book's availability after its copies changed
+func (s *copyService) releaseBook(bookID uuid.UUID) error {
+	hold, err := s.holdRepo.Release(bookID, s.policy.HoldPickupWindow)
+	if err != nil {
+		return fmt.Errorf("failed to release book: %w", err)
+	}
+
+	s.invalidateBook(bookID)
+	if hold != nil {
+		s.notifier.notifyReady(hold)
+	}
+
+	return nil
+}
+
+// checkCopyStatus validates a status set by staff. Only circulation moves a
+// copy to checked_out.
+func checkCopyStatus(status models.CopyStatus) error {
+	if !status.IsValid() {
+		return fmt.Errorf("invalid copy status: %s", status)
+	}
+	if status == models.CopyStatusCheckedOut {
+		return fmt.Errorf("invalid copy status: copies are checked out through POST /api/books/{id}/checkout")
+	}
+	return nil
+}
+
+// invalidateBook drops cached book lists whose copy counts changed
+func (s *copyService) invalidateBook(bookID uuid.UUID) {
+	if s.cache != nil {
+		s.cache.InvalidateBook(bookID)
+	}
+}
+
+// normalizeBarcode trims whitespace and upper-cases a barcode
+func normalizeBarcode(barcode string) string {
+	return strings.ToUpper(strings.TrimSpace(barcode))
+}
@@ -0,0 +1,190 @@
+package service
+
+import (
+	"errors"
+	"libmngmt/internal/models"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockCopyRepository is a mock implementation of CopyRepository for testing
+type MockCopyRepository struct {
+	mock.Mock
+}
+
+func (m *MockCopyRepository) Create(bookID uuid.UUID, req *models.CreateCopyRequest) (*models.Copy, error) {
+	args := m.Called(bookID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Copy), args.Error(1)
+}
+
+func (m *MockCopyRepository) GetByID(id uuid.UUID) (*models.Copy, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Copy), args.Error(1)
+}
+
+func (m *MockCopyRepository) GetByBook(bookID uuid.UUID) ([]models.Copy, error) {
+	args := m.Called(bookID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Copy), args.Error(1)
+}
+
+func (m *MockCopyRepository) Update(id uuid.UUID, req *models.UpdateCopyRequest) (*models.Copy, error) {
+	args := m.Called(id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Copy), args.Error(1)
+}
+
+func (m *MockCopyRepository) Delete(id uuid.UUID) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockCopyRepository) ExistsByBarcode(barcode string, excludeID *uuid.UUID) (bool, error) {
+	args := m.Called(barcode, excludeID)
+	return args.Bool(0), args.Error(1)
+}
+
+func TestCopyService_AddCopy(t *testing.T) {
+	t.Run("add copy normalizes barcode", func(t *testing.T) {
+		copyRepo := &MockCopyRepository{}
+		bookRepo := &MockBookRepository{}
+		holdRepo := &MockHoldRepository{}
+		service := NewCopyService(copyRepo, bookRepo, holdRepo, nil, nil, testPolicy)
+
+		bookID := uuid.New()
+		req := &models.CreateCopyRequest{Barcode: "  b0001 ", Location: " Main stacks "}
+
+		bookRepo.On("GetByID", bookID).Return(&models.Book{ID: bookID}, nil)
+		copyRepo.On("ExistsByBarcode", "B0001", (*uuid.UUID)(nil)).Return(false, nil)
+		copyRepo.On("Create", bookID, req).Return(&models.Copy{BookID: bookID, Barcode: "B0001"}, nil)
+		holdRepo.On("Release", bookID, testPolicy.HoldPickupWindow).Return(nil, nil)
+
+		item, err := service.AddCopy(bookID, req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "B0001", item.Barcode)
+		assert.Equal(t, "Main stacks", req.Location)
+		copyRepo.AssertExpectations(t)
+		holdRepo.AssertExpectations(t)
+	})
+
+	t.Run("add copy with duplicate barcode", func(t *testing.T) {
+		copyRepo := &MockCopyRepository{}
+		bookRepo := &MockBookRepository{}
+		service := NewCopyService(copyRepo, bookRepo, &MockHoldRepository{}, nil, nil, testPolicy)
+
+		bookID := uuid.New()
+		bookRepo.On("GetByID", bookID).Return(&models.Book{ID: bookID}, nil)
+		copyRepo.On("ExistsByBarcode", "B0001", (*uuid.UUID)(nil)).Return(true, nil)
+
+		_, err := service.AddCopy(bookID, &models.CreateCopyRequest{Barcode: "B0001"})
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "already exists")
+		copyRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("add copy to unknown book", func(t *testing.T) {
+		copyRepo := &MockCopyRepository{}
+		bookRepo := &MockBookRepository{}
+		service := NewCopyService(copyRepo, bookRepo, &MockHoldRepository{}, nil, nil, testPolicy)
+
+		bookID := uuid.New()
+		bookRepo.On("GetByID", bookID).Return(nil, errors.New("book not found"))
+
+		_, err := service.AddCopy(bookID, &models.CreateCopyRequest{Barcode: "B0001"})
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "book not found")
+	})
+
+	t.Run("validation errors", func(t *testing.T) {
+		service := NewCopyService(&MockCopyRepository{}, &MockBookRepository{}, &MockHoldRepository{}, nil, nil, testPolicy)
+
+		_, err := service.AddCopy(uuid.New(), &models.CreateCopyRequest{Barcode: " "})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "barcode is required")
+
+		_, err = service.AddCopy(uuid.New(), &models.CreateCopyRequest{Barcode: "B1", Status: "borrowed"})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid copy status")
+
+		_, err = service.AddCopy(uuid.New(), &models.CreateCopyRequest{Barcode: "B1", Status: models.CopyStatusCheckedOut})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "checked out through")
+
+		_, err = service.AddCopy(uuid.New(), &models.CreateCopyRequest{Barcode: "B1", Condition: "mint"})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid copy condition")
+	})
+}
+
+func TestCopyService_UpdateCopy(t *testing.T) {
+	t.Run("update copy with invalid status", func(t *testing.T) {
+		copyRepo := &MockCopyRepository{}
+		service := NewCopyService(copyRepo, &MockBookRepository{}, &MockHoldRepository{}, nil, nil, testPolicy)
+
+		id := uuid.New()
+		status := models.CopyStatus("borrowed")
+		copyRepo.On("GetByID", id).Return(&models.Copy{ID: id}, nil)
+
+		_, err := service.UpdateCopy(id, &models.UpdateCopyRequest{Status: &status})
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid copy status")
+		copyRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("copy back from repair is set aside for the next hold", func(t *testing.T) {
+		copyRepo := &MockCopyRepository{}
+		holdRepo := &MockHoldRepository{}
+		service := NewCopyService(copyRepo, &MockBookRepository{}, holdRepo, nil, nil, testPolicy)
+
+		id := uuid.New()
+		bookID := uuid.New()
+		status := models.CopyStatusAvailable
+		req := &models.UpdateCopyRequest{Status: &status}
+		promoted := &models.Hold{ID: uuid.New(), BookID: bookID, Status: models.HoldStatusReady, CopyID: &id}
+
+		copyRepo.On("GetByID", id).Return(&models.Copy{ID: id, BookID: bookID, Status: models.CopyStatusInRepair}, nil)
+		copyRepo.On("Update", id, req).Return(&models.Copy{ID: id, BookID: bookID, Status: status}, nil)
+		holdRepo.On("Release", bookID, testPolicy.HoldPickupWindow).Return(promoted, nil)
+
+		item, err := service.UpdateCopy(id, req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, status, item.Status)
+		holdRepo.AssertExpectations(t)
+	})
+}
+
+func TestCopyService_DeleteCopy(t *testing.T) {
+	t.Run("copy on loan is kept", func(t *testing.T) {
+		copyRepo := &MockCopyRepository{}
+		holdRepo := &MockHoldRepository{}
+		service := NewCopyService(copyRepo, &MockBookRepository{}, holdRepo, nil, nil, testPolicy)
+
+		id := uuid.New()
+		copyRepo.On("GetByID", id).Return(&models.Copy{ID: id, BookID: uuid.New()}, nil)
+		copyRepo.On("Delete", id).Return(errors.New("copy is still checked out and cannot be deleted"))
+
+		err := service.DeleteCopy(id)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "still checked out")
+		holdRepo.AssertNotCalled(t, "Release", mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"libmngmt/internal/models"
+	"libmngmt/internal/repository"
+	"libmngmt/internal/workers"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FineRule describes how overdue fines accrue for a class of books
+type FineRule struct {
+	DailyRateCents int64
+	GraceDays      int
+	// MaxCents caps the fine for a single loan; zero means uncapped
+	MaxCents int64
+}
+
+// FinePolicy selects the fine rule for a book by genre
+type FinePolicy struct {
+	Default FineRule
+	// ByGenre is keyed by lower-cased genre
+	ByGenre map[string]FineRule
+}
+
+// RuleFor returns the rule for a genre, falling back to the default
+func (p FinePolicy) RuleFor(genre string) FineRule {
+	if rule, ok := p.ByGenre[strings.ToLower(strings.TrimSpace(genre))]; ok {
+		return rule
+	}
+	return p.Default
+}
+
+// Assess computes the total fine for a loan that was due at dueAt and is
+// still out (or was returned) at end. Only whole days past the grace period
+// are charged.
+func (r FineRule) Assess(dueAt, end time.Time) int64 {
+	if !end.After(dueAt) {
+		return 0
+	}
+
+	daysLate := int(end.Sub(dueAt) / (24 * time.Hour))
+	chargeable := daysLate - r.GraceDays
+	if chargeable <= 0 {
+		return 0
+	}
+
+	fine := int64(chargeable) * r.DailyRateCents
+	if r.MaxCents > 0 && fine > r.MaxCents {
+		fine = r.MaxCents
+	}
+	return fine
+}
+
+// FineService defines the interface for overdue fines and the borrower ledger
+type FineService interface {
+	AccrueOverdueFines() (int, error)
+	GetLedger(memberID uuid.UUID) (*models.FineLedger, error)
+	RecordPayment(memberID uuid.UUID, req *models.FineCreditRequest) (*models.FineEntry, error)
+	WaiveFine(memberID uuid.UUID, req *models.FineCreditRequest) (*models.FineEntry, error)
+}
+
+// fineService implements FineService interface
+type fineService struct {
+	fineRepo   repository.FineRepository
+	memberRepo repository.MemberRepository
+	policy     FinePolicy
+}
+
+// NewFineService creates a new fine service
+func NewFineService(fineRepo repository.FineRepository, memberRepo repository.MemberRepository, policy FinePolicy) FineService {
+	return &fineService{
+		fineRepo:   fineRepo,
+		memberRepo: memberRepo,
+		policy:     policy,
+	}
+}
+
+// AccrueOverdueFines charges every overdue loan up to the fine its rule allows
+// today. Returned loans are charged their final amount and closed. It returns
+// the number of loans that received a new charge.
+func (s *fineService) AccrueOverdueFines() (int, error) {
+	now := time.Now()
+
+	loans, err := s.fineRepo.GetAccruing(now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get overdue loans: %w", err)
+	}
+
+	charged := 0
+	for _, loan := range loans {
+		end := now
+		closed := false
+		if loan.ReturnedAt != nil {
+			end = *loan.ReturnedAt
+			closed = true
+		}
+
+		total := s.policy.RuleFor(loan.Genre).Assess(loan.DueAt, end)
+		amount, err := s.fineRepo.Accrue(loan, total, closed)
+		if err != nil {
+			return charged, fmt.Errorf("failed to accrue fine for loan %s: %w", loan.LoanID, err)
+		}
+		if amount > 0 {
+			charged++
+		}
+	}
+
+	return charged, nil
+}
+
+// GetLedger returns a borrower's fine history and balance
+func (s *fineService) GetLedger(memberID uuid.UUID) (*models.FineLedger, error) {
+	if _, err := s.memberRepo.GetByID(memberID); err != nil {
+		return nil, fmt.Errorf("failed to get member: %w", err)
+	}
+
+	entries, err := s.fineRepo.GetLedger(memberID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fine ledger: %w", err)
+	}
+
+	ledger := &models.FineLedger{MemberID: memberID, Entries: entries}
+	for _, entry := range entries {
+		switch entry.EntryType {
+		case models.FineEntryCharge:
+			ledger.ChargedCents += entry.AmountCents
+		case models.FineEntryPayment:
+			ledger.PaidCents += entry.AmountCents
+		case models.FineEntryWaiver:
+			ledger.WaivedCents += entry.AmountCents
+		}
+	}
+	ledger.BalanceCents = ledger.ChargedCents - ledger.PaidCents - ledger.WaivedCents
+
+	return ledger, nil
+}
+
+// RecordPayment records money received against a borrower's balance
+func (s *fineService) RecordPayment(memberID uuid.UUID, req *models.FineCreditRequest) (*models.FineEntry, error) {
+	return s.recordCredit(memberID, models.FineEntryPayment, req)
+}
+
+// WaiveFine forgives part or all of a borrower's balance. A note explaining
+// the waiver is required so the ledger stays auditable.
+func (s *fineService) WaiveFine(memberID uuid.UUID, req *models.FineCreditRequest) (*models.FineEntry, error) {
+	if strings.TrimSpace(req.Note) == "" {
+		return nil, fmt.Errorf("note is required for a waiver")
+	}
+	return s.recordCredit(memberID, models.FineEntryWaiver, req)
+}
+
+func (s *fineService) recordCredit(memberID uuid.UUID, entryType models.FineEntryType, req *models.FineCreditRequest) (*models.FineEntry, error) {
+	if req.AmountCents <= 0 {
+		return nil, fmt.Errorf("amount_cents must be greater than 0")
+	}
+	req.Note = strings.TrimSpace(req.Note)
+
+	entry, err := s.fineRepo.RecordCredit(memberID, entryType, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record %s: %w", entryType, err)
+	}
+	return entry, nil
+}
+
+// FineAccrualJob builds the worker job that runs an overdue fine sweep
+func FineAccrualJob(fines FineService) workers.BookJob {
+	return workers.BookJob{
+		ID:   uuid.New().String(),
+		Type: workers.JobTypeFineAccrual,
+		Task: func(ctx context.Context) (string, error) {
+			charged, err := fines.AccrueOverdueFines()
+			if err != nil {
+				log.Printf("Fine accrual sweep stopped after %d loans: %v", charged, err)
+				return "", err
+			}
+			return fmt.Sprintf("Accrued fines on %d overdue loans", charged), nil
+		},
+	}
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"libmngmt/internal/models"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockFineRepository is a mock implementation of FineRepository for testing
+type MockFineRepository struct {
+	mock.Mock
+}
+
+func (m *MockFineRepository) GetAccruing(now time.Time) ([]models.OverdueLoan, error) {
+	args := m.Called(now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.OverdueLoan), args.Error(1)
+}
+
+func (m *MockFineRepository) Accrue(loan models.OverdueLoan, totalCents int64, closed bool) (int64, error) {
+	args := m.Called(loan, totalCents, closed)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockFineRepository) RecordCredit(memberID uuid.UUID, entryType models.FineEntryType, req *models.FineCreditRequest) (*models.FineEntry, error) {
+	args := m.Called(memberID, entryType, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.FineEntry), args.Error(1)
+}
+
+func (m *MockFineRepository) GetLedger(memberID uuid.UUID) ([]models.FineEntry, error) {
+	args := m.Called(memberID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.FineEntry), args.Error(1)
+}
+
+var testFinePolicy = FinePolicy{
+	Default: FineRule{DailyRateCents: 25, MaxCents: 1000},
+	ByGenre: map[string]FineRule{
+		"children": {DailyRateCents: 10, GraceDays: 3, MaxCents: 50},
+	},
+}
+
+func TestFineRule_Assess(t *testing.T) {
+	due := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("not overdue", func(t *testing.T) {
+		assert.Equal(t, int64(0), testFinePolicy.Default.Assess(due, due.Add(-time.Hour)))
+	})
+
+	t.Run("partial days are not charged", func(t *testing.T) {
+		assert.Equal(t, int64(0), testFinePolicy.Default.Assess(due, due.Add(23*time.Hour)))
+		assert.Equal(t, int64(50), testFinePolicy.Default.Assess(due, due.Add(50*time.Hour)))
+	})
+
+	t.Run("grace period and cap", func(t *testing.T) {
+		rule := testFinePolicy.RuleFor(" Children ")
+		assert.Equal(t, int64(0), rule.Assess(due, due.AddDate(0, 0, 3)))
+		assert.Equal(t, int64(20), rule.Assess(due, due.AddDate(0, 0, 5)))
+		assert.Equal(t, int64(50), rule.Assess(due, due.AddDate(0, 0, 30)))
+	})
+
+	t.Run("unknown genre uses default", func(t *testing.T) {
+		assert.Equal(t, testFinePolicy.Default, testFinePolicy.RuleFor("Poetry"))
+	})
+}
+
+func TestFineService_AccrueOverdueFines(t *testing.T) {
+	t.Run("open loans accrue, returned loans are closed", func(t *testing.T) {
+		fineRepo := &MockFineRepository{}
+		service := NewFineService(fineRepo, &MockMemberRepository{}, testFinePolicy)
+
+		returned := time.Now().Add(-time.Hour)
+		open := models.OverdueLoan{LoanID: uuid.New(), Genre: "Fiction", DueAt: time.Now().AddDate(0, 0, -2).Add(-time.Minute)}
+		late := models.OverdueLoan{LoanID: uuid.New(), Genre: "Children", DueAt: returned.AddDate(0, 0, -4), ReturnedAt: &returned}
+
+		fineRepo.On("GetAccruing", mock.AnythingOfType("time.Time")).Return([]models.OverdueLoan{open, late}, nil)
+		fineRepo.On("Accrue", open, int64(50), false).Return(int64(25), nil)
+		fineRepo.On("Accrue", late, int64(10), true).Return(int64(0), nil)
+
+		charged, err := service.AccrueOverdueFines()
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, charged)
+		fineRepo.AssertExpectations(t)
+	})
+}
+
+func TestFineService_GetLedger(t *testing.T) {
+	t.Run("ledger totals and balance", func(t *testing.T) {
+		fineRepo := &MockFineRepository{}
+		memberRepo := &MockMemberRepository{}
+		service := NewFineService(fineRepo, memberRepo, testFinePolicy)
+
+		member := activeMember()
+		memberRepo.On("GetByID", member.ID).Return(member, nil)
+		fineRepo.On("GetLedger", member.ID).Return([]models.FineEntry{
+			{EntryType: models.FineEntryCharge, AmountCents: 300},
+			{EntryType: models.FineEntryPayment, AmountCents: 100},
+			{EntryType: models.FineEntryWaiver, AmountCents: 50},
+		}, nil)
+
+		ledger, err := service.GetLedger(member.ID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(300), ledger.ChargedCents)
+		assert.Equal(t, int64(100), ledger.PaidCents)
+		assert.Equal(t, int64(50), ledger.WaivedCents)
+		assert.Equal(t, int64(150), ledger.BalanceCents)
+	})
+}
+
+func TestFineService_Credits(t *testing.T) {
+	t.Run("payment must be positive", func(t *testing.T) {
+		service := NewFineService(&MockFineRepository{}, &MockMemberRepository{}, testFinePolicy)
+
+		_, err := service.RecordPayment(uuid.New(), &models.FineCreditRequest{AmountCents: 0})
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "must be greater than")
+	})
+
+	t.Run("waiver requires a note", func(t *testing.T) {
+		fineRepo := &MockFineRepository{}
+		service := NewFineService(fineRepo, &MockMemberRepository{}, testFinePolicy)
+
+		_, err := service.WaiveFine(uuid.New(), &models.FineCreditRequest{AmountCents: 100, Note: "  "})
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "note is required")
+		fineRepo.AssertNotCalled(t, "RecordCredit", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("record waiver", func(t *testing.T) {
+		fineRepo := &MockFineRepository{}
+		service := NewFineService(fineRepo, &MockMemberRepository{}, testFinePolicy)
+
+		memberID := uuid.New()
+		req := &models.FineCreditRequest{AmountCents: 100, Note: "Book returned in drop box before due date"}
+		fineRepo.On("RecordCredit", memberID, models.FineEntryWaiver, req).
+			Return(&models.FineEntry{EntryType: models.FineEntryWaiver, AmountCents: 100}, nil)
+
+		entry, err := service.WaiveFine(memberID, req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, models.FineEntryWaiver, entry.EntryType)
+		fineRepo.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"libmngmt/internal/cache"
+	"libmngmt/internal/models"
+	"libmngmt/internal/repository"
+	"libmngmt/internal/workers"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// HoldService defines the interface for the per-book reservation queue
+type HoldService interface {
+	PlaceHold(bookID uuid.UUID, req *models.PlaceHoldRequest) (*models.Hold, error)
+	GetHold(id uuid.UUID) (*models.Hold, error)
+	GetBookHolds(bookID uuid.UUID) ([]models.Hold, error)
+	CancelHold(id uuid.UUID) (*models.Hold, error)
+	ExpireReadyHolds() (int, error)
+}
+
+// holdService implements HoldService interface
+type holdService struct {
+	holdRepo   repository.HoldRepository
+	memberRepo repository.MemberRepository
+	cache      *cache.BookCache
+	notifier   *holdNotifier
+	policy     CirculationPolicy
+}
+
+// NewHoldService creates a new hold service
+func NewHoldService(holdRepo repository.HoldRepository, memberRepo repository.MemberRepository, cache *cache.BookCache, processor *workers.BookProcessor, policy CirculationPolicy) HoldService {
+	return &holdService{
+		holdRepo:   holdRepo,
+		memberRepo: memberRepo,
+		cache:      cache,
+		notifier:   &holdNotifier{holdRepo: holdRepo, processor: processor},
+		policy:     policy,
+	}
+}
+
+// PlaceHold adds an eligible member to the end of a book's queue
+func (s *holdService) PlaceHold(bookID uuid.UUID, req *models.PlaceHoldRequest) (*models.Hold, error) {
+	if req.MemberID == uuid.Nil {
+		return nil, fmt.Errorf("member_id is required")
+	}
+
+	member, err := s.memberRepo.GetByID(req.MemberID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get member: %w", err)
+	}
+	if err := checkMemberCanBorrow(member); err != nil {
+		return nil, err
+	}
+
+	hold, err := s.holdRepo.Place(bookID, member.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to place hold: %w", err)
+	}
+
+	return hold, nil
+}
+
+// GetHold retrieves a hold with its queue position
+func (s *holdService) GetHold(id uuid.UUID) (*models.Hold, error) {
+	hold, err := s.holdRepo.GetByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hold: %w", err)
+	}
+	return hold, nil
+}
+
+// GetBookHolds returns the active queue for a book
+func (s *holdService) GetBookHolds(bookID uuid.UUID) ([]models.Hold, error) {
+	holds, err := s.holdRepo.GetByBook(bookID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get holds: %w", err)
+	}
+	return holds, nil
+}
+
+// CancelHold withdraws a hold, passing a ready book on to the next patron
+func (s *holdService) CancelHold(id uuid.UUID) (*models.Hold, error) {
+	hold, promoted, err := s.holdRepo.Cancel(id, s.policy.HoldPickupWindow)
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel hold: %w", err)
+	}
+
+	if promoted != nil {
+		s.notifier.notifyReady(promoted)
+	} else if s.cache != nil {
+		// A cancelled ready hold with nobody waiting frees the book
+		s.cache.InvalidateBook(hold.BookID)
+	}
+
+	return hold, nil
+}
+
+// ExpireReadyHolds expires holds that were not picked up in time
+func (s *holdService) ExpireReadyHolds() (int, error) {
+	expired, promoted, err := s.holdRepo.ExpireReady(time.Now(), s.policy.HoldPickupWindow)
+	if err != nil {
+		return 0, fmt.Errorf("failed to expire holds: %w", err)
+	}
+
+	if expired > 0 && s.cache != nil {
+		s.cache.Clear()
+	}
+	for i := range promoted {
+		s.notifier.notifyReady(&promoted[i])
+	}
+
+	return expired, nil
+}
+
+// HoldExpiryJob builds the worker job that runs a hold expiry sweep
+func HoldExpiryJob(holds HoldService) workers.BookJob {
+	return workers.BookJob{
+		ID:   uuid.New().String(),
+		Type: workers.JobTypeHoldExpiry,
+		Task: func(ctx context.Context) (string, error) {
+			expired, err := holds.ExpireReadyHolds()
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("Expired %d uncollected holds", expired), nil
+		},
+	}
+}
+
+// holdNotifier tells patrons their hold is ready via the background worker pool
+type holdNotifier struct {
+	holdRepo  repository.HoldRepository
+	processor *workers.BookProcessor
+}
+
+// notifyReady submits a JobTypeHoldReady job for the hold
+func (n *holdNotifier) notifyReady(hold *models.Hold) {
+	if n.processor == nil {
+		return
+	}
+
+	job := workers.BookJob{
+		ID:   uuid.New().String(),
+		Type: workers.JobTypeHoldReady,
+		Task: func(ctx context.Context) (string, error) {
+			log.Printf("Notifying member %s that book %s is ready for pickup until %s",
+				hold.MemberID, hold.BookID, hold.ExpiresAt.Format(time.RFC3339))
+			if err := n.holdRepo.MarkNotified(hold.ID); err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("Hold %s ready notification sent", hold.ID), nil
+		},
+	}
+
+	if err := n.processor.SubmitJob(job); err != nil {
+		log.Printf("Failed to submit hold notification for %s: %v", hold.ID, err)
+	}
+}
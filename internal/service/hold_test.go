@@ -0,0 +1,167 @@
+package service
+
+import (
+	"errors"
+	"libmngmt/internal/models"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockHoldRepository is a mock implementation of HoldRepository for testing
+type MockHoldRepository struct {
+	mock.Mock
+}
+
+func (m *MockHoldRepository) Place(bookID, memberID uuid.UUID) (*models.Hold, error) {
+	args := m.Called(bookID, memberID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Hold), args.Error(1)
+}
+
+func (m *MockHoldRepository) GetByID(id uuid.UUID) (*models.Hold, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Hold), args.Error(1)
+}
+
+func (m *MockHoldRepository) GetByBook(bookID uuid.UUID) ([]models.Hold, error) {
+	args := m.Called(bookID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Hold), args.Error(1)
+}
+
+func (m *MockHoldRepository) Cancel(id uuid.UUID, pickupWindow time.Duration) (*models.Hold, *models.Hold, error) {
+	args := m.Called(id, pickupWindow)
+	var hold, promoted *models.Hold
+	if args.Get(0) != nil {
+		hold = args.Get(0).(*models.Hold)
+	}
+	if args.Get(1) != nil {
+		promoted = args.Get(1).(*models.Hold)
+	}
+	return hold, promoted, args.Error(2)
+}
+
+func (m *MockHoldRepository) ExpireReady(now time.Time, pickupWindow time.Duration) (int, []models.Hold, error) {
+	args := m.Called(now, pickupWindow)
+	var promoted []models.Hold
+	if args.Get(1) != nil {
+		promoted = args.Get(1).([]models.Hold)
+	}
+	return args.Int(0), promoted, args.Error(2)
+}
+
+func (m *MockHoldRepository) MarkNotified(id uuid.UUID) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockHoldRepository) Release(bookID uuid.UUID, pickupWindow time.Duration) (*models.Hold, error) {
+	args := m.Called(bookID, pickupWindow)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Hold), args.Error(1)
+}
+
+func TestHoldService_PlaceHold(t *testing.T) {
+	t.Run("place hold for active member", func(t *testing.T) {
+		holdRepo := &MockHoldRepository{}
+		memberRepo := &MockMemberRepository{}
+		service := NewHoldService(holdRepo, memberRepo, nil, nil, testPolicy)
+
+		bookID := uuid.New()
+		member := activeMember()
+		memberRepo.On("GetByID", member.ID).Return(member, nil)
+		holdRepo.On("Place", bookID, member.ID).
+			Return(&models.Hold{BookID: bookID, MemberID: member.ID, Status: models.HoldStatusWaiting, Position: 1}, nil)
+
+		hold, err := service.PlaceHold(bookID, &models.PlaceHoldRequest{MemberID: member.ID})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, hold.Position)
+		holdRepo.AssertExpectations(t)
+	})
+
+	t.Run("member_id is required", func(t *testing.T) {
+		service := NewHoldService(&MockHoldRepository{}, &MockMemberRepository{}, nil, nil, testPolicy)
+
+		_, err := service.PlaceHold(uuid.New(), &models.PlaceHoldRequest{})
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "member_id is required")
+	})
+
+	t.Run("suspended member cannot place hold", func(t *testing.T) {
+		holdRepo := &MockHoldRepository{}
+		memberRepo := &MockMemberRepository{}
+		service := NewHoldService(holdRepo, memberRepo, nil, nil, testPolicy)
+
+		member := activeMember()
+		member.Status = models.MemberStatusSuspended
+		memberRepo.On("GetByID", member.ID).Return(member, nil)
+
+		_, err := service.PlaceHold(uuid.New(), &models.PlaceHoldRequest{MemberID: member.ID})
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot borrow")
+		holdRepo.AssertNotCalled(t, "Place", mock.Anything, mock.Anything)
+	})
+}
+
+func TestHoldService_CancelHold(t *testing.T) {
+	t.Run("cancel passes the pickup window to the repository", func(t *testing.T) {
+		holdRepo := &MockHoldRepository{}
+		service := NewHoldService(holdRepo, &MockMemberRepository{}, nil, nil, testPolicy)
+
+		id := uuid.New()
+		holdRepo.On("Cancel", id, testPolicy.HoldPickupWindow).
+			Return(&models.Hold{ID: id, Status: models.HoldStatusCancelled}, nil, nil)
+
+		hold, err := service.CancelHold(id)
+
+		assert.NoError(t, err)
+		assert.Equal(t, models.HoldStatusCancelled, hold.Status)
+		holdRepo.AssertExpectations(t)
+	})
+
+	t.Run("cancel inactive hold", func(t *testing.T) {
+		holdRepo := &MockHoldRepository{}
+		service := NewHoldService(holdRepo, &MockMemberRepository{}, nil, nil, testPolicy)
+
+		id := uuid.New()
+		holdRepo.On("Cancel", id, testPolicy.HoldPickupWindow).
+			Return(nil, nil, errors.New("hold is already fulfilled"))
+
+		_, err := service.CancelHold(id)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "hold is already fulfilled")
+	})
+}
+
+func TestHoldService_ExpireReadyHolds(t *testing.T) {
+	t.Run("expiry sweep reports expired count", func(t *testing.T) {
+		holdRepo := &MockHoldRepository{}
+		service := NewHoldService(holdRepo, &MockMemberRepository{}, nil, nil, testPolicy)
+
+		holdRepo.On("ExpireReady", mock.AnythingOfType("time.Time"), testPolicy.HoldPickupWindow).
+			Return(2, []models.Hold{}, nil)
+
+		expired, err := service.ExpireReadyHolds()
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, expired)
+		holdRepo.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,27 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"libmngmt/internal/repository"
+	"libmngmt/internal/workers"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// IdempotencyKeyPurgeJob builds the worker job that deletes idempotency keys
+// whose stored responses are no longer replayed
+func IdempotencyKeyPurgeJob(keys repository.IdempotencyRepository) workers.BookJob {
+	return workers.BookJob{
+		ID:   uuid.New().String(),
+		Type: workers.JobTypeIdempotencyPurge,
+		Task: func(ctx context.Context) (string, error) {
+			deleted, err := keys.DeleteExpired(time.Now())
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("Deleted %d expired idempotency keys", deleted), nil
+		},
+	}
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"fmt"
+	"libmngmt/internal/cache"
+	"libmngmt/internal/models"
+	"libmngmt/internal/repository"
+	"libmngmt/internal/workers"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CirculationPolicy holds the lending rules applied by the loan and hold services
+type CirculationPolicy struct {
+	// LoanPeriod is used when a checkout request does not specify a due date
+	LoanPeriod time.Duration
+	// HoldPickupWindow is how long a freed copy is set aside for a hold
+	HoldPickupWindow time.Duration
+}
+
+// LoanService defines the interface for circulation (checkout/return) logic
+type LoanService interface {
+	CheckoutBook(bookID uuid.UUID, req *models.CheckoutRequest) (*models.Loan, error)
+	ReturnBook(bookID uuid.UUID, req *models.ReturnRequest) (*models.Loan, error)
+	GetMemberLoans(memberID uuid.UUID, openOnly bool) ([]models.Loan, error)
+}
+
+// loanService implements LoanService interface
+type loanService struct {
+	loanRepo   repository.LoanRepository
+	memberRepo repository.MemberRepository
+	cache      *cache.BookCache
+	notifier   *holdNotifier
+	policy     CirculationPolicy
+}
+
+// NewLoanService creates a new loan service
+func NewLoanService(loanRepo repository.LoanRepository, memberRepo repository.MemberRepository, holdRepo repository.HoldRepository, cache *cache.BookCache, processor *workers.BookProcessor, policy CirculationPolicy) LoanService {
+	return &loanService{
+		loanRepo:   loanRepo,
+		memberRepo: memberRepo,
+		cache:      cache,
+		notifier:   &holdNotifier{holdRepo: holdRepo, processor: processor},
+		policy:     policy,
+	}
+}
+
+// CheckoutBook lends a book to an eligible member
+func (s *loanService) CheckoutBook(bookID uuid.UUID, req *models.CheckoutRequest) (*models.Loan, error) {
+	if req.MemberID == uuid.Nil {
+		return nil, fmt.Errorf("member_id is required")
+	}
+
+	member, err := s.memberRepo.GetByID(req.MemberID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get member: %w", err)
+	}
+	if err := checkMemberCanBorrow(member); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	dueAt := now.Add(s.policy.LoanPeriod)
+	if req.DueAt != nil {
+		if !req.DueAt.After(now) {
+			return nil, fmt.Errorf("invalid due_at: must be in the future")
+		}
+		dueAt = *req.DueAt
+	}
+
+	loan, err := s.loanRepo.Checkout(bookID, member.ID, dueAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to checkout book: %w", err)
+	}
+
+	s.invalidateBook(bookID)
+
+	return loan, nil
+}
+
+// ReturnBook closes an open loan of a book and notifies the next hold, if any
+func (s *loanService) ReturnBook(bookID uuid.UUID, req *models.ReturnRequest) (*models.Loan, error) {
+	loan, hold, err := s.loanRepo.Return(bookID, req.CopyID, s.policy.HoldPickupWindow)
+	if err != nil {
+		return nil, fmt.Errorf("failed to return book: %w", err)
+	}
+
+	s.invalidateBook(bookID)
+
+	if hold != nil {
+		s.notifier.notifyReady(hold)
+	}
+
+	return loan, nil
+}
+
+// GetMemberLoans lists a member's loans
+func (s *loanService) GetMemberLoans(memberID uuid.UUID, openOnly bool) ([]models.Loan, error) {
+	if _, err := s.memberRepo.GetByID(memberID); err != nil {
+		return nil, fmt.Errorf("failed to get member: %w", err)
+	}
+
+	loans, err := s.loanRepo.GetByMember(memberID, openOnly)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get loans: %w", err)
+	}
+	return loans, nil
+}
+
+// invalidateBook drops cached copies of a book whose availability changed
+func (s *loanService) invalidateBook(bookID uuid.UUID) {
+	if s.cache != nil {
+		s.cache.InvalidateBook(bookID)
+	}
+}
+
+// checkMemberCanBorrow verifies the member's account allows new loans
+func checkMemberCanBorrow(member *models.Member) error {
+	if member.Status != models.MemberStatusActive {
+		return fmt.Errorf("member cannot borrow: membership is %s", member.Status)
+	}
+	if !member.ExpiresAt.IsZero() && member.ExpiresAt.Before(time.Now()) {
+		return fmt.Errorf("member cannot borrow: membership expired on %s", member.ExpiresAt.Format("2006-01-02"))
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"fmt"
+	"libmngmt/internal/models"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockLoanRepository is a mock implementation of repository.LoanRepository
+type MockLoanRepository struct {
+	mock.Mock
+}
+
+func (m *MockLoanRepository) Checkout(bookID, memberID uuid.UUID, dueAt time.Time) (*models.Loan, error) {
+	args := m.Called(bookID, memberID, dueAt)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Loan), args.Error(1)
+}
+
+func (m *MockLoanRepository) Return(bookID uuid.UUID, copyID *uuid.UUID, pickupWindow time.Duration) (*models.Loan, *models.Hold, error) {
+	args := m.Called(bookID, copyID, pickupWindow)
+	var loan *models.Loan
+	if args.Get(0) != nil {
+		loan = args.Get(0).(*models.Loan)
+	}
+	var hold *models.Hold
+	if args.Get(1) != nil {
+		hold = args.Get(1).(*models.Hold)
+	}
+	return loan, hold, args.Error(2)
+}
+
+func (m *MockLoanRepository) GetOpenByBook(bookID uuid.UUID) (*models.Loan, error) {
+	args := m.Called(bookID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Loan), args.Error(1)
+}
+
+func (m *MockLoanRepository) GetByMember(memberID uuid.UUID, openOnly bool) ([]models.Loan, error) {
+	args := m.Called(memberID, openOnly)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Loan), args.Error(1)
+}
+
+var testPolicy = CirculationPolicy{
+	LoanPeriod:       14 * 24 * time.Hour,
+	HoldPickupWindow: 3 * 24 * time.Hour,
+}
+
+func activeMember() *models.Member {
+	return &models.Member{
+		ID:        uuid.New(),
+		Status:    models.MemberStatusActive,
+		ExpiresAt: time.Now().AddDate(1, 0, 0),
+	}
+}
+
+func TestLoanService_CheckoutBook(t *testing.T) {
+	t.Run("checkout applies default loan period", func(t *testing.T) {
+		loanRepo := &MockLoanRepository{}
+		memberRepo := &MockMemberRepository{}
+		service := NewLoanService(loanRepo, memberRepo, nil, nil, nil, testPolicy)
+
+		bookID := uuid.New()
+		member := activeMember()
+
+		memberRepo.On("GetByID", member.ID).Return(member, nil)
+		loanRepo.On("Checkout", bookID, member.ID, mock.MatchedBy(func(due time.Time) bool {
+			return due.After(time.Now().Add(13*24*time.Hour)) && due.Before(time.Now().Add(15*24*time.Hour))
+		})).Return(&models.Loan{BookID: &bookID, MemberID: member.ID}, nil)
+
+		loan, err := service.CheckoutBook(bookID, &models.CheckoutRequest{MemberID: member.ID})
+
+		assert.NoError(t, err)
+		assert.Equal(t, bookID, *loan.BookID)
+		loanRepo.AssertExpectations(t)
+		memberRepo.AssertExpectations(t)
+	})
+
+	t.Run("suspended member cannot borrow", func(t *testing.T) {
+		loanRepo := &MockLoanRepository{}
+		memberRepo := &MockMemberRepository{}
+		service := NewLoanService(loanRepo, memberRepo, nil, nil, nil, testPolicy)
+
+		member := activeMember()
+		member.Status = models.MemberStatusSuspended
+		memberRepo.On("GetByID", member.ID).Return(member, nil)
+
+		_, err := service.CheckoutBook(uuid.New(), &models.CheckoutRequest{MemberID: member.ID})
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot borrow")
+		loanRepo.AssertNotCalled(t, "Checkout", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("checkout of a book already on loan", func(t *testing.T) {
+		loanRepo := &MockLoanRepository{}
+		memberRepo := &MockMemberRepository{}
+		service := NewLoanService(loanRepo, memberRepo, nil, nil, nil, testPolicy)
+
+		bookID := uuid.New()
+		member := activeMember()
+		memberRepo.On("GetByID", member.ID).Return(member, nil)
+		loanRepo.On("Checkout", bookID, member.ID, mock.Anything).Return(nil, fmt.Errorf("book is already checked out"))
+
+		_, err := service.CheckoutBook(bookID, &models.CheckoutRequest{MemberID: member.ID})
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "already checked out")
+	})
+
+	t.Run("checkout requires member id", func(t *testing.T) {
+		service := NewLoanService(&MockLoanRepository{}, &MockMemberRepository{}, nil, nil, nil, testPolicy)
+
+		_, err := service.CheckoutBook(uuid.New(), &models.CheckoutRequest{})
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "member_id is required")
+	})
+}
+
+func TestLoanService_ReturnBook(t *testing.T) {
+	t.Run("return passes the copy and pickup window to the repository", func(t *testing.T) {
+		loanRepo := &MockLoanRepository{}
+		service := NewLoanService(loanRepo, &MockMemberRepository{}, nil, nil, nil, testPolicy)
+
+		bookID := uuid.New()
+		copyID := uuid.New()
+		now := time.Now()
+		loanRepo.On("Return", bookID, &copyID, testPolicy.HoldPickupWindow).
+			Return(&models.Loan{BookID: &bookID, CopyID: &copyID, ReturnedAt: &now}, nil, nil)
+
+		loan, err := service.ReturnBook(bookID, &models.ReturnRequest{CopyID: &copyID})
+
+		assert.NoError(t, err)
+		assert.False(t, loan.IsOpen())
+		loanRepo.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,218 @@
+package service
+
+import (
+	"fmt"
+	"libmngmt/internal/models"
+	"libmngmt/internal/repository"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// defaultMembershipTerm is applied when a member is created without an expiry date
+const defaultMembershipTerm = 365 * 24 * time.Hour
+
+// MemberService defines the interface for member business logic
+type MemberService interface {
+	CreateMember(req *models.CreateMemberRequest) (*models.Member, error)
+	GetMemberByID(id uuid.UUID) (*models.Member, error)
+	GetAllMembers(filter models.MemberFilter) (*models.MembersListResponse, error)
+	UpdateMember(id uuid.UUID, req *models.UpdateMemberRequest) (*models.Member, error)
+	DeleteMember(id uuid.UUID) error
+}
+
+// memberService implements MemberService interface
+type memberService struct {
+	memberRepo repository.MemberRepository
+}
+
+// NewMemberService creates a new member service
+func NewMemberService(memberRepo repository.MemberRepository) MemberService {
+	return &memberService{memberRepo: memberRepo}
+}
+
+// CreateMember validates and creates a new member
+func (s *memberService) CreateMember(req *models.CreateMemberRequest) (*models.Member, error) {
+	s.normalizeMemberData(req)
+
+	if err := s.validateCreateRequest(req); err != nil {
+		return nil, err
+	}
+
+	exists, err := s.memberRepo.ExistsByCardNumber(req.CardNumber, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check card number uniqueness: %w", err)
+	}
+	if exists {
+		return nil, fmt.Errorf("member with card number %s already exists", req.CardNumber)
+	}
+
+	if req.Status == "" {
+		req.Status = models.MemberStatusActive
+	}
+	if req.ExpiresAt.IsZero() {
+		req.ExpiresAt = time.Now().Add(defaultMembershipTerm)
+	}
+
+	member, err := s.memberRepo.Create(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create member: %w", err)
+	}
+
+	return member, nil
+}
+
+// GetMemberByID retrieves a member by ID
+func (s *memberService) GetMemberByID(id uuid.UUID) (*models.Member, error) {
+	member, err := s.memberRepo.GetByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get member: %w", err)
+	}
+	return member, nil
+}
+
+// GetAllMembers retrieves members matching the filter
+func (s *memberService) GetAllMembers(filter models.MemberFilter) (*models.MembersListResponse, error) {
+	if filter.Limit <= 0 || filter.Limit > 100 {
+		filter.Limit = 50
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+	if filter.Status != "" && !filter.Status.IsValid() {
+		return nil, fmt.Errorf("invalid member status: %s", filter.Status)
+	}
+
+	members, total, err := s.memberRepo.GetAll(filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get members: %w", err)
+	}
+
+	return &models.MembersListResponse{
+		Members: members,
+		Total:   total,
+		Limit:   filter.Limit,
+		Offset:  filter.Offset,
+	}, nil
+}
+
+// UpdateMember validates and applies a partial update to a member
+func (s *memberService) UpdateMember(id uuid.UUID, req *models.UpdateMemberRequest) (*models.Member, error) {
+	if _, err := s.memberRepo.GetByID(id); err != nil {
+		return nil, fmt.Errorf("member not found: %w", err)
+	}
+
+	if err := s.validateUpdateRequest(req); err != nil {
+		return nil, err
+	}
+
+	if req.CardNumber != nil {
+		normalized := normalizeCardNumber(*req.CardNumber)
+		req.CardNumber = &normalized
+
+		exists, err := s.memberRepo.ExistsByCardNumber(normalized, &id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check card number uniqueness: %w", err)
+		}
+		if exists {
+			return nil, fmt.Errorf("member with card number %s already exists", normalized)
+		}
+	}
+
+	for _, field := range []*string{req.FirstName, req.LastName, req.Phone, req.Address} {
+		if field != nil {
+			*field = strings.TrimSpace(*field)
+		}
+	}
+	if req.Email != nil {
+		normalized := strings.ToLower(strings.TrimSpace(*req.Email))
+		req.Email = &normalized
+	}
+
+	member, err := s.memberRepo.Update(id, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update member: %w", err)
+	}
+
+	return member, nil
+}
+
+// DeleteMember removes a member
+func (s *memberService) DeleteMember(id uuid.UUID) error {
+	if _, err := s.memberRepo.GetByID(id); err != nil {
+		return fmt.Errorf("member not found: %w", err)
+	}
+
+	if err := s.memberRepo.Delete(id); err != nil {
+		return fmt.Errorf("failed to delete member: %w", err)
+	}
+
+	return nil
+}
+
+func (s *memberService) normalizeMemberData(req *models.CreateMemberRequest) {
+	req.CardNumber = normalizeCardNumber(req.CardNumber)
+	req.FirstName = strings.TrimSpace(req.FirstName)
+	req.LastName = strings.TrimSpace(req.LastName)
+	req.Email = strings.ToLower(strings.TrimSpace(req.Email))
+	req.Phone = strings.TrimSpace(req.Phone)
+	req.Address = strings.TrimSpace(req.Address)
+}
+
+// validateCreateRequest validates the create member request
+func (s *memberService) validateCreateRequest(req *models.CreateMemberRequest) error {
+	if req.CardNumber == "" {
+		return fmt.Errorf("card number is required")
+	}
+	if req.FirstName == "" {
+		return fmt.Errorf("first name is required")
+	}
+	if req.LastName == "" {
+		return fmt.Errorf("last name is required")
+	}
+	if req.Email == "" {
+		return fmt.Errorf("email is required")
+	}
+	if !isValidEmail(req.Email) {
+		return fmt.Errorf("invalid email format")
+	}
+	if req.Status != "" && !req.Status.IsValid() {
+		return fmt.Errorf("invalid member status: %s", req.Status)
+	}
+	return nil
+}
+
+// validateUpdateRequest validates the update member request
+func (s *memberService) validateUpdateRequest(req *models.UpdateMemberRequest) error {
+	if req.CardNumber != nil && strings.TrimSpace(*req.CardNumber) == "" {
+		return fmt.Errorf("card number cannot be empty")
+	}
+	if req.FirstName != nil && strings.TrimSpace(*req.FirstName) == "" {
+		return fmt.Errorf("first name cannot be empty")
+	}
+	if req.LastName != nil && strings.TrimSpace(*req.LastName) == "" {
+		return fmt.Errorf("last name cannot be empty")
+	}
+	if req.Email != nil && !isValidEmail(strings.TrimSpace(*req.Email)) {
+		return fmt.Errorf("invalid email format")
+	}
+	if req.Status != nil && !req.Status.IsValid() {
+		return fmt.Errorf("invalid member status: %s", *req.Status)
+	}
+	return nil
+}
+
+// normalizeCardNumber trims and upper-cases a library card number
+func normalizeCardNumber(cardNumber string) string {
+	return strings.ToUpper(strings.TrimSpace(cardNumber))
+}
+
+// isValidEmail performs a basic structural check of an email address
+func isValidEmail(email string) bool {
+	at := strings.LastIndex(email, "@")
+	if at <= 0 || at == len(email)-1 {
+		return false
+	}
+	return strings.Contains(email[at+1:], ".") && !strings.ContainsAny(email, " \t")
+}
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"libmngmt/internal/models"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockMemberRepository is a mock implementation of repository.MemberRepository
+type MockMemberRepository struct {
+	mock.Mock
+}
+
+func (m *MockMemberRepository) Create(member *models.CreateMemberRequest) (*models.Member, error) {
+	args := m.Called(member)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Member), args.Error(1)
+}
+
+func (m *MockMemberRepository) GetByID(id uuid.UUID) (*models.Member, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Member), args.Error(1)
+}
+
+func (m *MockMemberRepository) GetAll(filter models.MemberFilter) ([]models.Member, int, error) {
+	args := m.Called(filter)
+	if args.Get(0) == nil {
+		return nil, args.Int(1), args.Error(2)
+	}
+	return args.Get(0).([]models.Member), args.Int(1), args.Error(2)
+}
+
+func (m *MockMemberRepository) Update(id uuid.UUID, member *models.UpdateMemberRequest) (*models.Member, error) {
+	args := m.Called(id, member)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Member), args.Error(1)
+}
+
+func (m *MockMemberRepository) Delete(id uuid.UUID) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockMemberRepository) ExistsByCardNumber(cardNumber string, excludeID *uuid.UUID) (bool, error) {
+	args := m.Called(cardNumber, excludeID)
+	return args.Bool(0), args.Error(1)
+}
+
+func TestMemberService_CreateMember(t *testing.T) {
+	t.Run("create member normalizes and applies defaults", func(t *testing.T) {
+		mockRepo := &MockMemberRepository{}
+		service := NewMemberService(mockRepo)
+
+		req := &models.CreateMemberRequest{
+			CardNumber: " lib-0001 ",
+			FirstName:  " Ada ",
+			LastName:   "Lovelace",
+			Email:      "Ada@Example.com",
+		}
+
+		mockRepo.On("ExistsByCardNumber", "LIB-0001", (*uuid.UUID)(nil)).Return(false, nil)
+		mockRepo.On("Create", mock.MatchedBy(func(r *models.CreateMemberRequest) bool {
+			return r.CardNumber == "LIB-0001" && r.FirstName == "Ada" && r.Email == "ada@example.com" &&
+				r.Status == models.MemberStatusActive && r.ExpiresAt.After(time.Now())
+		})).Return(&models.Member{ID: uuid.New(), CardNumber: "LIB-0001"}, nil)
+
+		member, err := service.CreateMember(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "LIB-0001", member.CardNumber)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("create member with duplicate card number", func(t *testing.T) {
+		mockRepo := &MockMemberRepository{}
+		service := NewMemberService(mockRepo)
+
+		req := &models.CreateMemberRequest{
+			CardNumber: "LIB-0001",
+			FirstName:  "Ada",
+			LastName:   "Lovelace",
+			Email:      "ada@example.com",
+		}
+
+		mockRepo.On("ExistsByCardNumber", "LIB-0001", (*uuid.UUID)(nil)).Return(true, nil)
+
+		member, err := service.CreateMember(req)
+
+		assert.Error(t, err)
+		assert.Nil(t, member)
+		assert.Contains(t, err.Error(), "already exists")
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("create member with invalid email", func(t *testing.T) {
+		service := NewMemberService(&MockMemberRepository{})
+
+		_, err := service.CreateMember(&models.CreateMemberRequest{
+			CardNumber: "LIB-0001",
+			FirstName:  "Ada",
+			LastName:   "Lovelace",
+			Email:      "not-an-email",
+		})
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid email format")
+	})
+}
+
+func TestMemberService_UpdateMember(t *testing.T) {
+	t.Run("update member with invalid status", func(t *testing.T) {
+		mockRepo := &MockMemberRepository{}
+		service := NewMemberService(mockRepo)
+
+		id := uuid.New()
+		status := models.MemberStatus("banned")
+
+		mockRepo.On("GetByID", id).Return(&models.Member{ID: id}, nil)
+
+		_, err := service.UpdateMember(id, &models.UpdateMemberRequest{Status: &status})
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid member status")
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestMemberService_DeleteMember(t *testing.T) {
+	t.Run("delete missing member", func(t *testing.T) {
+		mockRepo := &MockMemberRepository{}
+		service := NewMemberService(mockRepo)
+
+		id := uuid.New()
+		mockRepo.On("GetByID", id).Return(nil, assert.AnError)
+
+		err := service.DeleteMember(id)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "member not found")
+		mockRepo.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"libmngmt/internal/metadata"
+	"libmngmt/internal/models"
+	"log"
+	"strings"
+	"time"
+)
+
+// metadataLookupTimeout bounds how long book creation waits on a provider
+const metadataLookupTimeout = 5 * time.Second
+
+// MetadataProvider looks up bibliographic data for an ISBN. Implementations
+// return metadata.ErrNotFound when they have no record.
+type MetadataProvider interface {
+	Lookup(ctx context.Context, isbn string) (*models.BookMetadata, error)
+}
+
+// EnrichBook fills the missing fields of a create request from the metadata
+// provider without creating the book
+func (s *bookService) EnrichBook(req *models.CreateBookRequest) (*models.EnrichBookResponse, error) {
+	if s.metadata == nil {
+		return nil, fmt.Errorf("metadata provider is not configured")
+	}
+	if strings.TrimSpace(req.ISBN) == "" {
+		return nil, fmt.Errorf("ISBN is required")
+	}
+	if err := validateISBNChecksum(req.ISBN); err != nil {
+		return nil, err
+	}
+	req.ISBN = normalizeISBN(req.ISBN)
+
+	meta, err := s.lookupMetadata(req.ISBN)
+	if err != nil {
+		if errors.Is(err, metadata.ErrNotFound) {
+			return nil, fmt.Errorf("metadata for ISBN %s not found", req.ISBN)
+		}
+		return nil, fmt.Errorf("metadata lookup failed: %w", err)
+	}
+
+	filled := applyMetadata(req, meta)
+	return &models.EnrichBookResponse{Book: *req, Filled: filled}, nil
+}
+
+// enrichFromMetadata fills missing fields before a book is created. Lookup
+// failures are logged and never block creation.
+func (s *bookService) enrichFromMetadata(req *models.CreateBookRequest) {
+	if s.metadata == nil || validateISBNChecksum(req.ISBN) != nil {
+		return
+	}
+
+	meta, err := s.lookupMetadata(normalizeISBN(req.ISBN))
+	if err != nil {
+		if !errors.Is(err, metadata.ErrNotFound) {
+			log.Printf("Metadata lookup for ISBN %s failed: %v", req.ISBN, err)
+		}
+		return
+	}
+
+	applyMetadata(req, meta)
+}
+
+func (s *bookService) lookupMetadata(isbn string) (*models.BookMetadata, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), metadataLookupTimeout)
+	defer cancel()
+	return s.metadata.Lookup(ctx, isbn)
+}
+
+// applyMetadata copies metadata into the fields of req that were left empty
+// and returns the JSON names of the fields it filled
+func applyMetadata(req *models.CreateBookRequest, meta *models.BookMetadata) []string {
+	filled := make([]string, 0)
+
+	fillString := func(name string, field *string, value string) {
+		if strings.TrimSpace(*field) == "" && value != "" {
+			*field = value
+			filled = append(filled, name)
+		}
+	}
+
+	fillString("title", &req.Title, meta.Title)
+	fillString("author", &req.Author, meta.Author)
+	fillString("publisher", &req.Publisher, meta.Publisher)
+	fillString("genre", &req.Genre, meta.Genre)
+	fillString("language", &req.Language, meta.Language)
+
+	if req.PublishedAt.IsZero() && !meta.PublishedAt.IsZero() {
+		req.PublishedAt = meta.PublishedAt
+		filled = append(filled, "published_at")
+	}
+	if req.Pages <= 0 && meta.Pages > 0 {
+		req.Pages = meta.Pages
+		filled = append(filled, "pages")
+	}
+
+	return filled
+}
@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"libmngmt/internal/metadata"
+	"libmngmt/internal/models"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockMetadataProvider is a mock implementation of MetadataProvider
+type MockMetadataProvider struct {
+	mock.Mock
+}
+
+func (m *MockMetadataProvider) Lookup(ctx context.Context, isbn string) (*models.BookMetadata, error) {
+	args := m.Called(isbn)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.BookMetadata), args.Error(1)
+}
+
+func testMetadata() *models.BookMetadata {
+	return &models.BookMetadata{
+		ISBN:        "9780261102354",
+		Title:       "The Fellowship of the Ring",
+		Author:      "J.R.R. Tolkien",
+		Publisher:   "HarperCollins",
+		Genre:       "Fantasy",
+		PublishedAt: time.Date(1954, 7, 29, 0, 0, 0, 0, time.UTC),
+		Pages:       423,
+		Language:    "English",
+	}
+}
+
+func completeCreateRequest() *models.CreateBookRequest {
+	return &models.CreateBookRequest{
+		Title:       "Test Book",
+		Author:      "Test Author",
+		ISBN:        "9781234567897",
+		Publisher:   "Test Publisher",
+		Genre:       "Fiction",
+		PublishedAt: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+		Pages:       300,
+		Language:    "English",
+	}
+}
+
+func TestApplyMetadata(t *testing.T) {
+	t.Run("fills only empty fields", func(t *testing.T) {
+		req := &models.CreateBookRequest{
+			Title: "Fellowship",
+			ISBN:  "9780261102354",
+			Pages: 500,
+		}
+
+		filled := applyMetadata(req, testMetadata())
+
+		assert.Equal(t, "Fellowship", req.Title)
+		assert.Equal(t, 500, req.Pages)
+		assert.Equal(t, "J.R.R. Tolkien", req.Author)
+		assert.Equal(t, "HarperCollins", req.Publisher)
+		assert.Equal(t, 1954, req.PublishedAt.Year())
+		assert.Equal(t, []string{"author", "publisher", "genre", "language", "published_at"}, filled)
+	})
+
+	t.Run("complete request is left untouched", func(t *testing.T) {
+		req := completeCreateRequest()
+		before := *req
+
+		filled := applyMetadata(req, testMetadata())
+
+		assert.Empty(t, filled)
+		assert.Equal(t, before, *req)
+	})
+}
+
+func TestBookService_EnrichBook(t *testing.T) {
+	t.Run("enrich from ISBN-10", func(t *testing.T) {
+		provider := &MockMetadataProvider{}
+		service := NewBookService(&MockBookRepository{}, nil, nil, provider, nil)
+
+		provider.On("Lookup", "9780261102354").Return(testMetadata(), nil)
+
+		resp, err := service.EnrichBook(&models.CreateBookRequest{ISBN: "0-261-10235-4"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "9780261102354", resp.Book.ISBN)
+		assert.Equal(t, "The Fellowship of the Ring", resp.Book.Title)
+		assert.Contains(t, resp.Filled, "title")
+		provider.AssertExpectations(t)
+	})
+
+	t.Run("provider not configured", func(t *testing.T) {
+		service := NewBookService(&MockBookRepository{}, nil, nil, nil, nil)
+
+		_, err := service.EnrichBook(&models.CreateBookRequest{ISBN: "9780261102354"})
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "not configured")
+	})
+
+	t.Run("invalid ISBN", func(t *testing.T) {
+		provider := &MockMetadataProvider{}
+		service := NewBookService(&MockBookRepository{}, nil, nil, provider, nil)
+
+		_, err := service.EnrichBook(&models.CreateBookRequest{ISBN: "9780261102355"})
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid ISBN")
+		provider.AssertNotCalled(t, "Lookup", mock.Anything)
+	})
+
+	t.Run("unknown ISBN", func(t *testing.T) {
+		provider := &MockMetadataProvider{}
+		service := NewBookService(&MockBookRepository{}, nil, nil, provider, nil)
+
+		provider.On("Lookup", "9780261102354").Return(nil, metadata.ErrNotFound)
+
+		_, err := service.EnrichBook(&models.CreateBookRequest{ISBN: "9780261102354"})
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "not found")
+	})
+
+	t.Run("provider failure", func(t *testing.T) {
+		provider := &MockMetadataProvider{}
+		service := NewBookService(&MockBookRepository{}, nil, nil, provider, nil)
+
+		provider.On("Lookup", "9780261102354").Return(nil, errors.New("connection refused"))
+
+		_, err := service.EnrichBook(&models.CreateBookRequest{ISBN: "9780261102354"})
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "metadata lookup failed")
+	})
+}
+
+func TestBookService_CreateBookWithMetadata(t *testing.T) {
+	t.Run("missing fields are filled before validation", func(t *testing.T) {
+		mockRepo := &MockBookRepository{}
+		provider := &MockMetadataProvider{}
+		service := NewBookService(mockRepo, nil, nil, provider, nil)
+
+		req := &models.CreateBookRequest{ISBN: "9780261102354"}
+
+		provider.On("Lookup", "9780261102354").Return(testMetadata(), nil)
+		mockRepo.On("ExistsByISBN", "9780261102354", (*uuid.UUID)(nil)).Return(false, nil)
+		mockRepo.On("Create", mock.MatchedBy(func(r *models.CreateBookRequest) bool {
+			return r.Title == "The Fellowship of the Ring" && r.Author == "J.R.R. Tolkien" && r.Pages == 423
+		}), testAudit).Return(&models.Book{ID: uuid.New(), Title: "The Fellowship of the Ring"}, nil)
+
+		book, err := service.CreateBook(req, testAudit)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "The Fellowship of the Ring", book.Title)
+		mockRepo.AssertExpectations(t)
+		provider.AssertExpectations(t)
+	})
+
+	t.Run("lookup failure does not block creation", func(t *testing.T) {
+		mockRepo := &MockBookRepository{}
+		provider := &MockMetadataProvider{}
+		service := NewBookService(mockRepo, nil, nil, provider, nil)
+
+		req := completeCreateRequest()
+
+		provider.On("Lookup", req.ISBN).Return(nil, errors.New("timeout"))
+		mockRepo.On("ExistsByISBN", req.ISBN, (*uuid.UUID)(nil)).Return(false, nil)
+		mockRepo.On("Create", req, testAudit).Return(&models.Book{ID: uuid.New(), Title: req.Title}, nil)
+
+		book, err := service.CreateBook(req, testAudit)
+
+		assert.NoError(t, err)
+		assert.Equal(t, req.Title, book.Title)
+		mockRepo.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,339 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"libmngmt/internal/models"
+	"libmngmt/internal/query"
+	"libmngmt/internal/repository"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ArrivalMatcher checks a newly created book against saved searches and
+// alerts the owners of those it matches
+type ArrivalMatcher interface {
+	MatchNewArrival(ctx context.Context, book *models.Book) (int, error)
+}
+
+// SavedSearchService defines the interface for saved searches and their alerts
+type SavedSearchService interface {
+	ArrivalMatcher
+	CreateSavedSearch(req *models.CreateSavedSearchRequest) (*models.SavedSearch, error)
+	GetSavedSearch(id uuid.UUID) (*models.SavedSearch, error)
+	GetSavedSearches(owner string) ([]models.SavedSearch, error)
+	DeleteSavedSearch(id uuid.UUID) error
+	GetAlerts(owner string, unreadOnly bool) ([]models.SearchAlert, error)
+	MarkAlertRead(id uuid.UUID) (*models.SearchAlert, error)
+}
+
+// savedSearchService implements SavedSearchService interface
+type savedSearchService struct {
+	searchRepo repository.SavedSearchRepository
+	client     *http.Client
+}
+
+// NewSavedSearchService creates a new saved search service. The client
+// delivers webhooks and should carry a timeout; NewWebhookClient builds one
+// that cannot reach internal addresses.
+func NewSavedSearchService(searchRepo repository.SavedSearchRepository, client *http.Client) SavedSearchService {
+	return &savedSearchService{searchRepo: searchRepo, client: client}
+}
+
+// CreateSavedSearch validates and saves a search
+func (s *savedSearchService) CreateSavedSearch(req *models.CreateSavedSearchRequest) (*models.SavedSearch, error) {
+	req.Owner = strings.TrimSpace(req.Owner)
+	req.Name = strings.TrimSpace(req.Name)
+	req.WebhookURL = strings.TrimSpace(req.WebhookURL)
+
+	if req.Owner == "" {
+		return nil, fmt.Errorf("owner is required")
+	}
+	if len(req.Owner) > 255 {
+		return nil, fmt.Errorf("invalid owner: must be at most 255 characters")
+	}
+	if req.Name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	if len(req.Name) > 100 {
+		return nil, fmt.Errorf("invalid name: must be at most 100 characters")
+	}
+	if req.WebhookURL != "" {
+		if err := validateWebhookURL(context.Background(), req.WebhookURL); err != nil {
+			return nil, err
+		}
+	}
+
+	filter, err := savedSearchFilter(req.Filter)
+	if err != nil {
+		return nil, err
+	}
+	req.Filter = filter
+
+	search, err := s.searchRepo.Create(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create saved search: %w", err)
+	}
+	return search, nil
+}
+
+// savedSearchFilter keeps the matching criteria of a filter, normalised and
+// validated the way GetAllBooks treats them
+func savedSearchFilter(filter models.BookFilter) (models.BookFilter, error) {
+	filter.Limit, filter.Offset = 0, 0
+	filter.Cursor, filter.Sort = "", ""
+	filter.Facets, filter.IncludeTotal = false, nil
+	filter.BookProjection = models.BookProjection{}
+
+	if filter.ISBN != "" {
+		filter.ISBN = normalizeISBN(filter.ISBN)
+	}
+	if filter.Fuzzy && (filter.Similarity <= 0 || filter.Similarity > 1) {
+		filter.Similarity = defaultSimilarityThreshold
+	}
+	if err := validateBookFilterRanges(filter); err != nil {
+		return filter, err
+	}
+
+	filter.Query = strings.TrimSpace(filter.Query)
+	if filter.Query != "" {
+		if _, err := query.Parse(filter.Query); err != nil {
+			return filter, err
+		}
+	}
+	return filter, nil
+}
+
+// validateWebhookURL accepts absolute http and https URLs whose host
+// resolves only to public addresses
+func validateWebhookURL(ctx context.Context, raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid webhook_url: must be an absolute http or https URL")
+	}
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, u.Hostname())
+	if err != nil || len(addrs) == 0 {
+		return fmt.Errorf("invalid webhook_url: host %s could not be resolved", u.Hostname())
+	}
+	for _, addr := range addrs {
+		if !isPublicIP(addr.IP) {
+			return fmt.Errorf("invalid webhook_url: host %s is not a public address", u.Hostname())
+		}
+	}
+	return nil
+}
+
+// isPublicIP reports whether ip may receive webhooks: loopback, private,
+// link-local (including cloud metadata at 169.254.169.254), multicast and
+// unspecified addresses are refused
+func isPublicIP(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast() || ip.IsUnspecified())
+}
+
+// NewWebhookClient returns a client for webhook delivery with the given
+// timeout. Every connection, including those made for redirects, is checked
+// after DNS resolution, so a saved host later pointed at an internal address
+// is still refused. Proxies from the environment are not used.
+func NewWebhookClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !isPublicIP(ip) {
+				return fmt.Errorf("webhook address %s is not public", host)
+			}
+			return nil
+		},
+	}
+
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+			MaxIdleConns:        10,
+			IdleConnTimeout:     90 * time.Second,
+		},
+	}
+}
+
+// GetSavedSearch retrieves a saved search
+func (s *savedSearchService) GetSavedSearch(id uuid.UUID) (*models.SavedSearch, error) {
+	search, err := s.searchRepo.GetByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get saved search: %w", err)
+	}
+	return search, nil
+}
+
+// GetSavedSearches lists an owner's saved searches
+func (s *savedSearchService) GetSavedSearches(owner string) ([]models.SavedSearch, error) {
+	owner = strings.TrimSpace(owner)
+	if owner == "" {
+		return nil, fmt.Errorf("owner is required")
+	}
+
+	searches, err := s.searchRepo.GetByOwner(owner)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get saved searches: %w", err)
+	}
+	return searches, nil
+}
+
+// DeleteSavedSearch removes a saved search and its alerts
+func (s *savedSearchService) DeleteSavedSearch(id uuid.UUID) error {
+	if err := s.searchRepo.Delete(id); err != nil {
+		return fmt.Errorf("failed to delete saved search: %w", err)
+	}
+	return nil
+}
+
+// GetAlerts lists an owner's alert inbox, optionally only unread alerts
+func (s *savedSearchService) GetAlerts(owner string, unreadOnly bool) ([]models.SearchAlert, error) {
+	owner = strings.TrimSpace(owner)
+	if owner == "" {
+		return nil, fmt.Errorf("owner is required")
+	}
+
+	alerts, err := s.searchRepo.GetAlerts(owner, unreadOnly)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get alerts: %w", err)
+	}
+	return alerts, nil
+}
+
+// MarkAlertRead marks an inbox alert as read
+func (s *savedSearchService) MarkAlertRead(id uuid.UUID) (*models.SearchAlert, error) {
+	alert, err := s.searchRepo.MarkAlertRead(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to mark alert read: %w", err)
+	}
+	return alert, nil
+}
+
+// MatchNewArrival raises an alert for every saved search the book matches and
+// delivers it to the search's webhook, if any. The searches are matched in
+// batches by the repository. Webhooks are delivered one after another on the
+// calling worker, each bounded by the client's timeout, so a book matching
+// many slow webhooks holds a BookProcessor worker for up to that many
+// timeouts. An alert that fails to store or deliver is logged and skipped so
+// the others are still alerted. It returns the number of alerts raised.
+func (s *savedSearchService) MatchNewArrival(ctx context.Context, book *models.Book) (int, error) {
+	searches, err := s.searchRepo.GetAll()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get saved searches: %w", err)
+	}
+	if len(searches) == 0 {
+		return 0, nil
+	}
+
+	matching, err := s.searchRepo.MatchingSearches(searches, book.ID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to match saved searches: %w", err)
+	}
+
+	alerted := 0
+	for i := range searches {
+		if ctx.Err() != nil {
+			return alerted, ctx.Err()
+		}
+
+		search := &searches[i]
+		if !matching[search.ID] {
+			continue
+		}
+
+		alert := &models.SearchAlert{
+			ID:            uuid.New(),
+			SavedSearchID: search.ID,
+			Owner:         search.Owner,
+			BookID:        book.ID,
+			BookTitle:     book.Title,
+			BookAuthor:    book.Author,
+			CreatedAt:     time.Now(),
+		}
+		created, err := s.searchRepo.CreateAlert(alert)
+		if err != nil {
+			log.Printf("Failed to store alert for saved search %s: %v", search.ID, err)
+			continue
+		}
+		if !created {
+			continue
+		}
+		alerted++
+
+		if search.WebhookURL != "" {
+			s.deliverWebhook(ctx, search, alert, book)
+		}
+	}
+
+	return alerted, nil
+}
+
+// deliverWebhook POSTs an alert to its search's webhook and records the outcome
+func (s *savedSearchService) deliverWebhook(ctx context.Context, search *models.SavedSearch, alert *models.SearchAlert, book *models.Book) {
+	var deliveredAt *time.Time
+	deliveryError := ""
+
+	if err := s.postWebhook(ctx, search, alert, book); err != nil {
+		log.Printf("Failed to deliver alert %s to %s: %v", alert.ID, search.WebhookURL, err)
+		deliveryError = err.Error()
+	} else {
+		now := time.Now()
+		deliveredAt = &now
+	}
+
+	if err := s.searchRepo.MarkAlertDelivered(alert.ID, deliveredAt, deliveryError); err != nil {
+		log.Printf("Failed to record delivery of alert %s: %v", alert.ID, err)
+	}
+}
+
+func (s *savedSearchService) postWebhook(ctx context.Context, search *models.SavedSearch, alert *models.SearchAlert, book *models.Book) error {
+	body, err := json.Marshal(models.NewArrivalWebhook{
+		Event:         models.NewArrivalEvent,
+		AlertID:       alert.ID,
+		SavedSearchID: search.ID,
+		SearchName:    search.Name,
+		Owner:         search.Owner,
+		Book:          *book,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, search.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := s.client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
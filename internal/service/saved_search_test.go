@@ -0,0 +1,285 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"libmngmt/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockSavedSearchRepository is a mock implementation of SavedSearchRepository for testing
+type MockSavedSearchRepository struct {
+	mock.Mock
+}
+
+func (m *MockSavedSearchRepository) Create(req *models.CreateSavedSearchRequest) (*models.SavedSearch, error) {
+	args := m.Called(req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SavedSearch), args.Error(1)
+}
+
+func (m *MockSavedSearchRepository) GetByID(id uuid.UUID) (*models.SavedSearch, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SavedSearch), args.Error(1)
+}
+
+func (m *MockSavedSearchRepository) GetByOwner(owner string) ([]models.SavedSearch, error) {
+	args := m.Called(owner)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.SavedSearch), args.Error(1)
+}
+
+func (m *MockSavedSearchRepository) GetAll() ([]models.SavedSearch, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.SavedSearch), args.Error(1)
+}
+
+func (m *MockSavedSearchRepository) Delete(id uuid.UUID) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockSavedSearchRepository) MatchingSearches(searches []models.SavedSearch, bookID uuid.UUID) (map[uuid.UUID]bool, error) {
+	args := m.Called(searches, bookID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[uuid.UUID]bool), args.Error(1)
+}
+
+func (m *MockSavedSearchRepository) CreateAlert(alert *models.SearchAlert) (bool, error) {
+	args := m.Called(alert)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockSavedSearchRepository) MarkAlertDelivered(id uuid.UUID, deliveredAt *time.Time, deliveryError string) error {
+	args := m.Called(id, deliveredAt, deliveryError)
+	return args.Error(0)
+}
+
+func (m *MockSavedSearchRepository) GetAlerts(owner string, unreadOnly bool) ([]models.SearchAlert, error) {
+	args := m.Called(owner, unreadOnly)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.SearchAlert), args.Error(1)
+}
+
+func (m *MockSavedSearchRepository) MarkAlertRead(id uuid.UUID) (*models.SearchAlert, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SearchAlert), args.Error(1)
+}
+
+func TestSavedSearchService_CreateSavedSearch(t *testing.T) {
+	t.Run("filter keeps matching criteria only", func(t *testing.T) {
+		repo := &MockSavedSearchRepository{}
+		service := NewSavedSearchService(repo, nil)
+
+		expected := &models.CreateSavedSearchRequest{
+			Owner:  "member-42",
+			Name:   "Go books",
+			Filter: models.BookFilter{Genre: "programming", ISBN: "9780306406157", Query: "pages>300"},
+		}
+		repo.On("Create", expected).Return(&models.SavedSearch{ID: uuid.New(), Owner: "member-42"}, nil)
+
+		_, err := service.CreateSavedSearch(&models.CreateSavedSearchRequest{
+			Owner: " member-42 ",
+			Name:  "Go books",
+			Filter: models.BookFilter{
+				Genre: "programming", ISBN: "0-306-40615-2", Query: " pages>300 ",
+				Limit: 10, Offset: 20, Sort: "title", Facets: true,
+			},
+		})
+
+		assert.NoError(t, err)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("invalid input is rejected", func(t *testing.T) {
+		repo := &MockSavedSearchRepository{}
+		service := NewSavedSearchService(repo, nil)
+		minPages, maxPages := 500, 100
+
+		requests := map[string]*models.CreateSavedSearchRequest{
+			"owner is required":       {Name: "Go books"},
+			"name is required":        {Owner: "member-42"},
+			"invalid webhook_url":     {Owner: "member-42", Name: "Go books", WebhookURL: "ftp://example.com/hook"},
+			"is not a public address": {Owner: "member-42", Name: "Go books", WebhookURL: "http://169.254.169.254/latest/meta-data"},
+			"invalid filter":          {Owner: "member-42", Name: "Go books", Filter: models.BookFilter{MinPages: &minPages, MaxPages: &maxPages}},
+			"unknown field":           {Owner: "member-42", Name: "Go books", Filter: models.BookFilter{Query: "rating>4"}},
+		}
+		for message, req := range requests {
+			_, err := service.CreateSavedSearch(req)
+			assert.Error(t, err, message)
+			assert.Contains(t, err.Error(), message)
+		}
+
+		repo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+}
+
+func TestSavedSearchService_MatchNewArrival(t *testing.T) {
+	t.Run("matching searches are alerted and webhooks delivered", func(t *testing.T) {
+		var received models.NewArrivalWebhook
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPost, r.Method)
+			assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		repo := &MockSavedSearchRepository{}
+		service := NewSavedSearchService(repo, server.Client())
+
+		book := &models.Book{ID: uuid.New(), Title: "The Go Programming Language", Author: "Alan Donovan"}
+		inbox := models.SavedSearch{ID: uuid.New(), Owner: "member-1", Filter: models.BookFilter{Genre: "programming"}}
+		webhook := models.SavedSearch{ID: uuid.New(), Owner: "member-2", Name: "Go", Filter: models.BookFilter{Query: "title:go"}, WebhookURL: server.URL}
+		other := models.SavedSearch{ID: uuid.New(), Owner: "member-3", Filter: models.BookFilter{Genre: "poetry"}}
+
+		searches := []models.SavedSearch{inbox, webhook, other}
+		repo.On("GetAll").Return(searches, nil)
+		repo.On("MatchingSearches", searches, book.ID).Return(map[uuid.UUID]bool{inbox.ID: true, webhook.ID: true}, nil)
+		repo.On("CreateAlert", mock.MatchedBy(func(alert *models.SearchAlert) bool {
+			return alert.BookID == book.ID && alert.BookTitle == book.Title
+		})).Return(true, nil).Twice()
+		repo.On("MarkAlertDelivered", mock.Anything, mock.AnythingOfType("*time.Time"), "").Return(nil).Once()
+
+		alerted, err := service.MatchNewArrival(context.Background(), book)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, alerted)
+		assert.Equal(t, models.NewArrivalEvent, received.Event)
+		assert.Equal(t, webhook.ID, received.SavedSearchID)
+		assert.Equal(t, book.ID, received.Book.ID)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("failed webhook is recorded and other searches are still alerted", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		repo := &MockSavedSearchRepository{}
+		service := NewSavedSearchService(repo, server.Client())
+
+		book := &models.Book{ID: uuid.New(), Title: "Dune"}
+		broken := models.SavedSearch{ID: uuid.New(), Filter: models.BookFilter{Query: "title:dune"}}
+		hooked := models.SavedSearch{ID: uuid.New(), Filter: models.BookFilter{Genre: "sf"}, WebhookURL: server.URL}
+		alertFor := func(search models.SavedSearch) interface{} {
+			return mock.MatchedBy(func(alert *models.SearchAlert) bool { return alert.SavedSearchID == search.ID })
+		}
+
+		searches := []models.SavedSearch{broken, hooked}
+		repo.On("GetAll").Return(searches, nil)
+		repo.On("MatchingSearches", searches, book.ID).Return(map[uuid.UUID]bool{broken.ID: true, hooked.ID: true}, nil)
+		repo.On("CreateAlert", alertFor(broken)).Return(false, errors.New("connection reset"))
+		repo.On("CreateAlert", alertFor(hooked)).Return(true, nil)
+		repo.On("MarkAlertDelivered", mock.Anything, (*time.Time)(nil), "webhook returned status 500").Return(nil)
+
+		alerted, err := service.MatchNewArrival(context.Background(), book)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, alerted)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("already alerted book is not delivered again", func(t *testing.T) {
+		repo := &MockSavedSearchRepository{}
+		service := NewSavedSearchService(repo, nil)
+
+		book := &models.Book{ID: uuid.New()}
+		search := models.SavedSearch{ID: uuid.New(), WebhookURL: "http://127.0.0.1:1/unreachable"}
+
+		repo.On("GetAll").Return([]models.SavedSearch{search}, nil)
+		repo.On("MatchingSearches", mock.Anything, book.ID).Return(map[uuid.UUID]bool{search.ID: true}, nil)
+		repo.On("CreateAlert", mock.Anything).Return(false, nil)
+
+		alerted, err := service.MatchNewArrival(context.Background(), book)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0, alerted)
+		repo.AssertNotCalled(t, "MarkAlertDelivered", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("failed matching alerts nobody", func(t *testing.T) {
+		repo := &MockSavedSearchRepository{}
+		service := NewSavedSearchService(repo, nil)
+
+		book := &models.Book{ID: uuid.New()}
+		repo.On("GetAll").Return([]models.SavedSearch{{ID: uuid.New()}}, nil)
+		repo.On("MatchingSearches", mock.Anything, book.ID).Return(nil, errors.New("connection reset"))
+
+		alerted, err := service.MatchNewArrival(context.Background(), book)
+
+		assert.Error(t, err)
+		assert.Equal(t, 0, alerted)
+		repo.AssertNotCalled(t, "CreateAlert", mock.Anything)
+	})
+}
+
+func TestNewWebhookClient(t *testing.T) {
+	t.Run("internal addresses are refused at delivery", func(t *testing.T) {
+		delivered := false
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			delivered = true
+		}))
+		defer server.Close()
+
+		resp, err := NewWebhookClient(time.Second).Post(server.URL, "application/json", nil)
+		if resp != nil {
+			resp.Body.Close()
+		}
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "is not public")
+		assert.False(t, delivered)
+	})
+}
+
+func TestSavedSearchService_GetAlerts(t *testing.T) {
+	t.Run("owner is required", func(t *testing.T) {
+		service := NewSavedSearchService(&MockSavedSearchRepository{}, nil)
+
+		_, err := service.GetAlerts(" ", false)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "owner is required")
+	})
+}
+
+func TestNewArrivalTask(t *testing.T) {
+	t.Run("created book is matched against saved searches", func(t *testing.T) {
+		repo := &MockSavedSearchRepository{}
+		book := &models.Book{ID: uuid.New()}
+		repo.On("GetAll").Return([]models.SavedSearch{}, nil)
+
+		message, err := newArrivalTask(NewSavedSearchService(repo, nil), book)(context.Background())
+
+		assert.NoError(t, err)
+		assert.Contains(t, message, "matched 0 saved searches")
+		repo.AssertExpectations(t)
+	})
+}
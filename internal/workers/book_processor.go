@@ -26,6 +26,9 @@ type BookJob struct {
 	BookData   *models.CreateBookRequest
 	UpdateData *models.UpdateBookRequest
 	Callback   func(BookResult)
+	// Task, when set, performs the job's work in place of the built-in
+	// handling for its type; the returned message is reported in the result
+	Task func(ctx context.Context) (string, error)
 }
 
 // JobType defines the type of operation
@@ -35,6 +38,11 @@ const (
 	JobTypeValidate JobType = iota
 	JobTypeProcess
 	JobTypeNotify
+	JobTypeHoldReady
+	JobTypeHoldExpiry
+	JobTypeFineAccrual
+	JobTypeTrashPurge
+	JobTypeIdempotencyPurge
 )
 
 // BookResult represents the result of a job
@@ -96,6 +104,31 @@ func (bp *BookProcessor) SubmitJob(job BookJob) error {
 	}
 }
 
+// Schedule submits a job produced by newJob every interval until the processor
+// is shut down. Ticks are skipped (and logged) while the queue is full. The
+// interval must be positive.
+func (bp *BookProcessor) Schedule(interval time.Duration, newJob func() BookJob) {
+	bp.wg.Add(1)
+	go func() {
+		defer bp.wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				job := newJob()
+				if err := bp.SubmitJob(job); err != nil {
+					log.Printf("Scheduler: failed to submit job %s: %v", job.ID, err)
+				}
+			case <-bp.ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
 // worker processes jobs from the job queue
 func (bp *BookProcessor) worker(id int) {
 	defer bp.wg.Done()
@@ -134,6 +167,16 @@ func (bp *BookProcessor) processJob(job BookJob) BookResult {
 		Success: true,
 	}
 
+	if job.Task != nil {
+		message, err := job.Task(bp.ctx)
+		result.Message = message
+		if err != nil {
+			result.Success = false
+			result.Error = err
+		}
+		return result
+	}
+
 	// Simulate processing time
 	processingTime := time.Millisecond * time.Duration(50+job.Type*10)
 	time.Sleep(processingTime)
@@ -39,9 +39,11 @@ run_test() {
     elif [ "$method" = "POST" ]; then
         status=$(curl -s -o "$temp_file" -w "%{http_code}" -X POST -H "Content-Type: application/json" -d "$data" "$url")
     elif [ "$method" = "PUT" ]; then
-        status=$(curl -s -o "$temp_file" -w "%{http_code}" -X PUT -H "Content-Type: application/json" -d "$data" "$url")
+        status=$(curl -s -o "$temp_file" -w "%{http_code}" -X PUT -H "Content-Type: application/json" -H "If-Match: *" -d "$data" "$url")
+    elif [ "$method" = "PATCH" ]; then
+        status=$(curl -s -o "$temp_file" -w "%{http_code}" -X PATCH -H "Content-Type: application/merge-patch+json" -H "If-Match: *" -d "$data" "$url")
     elif [ "$method" = "DELETE" ]; then
-        status=$(curl -s -o "$temp_file" -w "%{http_code}" -X DELETE "$url")
+        status=$(curl -s -o "$temp_file" -w "%{http_code}" -X DELETE -H "If-Match: *" "$url")
     fi
     
     local body=$(cat "$temp_file")
@@ -98,10 +100,18 @@ run_test "Create Book - Duplicate ISBN (Should Fail)" "POST" "$API_URL/books" '{
 # Test Validation Errors
 run_test "Create Book - Missing Title (Should Fail)" "POST" "$API_URL/books" '{
     "author": "Test Author",
-    "isbn": "9781234567890",
+    "isbn": "9781234567897",
     "pages": 100
 }' "400"
 
+# Atomic bulk create rejects the whole batch over a duplicate ISBN within it
+run_test "Bulk Create Atomic - Duplicate ISBN In Batch (Should Fail)" "POST" "$API_URL/books/bulk?atomic=true" '[
+    {"title": "Dune", "author": "Frank Herbert", "isbn": "9780441013593", "pages": 412},
+    {"title": "Dune", "author": "Frank Herbert", "isbn": "0441013597", "pages": 412}
+]' "400"
+
+run_test "Get Books - Rejected Batch Created Nothing" "GET" "$API_URL/books?isbn=9780441013593" "" "200"
+
 # Get All Books
 run_test "Get All Books" "GET" "$API_URL/books" "" "200"
 
@@ -119,14 +129,40 @@ if [ -n "$BOOK_ID" ]; then
     run_test "Get Book by ID" "GET" "$API_URL/books/$BOOK_ID" "" "200"
     
     # Update Book
-    run_test "Update Book - Mark as Unavailable" "PUT" "$API_URL/books/$BOOK_ID" '{
-        "available": false
+    run_test "Patch Book - Change Pages" "PATCH" "$API_URL/books/$BOOK_ID" '{
+        "pages": 320
     }' "200"
+
+    run_test "Replace Book - Missing Required Fields (Should Fail)" "PUT" "$API_URL/books/$BOOK_ID" '{
+        "pages": 320
+    }' "400"
+
+    run_test "Patch Book - Availability Is Read-Only (Should Fail)" "PATCH" "$API_URL/books/$BOOK_ID" '{
+        "available": false
+    }' "400"
+
+    run_test "Return Book Not Checked Out (Should Fail)" "POST" "$API_URL/books/$BOOK_ID/return" "" "409"
     
     # Delete Book
     run_test "Delete Book" "DELETE" "$API_URL/books/$BOOK_ID" "" "200"
     
     run_test "Get Deleted Book (Should Fail)" "GET" "$API_URL/books/$BOOK_ID" "" "404"
+
+    run_test "List Trash" "GET" "$API_URL/books/trash" "" "200"
+
+    run_test "Get Book History" "GET" "$API_URL/books/$BOOK_ID/history" "" "200"
+
+    run_test "Get Book As Of - Missing Time (Should Fail)" "GET" "$API_URL/books/$BOOK_ID/as-of" "" "400"
+
+    run_test "Restore Book" "POST" "$API_URL/books/$BOOK_ID/restore" "" "200"
+
+    run_test "Restore Book Not In Trash (Should Fail)" "POST" "$API_URL/books/$BOOK_ID/restore" "" "404"
+
+    run_test "Revert Book - Invalid Revision (Should Fail)" "POST" "$API_URL/books/$BOOK_ID/revisions/0/revert" "" "400"
+
+    run_test "Revert Book Without If-Match (Should Fail)" "POST" "$API_URL/books/$BOOK_ID/revisions/1/revert" "" "428"
+
+    run_test "Delete Restored Book" "DELETE" "$API_URL/books/$BOOK_ID" "" "200"
 fi
 
 # Test Invalid Book ID
@@ -202,10 +238,10 @@ if [ "$book1_id" != "null" ]; then
     echo ""
     echo "✏️  Updating Book 1"
     echo "---"
-    update_response=$(curl -s -X PUT "$API_BASE/api/books/$book1_id" \
-      -H "Content-Type: application/json" \
+    update_response=$(curl -s -X PATCH "$API_BASE/api/books/$book1_id" \
+      -H "Content-Type: application/merge-patch+json" \
+      -H "If-Match: *" \
       -d '{
-        "available": false,
         "title": "The Hobbit: There and Back Again"
       }')
     echo "$update_response" | jq '.'
@@ -246,7 +282,7 @@ if [ "$book2_id" != "null" ]; then
     echo ""
     echo "🗑️  Deleting Book 2"
     echo "---"
-    delete_response=$(curl -s -X DELETE "$API_BASE/api/books/$book2_id")
+    delete_response=$(curl -s -X DELETE -H "If-Match: *" "$API_BASE/api/books/$book2_id")
     echo "$delete_response" | jq '.'
 fi
 